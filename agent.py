@@ -29,6 +29,10 @@ import pytz
 import time
 import threading
 
+# Version of the HTTP API advertised in /status, bumped on breaking changes
+# so that clients can adapt or warn
+API_VERSION = 2
+
 def init_config(config_path: str = '/data/config.yml') -> Dict[str, Any]:
     """Load configuration from YAML file"""
     try:
@@ -691,6 +695,12 @@ class TomAgent:
         
         tomlogger.info(f"POST /reset for client_type: {client_type}", self.username, "api", "agent")
         
+        if not TomLLM.is_valid_client_type(client_type):
+            return {
+                "status": "ERROR",
+                "message": f"Invalid 'client_type': {client_type!r}, expected lowercase letters, digits, '-' or '_'"
+            }
+        
         # Reset history for the specific client type with behavior tuning analysis
         self.tomllm.reset_history(client_type, self.mcp_client)
         
@@ -821,6 +831,7 @@ class TomAgent:
             return {
                 "status": "OK",
                 "message": "MCP modules status",
+                "api_version": API_VERSION,
                 "modules_count": len(modules_status),
                 "modules": modules_status
             }
@@ -829,7 +840,8 @@ class TomAgent:
             tomlogger.error(f"Error getting status: {str(e)}", self.username, "api", "agent")
             return {
                 "status": "ERROR",
-                "message": f"Error getting status: {str(e)}"
+                "message": f"Error getting status: {str(e)}",
+                "api_version": API_VERSION
             }
     
     @cherrypy.expose
@@ -852,6 +864,8 @@ class TomAgent:
         position = request_data.get('position')  # Optional GPS coordinates
         client_type = request_data.get('client_type', 'web')
         sound_enabled = request_data.get('sound_enabled', False)
+        # A stateless request neither reads nor extends the history of its client type
+        use_history = request_data.get('history', True) is not False
         
         if not user_request:
             return {
@@ -859,6 +873,12 @@ class TomAgent:
                 "message": "Missing 'request' field in request data"
             }
         
+        if not TomLLM.is_valid_client_type(client_type):
+            return {
+                "status": "ERROR",
+                "message": f"Invalid 'client_type': {client_type!r}, expected lowercase letters, digits, '-' or '_'"
+            }
+        
         try:
             # Get available MCP modules (ALWAYS include all configured services, even without tools)
             available_modules = []
@@ -890,16 +910,19 @@ class TomAgent:
                 available_modules=available_modules,
                 client_type=client_type,
                 personal_context=personal_context,
-                mcp_client=self.mcp_client
+                mcp_client=self.mcp_client,
+                use_history=use_history
             )
             
             if required_modules == ["reset_performed"]:
                 # Handle conversation reset - clear history for this client with behavior tuning analysis
-                self.tomllm.reset_history(client_type, self.mcp_client)
+                # A stateless request has no conversation to reset
+                if use_history:
+                    self.tomllm.reset_history(client_type, self.mcp_client)
                 return {
                     "status": "OK",
                     "response": "Salut ! Comment puis-je t'aider ?",
-                    "conversation_reset": True
+                    "conversation_reset": use_history
                 }
             
             # Note: User request will be added to history after successful response generation
@@ -968,7 +991,8 @@ class TomAgent:
                     weeknumber=weeknumber,
                     gps=gps,
                     personal_context=personal_context,
-                    use_json_format=True
+                    use_json_format=True,
+                    use_history=use_history
                 )
                 
                 # Get MCP prompt consigns after conversation construction but before tool execution
@@ -1013,7 +1037,8 @@ class TomAgent:
                         weeknumber=weeknumber,
                         gps=gps,
                         personal_context=personal_context,
-                        use_json_format=True
+                        use_json_format=True,
+                        use_history=use_history
                     )
                     
                     # Get fallback response
@@ -1023,8 +1048,9 @@ class TomAgent:
                         response_content = response.choices[0].message.content
                         
                         # Add user request and assistant response to history
-                        self.tomllm.add_user_request(client_type, user_request)
-                        self.tomllm.add_assistant_response(client_type, response_content)
+                        if use_history:
+                            self.tomllm.add_user_request(client_type, user_request)
+                            self.tomllm.add_assistant_response(client_type, response_content)
                         
                         if sound_enabled:
                             tts_response = self.tomllm.synthesize_tts_response(response_content)
@@ -1071,7 +1097,7 @@ class TomAgent:
                         max_iterations=10,
                         mcp_client=self.mcp_client,
                         client_type=client_type,
-                        track_history=True,
+                        track_history=use_history,
                         selected_modules=required_modules
                     )
                 )
@@ -1131,7 +1157,8 @@ class TomAgent:
                     weeknumber=weeknumber,
                     gps=gps,
                     personal_context=personal_context,
-                    use_json_format=True
+                    use_json_format=True,
+                    use_history=use_history
                 )
                 
                 # Get direct response
@@ -1141,8 +1168,9 @@ class TomAgent:
                     response_content = response.choices[0].message.content
                     
                     # Add user request and assistant response to history
-                    self.tomllm.add_user_request(client_type, user_request)
-                    self.tomllm.add_assistant_response(client_type, response_content)
+                    if use_history:
+                        self.tomllm.add_user_request(client_type, user_request)
+                        self.tomllm.add_assistant_response(client_type, response_content)
                     
                     if sound_enabled:
                         tts_response = self.tomllm.synthesize_tts_response(response_content)
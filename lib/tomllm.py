@@ -5,6 +5,7 @@ Handles LLM interactions and MCP server communication for Tom Agent
 
 import json
 import os
+import re
 import time
 import copy
 import threading
@@ -18,6 +19,11 @@ from typing import Dict, Any, Optional, List
 from litellm import completion
 import tomlogger
 
+# Client types other than the built-in ones (telegram, email, nvim...) get a
+# history of their own on their first message, when their name matches
+CLIENT_TYPE_PATTERN = re.compile(r'^[a-z][a-z0-9_-]{0,31}$')
+MAX_CLIENT_HISTORIES = 32
+
 
 class TomLLM:
     """
@@ -48,7 +54,8 @@ class TomLLM:
         # Rate limiting for Mistral (1.5 seconds between requests)
         self.mistral_last_request = 0
         
-        # History management - separate history for each client type
+        # History management - separate history for each client type, the other
+        # client types being added on their first message (see _client_history)
         self.history = {
             'web': [],      # Web browser client
             'android': [],  # Android mobile client  
@@ -588,7 +595,7 @@ class TomLLM:
     
     def triage_modules(self, user_request: str, position: Optional[Dict[str, float]], 
                       available_modules: List[Dict[str, str]], client_type: str, personal_context: str = "", 
-                      mcp_client=None) -> List[str]:
+                      mcp_client=None, use_history: bool = True) -> List[str]:
         """
         Triage modules needed to answer user request
         
@@ -596,9 +603,10 @@ class TomLLM:
             user_request: User's request text
             position: Optional GPS position {'latitude': float, 'longitude': float}
             available_modules: List of {'name': str, 'description': str}
-            client_type: 'web', 'android', 'tui', or 'pwa'
+            client_type: 'web', 'android', 'tui', 'pwa', or any name matching CLIENT_TYPE_PATTERN
             personal_context: User's personal context from configuration
             mcp_client: Optional MCPClient instance for memory retrieval
+            use_history: Whether to triage with the history, False for a stateless request
             
         Returns:
             List of module names needed to answer the request
@@ -762,7 +770,8 @@ Once you call the 'modules_needed_to_answer_user_prompt' function, the user's re
             use_json_format=True,
             triage_instructions=triage_instructions,
             triage_modules_json=modules_json,
-            triage_mode=True
+            triage_mode=True,
+            use_history=use_history
         )
         
         tomlogger.info(f"🔍 Starting module triage for request: {user_request[:100]}...", 
@@ -1355,20 +1364,51 @@ Once you call the 'modules_needed_to_answer_user_prompt' function, the user's re
                 "function": function_name
             }
     
+    @staticmethod
+    def is_valid_client_type(client_type: Any) -> bool:
+        """Whether client_type may name a conversation history"""
+        return isinstance(client_type, str) and CLIENT_TYPE_PATTERN.match(client_type) is not None
+    
+    def _client_history(self, client_type: str, create: bool = False) -> Optional[List[Dict[str, Any]]]:
+        """
+        Get the history of a client type, adding it when create is set
+        
+        Args:
+            client_type: 'web', 'android', 'tui', 'pwa', or any name matching CLIENT_TYPE_PATTERN
+            create: Add an empty history for a client type without one
+            
+        Returns:
+            The history list, or None for an invalid client type, one without history
+            when create is not set, or when MAX_CLIENT_HISTORIES are already kept
+        """
+        if client_type in self.history:
+            return self.history[client_type]
+        if not create or not self.is_valid_client_type(client_type):
+            return None
+        if len(self.history) >= MAX_CLIENT_HISTORIES:
+            tomlogger.warning(f"Too many client types ({len(self.history)}), no history for '{client_type}'", 
+                            self.username, module_name="tomllm")
+            return None
+        self.history[client_type] = []
+        tomlogger.info(f"New conversation history for client type '{client_type}'", 
+                      self.username, module_name="tomllm")
+        return self.history[client_type]
+    
     def add_to_history(self, client_type: str, message: Dict[str, Any]):
         """
         Add a message to conversation history for specific client type
         
         Args:
-            client_type: 'web', 'android', 'tui', or 'pwa'
+            client_type: 'web', 'android', 'tui', 'pwa', or any name matching CLIENT_TYPE_PATTERN
             message: Message dict to add to history
         """
-        if client_type not in self.history:
-            tomlogger.warning(f"Invalid client type '{client_type}', defaulting to 'web'", 
+        client_history = self._client_history(client_type, create=True)
+        if client_history is None:
+            tomlogger.warning(f"No history for client type '{client_type}', message not kept", 
                             self.username, module_name="tomllm")
-            client_type = 'web'
+            return
         
-        self.history[client_type].append(message)
+        client_history.append(message)
         
         # Debug logging - show what message was added in DEBUG mode
         if tomlogger.logger and tomlogger.logger.logger.level <= 10:  # DEBUG level = 10
@@ -1645,7 +1685,8 @@ Once you call the 'modules_needed_to_answer_user_prompt' function, the user's re
                                       use_json_format: bool = True,
                                       triage_instructions: Optional[str] = None,
                                       triage_modules_json: Optional[str] = None,
-                                      triage_mode: bool = False) -> List[Dict[str, Any]]:
+                                      triage_mode: bool = False,
+                                      use_history: bool = True) -> List[Dict[str, Any]]:
         """
         Build conversation with history prepended in correct order
         
@@ -1667,18 +1708,19 @@ Once you call the 'modules_needed_to_answer_user_prompt' function, the user's re
             triage_instructions: Textual triage instructions (used only in triage_mode)
             triage_modules_json: JSON string containing available modules (used only in triage_mode)
             triage_mode: Whether to build conversation for triage (separates system prompts)
+            use_history: Whether to include the history, False for a stateless request
             
         Returns:
             Triage mode: global_system → history → triage_system → helper_systems → user_message
             Normal mode: global_system → history → helper_systems → user_messages
             Legacy mode: temporal → tom_prompt → personal_context → formatting → history → current messages
         """
-        if client_type not in self.history:
-            tomlogger.warning(f"Invalid client type '{client_type}', using empty history", 
-                            self.username, module_name="tomllm")
+        client_history = self._client_history(client_type) if use_history else None
+        if client_history is None:
+            if use_history and not self.is_valid_client_type(client_type):
+                tomlogger.warning(f"Invalid client type '{client_type}', using empty history", 
+                                self.username, module_name="tomllm")
             client_history = []
-        else:
-            client_history = self.history[client_type]
         
         # Debug logging - show history content in DEBUG mode
         if client_history and tomlogger.logger and tomlogger.logger.logger.level <= 10:  # DEBUG level = 10
@@ -1828,12 +1870,16 @@ Once you call the 'modules_needed_to_answer_user_prompt' function, the user's re
         Reset conversation history for specific client type, with optional behavior tuning analysis
         
         Args:
-            client_type: 'web', 'android', 'tui', or 'pwa'
+            client_type: 'web', 'android', 'tui', 'pwa', or any name matching CLIENT_TYPE_PATTERN
             mcp_client: Optional MCPClient instance for behavior tuning analysis
         """
         if client_type not in self.history:
-            tomlogger.warning(f"Invalid client type '{client_type}', cannot reset", 
-                            self.username, module_name="tomllm")
+            if self.is_valid_client_type(client_type):
+                tomlogger.info(f"🔄 No {client_type} conversation history to reset", 
+                              self.username, module_name="tomllm")
+            else:
+                tomlogger.warning(f"Invalid client type '{client_type}', cannot reset", 
+                                self.username, module_name="tomllm")
             return
         
         history_length = len(self.history[client_type])
@@ -512,7 +512,10 @@ def main():
         'tools.sessions.on': True,
         'tools.sessions.timeout': 3600 * 24 * 30,  # 30 days
         'tools.sessions.storage_type': 'file',
-        'tools.sessions.storage_path': sessions_dir
+        'tools.sessions.storage_path': sessions_dir,
+        # Compress the answers for the clients accepting it, on slow links
+        'tools.gzip.on': True,
+        'tools.gzip.mime_types': ['application/json', 'text/*']
     }
     
     # Add certificate chain if available
@@ -0,0 +1,7 @@
+module mail-gateway
+
+go 1.21
+
+require github.com/Mickael-Roger/tom/tools/memory-tui/pkg/chatclient v0.0.0
+
+replace github.com/Mickael-Roger/tom/tools/memory-tui/pkg/chatclient => ../memory-tui/pkg/chatclient
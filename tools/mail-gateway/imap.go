@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The few IMAP commands the gateway needs, over TLS: LOGIN, SELECT, UID
+// SEARCH, UID FETCH, UID STORE and LOGOUT. Go has no IMAP client and these
+// are a few lines each.
+const imapTimeout = time.Minute
+
+type imapConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+	tag  int
+}
+
+// imapResponse is an untagged response, with the literals ({n} then n
+// bytes) it carries apart
+type imapResponse struct {
+	line     string
+	literals [][]byte
+}
+
+func dialIMAP(host string, port int) (*imapConn, error) {
+	addr := net.JoinHostPort(host, strconv.Itoa(port))
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: imapTimeout}, "tcp", addr, &tls.Config{ServerName: host})
+	if err != nil {
+		return nil, err
+	}
+	c := &imapConn{conn: conn, r: bufio.NewReader(conn)}
+	conn.SetDeadline(time.Now().Add(imapTimeout))
+	greeting, err := c.readLine()
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if !strings.HasPrefix(greeting, "* OK") {
+		conn.Close()
+		return nil, fmt.Errorf("imap: unexpected greeting %q", greeting)
+	}
+	return c, nil
+}
+
+func (c *imapConn) readLine() (string, error) {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// command sends a command and reads its untagged responses until its
+// tagged one, an error unless that is OK
+func (c *imapConn) command(format string, args ...interface{}) ([]imapResponse, error) {
+	c.tag++
+	tag := fmt.Sprintf("a%d", c.tag)
+	cmd := fmt.Sprintf(format, args...)
+	c.conn.SetDeadline(time.Now().Add(imapTimeout))
+	if _, err := fmt.Fprintf(c.conn, "%s %s\r\n", tag, cmd); err != nil {
+		return nil, err
+	}
+
+	var responses []imapResponse
+	for {
+		line, err := c.readLine()
+		if err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(line, tag+" ") {
+			status := strings.TrimPrefix(line, tag+" ")
+			if !strings.HasPrefix(status, "OK") {
+				verb, _, _ := strings.Cut(cmd, " ")
+				return nil, fmt.Errorf("imap: %s: %s", verb, status)
+			}
+			return responses, nil
+		}
+		if !strings.HasPrefix(line, "* ") {
+			continue
+		}
+		resp := imapResponse{line: line}
+		for {
+			n, ok := literalSize(line)
+			if !ok {
+				break
+			}
+			literal := make([]byte, n)
+			if _, err := io.ReadFull(c.r, literal); err != nil {
+				return nil, err
+			}
+			resp.literals = append(resp.literals, literal)
+			if line, err = c.readLine(); err != nil {
+				return nil, err
+			}
+			resp.line += " " + line
+		}
+		responses = append(responses, resp)
+	}
+}
+
+// literalSize returns n when a line ends with the {n} of a literal
+func literalSize(line string) (int, bool) {
+	if !strings.HasSuffix(line, "}") {
+		return 0, false
+	}
+	open := strings.LastIndex(line, "{")
+	if open < 0 {
+		return 0, false
+	}
+	n, err := strconv.Atoi(strings.TrimSuffix(line[open+1:len(line)-1], "+"))
+	return n, err == nil
+}
+
+// imapQuote quotes a string for a command
+func imapQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func (c *imapConn) login(username, password string) error {
+	_, err := c.command("LOGIN %s %s", imapQuote(username), imapQuote(password))
+	return err
+}
+
+func (c *imapConn) selectMailbox(mailbox string) error {
+	_, err := c.command("SELECT %s", imapQuote(mailbox))
+	return err
+}
+
+// unseen returns the UIDs of the messages not read yet
+func (c *imapConn) unseen() ([]uint32, error) {
+	responses, err := c.command("UID SEARCH UNSEEN")
+	if err != nil {
+		return nil, err
+	}
+	var uids []uint32
+	for _, resp := range responses {
+		fields := strings.Fields(resp.line)
+		if len(fields) < 2 || fields[1] != "SEARCH" {
+			continue
+		}
+		for _, f := range fields[2:] {
+			if uid, err := strconv.ParseUint(f, 10, 32); err == nil {
+				uids = append(uids, uint32(uid))
+			}
+		}
+	}
+	return uids, nil
+}
+
+// fetch returns a whole message, without marking it read
+func (c *imapConn) fetch(uid uint32) ([]byte, error) {
+	responses, err := c.command("UID FETCH %d BODY.PEEK[]", uid)
+	if err != nil {
+		return nil, err
+	}
+	for _, resp := range responses {
+		if strings.Contains(resp.line, "FETCH") && len(resp.literals) > 0 {
+			return resp.literals[0], nil
+		}
+	}
+	return nil, fmt.Errorf("imap: message %d not found", uid)
+}
+
+func (c *imapConn) markSeen(uid uint32) error {
+	_, err := c.command(`UID STORE %d +FLAGS.SILENT (\Seen)`, uid)
+	return err
+}
+
+func (c *imapConn) logout() {
+	c.command("LOGOUT")
+	c.conn.Close()
+}
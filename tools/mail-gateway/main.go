@@ -0,0 +1,258 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/Mickael-Roger/tom/tools/memory-tui/pkg/chatclient"
+)
+
+// Mail gateway: reads the messages of an inbox every poll_interval, sends
+// those of the senders allowed to Tom, in the conversation of the "email"
+// client of the sender's profile, a history Tom keeps apart from his apps,
+// and replies with his answer, in the thread of the message. Messages from
+// others, and automatic ones, are only marked read, so an auto-reply never
+// starts a loop.
+const (
+	clientType        = "email"
+	defaultPoll       = time.Minute
+	defaultMailbox    = "INBOX"
+	defaultIMAPPort   = 993
+	defaultSMTPPort   = 587
+	processTimeout    = 3 * time.Minute
+	defaultConfigName = "mail-gateway.json"
+	resetCommand      = "reset" // The whole text of a message starting a new conversation
+)
+
+type config struct {
+	ServerURL    string                `json:"server_url"`
+	IMAP         imapConfig            `json:"imap"`
+	SMTP         smtpConfig            `json:"smtp"`
+	Senders      map[string]tomProfile `json:"senders"`       // By address
+	RequireAuth  *bool                 `json:"require_auth"`  // Refuse the messages AuthservID did not authenticate, on when unset
+	AuthservID   string                `json:"authserv_id"`   // Of the Authentication-Results header of the receiving server
+	PollInterval int                   `json:"poll_interval"` // Seconds
+}
+
+type imapConfig struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Mailbox  string `json:"mailbox"`
+}
+
+type smtpConfig struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	From     string `json:"from"`
+}
+
+// tomProfile is the Tom user a sender talks as
+type tomProfile struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type gateway struct {
+	cfg   config
+	chats map[string]*chatclient.Client // By Tom username
+}
+
+func main() {
+	home, _ := os.UserHomeDir()
+	configPath := flag.String("config", filepath.Join(home, ".tom", defaultConfigName), "configuration file")
+	once := flag.Bool("once", false, "read the inbox once and exit, for cron")
+	flag.Parse()
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	g := &gateway{cfg: cfg, chats: map[string]*chatclient.Client{}}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	log.Printf("answering the messages of %d senders in %s@%s", len(cfg.Senders), cfg.IMAP.Mailbox, cfg.IMAP.Host)
+	for {
+		if err := g.poll(ctx); err != nil {
+			log.Printf("inbox: %v", err)
+		}
+		if *once {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Duration(cfg.PollInterval) * time.Second):
+		}
+	}
+}
+
+func loadConfig(path string) (config, error) {
+	var cfg config
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+	if cfg.IMAP.Port == 0 {
+		cfg.IMAP.Port = defaultIMAPPort
+	}
+	if cfg.IMAP.Mailbox == "" {
+		cfg.IMAP.Mailbox = defaultMailbox
+	}
+	if cfg.SMTP.Port == 0 {
+		cfg.SMTP.Port = defaultSMTPPort
+	}
+	if cfg.SMTP.From == "" {
+		cfg.SMTP.From = cfg.IMAP.Username
+	}
+	if cfg.RequireAuth == nil {
+		on := true
+		cfg.RequireAuth = &on
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = int(defaultPoll / time.Second)
+	}
+	switch {
+	case cfg.ServerURL == "":
+		return cfg, fmt.Errorf("%s: no server_url", path)
+	case cfg.IMAP.Host == "" || cfg.IMAP.Username == "":
+		return cfg, fmt.Errorf("%s: no imap host or username", path)
+	case cfg.SMTP.Host == "":
+		return cfg, fmt.Errorf("%s: no smtp host", path)
+	case !strings.Contains(cfg.SMTP.From, "@"):
+		return cfg, fmt.Errorf("%s: no smtp from address", path)
+	case *cfg.RequireAuth && cfg.AuthservID == "":
+		return cfg, fmt.Errorf("%s: no authserv_id, the name of the receiving server in Authentication-Results, or require_auth false", path)
+	case len(cfg.Senders) == 0:
+		return cfg, fmt.Errorf("%s: no senders, map addresses to Tom profiles", path)
+	}
+	senders := make(map[string]tomProfile, len(cfg.Senders))
+	for address, profile := range cfg.Senders {
+		senders[strings.ToLower(address)] = profile
+	}
+	cfg.Senders = senders
+	return cfg, nil
+}
+
+// chat returns the Tom client of a profile
+func (g *gateway) chat(profile tomProfile) *chatclient.Client {
+	if c, ok := g.chats[profile.Username]; ok {
+		return c
+	}
+	c := chatclient.New(g.cfg.ServerURL, clientType)
+	c.Credentials = &chatclient.Credentials{Username: profile.Username, Password: profile.Password}
+	g.chats[profile.Username] = c
+	return c
+}
+
+// poll reads the unread messages, marks them read at once so a failure
+// never answers one twice, then answers them
+func (g *gateway) poll(ctx context.Context) error {
+	c, err := dialIMAP(g.cfg.IMAP.Host, g.cfg.IMAP.Port)
+	if err != nil {
+		return err
+	}
+	var messages [][]byte
+	err = func() error {
+		defer c.logout()
+		if err := c.login(g.cfg.IMAP.Username, g.cfg.IMAP.Password); err != nil {
+			return err
+		}
+		if err := c.selectMailbox(g.cfg.IMAP.Mailbox); err != nil {
+			return err
+		}
+		uids, err := c.unseen()
+		if err != nil {
+			return err
+		}
+		for _, uid := range uids {
+			raw, err := c.fetch(uid)
+			if err != nil {
+				return err
+			}
+			if err := c.markSeen(uid); err != nil {
+				return err
+			}
+			messages = append(messages, raw)
+		}
+		return nil
+	}()
+
+	for _, raw := range messages {
+		if ctx.Err() != nil {
+			break
+		}
+		g.answer(ctx, raw)
+	}
+	return err
+}
+
+func (g *gateway) answer(ctx context.Context, raw []byte) {
+	in, err := parseMessage(raw, g.cfg.AuthservID)
+	if err != nil {
+		log.Printf("unreadable message: %v", err)
+		return
+	}
+	profile, ok := g.cfg.Senders[in.From]
+	switch {
+	case !ok:
+		log.Printf("ignored a message of %s, not in senders", in.From)
+		return
+	case in.Automatic:
+		log.Printf("ignored an automatic message of %s", in.From)
+		return
+	case *g.cfg.RequireAuth && !in.Verified:
+		log.Printf("ignored a message of %s, %s passed neither DMARC nor DKIM for its domain", in.From, g.cfg.AuthservID)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, processTimeout)
+	defer cancel()
+	chat := g.chat(profile)
+	var text string
+	if prompt := in.prompt(); strings.EqualFold(prompt, resetCommand) {
+		text = "New conversation started."
+		if err = chat.Reset(ctx); err != nil {
+			text = failMessage(in, err)
+		}
+	} else if prompt == "" {
+		text = fmt.Sprintf("Write your question in the message. %q alone starts a new conversation.", resetCommand)
+	} else if answer, err := chat.Process(ctx, prompt); err != nil {
+		text = failMessage(in, err)
+	} else {
+		text = answer.Text
+	}
+
+	if err := g.cfg.SMTP.send(in.From, reply(g.cfg.SMTP.From, in, text, time.Now())); err != nil {
+		log.Printf("reply to %s: %v", in.From, err)
+		return
+	}
+	log.Printf("answered a message of %s", in.From)
+}
+
+// failMessage tells the sender Tom could not answer, the details going to
+// the log
+func failMessage(in incoming, err error) string {
+	log.Printf("request of %s: %v", in.From, err)
+	if errors.Is(err, chatclient.ErrInvalidCredentials) {
+		return "The Tom profile of this address refuses its password."
+	}
+	return "Tom could not answer, try again later."
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"regexp"
+	"strings"
+)
+
+// incoming is a message read from the inbox, as the gateway needs it
+type incoming struct {
+	From      string // Address, lower case
+	Subject   string
+	MessageID string
+	Refs      string // References of the message, for the thread of the reply
+	Text      string // The prompt, without the quoted message nor the signature
+	Automatic bool   // An auto-reply or a list message, never answered
+	Verified  bool   // The receiving server authenticated the From domain
+}
+
+var wordDecoder = &mime.WordDecoder{}
+
+// parseMessage reads a message, authservID being the name the receiving
+// server gives itself in its Authentication-Results header
+func parseMessage(raw []byte, authservID string) (incoming, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return incoming{}, err
+	}
+	h := msg.Header
+	from, err := mail.ParseAddress(h.Get("From"))
+	if err != nil {
+		return incoming{}, fmt.Errorf("From: %w", err)
+	}
+	subject, err := wordDecoder.DecodeHeader(h.Get("Subject"))
+	if err != nil {
+		subject = h.Get("Subject")
+	}
+	in := incoming{
+		From:      strings.ToLower(from.Address),
+		Subject:   subject,
+		MessageID: h.Get("Message-Id"),
+		Refs:      strings.TrimSpace(h.Get("References") + " " + h.Get("Message-Id")),
+	}
+	auto := strings.ToLower(h.Get("Auto-Submitted"))
+	precedence := strings.ToLower(h.Get("Precedence"))
+	in.Automatic = (auto != "" && auto != "no") || precedence == "bulk" || precedence == "list" ||
+		precedence == "junk" || h.Get("List-Id") != ""
+	// The receiving server adds its header on top: those below come from
+	// the relays or the sender, who can write anything
+	if results := h["Authentication-Results"]; len(results) > 0 && authservID != "" {
+		in.Verified = authenticated(results[0], authservID, addressDomain(in.From))
+	}
+
+	body, _, err := textBody(h.Get("Content-Type"), h.Get("Content-Transfer-Encoding"), msg.Body)
+	if err != nil {
+		return incoming{}, err
+	}
+	in.Text = stripReply(body)
+	return in, nil
+}
+
+var headerComment = regexp.MustCompile(`\([^()]*\)`)
+
+// authenticated tells whether an Authentication-Results header of the
+// server authservID authenticates the From domain: a DMARC pass, or a DKIM
+// pass of a signature of that domain. SPF checks the envelope sender, which
+// anyone sets to a domain of their own, and a DKIM pass of another domain
+// says nothing of the From address.
+func authenticated(header, authservID, fromDomain string) bool {
+	header = strings.ToLower(headerComment.ReplaceAllString(header, ""))
+	parts := strings.Split(header, ";")
+	if id := strings.Fields(parts[0]); len(id) == 0 || id[0] != strings.ToLower(authservID) {
+		return false
+	}
+	for _, result := range parts[1:] {
+		props := map[string]string{}
+		for _, field := range strings.Fields(result) {
+			if key, value, ok := strings.Cut(field, "="); ok {
+				props[key] = strings.Trim(value, `"`)
+			}
+		}
+		switch {
+		case props["dmarc"] == "pass":
+			if from, ok := props["header.from"]; !ok || from == fromDomain {
+				return true
+			}
+		case props["dkim"] == "pass":
+			if props["header.d"] == fromDomain {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func addressDomain(address string) string {
+	_, domain, _ := strings.Cut(address, "@")
+	return domain
+}
+
+// textBody returns the text/plain part of a body, looking into multipart
+// ones, or the first text/html one with its tags removed when there is no
+// plain text
+func textBody(contentType, encoding string, body io.Reader) (text string, isHTML bool, err error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = "text/plain"
+	}
+	if strings.HasPrefix(mediaType, "multipart/") {
+		r := multipart.NewReader(body, params["boundary"])
+		var html string
+		for {
+			part, err := r.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return "", false, err
+			}
+			// multipart decodes the quoted-printable parts itself
+			text, isHTML, err := textBody(part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"), part)
+			switch {
+			case err != nil:
+				return "", false, err
+			case text == "":
+			case !isHTML:
+				return text, false, nil
+			case html == "":
+				html = text
+			}
+		}
+		return html, html != "", nil
+	}
+
+	switch strings.ToLower(encoding) {
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, newlineStripper{body})
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return "", false, err
+	}
+	switch mediaType {
+	case "text/plain":
+		return string(data), false, nil
+	case "text/html":
+		return htmlToText(string(data)), true, nil
+	}
+	return "", false, nil
+}
+
+// newlineStripper drops the line breaks of a base64 body
+type newlineStripper struct{ r io.Reader }
+
+func (n newlineStripper) Read(p []byte) (int, error) {
+	count, err := n.r.Read(p)
+	kept := 0
+	for _, b := range p[:count] {
+		if b != '\r' && b != '\n' {
+			p[kept] = b
+			kept++
+		}
+	}
+	return kept, err
+}
+
+var (
+	htmlBreak = regexp.MustCompile(`(?i)<br\s*/?>|</p>|</div>`)
+	htmlTag   = regexp.MustCompile(`<[^>]*>`)
+)
+
+func htmlToText(s string) string {
+	s = htmlBreak.ReplaceAllString(s, "\n")
+	s = htmlTag.ReplaceAllString(s, "")
+	return strings.NewReplacer("&nbsp;", " ", "&lt;", "<", "&gt;", ">", "&quot;", `"`, "&#39;", "'", "&amp;", "&").Replace(s)
+}
+
+// replyHeader is the line mail clients put above the message answered
+var replyHeader = regexp.MustCompile(`^(On .+ wrote:|Le .+ a écrit\s?:|-----\s*Original Message\s*-----)$`)
+
+// stripReply keeps the text of a message above the quoted message it
+// answers and the signature
+func stripReply(body string) string {
+	var kept []string
+	for _, line := range strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if line == "-- " || replyHeader.MatchString(trimmed) {
+			break
+		}
+		if strings.HasPrefix(trimmed, ">") {
+			continue
+		}
+		kept = append(kept, strings.TrimRight(line, " \t"))
+	}
+	return strings.TrimSpace(strings.Join(kept, "\n"))
+}
+
+// prompt is what Tom is asked: the text, or the subject when there is none
+func (in incoming) prompt() string {
+	subject := strings.TrimSpace(in.Subject)
+	for {
+		lower := strings.ToLower(subject)
+		if !strings.HasPrefix(lower, "re:") && !strings.HasPrefix(lower, "fwd:") && !strings.HasPrefix(lower, "tr:") {
+			break
+		}
+		_, subject, _ = strings.Cut(subject, ":")
+		subject = strings.TrimSpace(subject)
+	}
+	if in.Text == "" {
+		return subject
+	}
+	return in.Text
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// smtpsPort is the port of SMTP over TLS from the start, the others
+// upgrading with STARTTLS
+const smtpsPort = 465
+
+// reply builds the answer to a message, in its thread
+func reply(from string, in incoming, text string, now time.Time) []byte {
+	subject := in.Subject
+	if !strings.HasPrefix(strings.ToLower(subject), "re:") {
+		subject = "Re: " + subject
+	}
+	var b bytes.Buffer
+	header := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(&b, "%s: %s\r\n", name, value)
+		}
+	}
+	header("From", (&mail.Address{Name: "Tom", Address: from}).String())
+	header("To", in.From)
+	header("Subject", mime.QEncoding.Encode("utf-8", subject))
+	header("Date", now.Format(time.RFC1123Z))
+	header("Message-ID", messageID(from))
+	header("In-Reply-To", in.MessageID)
+	header("References", in.Refs)
+	header("Auto-Submitted", "auto-replied")
+	header("MIME-Version", "1.0")
+	header("Content-Type", `text/plain; charset="utf-8"`)
+	header("Content-Transfer-Encoding", "quoted-printable")
+	b.WriteString("\r\n")
+	qp := quotedprintable.NewWriter(&b)
+	qp.Write([]byte(strings.ReplaceAll(text, "\n", "\r\n")))
+	qp.Close()
+	return b.Bytes()
+}
+
+func messageID(from string) string {
+	random := make([]byte, 12)
+	rand.Read(random)
+	_, domain, _ := strings.Cut(from, "@")
+	if domain == "" {
+		domain = "localhost"
+	}
+	return fmt.Sprintf("<%s@%s>", hex.EncodeToString(random), domain)
+}
+
+// send sends a message with the SMTP server of the configuration
+func (s smtpConfig) send(to string, msg []byte) error {
+	addr := net.JoinHostPort(s.Host, strconv.Itoa(s.Port))
+	auth := smtp.PlainAuth("", s.Username, s.Password, s.Host)
+	if s.Port != smtpsPort {
+		return smtp.SendMail(addr, auth, s.From, []string{to}, msg)
+	}
+
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: imapTimeout}, "tcp", addr, &tls.Config{ServerName: s.Host})
+	if err != nil {
+		return err
+	}
+	c, err := smtp.NewClient(conn, s.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+	if err := c.Auth(auth); err != nil {
+		return err
+	}
+	if err := c.Mail(s.From); err != nil {
+		return err
+	}
+	if err := c.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/charmbracelet/bubbletea"
+)
+
+// Access log of a memory: when the assistant last retrieved it in a search or
+// injected it into a conversation, shown in the detail view to tell stale
+// memories from the ones still in use. Servers without the endpoint answer
+// 404 and the section is then left out for the session.
+const maxRecentAccesses = 5
+
+type memoryAccess struct {
+	At           string `json:"at"`
+	Kind         string `json:"kind"` // "retrieved" or "injected"
+	Conversation string `json:"conversation,omitempty"`
+}
+
+type memoryAccessLog struct {
+	LastRetrieved string         `json:"last_retrieved"`
+	LastInjected  string         `json:"last_injected"`
+	Retrievals    int            `json:"retrievals"`
+	Injections    int            `json:"injections"`
+	Recent        []memoryAccess `json:"recent"`
+}
+
+type accessLogMsg struct {
+	id          string
+	log         memoryAccessLog
+	unsupported bool
+	err         error
+}
+
+// GetMemoryAccesses returns the access log of a memory, and false when the
+// server does not keep one
+func (api *MemoryAPI) GetMemoryAccesses(id string) (memoryAccessLog, bool, error) {
+	resp, err := api.Client.Get(api.buildURL("/accesses/" + url.PathEscape(id)))
+	if err != nil {
+		return memoryAccessLog{}, false, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusUnauthorized:
+		return memoryAccessLog{}, false, errSessionExpired
+	case http.StatusNotFound, http.StatusMethodNotAllowed:
+		return memoryAccessLog{}, false, nil
+	case http.StatusOK:
+	default:
+		return memoryAccessLog{}, false, fmt.Errorf("access log: server returned %s", resp.Status)
+	}
+
+	var log memoryAccessLog
+	if err := json.NewDecoder(resp.Body).Decode(&log); err != nil {
+		return memoryAccessLog{}, false, err
+	}
+	return log, true, nil
+}
+
+func (m model) loadAccessLog(id string) tea.Cmd {
+	if m.accessUnsupported || m.api == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		log, ok, err := m.api.GetMemoryAccesses(id)
+		return accessLogMsg{id: id, log: log, unsupported: !ok && err == nil, err: err}
+	}
+}
+
+// openDetail shows a memory in the detail view and fetches its access log
+func (m model) openDetail(memory Memory) (tea.Model, tea.Cmd) {
+	m.currentMem = memory
+	m.accessLog, m.accessErr = nil, nil
+	m.showView(detailView)
+	return m, m.loadAccessLog(memory.ID)
+}
+
+func (m model) handleAccessLog(msg accessLogMsg) (tea.Model, tea.Cmd) {
+	if msg.unsupported {
+		logf("INFO", "the server keeps no memory access log")
+		m.accessUnsupported = true
+		return m, nil
+	}
+	// The user may have moved to another memory meanwhile
+	if msg.id != m.currentMem.ID {
+		return m, nil
+	}
+	if msg.err != nil {
+		logf("WARN", "access log of memory %s: %v", msg.id, msg.err)
+		m.accessErr = msg.err
+		return m, nil
+	}
+	m.accessLog = &msg.log
+	return m, nil
+}
+
+// renderAccessLog is the "Last used" section of the detail view
+func (m model) renderAccessLog() string {
+	if m.accessUnsupported {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(selectedItemStyle.Render(tr("Last used:")))
+	b.WriteString("\n")
+	switch {
+	case m.accessErr != nil:
+		b.WriteString(helpStyle.Render("  " + tr("Unavailable: %v", m.accessErr)))
+		b.WriteString("\n")
+	case m.accessLog == nil:
+		b.WriteString(helpStyle.Render("  " + tr("Loading...")))
+		b.WriteString("\n")
+	case m.accessLog.Retrievals == 0 && m.accessLog.Injections == 0:
+		b.WriteString("  " + tr("Never retrieved nor injected") + "\n")
+	default:
+		log := m.accessLog
+		b.WriteString("  " + tr("Retrieved: %s (%d times)", accessTime(log.LastRetrieved), log.Retrievals) + "\n")
+		b.WriteString("  " + tr("Injected:  %s (%d times)", accessTime(log.LastInjected), log.Injections) + "\n")
+		for i, a := range log.Recent {
+			if i == maxRecentAccesses {
+				break
+			}
+			line := fmt.Sprintf("  • %s %s", formatTime(a.At), tr(a.Kind))
+			if a.Conversation != "" {
+				line += " " + tr("in %s", truncateString(a.Conversation, 40))
+			}
+			b.WriteString(helpStyle.Render(line))
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+func accessTime(at string) string {
+	if at == "" {
+		return tr("never")
+	}
+	return formatTime(at)
+}
@@ -0,0 +1,457 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Applying diffs: /apply [N [FILE]] applies the unified diff of the block N
+// saved by /scratch, the last diff block by default, to the files it names
+// in the current directory, or to FILE for a diff of one file. Every hunk is
+// checked against the files first, found where the diff says or elsewhere
+// when the file moved on, and the result shows as a preview; Y writes the
+// files, the former content going to FILE.orig, and O does when a FILE.orig
+// is already there. The paths of the diff come from Tom's answer: those
+// leaving the current directory, by name or through a symlink, are refused.
+var (
+	diffAddedStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#25A065"))
+	diffRemovedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5F5F"))
+	diffHunkStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("#5F87FF"))
+)
+
+type diffHunk struct {
+	oldStart int      // 1-based, as in @@ -oldStart,n +m,n @@
+	lines    []string // Prefixed by ' ', '-' or '+'
+}
+
+type fileDiff struct {
+	oldPath, newPath string // Without the a/ and b/ prefixes, /dev/null for a new file
+	hunks            []diffHunk
+}
+
+// filePatch is a diff checked against its file, ready to write
+type filePatch struct {
+	path     string
+	exists   bool
+	original string
+	patched  string
+	hunks    int
+	backedUp bool // FILE.orig exists, replaced only on demand
+	fromDiff bool // The path comes from the diff, checked again before writing
+}
+
+// parseUnifiedDiff reads the files and hunks of a diff, loose on the line
+// counts of the hunk headers as the diffs written by hand or by Tom rarely
+// get them right
+func parseUnifiedDiff(text string) ([]fileDiff, error) {
+	var files []fileDiff
+	var hunk *diffHunk
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		switch {
+		case strings.HasPrefix(line, "--- ") && i+1 < len(lines) && strings.HasPrefix(lines[i+1], "+++ "):
+			files = append(files, fileDiff{oldPath: diffPath(line[4:]), newPath: diffPath(lines[i+1][4:])})
+			hunk = nil
+			i++
+		case strings.HasPrefix(line, "@@"):
+			if len(files) == 0 {
+				return nil, errors.New("hunk before any --- +++ file header")
+			}
+			start, err := hunkOldStart(line)
+			if err != nil {
+				return nil, err
+			}
+			f := &files[len(files)-1]
+			f.hunks = append(f.hunks, diffHunk{oldStart: start})
+			hunk = &f.hunks[len(f.hunks)-1]
+		case hunk == nil || strings.HasPrefix(line, `\`):
+			// Headers such as diff --git or index, and "\ No newline at end of file"
+		case line == "":
+			// Trailing spaces, so the blank context lines, tend to get lost
+			hunk.lines = append(hunk.lines, " ")
+		case line[0] == ' ' || line[0] == '-' || line[0] == '+':
+			hunk.lines = append(hunk.lines, line)
+		default:
+			hunk = nil
+		}
+	}
+
+	if len(files) == 0 {
+		return nil, errors.New("no unified diff, --- and +++ file headers then @@ hunks")
+	}
+	for i := range files {
+		if files[i].newPath == "/dev/null" {
+			return nil, fmt.Errorf("%s: deleting files is not supported", files[i].oldPath)
+		}
+		if len(files[i].hunks) == 0 {
+			return nil, fmt.Errorf("%s: no hunk", files[i].newPath)
+		}
+		for j := range files[i].hunks {
+			h := &files[i].hunks[j]
+			for len(h.lines) > 0 && h.lines[len(h.lines)-1] == " " {
+				h.lines = h.lines[:len(h.lines)-1]
+			}
+		}
+	}
+	return files, nil
+}
+
+// diffPath drops the timestamp and the a/ or b/ prefix of a file header
+func diffPath(header string) string {
+	path, _, _ := strings.Cut(header, "\t")
+	path = strings.TrimSpace(path)
+	if path == "/dev/null" {
+		return path
+	}
+	for _, prefix := range []string{"a/", "b/"} {
+		if rest, ok := strings.CutPrefix(path, prefix); ok {
+			return rest
+		}
+	}
+	return path
+}
+
+func hunkOldStart(header string) (int, error) {
+	fields := strings.Fields(header)
+	if len(fields) < 3 || !strings.HasPrefix(fields[1], "-") {
+		return 0, fmt.Errorf("invalid hunk header %q", header)
+	}
+	start, _, _ := strings.Cut(fields[1][1:], ",")
+	n, err := strconv.Atoi(start)
+	if err != nil {
+		return 0, fmt.Errorf("invalid hunk header %q", header)
+	}
+	return n, nil
+}
+
+// applyHunks patches the content, each hunk where its old lines are, the
+// nearest place to the line the diff gives when they show several times
+func applyHunks(content string, hunks []diffHunk) (string, error) {
+	trailingNewline := content == "" || strings.HasSuffix(content, "\n")
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	if content == "" {
+		lines = nil
+	}
+
+	offset, from := 0, 0 // Lines added by the hunks applied, and the first line free
+	for i, h := range hunks {
+		var oldLines, newLines []string
+		for _, line := range h.lines {
+			if line[0] != '+' {
+				oldLines = append(oldLines, line[1:])
+			}
+			if line[0] != '-' {
+				newLines = append(newLines, line[1:])
+			}
+		}
+		at := findLines(lines, oldLines, from, max(0, h.oldStart-1)+offset)
+		if at < 0 {
+			return "", fmt.Errorf("hunk %d does not match the file", i+1)
+		}
+		lines = append(lines[:at:at], append(newLines, lines[at+len(oldLines):]...)...)
+		from = at + len(newLines)
+		offset += len(newLines) - len(oldLines)
+	}
+
+	patched := strings.Join(lines, "\n")
+	if trailingNewline && len(lines) > 0 {
+		patched += "\n"
+	}
+	return patched, nil
+}
+
+// findLines returns where old starts in lines from from on, the nearest to
+// want, -1 when nowhere. Trailing spaces do not count.
+func findLines(lines, old []string, from, want int) int {
+	if len(old) == 0 {
+		return min(max(from, want), len(lines))
+	}
+	best := -1
+	for at := from; at+len(old) <= len(lines); at++ {
+		match := true
+		for j, line := range old {
+			if strings.TrimRight(lines[at+j], " \t") != strings.TrimRight(line, " \t") {
+				match = false
+				break
+			}
+		}
+		if match && (best < 0 || abs(at-want) < abs(best-want)) {
+			best = at
+		}
+	}
+	return best
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// preparePatches checks the diff against the files, target replacing the
+// path of a diff of one file when given
+func preparePatches(files []fileDiff, target string) ([]filePatch, error) {
+	if target != "" && len(files) > 1 {
+		return nil, fmt.Errorf("the diff changes %d files, FILE only goes with a diff of one", len(files))
+	}
+	var patches []filePatch
+	for _, f := range files {
+		path := f.newPath
+		if target != "" {
+			path = target
+		} else if err := checkLocalPath(path); err != nil {
+			return nil, err
+		}
+		data, err := os.ReadFile(path)
+		exists := err == nil
+		if err != nil && !(os.IsNotExist(err) && f.oldPath == "/dev/null") {
+			return nil, err
+		}
+		if exists && f.oldPath == "/dev/null" {
+			return nil, fmt.Errorf("%s: the diff creates it but it exists", path)
+		}
+		patched, err := applyHunks(string(data), f.hunks)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		_, err = os.Lstat(path + ".orig")
+		patches = append(patches, filePatch{path: path, exists: exists, original: string(data), patched: patched, hunks: len(f.hunks), backedUp: exists && err == nil, fromDiff: target == ""})
+	}
+	return patches, nil
+}
+
+// checkLocalPath refuses a path of a diff outside the current directory:
+// absolute, going up with .., or through a symlink leading out of it
+func checkLocalPath(path string) error {
+	if !filepath.IsLocal(filepath.Clean(path)) {
+		return fmt.Errorf("%s: outside the current directory", path)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	if wd, err = filepath.EvalSymlinks(wd); err != nil {
+		return err
+	}
+	// The file may not exist yet: its nearest existing parent then counts
+	for p := filepath.Join(wd, path); ; p = filepath.Dir(p) {
+		real, err := filepath.EvalSymlinks(p)
+		if os.IsNotExist(err) && p != wd {
+			// A dangling symlink would be followed when writing
+			if info, lerr := os.Lstat(p); lerr == nil && info.Mode()&os.ModeSymlink != 0 {
+				return fmt.Errorf("%s: leads to a missing symlink target", path)
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if rel, err := filepath.Rel(wd, real); err != nil || !filepath.IsLocal(rel) && rel != "." {
+			return fmt.Errorf("%s: leads outside the current directory through a symlink", path)
+		}
+		return nil
+	}
+}
+
+// writePatches writes the patched files, saving the former content to
+// .orig, unless a file changed since the preview or, without overwrite, a
+// .orig would be replaced
+func writePatches(patches []filePatch, overwrite bool) error {
+	for _, p := range patches {
+		data, err := os.ReadFile(p.path)
+		if p.exists && (err != nil || string(data) != p.original) {
+			return fmt.Errorf("%s changed since the preview, /apply again", p.path)
+		}
+		if p.fromDiff {
+			if err := checkLocalPath(p.path); err != nil {
+				return err
+			}
+		}
+		if info, err := os.Lstat(p.path + ".orig"); p.exists && err == nil {
+			if info.Mode()&os.ModeSymlink != 0 {
+				return fmt.Errorf("%s.orig is a symlink, move it away first", p.path)
+			}
+			if !overwrite {
+				return fmt.Errorf("%s.orig exists, O to replace it", p.path)
+			}
+		}
+	}
+	for _, p := range patches {
+		mode := os.FileMode(0644)
+		if p.exists {
+			if info, err := os.Stat(p.path); err == nil {
+				mode = info.Mode().Perm()
+			}
+			if err := os.WriteFile(p.path+".orig", []byte(p.original), mode); err != nil {
+				return err
+			}
+		} else if err := os.MkdirAll(filepath.Dir(p.path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(p.path, []byte(p.patched), mode); err != nil {
+			return err
+		}
+		logf("INFO", "diff applied to %s", p.path)
+	}
+	return nil
+}
+
+// lastDiffBlock returns the last block of dir holding a diff
+func lastDiffBlock(dir string) (scratchFile, error) {
+	files, err := listScratch(dir)
+	if err != nil {
+		return scratchFile{}, err
+	}
+	for i := len(files) - 1; i >= 0; i-- {
+		if filepath.Ext(files[i].path) == ".diff" {
+			return files[i], nil
+		}
+		if data, err := os.ReadFile(files[i].path); err == nil && strings.Contains(string(data), "\n@@ ") {
+			return files[i], nil
+		}
+	}
+	return scratchFile{}, errors.New("no diff in the code blocks of Tom's answers, see /scratch")
+}
+
+func runApplyCommand(m model, args string) (tea.Model, tea.Cmd) {
+	fields := strings.Fields(args)
+	var block scratchFile
+	var err error
+	switch {
+	case len(fields) == 0:
+		block, err = lastDiffBlock(m.scratchDir)
+	case len(fields) <= 2:
+		n, convErr := strconv.Atoi(fields[0])
+		if convErr != nil {
+			m.message = tr("Usage: %s", "/apply [N [FILE]]")
+			return m, nil
+		}
+		block, err = scratchBlock(m.scratchDir, n)
+	default:
+		m.message = tr("Usage: %s", "/apply [N [FILE]]")
+		return m, nil
+	}
+	if err != nil {
+		m.err = err
+		return m, nil
+	}
+
+	data, err := os.ReadFile(block.path)
+	if err != nil {
+		m.err = err
+		return m, nil
+	}
+	files, err := parseUnifiedDiff(string(data))
+	if err != nil {
+		m.err = fmt.Errorf("block %d: %w", block.n, err)
+		return m, nil
+	}
+	var target string
+	if len(fields) == 2 {
+		target = expandHome(fields[1])
+	}
+	patches, err := preparePatches(files, target)
+	if err != nil {
+		m.err = fmt.Errorf("block %d: %w", block.n, err)
+		return m, nil
+	}
+
+	m.applyBlock = block
+	m.applyDiff = string(data)
+	m.applyPatches = patches
+	m.layoutApply()
+	cmd := m.showView(applyView)
+	return m, cmd
+}
+
+// layoutApply colors the diff in the preview
+func (m *model) layoutApply() {
+	m.applyViewport = viewport.New(max(20, m.width-8), max(3, m.height-14-len(m.applyPatches)))
+	var b strings.Builder
+	for _, line := range strings.Split(strings.TrimRight(m.applyDiff, "\n"), "\n") {
+		line = truncateString(line, max(20, m.width-10))
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			b.WriteString(selectedItemStyle.Render(line))
+		case strings.HasPrefix(line, "@@"):
+			b.WriteString(diffHunkStyle.Render(line))
+		case strings.HasPrefix(line, "+"):
+			b.WriteString(diffAddedStyle.Render(line))
+		case strings.HasPrefix(line, "-"):
+			b.WriteString(diffRemovedStyle.Render(line))
+		default:
+			b.WriteString(line)
+		}
+		b.WriteString("\n")
+	}
+	m.applyViewport.SetContent(b.String())
+}
+
+func (m model) updateApplyView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "Y", "O":
+		if err := writePatches(m.applyPatches, msg.String() == "O"); err != nil {
+			m.err = err
+			return m, nil
+		}
+		if len(m.applyPatches) == 1 {
+			p := m.applyPatches[0]
+			m.message = tr("Diff applied to %s", p.path)
+			if p.exists {
+				m.message = tr("Diff applied to %s, former content in %s", p.path, p.path+".orig")
+			}
+		} else {
+			m.message = tr("Diff applied to %d files, former contents in .orig files", len(m.applyPatches))
+		}
+		cmd := m.showView(listView)
+		return m, cmd
+	case "n", "N", "esc", "q":
+		cmd := m.showView(listView)
+		return m, cmd
+	case "g", "home":
+		m.applyViewport.GotoTop()
+		return m, nil
+	case "G", "end":
+		m.applyViewport.GotoBottom()
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.applyViewport, cmd = m.applyViewport.Update(msg)
+	return m, cmd
+}
+
+func (m model) renderApplyView() string {
+	style := contentBoxStyle.Width(m.width - 4)
+	if m.focus == focusContent {
+		style = contentBoxFocusedStyle.Width(m.width - 4)
+	}
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(tr("🩹 Apply block %d?", m.applyBlock.n)))
+	b.WriteString("\n\n")
+	for _, p := range m.applyPatches {
+		status := tr("%d hunks, backup to %s", p.hunks, filepath.Base(p.path)+".orig")
+		switch {
+		case !p.exists:
+			status = tr("new file")
+		case p.backedUp:
+			status = tr("%d hunks, %s exists: O to replace it", p.hunks, filepath.Base(p.path)+".orig")
+		}
+		b.WriteString("• " + p.path + " " + helpStyle.Render(status) + "\n")
+	}
+	b.WriteString("\n")
+	b.WriteString(m.applyViewport.View())
+	b.WriteString("\n\n")
+	b.WriteString(m.keyHint(""))
+	return style.Render(b.String())
+}
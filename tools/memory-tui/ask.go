@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Questions to Tom about the memories: /ask QUESTION sends the marked
+// memories, or the listed ones when nothing is marked, with the question to
+// Tom, who answers from them alone; the answer shows in a scrollable modal.
+// /filter narrows the list to the memories worth asking about first.
+const askPrompt = "Answer my question from these notes from my memory only, saying so when they do not hold " +
+	"the answer. Do not use any tool. Reply in the language of the question.\n\nQuestion: %s\n\nNotes:"
+
+// Renders the Markdown of the answers
+var answerMarkdown markdownCache
+
+type askAnswerMsg struct {
+	question string
+	answer   string
+	count    int // Memories sent
+}
+
+func runAskCommand(m model, args string) (tea.Model, tea.Cmd) {
+	memories := m.selectedMemories()
+	switch {
+	case len(memories) == 0:
+		m.message = tr("No memory to ask about")
+		return m, nil
+	case len(memories) > maxProcessMemories:
+		m.message = tr("%d memories are too many to ask about, mark or /filter %d at most", len(memories), maxProcessMemories)
+		return m, nil
+	}
+
+	m.loading = true
+	m.setFocus(focusContent)
+	api, prompt := m.api, withFileContext(memoriesPrompt(fmt.Sprintf(askPrompt, args), memories), m.promptFiles())
+	m.fileContext = nil
+	return m, func() tea.Msg {
+		answer, err := api.Process(prompt)
+		if err != nil {
+			return errMsg{err}
+		}
+		answer = strings.TrimSpace(answer)
+		runHook(api.Hooks, hookResponse, map[string]interface{}{"source": "ask", "prompt": args, "answer": answer})
+		return askAnswerMsg{args, answer, len(memories)}
+	}
+}
+
+func (m model) openAnswer(msg askAnswerMsg) (tea.Model, tea.Cmd) {
+	m.loading = false
+	m.askAnswer = msg
+	m.layoutAnswer()
+	cmd := m.showView(askView)
+	return m, cmd
+}
+
+// layoutAnswer sizes the answer to the terminal
+func (m *model) layoutAnswer() {
+	width := m.answerWidth()
+	m.askViewport = viewport.New(width, max(5, m.height-14))
+	m.askViewport.SetContent(answerMarkdown.render(m.askAnswer.answer, width))
+}
+
+func (m model) answerWidth() int {
+	return max(20, min(80, m.width-10)-6)
+}
+
+func (m model) updateAskView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q", "enter":
+		cmd := m.showView(listView)
+		return m, cmd
+	case "p":
+		return m.pinAnswer("ask", m.askAnswer.question, m.askAnswer.answer)
+	case "g", "home":
+		m.askViewport.GotoTop()
+		return m, nil
+	case "G", "end":
+		m.askViewport.GotoBottom()
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.askViewport, cmd = m.askViewport.Update(msg)
+	return m, cmd
+}
+
+func (m model) renderAskModal() string {
+	modalWidth := min(80, m.width-10)
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(tr("💬 Tom's Answer")))
+	b.WriteString("\n\n")
+	b.WriteString(selectedItemStyle.Render(wrapText(m.askAnswer.question, m.answerWidth())))
+	b.WriteString("\n")
+	if m.askAnswer.count > 0 {
+		b.WriteString(helpStyle.Render(tr("From %d memories", m.askAnswer.count)))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	b.WriteString(m.askViewport.View())
+	b.WriteString("\n\n")
+	b.WriteString(m.keyHint(""))
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, modalStyle.Width(modalWidth).Render(b.String()))
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/bubbletea"
+)
+
+// Audit log: every add and delete sent through memory-tui, one row per change
+// in the local store, see store.go, to reconstruct a cleanup session afterwards.
+// Only hashes of the texts are kept, the /audit viewer names the memories
+// still loaded. Dry runs change nothing and are not recorded.
+const (
+	auditHashSize = 12   // Hex digits of the SHA-256 kept
+	auditTailSize = 1000 // Entries loaded by the viewer
+)
+
+type auditEntry struct {
+	At     string `json:"at"`
+	Action string `json:"action"` // "add" or "delete"
+	ID     string `json:"id,omitempty"`
+	Before string `json:"before,omitempty"` // Hash of the text before the change
+	After  string `json:"after,omitempty"`  // Hash of the text after the change
+	Server string `json:"server"`
+}
+
+type auditLoadedMsg struct {
+	entries []auditEntry
+	err     error
+}
+
+// textHash identifies a text in the audit log without keeping it
+func textHash(text string) string {
+	if text == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])[:auditHashSize]
+}
+
+// recordAudit appends a change to the audit log, a failure only being logged
+// since the change itself went through
+func (api *MemoryAPI) recordAudit(action, id, before, after string) {
+	entry := auditEntry{
+		At:     time.Now().Format(time.RFC3339),
+		Action: action,
+		ID:     id,
+		Before: textHash(before),
+		After:  textHash(after),
+		Server: api.ServerURL,
+	}
+	if err := appendAudit(entry); err != nil {
+		logf("WARN", "audit log: %v", err)
+	}
+}
+
+func appendAudit(entry auditEntry) error {
+	db, err := openStore()
+	if err != nil {
+		return err
+	}
+	return insertAudit(db, entry)
+}
+
+func loadAudit() tea.Msg {
+	entries, err := auditTail(auditTailSize)
+	return auditLoadedMsg{entries, err}
+}
+
+func (m model) openAudit() (tea.Model, tea.Cmd) {
+	m.showView(auditView)
+	m.auditViewport = viewport.New(max(20, m.width-8), max(5, m.height-12))
+	return m, loadAudit
+}
+
+func (m model) handleAuditLoaded(msg auditLoadedMsg) (tea.Model, tea.Cmd) {
+	if m.state != auditView {
+		return m, nil
+	}
+	if msg.err != nil {
+		m.err = msg.err
+	}
+	m.auditEntries = msg.entries
+	m.refreshAuditViewport()
+	m.auditViewport.GotoBottom()
+	return m, nil
+}
+
+// refreshAuditViewport lists the changes, oldest first, with the text of the
+// memories still loaded
+func (m *model) refreshAuditViewport() {
+	known := map[string]string{}
+	for _, mem := range m.memories {
+		known[textHash(mem.Memory)] = mem.Memory
+	}
+
+	var b strings.Builder
+	if len(m.auditEntries) == 0 {
+		b.WriteString(helpStyle.Render(tr("No change recorded yet")))
+	}
+	width := max(20, m.auditViewport.Width-48)
+	for _, e := range m.auditEntries {
+		hashes := e.Before + " → " + e.After
+		if e.Before == "" {
+			hashes = "+ " + e.After
+		} else if e.After == "" {
+			hashes = "- " + e.Before
+		}
+		line := fmt.Sprintf("%s  %-6s %-8s %s", formatTime(e.At), e.Action, truncateString(e.ID, 8), hashes)
+		if text, ok := known[e.After]; ok && e.After != "" {
+			line += "  " + helpStyle.Render(truncateString(singleLine(text), width))
+		}
+		b.WriteString(line + "\n")
+	}
+	m.auditViewport.SetContent(b.String())
+}
+
+func (m model) updateAuditView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		m.showView(listView)
+		m.auditEntries = nil
+		return m, nil
+	case "r":
+		return m, loadAudit
+	case "G", "end":
+		m.auditViewport.GotoBottom()
+		return m, nil
+	case "g", "home":
+		m.auditViewport.GotoTop()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.auditViewport, cmd = m.auditViewport.Update(msg)
+	return m, cmd
+}
+
+func (m model) renderAuditView() string {
+	style := contentBoxStyle.Width(m.width - 4)
+	if m.focus == focusContent {
+		style = contentBoxFocusedStyle.Width(m.width - 4)
+	}
+
+	path, _ := storePath()
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(tr("🧾 Audit")))
+	b.WriteString(" " + helpStyle.Render(tr("%s • %d changes", path, len(m.auditEntries))))
+	b.WriteString("\n\n")
+	b.WriteString(m.auditViewport.View())
+	b.WriteString("\n\n")
+	b.WriteString(m.keyHint(""))
+	return style.Render(b.String())
+}
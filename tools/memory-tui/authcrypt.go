@@ -0,0 +1,159 @@
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Passphrase encryption of the credentials file: AES-256-GCM with a key
+// derived by scrypt, stored as "tom-enc:v1:" + base64(salt | nonce | ciphertext)
+const encryptedAuthPrefix = "tom-enc:v1:"
+
+const (
+	scryptN    = 1 << 15
+	scryptR    = 8
+	scryptP    = 1
+	saltLength = 16
+)
+
+var (
+	errPassphraseRequired = errors.New("credentials are encrypted, passphrase required")
+	errWrongPassphrase    = errors.New("wrong passphrase")
+)
+
+func isEncryptedAuth(data []byte) bool {
+	return strings.HasPrefix(string(data), encryptedAuthPrefix)
+}
+
+func deriveKey(passphrase string, salt []byte) ([]byte, error) {
+	return scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, 32)
+}
+
+func encryptAuth(plaintext []byte, passphrase string) ([]byte, error) {
+	salt := make([]byte, saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	key, err := deriveKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	sealed := gcm.Seal(nil, nonce, plaintext, []byte(encryptedAuthPrefix))
+	payload := append(append(salt, nonce...), sealed...)
+	return []byte(encryptedAuthPrefix + base64.StdEncoding.EncodeToString(payload)), nil
+}
+
+func decryptAuth(data []byte, passphrase string) ([]byte, error) {
+	if passphrase == "" {
+		return nil, errPassphraseRequired
+	}
+
+	payload, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(string(data), encryptedAuthPrefix))
+	if err != nil {
+		return nil, fmt.Errorf("corrupted credentials file: %w", err)
+	}
+	if len(payload) < saltLength {
+		return nil, fmt.Errorf("corrupted credentials file")
+	}
+	salt, payload := payload[:saltLength], payload[saltLength:]
+
+	key, err := deriveKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(payload) < gcm.NonceSize() {
+		return nil, fmt.Errorf("corrupted credentials file")
+	}
+	nonce, sealed := payload[:gcm.NonceSize()], payload[gcm.NonceSize():]
+
+	plaintext, err := gcm.Open(nil, nonce, sealed, []byte(encryptedAuthPrefix))
+	if err != nil {
+		return nil, errWrongPassphrase
+	}
+	return plaintext, nil
+}
+
+func (m model) updatePassphraseView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	case tea.KeyEsc:
+		// Skip the stored credentials and log in again
+		m.err = nil
+		m.passphraseInput.Blur()
+		m.state = loginView
+		return m, nil
+	case tea.KeyEnter:
+		if m.passphraseInput.Value() == "" {
+			return m, nil
+		}
+		m.err = nil
+		m.passphrase = m.passphraseInput.Value()
+		m.passphraseInput.Reset()
+		m.passphraseInput.Blur()
+		m.state = connectingView
+		if m.choosePassphrase {
+			m.serverInput.SetValue(strings.TrimSuffix(m.serverInput.Value(), "/"))
+			return m, tea.Batch(m.spinner.Tick, login(m))
+		}
+		return m, tea.Batch(m.spinner.Tick, checkAuth(m.passphrase))
+	}
+
+	var cmd tea.Cmd
+	m.passphraseInput, cmd = m.passphraseInput.Update(msg)
+	return m, cmd
+}
+
+func (m model) renderPassphraseView() string {
+	var b strings.Builder
+	if m.choosePassphrase {
+		b.WriteString(tr("Choose a passphrase") + "\n\n")
+		b.WriteString(tr("It encrypts the credentials saved in ~/.tom/auth") + "\n")
+		b.WriteString(tr("and will be asked at each startup.") + "\n\n")
+	} else {
+		b.WriteString(tr("Unlock saved credentials") + "\n\n")
+	}
+	b.WriteString(m.passphraseInput.View())
+	if m.err != nil {
+		b.WriteString("\n\n")
+		b.WriteString(loginErrorStyle.Render("❌ " + m.err.Error()))
+	}
+	if m.choosePassphrase {
+		b.WriteString("\n\n" + tr("(enter to continue, esc to go back)"))
+	} else {
+		b.WriteString("\n\n" + tr("(enter to unlock, esc to log in again)"))
+	}
+	ui := loginBoxStyle.Render(b.String())
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, ui)
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/charmbracelet/bubbletea"
+)
+
+// Bell: when a request ends while the terminal is unfocused, or after
+// "bell_after" seconds, memory-tui rings the terminal bell ("bell") or runs
+// the "bell_sound" command, so a long search or batch needs no watching.
+// Focus comes from the terminal focus reports (xterm mode 1004); terminals
+// without them count as focused and only the delay applies.
+const defaultBellAfter = 10
+
+const (
+	focusReportsOn  = "\x1b[?1004h"
+	focusReportsOff = "\x1b[?1004l"
+)
+
+// Bubble Tea v0.25 passes the focus reports as unknown CSI sequences
+var focusReports = map[string]bool{
+	fmt.Sprintf("?CSI%+v?", []byte("I")): true,
+	fmt.Sprintf("?CSI%+v?", []byte("O")): false,
+}
+
+func bellEnabled(cfg config) bool {
+	return cfg.Bell || cfg.BellSound != ""
+}
+
+// reportFocus asks the terminal for focus reports when the bell is enabled
+// and returns the function turning them off
+func reportFocus(cfg config) func() {
+	if !bellEnabled(cfg) {
+		return func() {}
+	}
+	fmt.Fprint(os.Stdout, focusReportsOn)
+	return func() { fmt.Fprint(os.Stdout, focusReportsOff) }
+}
+
+func (m model) busy() bool {
+	return m.loading || m.batch != nil
+}
+
+// Update tracks the terminal focus, the idle lock and the running requests
+// around the handling of each message, ringing when a request ends
+// unattended and refreshing the transfer progress meanwhile
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if _, ok := msg.(transferTickMsg); ok {
+		if m.busy() {
+			return m, transferTick()
+		}
+		return m, nil
+	}
+	if m.state == lockView || isIdleTick(msg) {
+		return m.updateLocked(msg)
+	}
+	if _, ok := msg.(tea.KeyMsg); ok {
+		m.lastActivity = time.Now()
+	}
+	if s, ok := msg.(fmt.Stringer); ok {
+		if focused, ok := focusReports[s.String()]; ok {
+			m.unfocused = !focused
+			return m, nil
+		}
+	}
+
+	wasBusy := m.busy()
+	updated, cmd := m.update(msg)
+	next, ok := updated.(model)
+	if !ok {
+		return updated, cmd
+	}
+	switch {
+	case next.busy() && !wasBusy:
+		next.busySince = time.Now()
+		cmd = tea.Batch(cmd, transferTick())
+	case !next.busy() && wasBusy && next.shouldRing():
+		cmd = tea.Batch(cmd, ringBell(next.config))
+	}
+	return next, cmd
+}
+
+func (m model) shouldRing() bool {
+	if !bellEnabled(m.config) {
+		return false
+	}
+	late := m.config.BellAfter > 0 && time.Since(m.busySince) >= time.Duration(m.config.BellAfter)*time.Second
+	return m.unfocused || late
+}
+
+func ringBell(cfg config) tea.Cmd {
+	return func() tea.Msg {
+		if cfg.BellSound == "" {
+			fmt.Fprint(os.Stdout, "\a")
+			return nil
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), hookTimeout)
+		defer cancel()
+		if err := exec.CommandContext(ctx, "sh", "-c", cfg.BellSound).Run(); err != nil {
+			logf("WARN", "bell sound failed: %v", err)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,314 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Mickael-Roger/tom/tools/memory-tui/pkg/memoryclient"
+)
+
+// `memory-tui bench`: load test of the memory API of a staging server, to
+// size the mem0 and Qdrant backends. Synthetic memories, tagged with the
+// run, are added and searched by concurrent workers, each operation in its
+// own phase, and the throughput and latency percentiles of each phase are
+// reported. The memories added are deleted at the end, after an interrupt
+// too, a "delete" operation measuring that cleanup.
+const (
+	benchDefaultOps       = "add,search"
+	benchDefaultRequests  = 100
+	benchRequestTimeout   = 2 * time.Minute // mem0 asks a model at each add
+	benchCleanupSearches  = 5               // For the memories mem0 rewrote
+	benchCleanupSearchMax = 100
+)
+
+var benchOps = []string{"add", "search", "delete"}
+
+// Vocabulary of the synthetic memories and queries
+var (
+	benchPeople   = []string{"Alice", "Bruno", "Chloé", "David", "Emma", "Farid", "Gaëlle", "Hugo"}
+	benchVerbs    = []string{"likes", "dislikes", "is learning", "collects", "often talks about", "is allergic to"}
+	benchTopics   = []string{"jazz", "sailing", "Italian cooking", "chess", "orchids", "vintage cameras", "trail running", "pottery", "astronomy", "board games"}
+	benchContexts = []string{"since childhood", "at the weekend", "with colleagues", "on holiday", "every morning", "when it rains"}
+)
+
+type benchResult struct {
+	Op         string           `json:"op"`
+	Requests   int              `json:"requests"`
+	Errors     int              `json:"errors"`
+	FirstError string           `json:"first_error,omitempty"`
+	DurationMS int64            `json:"duration_ms"`
+	Throughput float64          `json:"throughput"` // Successful requests per second
+	LatencyMS  map[string]int64 `json:"latency_ms"` // p50, p90, p95, p99 and max of the successful requests
+}
+
+type benchReport struct {
+	Server      string        `json:"server"`
+	Run         string        `json:"run"` // Tag of the memories
+	Concurrency int           `json:"concurrency"`
+	Results     []benchResult `json:"results"`
+	Deleted     int           `json:"deleted"`
+	Left        []string      `json:"left,omitempty"` // IDs the cleanup could not delete
+}
+
+type bench struct {
+	memories    *memoryclient.Client
+	run         string
+	concurrency int
+
+	mu    sync.Mutex
+	added map[string]bool // IDs to delete
+}
+
+func runBench(server, user, passwordFile, ops string, concurrency, requests int, format string) error {
+	phases := strings.Split(ops, ",")
+	for _, op := range phases {
+		if !containsString(benchOps, op) {
+			return fmt.Errorf("unknown operation %q, expected %s", op, strings.Join(benchOps, ", "))
+		}
+	}
+	switch {
+	case server == "" || user == "":
+		return errors.New("--server and --user are required")
+	case concurrency < 1 || requests < 1:
+		return errors.New("--concurrency and --requests must be positive")
+	case format != smokeFormatJSON && format != smokeFormatText:
+		return fmt.Errorf("unknown --format %q, expected json or text", format)
+	}
+	password, err := readPassword(passwordFile)
+	if err != nil {
+		return err
+	}
+	server = strings.TrimRight(server, "/")
+
+	client := NewMemoryAPI(server).Client
+	if _, err := postLogin(client, server, user, password); err != nil {
+		return err
+	}
+	b := &bench{
+		memories:    memoryclient.NewWithHTTPClient(server, client),
+		run:         "bench-" + strings.TrimPrefix(smokeWord(), "smoke-"),
+		concurrency: concurrency,
+		added:       map[string]bool{},
+	}
+	report := benchReport{Server: server, Run: b.run, Concurrency: concurrency}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	for _, op := range phases {
+		if op == "delete" || ctx.Err() != nil {
+			continue // Measured by the cleanup
+		}
+		fmt.Fprintf(os.Stderr, "%s: %d requests, %d at a time...\n", op, requests, concurrency)
+		report.Results = append(report.Results, b.phase(ctx, op, requests))
+	}
+	stop()
+
+	// The cleanup runs after an interrupt too, on a context of its own
+	fmt.Fprintf(os.Stderr, "cleanup of %s...\n", b.run)
+	deletion, left := b.cleanup()
+	report.Deleted = deletion.Requests - deletion.Errors
+	report.Left = left
+	if containsString(phases, "delete") {
+		report.Results = append(report.Results, deletion)
+	}
+
+	if err := printBenchReport(report, format); err != nil {
+		return err
+	}
+	if len(left) > 0 {
+		return fmt.Errorf("%d memories of %s left on the server", len(left), b.run)
+	}
+	return nil
+}
+
+// phase sends requests of an operation from the workers, until done or ctx
+// ends
+func (b *bench) phase(ctx context.Context, op string, requests int) benchResult {
+	var next atomic.Int64
+	return b.measure(op, func(int) bool { return ctx.Err() == nil && next.Add(1) <= int64(requests) }, func(i int) error {
+		rctx, cancel := context.WithTimeout(ctx, benchRequestTimeout)
+		defer cancel()
+		rng := rand.New(rand.NewSource(time.Now().UnixNano() + int64(i)))
+		switch op {
+		case "add":
+			added, err := b.memories.Add(rctx, benchMemory(rng, b.run), map[string]interface{}{"source": "bench", "run": b.run})
+			if err == nil && added.ID != "" {
+				b.mu.Lock()
+				b.added[added.ID] = true
+				b.mu.Unlock()
+			}
+			return err
+		default:
+			_, err := b.memories.Search(rctx, benchQuery(rng), 10)
+			return err
+		}
+	})
+}
+
+// measure runs the requests from the workers while more says so, timing
+// the successful ones
+func (b *bench) measure(op string, more func(worker int) bool, request func(worker int) error) benchResult {
+	result := benchResult{Op: op}
+	var (
+		mu        sync.Mutex
+		latencies []time.Duration
+		wg        sync.WaitGroup
+	)
+	start := time.Now()
+	for w := 0; w < b.concurrency; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for more(w) {
+				t := time.Now()
+				err := request(w)
+				d := time.Since(t)
+				mu.Lock()
+				result.Requests++
+				if err != nil {
+					result.Errors++
+					if result.FirstError == "" {
+						result.FirstError = err.Error()
+					}
+				} else {
+					latencies = append(latencies, d)
+				}
+				mu.Unlock()
+			}
+		}(w)
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	result.DurationMS = elapsed.Milliseconds()
+	if elapsed > 0 {
+		result.Throughput = float64(len(latencies)) / elapsed.Seconds()
+	}
+	result.LatencyMS = benchPercentiles(latencies)
+	return result
+}
+
+// cleanup deletes the memories of the run, those added and those mem0
+// rewrote under another ID that the searches for the run tag find
+func (b *bench) cleanup() (benchResult, []string) {
+	ctx := context.Background()
+	for i := 0; i < benchCleanupSearches; i++ {
+		rctx, cancel := context.WithTimeout(ctx, benchRequestTimeout)
+		found, err := b.memories.Search(rctx, b.run+" "+benchTopics[i%len(benchTopics)], benchCleanupSearchMax)
+		cancel()
+		if err != nil {
+			logf("WARN", "bench: cleanup search: %v", err)
+			break
+		}
+		b.mu.Lock()
+		for _, m := range found {
+			if strings.Contains(m.Memory, b.run) {
+				b.added[m.ID] = true
+			}
+		}
+		b.mu.Unlock()
+	}
+
+	ids := make(chan string, len(b.added))
+	for id := range b.added {
+		ids <- id
+	}
+	close(ids)
+	var (
+		mu      sync.Mutex
+		left    []string
+		current = make([]string, b.concurrency) // ID each worker deletes
+	)
+	more := func(w int) bool {
+		id, ok := <-ids
+		current[w] = id
+		return ok
+	}
+	result := b.measure("delete", more, func(w int) error {
+		id := current[w]
+		rctx, cancel := context.WithTimeout(ctx, benchRequestTimeout)
+		defer cancel()
+		err := b.memories.Delete(rctx, id)
+		if errors.Is(err, memoryclient.ErrNotFound) {
+			return nil
+		}
+		if err != nil {
+			mu.Lock()
+			left = append(left, id)
+			mu.Unlock()
+		}
+		return err
+	})
+	return result, left
+}
+
+// benchMemory is a synthetic memory of the run, such as "Emma collects
+// orchids at the weekend (bench-1f2e3d4c5b6a)."
+func benchMemory(rng *rand.Rand, run string) string {
+	return fmt.Sprintf("%s %s %s %s (%s).",
+		benchPeople[rng.Intn(len(benchPeople))], benchVerbs[rng.Intn(len(benchVerbs))],
+		benchTopics[rng.Intn(len(benchTopics))], benchContexts[rng.Intn(len(benchContexts))], run)
+}
+
+func benchQuery(rng *rand.Rand) string {
+	return fmt.Sprintf("What does %s think about %s?", benchPeople[rng.Intn(len(benchPeople))], benchTopics[rng.Intn(len(benchTopics))])
+}
+
+// benchPercentiles returns the nearest-rank percentiles of the latencies
+func benchPercentiles(latencies []time.Duration) map[string]int64 {
+	p := map[string]int64{}
+	if len(latencies) == 0 {
+		return p
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	for _, q := range []int{50, 90, 95, 99} {
+		rank := (q*len(latencies) + 99) / 100
+		p[fmt.Sprintf("p%d", q)] = latencies[max(rank-1, 0)].Milliseconds()
+	}
+	p["max"] = latencies[len(latencies)-1].Milliseconds()
+	return p
+}
+
+func printBenchReport(report benchReport, format string) error {
+	if format == smokeFormatJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	fmt.Printf("%s, %d workers, run %s\n\n", report.Server, report.Concurrency, report.Run)
+	fmt.Printf("%-7s %8s %7s %10s %8s %8s %8s %8s %8s\n", "op", "requests", "errors", "req/s", "p50", "p90", "p95", "p99", "max")
+	for _, r := range report.Results {
+		fmt.Printf("%-7s %8d %7d %10.2f %6dms %6dms %6dms %6dms %6dms\n", r.Op, r.Requests, r.Errors, r.Throughput,
+			r.LatencyMS["p50"], r.LatencyMS["p90"], r.LatencyMS["p95"], r.LatencyMS["p99"], r.LatencyMS["max"])
+	}
+	for _, r := range report.Results {
+		if r.FirstError != "" {
+			fmt.Printf("\n%s: first error: %s", r.Op, r.FirstError)
+		}
+	}
+	fmt.Printf("\n%d memories deleted", report.Deleted)
+	if len(report.Left) > 0 {
+		fmt.Printf(", %d left: %s", len(report.Left), strings.Join(report.Left, " "))
+	}
+	fmt.Println()
+	return nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"github.com/charmbracelet/bubbletea"
+)
+
+// Bookmarks in the memory list, as in vi: m then a letter bookmarks the
+// selected memory, ' then the letter selects it again, and '' returns to
+// the memory selected before the last jump. They last for the session and
+// follow the memory, not its position, across reloads and sorts.
+
+// bookmarkKey waits for the letter after m or '
+func (m model) bookmarkKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	pending := m.bookmarkPending
+	m.bookmarkPending = 0
+	key := msg.String()
+	if msg.Type != tea.KeyRunes || len(msg.Runes) != 1 {
+		m.message = ""
+		return m, nil
+	}
+	letter := msg.Runes[0]
+
+	if pending == 'm' {
+		if !isBookmarkLetter(letter) {
+			m.message = tr("Bookmarks are letters, a to z")
+			return m, nil
+		}
+		item, ok := m.list.SelectedItem().(memoryItem)
+		if !ok {
+			return m, nil
+		}
+		if m.bookmarks == nil {
+			m.bookmarks = map[rune]string{}
+		}
+		m.bookmarks[letter] = item.memory.ID
+		m.message = tr("Bookmark %s set", key)
+		return m, nil
+	}
+
+	id := m.lastJump
+	if letter != '\'' {
+		var ok bool
+		if id, ok = m.bookmarks[letter]; !ok {
+			m.message = tr("No bookmark %s", key)
+			return m, nil
+		}
+	}
+	if id == "" {
+		return m, nil
+	}
+	return m.jumpToMemory(id), nil
+}
+
+func isBookmarkLetter(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+// jumpToMemory selects the memory in the list, remembering the one left
+func (m model) jumpToMemory(id string) model {
+	for i, item := range m.list.VisibleItems() {
+		if mi, ok := item.(memoryItem); ok && mi.memory.ID == id {
+			if current, ok := m.list.SelectedItem().(memoryItem); ok {
+				m.lastJump = current.memory.ID
+			}
+			m.list.Select(i)
+			m.message = ""
+			return m
+		}
+	}
+	m.message = tr("The bookmarked memory is not in the list, deleted or filtered out")
+	return m
+}
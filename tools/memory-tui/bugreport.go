@@ -0,0 +1,230 @@
+package main
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// Bug report bundle: `memory-tui bugreport` gathers what an issue needs into
+// a tarball to attach as is: the client and server versions, the terminal,
+// the configuration without its secrets, the last errors and the log, every
+// text scrubbed of emails, cards, keys, tokens and passwords first. Memory
+// texts only reach the log in dry run, and are scrubbed the same way.
+const (
+	bugReportErrors  = 20 // Last ERROR lines of the report
+	bugReportContext = 5  // Log lines kept before each of them
+)
+
+// Configuration keys whose values are dropped whatever they hold: hook
+// commands and redaction patterns tend to embed the values they protect
+var bugReportSecretKeys = regexp.MustCompile(`(?i)password|passphrase|secret|token|cookie|key|hooks|redact_patterns|bell_sound`)
+
+// Session cookies and basic auth in URLs, on top of the redaction rules
+var bugReportCredentials = regexp.MustCompile(`(?i)(session_id|cookie)=[^\s;&"]+|://[^/\s:@]+:[^/\s@]+@`)
+
+// scrubText masks the sensitive values of text, whatever "redact" says
+func scrubText(text string) string {
+	names := []string{"email", "card", "api_key"}
+	for _, name := range names {
+		rule := builtinRedactions[name]
+		text = rule.re.ReplaceAllStringFunc(text, func(match string) string {
+			if rule.valid != nil && !rule.valid(match) {
+				return match
+			}
+			return "[" + name + "]"
+		})
+	}
+	for _, rule := range redactionRules {
+		text = rule.re.ReplaceAllString(text, "["+rule.name+"]")
+	}
+	for _, d := range secretDetectors {
+		text = d.re.ReplaceAllString(text, "["+d.kind+"]")
+	}
+	return bugReportCredentials.ReplaceAllString(text, "[credentials]")
+}
+
+// scrubConfig drops the secret values of a configuration, recursively
+func scrubConfig(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if bugReportSecretKeys.MatchString(key) {
+				v[key] = "[removed]"
+				continue
+			}
+			v[key] = scrubConfig(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = scrubConfig(item)
+		}
+	case string:
+		return scrubText(v)
+	}
+	return value
+}
+
+// bugReportConfig is the configuration file without its secrets
+func bugReportConfig() string {
+	path, err := getConfigFilePath()
+	if err != nil {
+		return fmt.Sprintf("unavailable: %v\n", err)
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "none, defaults used\n"
+	}
+	if err != nil {
+		return fmt.Sprintf("unavailable: %v\n", err)
+	}
+	var raw interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Sprintf("invalid: %v\n", err)
+	}
+	data, _ = json.MarshalIndent(scrubConfig(raw), "", "  ")
+	return string(data) + "\n"
+}
+
+// bugReportServer describes the server of the saved credentials, logging in
+// as the list command does
+func bugReportServer() string {
+	sess, err := openSession()
+	if err != nil {
+		return fmt.Sprintf("unavailable: %v\n", err)
+	}
+	info, err := fetchServerInfo(sess.API.Client, sess.API.ServerURL)
+	if err != nil {
+		return fmt.Sprintf("%s, status unavailable: %v\n", sess.API.ServerURL, err)
+	}
+	modules := info.enabledModules()
+	if modules == nil {
+		modules = []string{"not listed"}
+	}
+	return fmt.Sprintf("%s\nAPI version: v%d\nCapabilities: %s\nModules: %s\nRead-only: %v\n",
+		sess.API.ServerURL, info.APIVersion, strings.Join(info.Capabilities, ", "), strings.Join(modules, ", "), info.ReadOnly)
+}
+
+func bugReportTerminal() string {
+	var b strings.Builder
+	for _, name := range []string{"TERM", "COLORTERM", "TERM_PROGRAM", "TERM_PROGRAM_VERSION", "LANG", "LC_ALL", "TMUX", "STY", "SSH_CONNECTION"} {
+		if value, ok := os.LookupEnv(name); ok {
+			if name == "SSH_CONNECTION" || name == "TMUX" {
+				value = "set"
+			}
+			fmt.Fprintf(&b, "%s=%s\n", name, value)
+		}
+	}
+	if width, height, err := term.GetSize(int(os.Stdout.Fd())); err == nil {
+		fmt.Fprintf(&b, "Size: %dx%d\n", width, height)
+	} else {
+		b.WriteString("Size: not a terminal\n")
+	}
+	return b.String()
+}
+
+// readLogLines returns the lines of the log and of its rotated copy
+func readLogLines() ([]string, error) {
+	path, err := logFilePath()
+	if err != nil {
+		return nil, err
+	}
+	var lines []string
+	for _, p := range []string{path + ".1", path} {
+		f, err := os.Open(p)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+		f.Close()
+	}
+	return lines, nil
+}
+
+// lastErrors returns the last ERROR lines of the log, each with the lines
+// leading to it
+func lastErrors(lines []string) string {
+	var starts []int
+	for i := len(lines) - 1; i >= 0 && len(starts) < bugReportErrors; i-- {
+		if logLevels[lineLevel(lines[i])] == "ERROR" {
+			starts = append(starts, i)
+		}
+	}
+	if len(starts) == 0 {
+		return "none in the log\n"
+	}
+	var b strings.Builder
+	for i := len(starts) - 1; i >= 0; i-- {
+		end := starts[i]
+		for _, line := range lines[max(0, end-bugReportContext) : end+1] {
+			b.WriteString(line + "\n")
+		}
+		b.WriteString("--\n")
+	}
+	return b.String()
+}
+
+// writeBugReport writes the bundle to path
+func writeBugReport(path string) error {
+	cfg, _ := loadConfig()
+	applyRedactionConfig(cfg)
+
+	lines, err := readLogLines()
+	logText := strings.Join(lines, "\n") + "\n"
+	if err != nil {
+		logText = fmt.Sprintf("unavailable: %v\n", err)
+	}
+	server := bugReportServer()
+
+	var report strings.Builder
+	fmt.Fprintf(&report, "# Client\n%s\nGo %s %s/%s\n\n", versionString(), runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(&report, "# Server\n%s\n", server)
+	fmt.Fprintf(&report, "# Terminal\n%s\n", bugReportTerminal())
+	fmt.Fprintf(&report, "# Configuration\n%s\n", bugReportConfig())
+	fmt.Fprintf(&report, "# Last errors\n%s", lastErrors(lines))
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	now := time.Now()
+	for _, file := range []struct{ name, text string }{
+		{"report.txt", report.String()},
+		{"memory-tui.log", logText},
+	} {
+		data := []byte(scrubText(file.text))
+		if err := tw.WriteHeader(&tar.Header{Name: file.name, Mode: 0600, Size: int64(len(data)), ModTime: now}); err != nil {
+			return err
+		}
+		if _, err := tw.Write(data); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Bulk operations: servers advertising the "batch" capability in /status take
+// up to bulkBatchSize memories in one POST /batch/add or /batch/delete, so
+// imports, splits and bulk deletes send one request per batch. Other servers
+// get one request per memory, paced to "bulk_rate" requests per second with
+// some jitter, so a large import does not swamp a small server such as a
+// Raspberry Pi.
+const (
+	capBatch        = "batch"
+	bulkBatchSize   = 50
+	defaultBulkRate = 4.0 // Requests per second
+	bulkJitter      = 0.5 // Pauses vary by up to half their length
+)
+
+// batchAddItem is a memory of POST /batch/add
+type batchAddItem struct {
+	Text     string                 `json:"text"`
+	Metadata map[string]interface{} `json:"metadata"`
+
+	label string // Names the memory in errors, e.g. the imported note
+}
+
+type bulkPacer struct {
+	mu   sync.Mutex
+	rate float64 // Requests per second, 0 for no pacing
+	next time.Time
+}
+
+var bulkPace = &bulkPacer{rate: defaultBulkRate}
+
+func applyBulkConfig(cfg config) {
+	bulkPace.mu.Lock()
+	defer bulkPace.mu.Unlock()
+	bulkPace.rate = cfg.BulkRate
+}
+
+// wait blocks until the next bulk request may go, the pauses between two
+// requests varying randomly around 1/rate
+func (p *bulkPacer) wait() {
+	p.mu.Lock()
+	if p.rate <= 0 {
+		p.mu.Unlock()
+		return
+	}
+	interval := float64(time.Second) / p.rate
+	pause := time.Duration(interval * (1 + bulkJitter*(2*rand.Float64()-1)))
+	at := p.next
+	if now := time.Now(); at.Before(now) {
+		at = now
+	}
+	p.next = at.Add(pause)
+	p.mu.Unlock()
+	time.Sleep(time.Until(at))
+}
+
+// bulkStepSize is the number of memories sent per request
+func (api *MemoryAPI) bulkStepSize() int {
+	if api.Batch {
+		return bulkBatchSize
+	}
+	return 1
+}
+
+func (api *MemoryAPI) postBatch(endpoint string, payload interface{}) (APIResponse, error) {
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return APIResponse{}, err
+	}
+
+	resp, err := api.Client.Post(api.buildURL(endpoint), "application/json", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return APIResponse{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return APIResponse{}, errSessionExpired
+	}
+
+	var apiResp APIResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return APIResponse{}, err
+	}
+	if apiResp.Error != "" {
+		return APIResponse{}, fmt.Errorf("API error: %s", apiResp.Error)
+	}
+	return apiResp, nil
+}
+
+// AddMemories adds the memories in a single request, see capBatch
+func (api *MemoryAPI) AddMemories(items []batchAddItem) error {
+	payload := map[string]interface{}{"memories": items}
+
+	if err := api.refuseReadOnly(); err != nil {
+		return err
+	}
+	if api.skipDryRun("POST", "/batch/add", payload) {
+		return nil
+	}
+
+	apiResp, err := api.postBatch("/batch/add", payload)
+	if err != nil {
+		return err
+	}
+
+	// The results come in the order of the memories sent
+	added := apiResp.Results.Results
+	for i, item := range items {
+		id := ""
+		if i < len(added) {
+			id = added[i].ID
+		}
+		api.recordAudit("add", id, "", item.Text)
+		runHook(api.Hooks, hookMemoryAdded, map[string]interface{}{"text": item.Text, "metadata": item.Metadata})
+	}
+	return nil
+}
+
+// DeleteMemories deletes the memories in a single request, see capBatch;
+// servers with a trash move them there
+func (api *MemoryAPI) DeleteMemories(memories []Memory) error {
+	ids := make([]string, len(memories))
+	for i, memory := range memories {
+		ids[i] = memory.ID
+	}
+	payload := map[string]interface{}{"ids": ids}
+
+	if err := api.refuseReadOnly(); err != nil {
+		return err
+	}
+	if api.skipDryRun("POST", "/batch/delete", payload) {
+		return nil
+	}
+
+	if _, err := api.postBatch("/batch/delete", payload); err != nil {
+		return err
+	}
+
+	action := "delete"
+	if api.SoftDelete {
+		action = "trash"
+	}
+	for _, memory := range memories {
+		api.recordAudit(action, memory.ID, memory.Memory, "")
+		runHook(api.Hooks, hookMemoryDeleted, map[string]interface{}{"id": memory.ID})
+	}
+	return nil
+}
+
+// addJob adds n memories built by item, in batches or one by one; sent is
+// called with the index of each memory added
+func (api *MemoryAPI) addJob(title string, n int, item func(i int) batchAddItem, sent func(i int)) *batchJob {
+	job := newBatchJob(title, n, nil)
+	job.stepSize = api.bulkStepSize()
+	job.step = func(i int) error {
+		end := min(i+job.stepSize, n)
+		items := make([]batchAddItem, 0, end-i)
+		for j := i; j < end; j++ {
+			items = append(items, item(j))
+		}
+
+		if api.Batch {
+			if err := api.AddMemories(items); err != nil {
+				return err
+			}
+		} else {
+			if !api.DryRun {
+				bulkPace.wait()
+			}
+			if err := api.AddMemory(items[0].Text, items[0].Metadata); err != nil {
+				if items[0].label != "" {
+					return fmt.Errorf("%s: %w", items[0].label, err)
+				}
+				return err
+			}
+		}
+
+		if sent != nil {
+			for j := i; j < end; j++ {
+				sent(j)
+			}
+		}
+		return nil
+	}
+	return job
+}
+
+// deleteJob deletes the memories, in batches or one by one
+func (api *MemoryAPI) deleteJob(title string, memories []Memory) *batchJob {
+	job := newBatchJob(title, len(memories), nil)
+	job.stepSize = api.bulkStepSize()
+	job.step = func(i int) error {
+		if api.Batch {
+			return api.DeleteMemories(memories[i:min(i+job.stepSize, len(memories))])
+		}
+		if !api.DryRun {
+			bulkPace.wait()
+		}
+		return api.DeleteMemory(memories[i])
+	}
+	return job
+}
@@ -0,0 +1,381 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/bubbletea"
+)
+
+// Local calculator: /calc and /conv are evaluated here, no request to Tom,
+// whose model would take seconds on a self-hosted server for 15% of 84.
+// /calc takes + - * / ^, parentheses, the constants pi and e, functions
+// such as sqrt and round, "mod", and percentages: "15% of 84", "84 + 15%".
+// /conv converts between the units of calcUnits, "/conv 10 km to mi".
+
+// calcParser evaluates an expression by recursive descent
+type calcParser struct {
+	tokens []string
+	pos    int
+}
+
+var calcFunctions = map[string]func(float64) float64{
+	"sqrt":  math.Sqrt,
+	"abs":   math.Abs,
+	"round": math.Round,
+	"floor": math.Floor,
+	"ceil":  math.Ceil,
+	"ln":    math.Log,
+	"log":   math.Log10,
+	"exp":   math.Exp,
+	"sin":   math.Sin,
+	"cos":   math.Cos,
+	"tan":   math.Tan,
+}
+
+var calcConstants = map[string]float64{"pi": math.Pi, "e": math.E}
+
+func evalExpression(expr string) (float64, error) {
+	tokens, err := tokenizeExpression(expr)
+	if err != nil {
+		return 0, err
+	}
+	if len(tokens) == 0 {
+		return 0, fmt.Errorf("empty expression")
+	}
+	p := &calcParser{tokens: tokens}
+	v, _, err := p.sum()
+	if err != nil {
+		return 0, err
+	}
+	if p.pos < len(p.tokens) {
+		return 0, fmt.Errorf("unexpected %q", p.tokens[p.pos])
+	}
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, fmt.Errorf("undefined result")
+	}
+	return v, nil
+}
+
+// tokenizeExpression splits numbers, words and operators; a comma is read
+// as a decimal point, "×" and "÷" as * and /
+func tokenizeExpression(expr string) ([]string, error) {
+	replacer := strings.NewReplacer(",", ".", "×", "*", "÷", "/", "**", "^")
+	runes := []rune(replacer.Replace(strings.ToLower(expr)))
+	var tokens []string
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsDigit(r) || r == '.':
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			// Exponent, 1e6 or 2.5e-3
+			if i+1 < len(runes) && runes[i] == 'e' && (unicode.IsDigit(runes[i+1]) ||
+				(i+2 < len(runes) && (runes[i+1] == '-' || runes[i+1] == '+') && unicode.IsDigit(runes[i+2]))) {
+				i += 2
+				for i < len(runes) && unicode.IsDigit(runes[i]) {
+					i++
+				}
+			}
+			tokens = append(tokens, string(runes[start:i]))
+		case unicode.IsLetter(r):
+			start := i
+			for i < len(runes) && unicode.IsLetter(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, string(runes[start:i]))
+		case strings.ContainsRune("+-*/^%()", r):
+			tokens = append(tokens, string(r))
+			i++
+		default:
+			return nil, fmt.Errorf("unexpected %q", string(r))
+		}
+	}
+	return tokens, nil
+}
+
+func (p *calcParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+// sum reads terms joined by + and -, "84 + 15%" adding 15% of 84; percent
+// reports a lone percentage, for the sum around it
+func (p *calcParser) sum() (v float64, percent bool, err error) {
+	if v, percent, err = p.product(); err != nil {
+		return 0, false, err
+	}
+	for op := p.peek(); op == "+" || op == "-"; op = p.peek() {
+		p.pos++
+		right, rightPercent, err := p.product()
+		if err != nil {
+			return 0, false, err
+		}
+		if rightPercent {
+			right *= v
+		}
+		if op == "+" {
+			v += right
+		} else {
+			v -= right
+		}
+		percent = false
+	}
+	return v, percent, nil
+}
+
+// product reads factors joined by *, /, "mod" and "of"
+func (p *calcParser) product() (v float64, percent bool, err error) {
+	if v, percent, err = p.unary(); err != nil {
+		return 0, false, err
+	}
+	for op := p.peek(); op == "*" || op == "/" || op == "mod" || op == "of"; op = p.peek() {
+		p.pos++
+		right, _, err := p.unary()
+		if err != nil {
+			return 0, false, err
+		}
+		switch op {
+		case "*", "of":
+			v *= right
+		case "/":
+			if right == 0 {
+				return 0, false, fmt.Errorf("division by zero")
+			}
+			v /= right
+		case "mod":
+			v = math.Mod(v, right)
+		}
+		percent = false
+	}
+	return v, percent, nil
+}
+
+func (p *calcParser) unary() (float64, bool, error) {
+	switch p.peek() {
+	case "-":
+		p.pos++
+		v, percent, err := p.unary()
+		return -v, percent, err
+	case "+":
+		p.pos++
+		return p.unary()
+	}
+	return p.power()
+}
+
+// power is right associative, 2^3^2 being 2^9
+func (p *calcParser) power() (float64, bool, error) {
+	base, percent, err := p.percentage()
+	if err != nil {
+		return 0, false, err
+	}
+	if p.peek() != "^" {
+		return base, percent, nil
+	}
+	p.pos++
+	exponent, _, err := p.unary()
+	if err != nil {
+		return 0, false, err
+	}
+	return math.Pow(base, exponent), false, nil
+}
+
+func (p *calcParser) percentage() (float64, bool, error) {
+	v, err := p.primary()
+	if err != nil {
+		return 0, false, err
+	}
+	if p.peek() == "%" {
+		p.pos++
+		return v / 100, true, nil
+	}
+	return v, false, nil
+}
+
+func (p *calcParser) primary() (float64, error) {
+	token := p.peek()
+	if token == "" {
+		return 0, fmt.Errorf("incomplete expression")
+	}
+	p.pos++
+	switch {
+	case token == "(":
+		v, _, err := p.sum()
+		if err != nil {
+			return 0, err
+		}
+		if p.peek() != ")" {
+			return 0, fmt.Errorf("missing )")
+		}
+		p.pos++
+		return v, nil
+	case unicode.IsDigit(rune(token[0])) || token[0] == '.':
+		v, err := strconv.ParseFloat(token, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number %q", token)
+		}
+		return v, nil
+	}
+	if v, ok := calcConstants[token]; ok {
+		return v, nil
+	}
+	if f, ok := calcFunctions[token]; ok {
+		arg, err := p.primary()
+		if err != nil {
+			return 0, err
+		}
+		return f(arg), nil
+	}
+	return 0, fmt.Errorf("unknown %q", token)
+}
+
+// formatNumber writes v with 10 significant digits at most, without
+// exponent below a trillion
+func formatNumber(v float64) string {
+	rounded, _ := strconv.ParseFloat(strconv.FormatFloat(v, 'g', 10, 64), 64)
+	if math.Abs(rounded) >= 1e12 || (rounded != 0 && math.Abs(rounded) < 1e-6) {
+		return strconv.FormatFloat(rounded, 'g', -1, 64)
+	}
+	return strconv.FormatFloat(rounded, 'f', -1, 64)
+}
+
+func runCalcCommand(m model, args string) (tea.Model, tea.Cmd) {
+	v, err := evalExpression(args)
+	if err != nil {
+		m.err = fmt.Errorf("/calc: %w", err)
+		return m, nil
+	}
+	m.message = fmt.Sprintf("%s = %s", strings.TrimSpace(args), formatNumber(v))
+	return m, nil
+}
+
+// calcUnit converts to the base unit of its dimension: base = value*factor
+// + offset
+type calcUnit struct {
+	dimension string
+	factor    float64
+	offset    float64
+}
+
+var calcUnits = map[string]calcUnit{
+	// Length, in meters
+	"mm": {"length", 0.001, 0}, "cm": {"length", 0.01, 0}, "m": {"length", 1, 0}, "km": {"length", 1000, 0},
+	"in": {"length", 0.0254, 0}, "ft": {"length", 0.3048, 0}, "yd": {"length", 0.9144, 0},
+	"mi": {"length", 1609.344, 0}, "nmi": {"length", 1852, 0},
+	// Mass, in kilograms
+	"mg": {"mass", 1e-6, 0}, "g": {"mass", 0.001, 0}, "kg": {"mass", 1, 0}, "t": {"mass", 1000, 0},
+	"oz": {"mass", 0.028349523125, 0}, "lb": {"mass", 0.45359237, 0}, "st": {"mass", 6.35029318, 0},
+	// Volume, in liters
+	"ml": {"volume", 0.001, 0}, "cl": {"volume", 0.01, 0}, "dl": {"volume", 0.1, 0}, "l": {"volume", 1, 0},
+	"tsp": {"volume", 0.00492892159375, 0}, "tbsp": {"volume", 0.01478676478125, 0},
+	"floz": {"volume", 0.0295735295625, 0}, "cup": {"volume", 0.2365882365, 0},
+	"pt": {"volume", 0.473176473, 0}, "qt": {"volume", 0.946352946, 0}, "gal": {"volume", 3.785411784, 0},
+	// Temperature, in kelvins
+	"c": {"temperature", 1, 273.15}, "k": {"temperature", 1, 0}, "f": {"temperature", 5.0 / 9, 273.15 - 32*5.0/9},
+	// Speed, in meters per second
+	"m/s": {"speed", 1, 0}, "km/h": {"speed", 1 / 3.6, 0}, "mph": {"speed", 0.44704, 0}, "kn": {"speed", 1852.0 / 3600, 0},
+	// Time, in seconds
+	"ms": {"time", 0.001, 0}, "s": {"time", 1, 0}, "min": {"time", 60, 0}, "h": {"time", 3600, 0},
+	"d": {"time", 86400, 0}, "wk": {"time", 604800, 0},
+	// Area, in square meters
+	"m2": {"area", 1, 0}, "km2": {"area", 1e6, 0}, "ha": {"area", 1e4, 0}, "acre": {"area", 4046.8564224, 0},
+	"ft2": {"area", 0.09290304, 0},
+	// Data, in bytes
+	"b": {"data", 1, 0}, "kb": {"data", 1e3, 0}, "mb": {"data", 1e6, 0}, "gb": {"data", 1e9, 0}, "tb": {"data", 1e12, 0},
+	"kib": {"data", 1 << 10, 0}, "mib": {"data", 1 << 20, 0}, "gib": {"data", 1 << 30, 0}, "tib": {"data", 1 << 40, 0},
+}
+
+var calcUnitAliases = map[string]string{
+	"meter": "m", "meters": "m", "metre": "m", "metres": "m", "mètre": "m", "mètres": "m",
+	"inch": "in", "inches": "in", "\"": "in", "foot": "ft", "feet": "ft", "'": "ft",
+	"mile": "mi", "miles": "mi", "yard": "yd", "yards": "yd",
+	"gram": "g", "grams": "g", "gramme": "g", "grammes": "g", "kilo": "kg", "kilos": "kg",
+	"pound": "lb", "pounds": "lb", "lbs": "lb", "ounce": "oz", "ounces": "oz", "tonne": "t", "tonnes": "t",
+	"liter": "l", "liters": "l", "litre": "l", "litres": "l", "gallon": "gal", "gallons": "gal", "cups": "cup",
+	"°c": "c", "celsius": "c", "°f": "f", "fahrenheit": "f", "kelvin": "k",
+	"kmh": "km/h", "kph": "km/h", "knot": "kn", "knots": "kn", "kt": "kn",
+	"sec": "s", "second": "s", "seconds": "s", "minute": "min", "minutes": "min", "hour": "h", "hours": "h",
+	"day": "d", "days": "d", "jour": "d", "jours": "d", "week": "wk", "weeks": "wk",
+	"m²": "m2", "km²": "km2", "ft²": "ft2", "acres": "acre", "byte": "b", "bytes": "b", "o": "b", "ko": "kb", "mo": "mb", "go": "gb", "to": "tb",
+}
+
+func lookupUnit(name string) (string, calcUnit, bool) {
+	name = strings.ToLower(name)
+	if alias, ok := calcUnitAliases[name]; ok {
+		name = alias
+	}
+	unit, ok := calcUnits[name]
+	return name, unit, ok
+}
+
+// parseConversion reads "10 km to mi", "10km mi", "72 °F in C"
+func parseConversion(args string) (value float64, from, to string, err error) {
+	fields := strings.Fields(args)
+	if len(fields) > 0 {
+		// "10km": the value and its unit in one field
+		first := fields[0]
+		i := strings.IndexFunc(first, func(r rune) bool { return !unicode.IsDigit(r) && !strings.ContainsRune(".,-+e", r) })
+		if i > 0 && i < len(first) {
+			fields = append([]string{first[:i], first[i:]}, fields[1:]...)
+		}
+	}
+	if len(fields) == 4 && (fields[2] == "to" || fields[2] == "in" || fields[2] == "en" || fields[2] == "->") {
+		fields = append(fields[:2], fields[3])
+	}
+	if len(fields) != 3 {
+		return 0, "", "", fmt.Errorf("expected VALUE FROM TO, such as /conv 10 km to mi")
+	}
+	if value, err = evalExpression(fields[0]); err != nil {
+		return 0, "", "", fmt.Errorf("invalid value %q", fields[0])
+	}
+	return value, fields[1], fields[2], nil
+}
+
+func convertUnits(value float64, from, to string) (float64, error) {
+	fromName, fromUnit, ok := lookupUnit(from)
+	if !ok {
+		return 0, fmt.Errorf("unknown unit %q, known: %s", from, strings.Join(unitNames(), " "))
+	}
+	toName, toUnit, ok := lookupUnit(to)
+	if !ok {
+		return 0, fmt.Errorf("unknown unit %q, known: %s", to, strings.Join(unitNames(), " "))
+	}
+	if fromUnit.dimension != toUnit.dimension {
+		return 0, fmt.Errorf("cannot convert %s (%s) to %s (%s)", fromName, fromUnit.dimension, toName, toUnit.dimension)
+	}
+	base := value*fromUnit.factor + fromUnit.offset
+	return (base - toUnit.offset) / toUnit.factor, nil
+}
+
+func unitNames() []string {
+	names := make([]string, 0, len(calcUnits))
+	for name := range calcUnits {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func runConvCommand(m model, args string) (tea.Model, tea.Cmd) {
+	value, from, to, err := parseConversion(args)
+	if err == nil {
+		var result float64
+		if result, err = convertUnits(value, from, to); err == nil {
+			m.message = fmt.Sprintf("%s %s = %s %s", formatNumber(value), from, formatNumber(result), to)
+			return m, nil
+		}
+	}
+	m.err = fmt.Errorf("/conv: %w", err)
+	return m, nil
+}
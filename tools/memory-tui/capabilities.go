@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+)
+
+// Server modules: /status lists the modules of the server with their state,
+// and the prompt commands needing a module or a capability the server does
+// not enable are left out of the completion and the help, and refused with
+// a clear message. Servers not listing their modules are assumed to enable
+// everything.
+const moduleMemory = "memory"
+
+type serverModule struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+	Status  string `json:"status"`
+}
+
+// enabledModules lists the names of the enabled modules, nil when the
+// server does not list them
+func (info serverInfo) enabledModules() []string {
+	if info.Modules == nil {
+		return nil
+	}
+	names := []string{}
+	for _, module := range info.Modules {
+		if module.Enabled {
+			names = append(names, module.Name)
+		}
+	}
+	return names
+}
+
+// supports reports whether the server enables a module or a capability
+func (api *MemoryAPI) supports(feature string) bool {
+	if api == nil || feature == "" {
+		return true
+	}
+	switch feature {
+	case capSoftDelete:
+		return api.SoftDelete
+	case capEmbeddings:
+		return api.Embeddings
+	}
+	if api.Modules == nil {
+		return true
+	}
+	for _, name := range api.Modules {
+		if name == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// available reports whether the command can run on the server of api, in
+// its mode
+func (c promptCommand) available(api *MemoryAPI) bool {
+	if (c.Writes || c.SideEffects) && api != nil && api.ReadOnly {
+		return false
+	}
+	return api.supports(c.Requires)
+}
+
+// unavailable explains why the command cannot run
+func (c promptCommand) unavailable(api *MemoryAPI) error {
+	if c.Writes && api != nil && api.ReadOnly {
+		return readOnlyError()
+	}
+	if c.SideEffects && api != nil && api.ReadOnly {
+		return errors.New(tr("Read-only mode: /%s is disabled", c.Name))
+	}
+	return errors.New(tr("/%s is not enabled on this server", c.Name))
+}
+
+// refreshPromptCommands updates the prompt completion and placeholder once
+// the server told what it enables
+func (m *model) refreshPromptCommands() {
+	m.promptInput.Placeholder = promptCommandsSummary(m.api)
+	m.promptInput.SetSuggestions(promptSuggestions(m.api))
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Splitting of long texts into several memories
+const (
+	defaultChunkSize = 800 // Characters per chunk before a memory is split
+	minChunkSize     = 200
+	chunkSizeStep    = 100
+)
+
+// splitIntoChunks groups paragraphs, then sentences, into chunks of at most
+// size characters, paragraphs staying apart by a blank line. A sentence
+// longer than size, a pasted log say, is cut between words, or anywhere in
+// a word longer than size. When overlap is set, each chunk starts with the
+// last sentence of the previous one so context isn't lost at the boundary.
+func splitIntoChunks(text string, size int, overlap bool) []string {
+	type unit struct {
+		text      string
+		paragraph bool // Starts a paragraph
+	}
+	var units []unit
+	for _, paragraph := range strings.Split(text, "\n\n") {
+		paragraph = strings.TrimSpace(paragraph)
+		if paragraph == "" {
+			continue
+		}
+		if utf8.RuneCountInString(paragraph) <= size {
+			units = append(units, unit{paragraph, true})
+			continue
+		}
+		first := true
+		for _, sentence := range splitSentences(paragraph) {
+			for _, piece := range splitWords(sentence, size) {
+				units = append(units, unit{piece, first})
+				first = false
+			}
+		}
+	}
+
+	var chunks []string
+	var current strings.Builder
+	currentLen := 0
+	for _, u := range units {
+		sep := " "
+		if u.paragraph {
+			sep = "\n\n"
+		}
+		unitLen := utf8.RuneCountInString(u.text)
+		if currentLen > 0 && currentLen+len(sep)+unitLen > size {
+			chunks = append(chunks, current.String())
+			current.Reset()
+			currentLen = 0
+			if overlap {
+				sentences := splitSentences(chunks[len(chunks)-1])
+				tail := sentences[len(sentences)-1]
+				if tailLen := utf8.RuneCountInString(tail); tailLen+len(sep)+unitLen <= size && len(sentences) > 1 {
+					current.WriteString(tail)
+					currentLen = tailLen
+				}
+			}
+		}
+		if currentLen > 0 {
+			current.WriteString(sep)
+			currentLen += len(sep)
+		}
+		current.WriteString(u.text)
+		currentLen += unitLen
+	}
+	if currentLen > 0 {
+		chunks = append(chunks, current.String())
+	}
+	return chunks
+}
+
+// splitWords cuts text longer than size between words, and words longer
+// than size every size characters
+func splitWords(text string, size int) []string {
+	if utf8.RuneCountInString(text) <= size {
+		return []string{text}
+	}
+	var pieces []string
+	var current []rune
+	for _, word := range strings.Fields(text) {
+		runes := []rune(word)
+		if len(current) > 0 && len(current)+1+len(runes) > size {
+			pieces = append(pieces, string(current))
+			current = nil
+		}
+		if len(current) > 0 {
+			current = append(current, ' ')
+		}
+		current = append(current, runes...)
+		for len(current) > size {
+			pieces = append(pieces, string(current[:size]))
+			current = current[size:]
+		}
+	}
+	if len(current) > 0 {
+		pieces = append(pieces, string(current))
+	}
+	return pieces
+}
+
+// splitSentences cuts text after sentence-ending punctuation
+func splitSentences(text string) []string {
+	var sentences []string
+	start := 0
+	runes := []rune(text)
+	for i, r := range runes {
+		if r != '.' && r != '!' && r != '?' && r != '\n' {
+			continue
+		}
+		if i+1 < len(runes) && runes[i+1] != ' ' && runes[i+1] != '\n' {
+			continue
+		}
+		if sentence := strings.TrimSpace(string(runes[start : i+1])); sentence != "" {
+			sentences = append(sentences, sentence)
+		}
+		start = i + 1
+	}
+	if rest := strings.TrimSpace(string(runes[start:])); rest != "" {
+		sentences = append(sentences, rest)
+	}
+	return sentences
+}
+
+func (m model) openChunkPreview(text string) model {
+	m.chunkSource = text
+	if m.chunkSize == 0 {
+		m.chunkSize = defaultChunkSize
+	}
+	m.chunks = splitIntoChunks(text, m.chunkSize, m.chunkOverlap)
+	m.chunkIndex = 0
+	m.showView(chunkPreviewView)
+	return m
+}
+
+func (m model) updateChunkPreviewView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.showView(addView)
+		return m, nil
+	case "up", "k":
+		if m.chunkIndex > 0 {
+			m.chunkIndex--
+		}
+	case "down", "j":
+		if m.chunkIndex < len(m.chunks)-1 {
+			m.chunkIndex++
+		}
+	case "m":
+		// Merge the selected chunk with the next one
+		if m.chunkIndex < len(m.chunks)-1 {
+			merged := m.chunks[m.chunkIndex] + "\n\n" + m.chunks[m.chunkIndex+1]
+			m.chunks = append(m.chunks[:m.chunkIndex+1], m.chunks[m.chunkIndex+2:]...)
+			m.chunks[m.chunkIndex] = merged
+		}
+	case "x":
+		// Drop the selected chunk
+		if len(m.chunks) > 1 {
+			m.chunks = append(m.chunks[:m.chunkIndex], m.chunks[m.chunkIndex+1:]...)
+			if m.chunkIndex >= len(m.chunks) {
+				m.chunkIndex = len(m.chunks) - 1
+			}
+		}
+	case "+", "-":
+		if msg.String() == "+" {
+			m.chunkSize += chunkSizeStep
+		} else if m.chunkSize-chunkSizeStep >= minChunkSize {
+			m.chunkSize -= chunkSizeStep
+		}
+		m = m.openChunkPreview(m.chunkSource)
+	case "o":
+		m.chunkOverlap = !m.chunkOverlap
+		m = m.openChunkPreview(m.chunkSource)
+	case "w":
+		// Keep the text whole, as a single memory
+		m.chunks = []string{m.chunkSource}
+		m.chunkIndex = 0
+	case "ctrl+s", "enter":
+		chunks := m.chunks
+		api := m.api.Background(timeoutBulk)
+		return m.startBatch(api.addJob("Adding memories", len(chunks), func(i int) batchAddItem {
+			var metadata map[string]interface{}
+			if len(chunks) > 1 {
+				metadata = map[string]interface{}{"chunk": i + 1, "chunks": len(chunks)}
+			}
+			return batchAddItem{Text: chunks[i], Metadata: metadata}
+		}, nil))
+	}
+	return m, nil
+}
+
+func (m model) renderChunkPreviewView() string {
+	style := contentBoxStyle.Width(m.width - 4)
+	if m.focus == focusContent {
+		style = contentBoxFocusedStyle.Width(m.width - 4)
+	}
+
+	overlap := "off"
+	if m.chunkOverlap {
+		overlap = "on"
+	}
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(tr("✂️ Split Memory")))
+	b.WriteString("\n\n")
+	b.WriteString(fmt.Sprintf("%d characters → %d memories (max %d chars each, overlap %s)\n\n",
+		utf8.RuneCountInString(m.chunkSource), len(m.chunks), m.chunkSize, overlap))
+
+	chunkWidth := max(20, m.width-14)
+	for i, chunk := range m.chunks {
+		header := fmt.Sprintf("#%d (%d chars)", i+1, utf8.RuneCountInString(chunk))
+		if i == m.chunkIndex {
+			b.WriteString(selectedItemStyle.Render("▶ " + header))
+			b.WriteString("\n")
+			b.WriteString(lipgloss.NewStyle().PaddingLeft(2).Render(wrapLines(chunk, chunkWidth)))
+		} else {
+			b.WriteString("  " + header + "  ")
+			b.WriteString(helpStyle.Render(truncateString(strings.ReplaceAll(chunk, "\n", " "), max(10, chunkWidth-len(header)))))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(m.keyHint(""))
+	return style.Render(b.String())
+}
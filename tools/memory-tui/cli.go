@@ -0,0 +1,269 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Command line layer: a tree of commands built on flag.FlagSet, documenting
+// itself in --help and in man pages generated by the hidden gen-docs command
+type command struct {
+	Name   string
+	Args   string // Positional arguments shown in the usage line
+	Short  string
+	Long   string
+	Hidden bool
+	// Flags declares the command flags and returns the function running it
+	Flags       func(fs *flag.FlagSet) func(args []string) error
+	Subcommands []*command
+
+	parent *command
+}
+
+func (c *command) path() string {
+	if c.parent == nil {
+		return c.Name
+	}
+	return c.parent.path() + " " + c.Name
+}
+
+func (c *command) usageLine() string {
+	line := c.path()
+	if len(c.visibleSubcommands()) > 0 {
+		line += " [COMMAND]"
+	}
+	if c.hasFlags() {
+		line += " [flags]"
+	}
+	if c.Args != "" {
+		line += " " + c.Args
+	}
+	return line
+}
+
+func (c *command) visibleSubcommands() []*command {
+	var subs []*command
+	for _, sub := range c.Subcommands {
+		if !sub.Hidden {
+			subs = append(subs, sub)
+		}
+	}
+	sort.Slice(subs, func(i, j int) bool { return subs[i].Name < subs[j].Name })
+	return subs
+}
+
+func (c *command) find(name string) *command {
+	for _, sub := range c.Subcommands {
+		if sub.Name == name {
+			return sub
+		}
+	}
+	return nil
+}
+
+// flagSet returns the command flags and the function running the command
+func (c *command) flagSet() (*flag.FlagSet, func(args []string) error) {
+	fs := flag.NewFlagSet(c.path(), flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	var run func(args []string) error
+	if c.Flags != nil {
+		run = c.Flags(fs)
+	}
+	return fs, run
+}
+
+func (c *command) hasFlags() bool {
+	fs, _ := c.flagSet()
+	found := false
+	fs.VisitAll(func(*flag.Flag) { found = true })
+	return found
+}
+
+func (c *command) printHelp(w io.Writer) {
+	if c.Long != "" {
+		fmt.Fprintf(w, "%s\n\n", c.Long)
+	} else {
+		fmt.Fprintf(w, "%s\n\n", c.Short)
+	}
+	fmt.Fprintf(w, "Usage:\n  %s\n", c.usageLine())
+
+	if subs := c.visibleSubcommands(); len(subs) > 0 {
+		fmt.Fprintf(w, "\nCommands:\n")
+		for _, sub := range subs {
+			fmt.Fprintf(w, "  %-12s %s\n", sub.Name, sub.Short)
+		}
+	}
+
+	if c.hasFlags() {
+		fs, _ := c.flagSet()
+		fmt.Fprintf(w, "\nFlags:\n")
+		fs.SetOutput(w)
+		fs.PrintDefaults()
+	}
+
+	if len(c.visibleSubcommands()) > 0 {
+		fmt.Fprintf(w, "\nRun '%s COMMAND --help' for more information on a command.\n", c.path())
+	}
+}
+
+// execute dispatches args to the matching subcommand and runs it
+func (c *command) execute(args []string) error {
+	if len(args) > 0 {
+		if args[0] == "help" {
+			target := c
+			for _, name := range args[1:] {
+				if target = target.find(name); target == nil {
+					return fmt.Errorf("unknown command %q", strings.Join(args[1:], " "))
+				}
+			}
+			target.printHelp(os.Stdout)
+			return nil
+		}
+		if sub := c.find(args[0]); sub != nil {
+			return sub.execute(args[1:])
+		}
+	}
+
+	fs, run := c.flagSet()
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			c.printHelp(os.Stdout)
+			return nil
+		}
+		return fmt.Errorf("%v\n\nRun '%s --help' for usage", err, c.path())
+	}
+	if run == nil {
+		c.printHelp(os.Stderr)
+		if fs.NArg() > 0 {
+			return fmt.Errorf("unknown command %q", fs.Arg(0))
+		}
+		return nil
+	}
+	return run(fs.Args())
+}
+
+// parseInterspersed parses the flags left among the arguments once the first
+// one stopped fs.Parse, for commands taking them after their arguments too
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var rest []string
+	for len(args) > 0 {
+		switch {
+		case args[0] == "--":
+			return append(rest, args[1:]...), nil
+		case len(args[0]) > 1 && strings.HasPrefix(args[0], "-"):
+			if err := fs.Parse(args); err != nil {
+				return nil, fmt.Errorf("%v\n\nRun '%s --help' for usage", err, fs.Name())
+			}
+			args = fs.Args()
+		default:
+			rest = append(rest, args[0])
+			args = args[1:]
+		}
+	}
+	return rest, nil
+}
+
+// writeManPages writes one roff man page per visible command into dir
+func writeManPages(root *command, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	var walk func(c *command) error
+	walk = func(c *command) error {
+		if c.Hidden {
+			return nil
+		}
+		name := strings.ReplaceAll(c.path(), " ", "-")
+		f, err := os.Create(filepath.Join(dir, name+".1"))
+		if err != nil {
+			return err
+		}
+		writeManPage(f, c)
+		if err := f.Close(); err != nil {
+			return err
+		}
+		for _, sub := range c.Subcommands {
+			if err := walk(sub); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return walk(root)
+}
+
+func writeManPage(w io.Writer, c *command) {
+	name := strings.ReplaceAll(c.path(), " ", "-")
+	fmt.Fprintf(w, ".TH %s 1 \"%s\" \"memory-tui\" \"User Commands\"\n", strings.ToUpper(name), time.Now().Format("January 2006"))
+	fmt.Fprintf(w, ".SH NAME\n%s \\- %s\n", roffEscape(name), roffEscape(c.Short))
+	fmt.Fprintf(w, ".SH SYNOPSIS\n.B %s\n", roffEscape(c.usageLine()))
+	fmt.Fprintf(w, ".SH DESCRIPTION\n")
+	description := c.Long
+	if description == "" {
+		description = c.Short
+	}
+	for _, paragraph := range strings.Split(description, "\n\n") {
+		fmt.Fprintf(w, ".PP\n%s\n", roffEscape(paragraph))
+	}
+
+	fs, _ := c.flagSet()
+	first := true
+	fs.VisitAll(func(f *flag.Flag) {
+		if first {
+			fmt.Fprintf(w, ".SH OPTIONS\n")
+			first = false
+		}
+		fmt.Fprintf(w, ".TP\n.B \\-\\-%s\n%s\n", roffEscape(f.Name), roffEscape(f.Usage))
+		if f.DefValue != "" && f.DefValue != "false" {
+			fmt.Fprintf(w, "(default: %s)\n", roffEscape(f.DefValue))
+		}
+	})
+
+	if subs := c.visibleSubcommands(); len(subs) > 0 {
+		fmt.Fprintf(w, ".SH COMMANDS\n")
+		for _, sub := range subs {
+			fmt.Fprintf(w, ".TP\n.B %s\n%s\n", sub.Name, roffEscape(sub.Short))
+		}
+	}
+
+	var related []string
+	if c.parent != nil {
+		related = append(related, strings.ReplaceAll(c.parent.path(), " ", "-")+"(1)")
+	}
+	for _, sub := range c.visibleSubcommands() {
+		related = append(related, strings.ReplaceAll(sub.path(), " ", "-")+"(1)")
+	}
+	if len(related) > 0 {
+		fmt.Fprintf(w, ".SH SEE ALSO\n%s\n", roffEscape(strings.Join(related, ", ")))
+	}
+}
+
+func roffEscape(s string) string {
+	s = strings.ReplaceAll(s, "\\", "\\\\")
+	s = strings.ReplaceAll(s, "-", "\\-")
+	// A leading dot or quote would be read as a roff request
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'") {
+			lines[i] = "\\&" + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// link sets the parent of every command of the tree
+func (c *command) link() *command {
+	for _, sub := range c.Subcommands {
+		sub.parent = c
+		sub.link()
+	}
+	return c
+}
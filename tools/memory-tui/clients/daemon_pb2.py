@@ -0,0 +1,55 @@
+# -*- coding: utf-8 -*-
+# Generated by the protocol buffer compiler.  DO NOT EDIT!
+# source: daemon.proto
+"""Generated protocol buffer code."""
+from google.protobuf.internal import builder as _builder
+from google.protobuf import descriptor as _descriptor
+from google.protobuf import descriptor_pool as _descriptor_pool
+from google.protobuf import symbol_database as _symbol_database
+# @@protoc_insertion_point(imports)
+
+_sym_db = _symbol_database.Default()
+
+
+from google.protobuf import struct_pb2 as google_dot_protobuf_dot_struct__pb2
+
+
+DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\n\x0c\x64\x61\x65mon.proto\x12\rtom.daemon.v1\x1a\x1cgoogle/protobuf/struct.proto\"\x96\x01\n\x06Memory\x12\n\n\x02id\x18\x01 \x01(\t\x12\x0e\n\x06memory\x18\x02 \x01(\t\x12\x0c\n\x04hash\x18\x03 \x01(\t\x12\x12\n\ncreated_at\x18\x04 \x01(\t\x12\x12\n\nupdated_at\x18\x05 \x01(\t\x12\x0f\n\x07user_id\x18\x06 \x01(\t\x12)\n\x08metadata\x18\x07 \x01(\x0b\x32\x17.google.protobuf.Struct\"\x1c\n\nAskRequest\x12\x0e\n\x06prompt\x18\x01 \x01(\t\":\n\x0b\x41skResponse\x12\r\n\x05\x63hunk\x18\x01 \x01(\t\x12\x0c\n\x04\x64one\x18\x02 \x01(\x08\x12\x0e\n\x06\x61nswer\x18\x03 \x01(\t\"\x1e\n\x10GetMemoryRequest\x12\n\n\x02id\x18\x01 \x01(\t\"\x15\n\x13ListMemoriesRequest\"R\n\x14ListMemoriesResponse\x12\'\n\x08memories\x18\x01 \x03(\x0b\x32\x15.tom.daemon.v1.Memory\x12\x11\n\tsynced_at\x18\x02 \x01(\t\"5\n\x15SearchMemoriesRequest\x12\r\n\x05query\x18\x01 \x01(\t\x12\r\n\x05limit\x18\x02 \x01(\x05\"K\n\x10\x41\x64\x64MemoryRequest\x12\x0c\n\x04text\x18\x01 \x01(\t\x12)\n\x08metadata\x18\x02 \x01(\x0b\x32\x17.google.protobuf.Struct\"Z\n\x13UpdateMemoryRequest\x12\n\n\x02id\x18\x01 \x01(\t\x12\x0c\n\x04text\x18\x02 \x01(\t\x12)\n\x08metadata\x18\x03 \x01(\x0b\x32\x17.google.protobuf.Struct\"!\n\x13\x44\x65leteMemoryRequest\x12\n\n\x02id\x18\x01 \x01(\t\"\x16\n\x14\x44\x65leteMemoryResponse\"9\n\x1dSubscribeNotificationsRequest\x12\x18\n\x10interval_seconds\x18\x01 \x01(\x05\"&\n\x04Task\x12\x0e\n\x06module\x18\x01 \x01(\t\x12\x0e\n\x06status\x18\x02 \x01(\t\"3\n\rNotifications\x12\"\n\x05tasks\x18\x01 \x03(\x0b\x32\x13.tom.daemon.v1.Task2\x97\x05\n\tTomDaemon\x12>\n\x03\x41sk\x12\x19.tom.daemon.v1.AskRequest\x1a\x1a.tom.daemon.v1.AskResponse0\x01\x12\x43\n\tGetMemory\x12\x1f.tom.daemon.v1.GetMemoryRequest\x1a\x15.tom.daemon.v1.Memory\x12W\n\x0cListMemories\x12\".tom.daemon.v1.ListMemoriesRequest\x1a#.tom.daemon.v1.ListMemoriesResponse\x12[\n\x0eSearchMemories\x12$.tom.daemon.v1.SearchMemoriesRequest\x1a#.tom.daemon.v1.ListMemoriesResponse\x12\x43\n\tAddMemory\x12\x1f.tom.daemon.v1.AddMemoryRequest\x1a\x15.tom.daemon.v1.Memory\x12I\n\x0cUpdateMemory\x12\".tom.daemon.v1.UpdateMemoryRequest\x1a\x15.tom.daemon.v1.Memory\x12W\n\x0c\x44\x65leteMemory\x12\".tom.daemon.v1.DeleteMemoryRequest\x1a#.tom.daemon.v1.DeleteMemoryResponse\x12\x66\n\x16SubscribeNotifications\x12,.tom.daemon.v1.SubscribeNotificationsRequest\x1a\x1c.tom.daemon.v1.Notifications0\x01\x42\x1eZ\x1cmemory-tui/daemonpb;daemonpbb\x06proto3')
+
+_builder.BuildMessageAndEnumDescriptors(DESCRIPTOR, globals())
+_builder.BuildTopDescriptorsAndMessages(DESCRIPTOR, 'daemon_pb2', globals())
+if _descriptor._USE_C_DESCRIPTORS == False:
+
+  DESCRIPTOR._options = None
+  DESCRIPTOR._serialized_options = b'Z\034memory-tui/daemonpb;daemonpb'
+  _MEMORY._serialized_start=62
+  _MEMORY._serialized_end=212
+  _ASKREQUEST._serialized_start=214
+  _ASKREQUEST._serialized_end=242
+  _ASKRESPONSE._serialized_start=244
+  _ASKRESPONSE._serialized_end=302
+  _GETMEMORYREQUEST._serialized_start=304
+  _GETMEMORYREQUEST._serialized_end=334
+  _LISTMEMORIESREQUEST._serialized_start=336
+  _LISTMEMORIESREQUEST._serialized_end=357
+  _LISTMEMORIESRESPONSE._serialized_start=359
+  _LISTMEMORIESRESPONSE._serialized_end=441
+  _SEARCHMEMORIESREQUEST._serialized_start=443
+  _SEARCHMEMORIESREQUEST._serialized_end=496
+  _ADDMEMORYREQUEST._serialized_start=498
+  _ADDMEMORYREQUEST._serialized_end=573
+  _UPDATEMEMORYREQUEST._serialized_start=575
+  _UPDATEMEMORYREQUEST._serialized_end=665
+  _DELETEMEMORYREQUEST._serialized_start=667
+  _DELETEMEMORYREQUEST._serialized_end=700
+  _DELETEMEMORYRESPONSE._serialized_start=702
+  _DELETEMEMORYRESPONSE._serialized_end=724
+  _SUBSCRIBENOTIFICATIONSREQUEST._serialized_start=726
+  _SUBSCRIBENOTIFICATIONSREQUEST._serialized_end=783
+  _TASK._serialized_start=785
+  _TASK._serialized_end=823
+  _NOTIFICATIONS._serialized_start=825
+  _NOTIFICATIONS._serialized_end=876
+  _TOMDAEMON._serialized_start=879
+  _TOMDAEMON._serialized_end=1542
+# @@protoc_insertion_point(module_scope)
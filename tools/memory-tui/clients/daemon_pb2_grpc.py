@@ -0,0 +1,309 @@
+# Written in the layout of the gRPC Python protocol compiler plugin, which
+# regenerates it from proto/daemon.proto: see the command there.
+"""Client and server classes corresponding to protobuf-defined services."""
+import grpc
+
+import daemon_pb2 as daemon__pb2
+
+
+class TomDaemonStub(object):
+    """Missing associated documentation comment in .proto file."""
+
+    def __init__(self, channel):
+        """Constructor.
+
+        Args:
+            channel: A grpc.Channel.
+        """
+        self.Ask = channel.unary_stream(
+                '/tom.daemon.v1.TomDaemon/Ask',
+                request_serializer=daemon__pb2.AskRequest.SerializeToString,
+                response_deserializer=daemon__pb2.AskResponse.FromString,
+                )
+        self.GetMemory = channel.unary_unary(
+                '/tom.daemon.v1.TomDaemon/GetMemory',
+                request_serializer=daemon__pb2.GetMemoryRequest.SerializeToString,
+                response_deserializer=daemon__pb2.Memory.FromString,
+                )
+        self.ListMemories = channel.unary_unary(
+                '/tom.daemon.v1.TomDaemon/ListMemories',
+                request_serializer=daemon__pb2.ListMemoriesRequest.SerializeToString,
+                response_deserializer=daemon__pb2.ListMemoriesResponse.FromString,
+                )
+        self.SearchMemories = channel.unary_unary(
+                '/tom.daemon.v1.TomDaemon/SearchMemories',
+                request_serializer=daemon__pb2.SearchMemoriesRequest.SerializeToString,
+                response_deserializer=daemon__pb2.ListMemoriesResponse.FromString,
+                )
+        self.AddMemory = channel.unary_unary(
+                '/tom.daemon.v1.TomDaemon/AddMemory',
+                request_serializer=daemon__pb2.AddMemoryRequest.SerializeToString,
+                response_deserializer=daemon__pb2.Memory.FromString,
+                )
+        self.UpdateMemory = channel.unary_unary(
+                '/tom.daemon.v1.TomDaemon/UpdateMemory',
+                request_serializer=daemon__pb2.UpdateMemoryRequest.SerializeToString,
+                response_deserializer=daemon__pb2.Memory.FromString,
+                )
+        self.DeleteMemory = channel.unary_unary(
+                '/tom.daemon.v1.TomDaemon/DeleteMemory',
+                request_serializer=daemon__pb2.DeleteMemoryRequest.SerializeToString,
+                response_deserializer=daemon__pb2.DeleteMemoryResponse.FromString,
+                )
+        self.SubscribeNotifications = channel.unary_stream(
+                '/tom.daemon.v1.TomDaemon/SubscribeNotifications',
+                request_serializer=daemon__pb2.SubscribeNotificationsRequest.SerializeToString,
+                response_deserializer=daemon__pb2.Notifications.FromString,
+                )
+
+
+class TomDaemonServicer(object):
+    """Missing associated documentation comment in .proto file."""
+
+    def Ask(self, request, context):
+        """Ask sends a prompt to Tom, in a conversation of its own (client type
+        daemon), and streams the answer: chunks as it comes, then the whole
+        answer with done set
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def GetMemory(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ListMemories(self, request, context):
+        """ListMemories returns the memories of the daemon cache, without a request
+        to Tom
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def SearchMemories(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def AddMemory(self, request, context):
+        """AddMemory stores a memory, the sensitive values masked, and returns it
+        as stored
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def UpdateMemory(self, request, context):
+        """UpdateMemory adds the new text then deletes the former memory, the
+        memory server having no update: the ID changes
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def DeleteMemory(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def SubscribeNotifications(self, request, context):
+        """SubscribeNotifications streams the notification status of Tom's
+        modules at once, then at each change, until the client cancels
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+
+def add_TomDaemonServicer_to_server(servicer, server):
+    rpc_method_handlers = {
+            'Ask': grpc.unary_stream_rpc_method_handler(
+                    servicer.Ask,
+                    request_deserializer=daemon__pb2.AskRequest.FromString,
+                    response_serializer=daemon__pb2.AskResponse.SerializeToString,
+            ),
+            'GetMemory': grpc.unary_unary_rpc_method_handler(
+                    servicer.GetMemory,
+                    request_deserializer=daemon__pb2.GetMemoryRequest.FromString,
+                    response_serializer=daemon__pb2.Memory.SerializeToString,
+            ),
+            'ListMemories': grpc.unary_unary_rpc_method_handler(
+                    servicer.ListMemories,
+                    request_deserializer=daemon__pb2.ListMemoriesRequest.FromString,
+                    response_serializer=daemon__pb2.ListMemoriesResponse.SerializeToString,
+            ),
+            'SearchMemories': grpc.unary_unary_rpc_method_handler(
+                    servicer.SearchMemories,
+                    request_deserializer=daemon__pb2.SearchMemoriesRequest.FromString,
+                    response_serializer=daemon__pb2.ListMemoriesResponse.SerializeToString,
+            ),
+            'AddMemory': grpc.unary_unary_rpc_method_handler(
+                    servicer.AddMemory,
+                    request_deserializer=daemon__pb2.AddMemoryRequest.FromString,
+                    response_serializer=daemon__pb2.Memory.SerializeToString,
+            ),
+            'UpdateMemory': grpc.unary_unary_rpc_method_handler(
+                    servicer.UpdateMemory,
+                    request_deserializer=daemon__pb2.UpdateMemoryRequest.FromString,
+                    response_serializer=daemon__pb2.Memory.SerializeToString,
+            ),
+            'DeleteMemory': grpc.unary_unary_rpc_method_handler(
+                    servicer.DeleteMemory,
+                    request_deserializer=daemon__pb2.DeleteMemoryRequest.FromString,
+                    response_serializer=daemon__pb2.DeleteMemoryResponse.SerializeToString,
+            ),
+            'SubscribeNotifications': grpc.unary_stream_rpc_method_handler(
+                    servicer.SubscribeNotifications,
+                    request_deserializer=daemon__pb2.SubscribeNotificationsRequest.FromString,
+                    response_serializer=daemon__pb2.Notifications.SerializeToString,
+            ),
+    }
+    generic_handler = grpc.method_handlers_generic_handler(
+            'tom.daemon.v1.TomDaemon', rpc_method_handlers)
+    server.add_generic_rpc_handlers((generic_handler,))
+
+
+ # This class is part of an EXPERIMENTAL API.
+class TomDaemon(object):
+    """Missing associated documentation comment in .proto file."""
+
+    @staticmethod
+    def Ask(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_stream(request, target, '/tom.daemon.v1.TomDaemon/Ask',
+            daemon__pb2.AskRequest.SerializeToString,
+            daemon__pb2.AskResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def GetMemory(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/tom.daemon.v1.TomDaemon/GetMemory',
+            daemon__pb2.GetMemoryRequest.SerializeToString,
+            daemon__pb2.Memory.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def ListMemories(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/tom.daemon.v1.TomDaemon/ListMemories',
+            daemon__pb2.ListMemoriesRequest.SerializeToString,
+            daemon__pb2.ListMemoriesResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def SearchMemories(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/tom.daemon.v1.TomDaemon/SearchMemories',
+            daemon__pb2.SearchMemoriesRequest.SerializeToString,
+            daemon__pb2.ListMemoriesResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def AddMemory(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/tom.daemon.v1.TomDaemon/AddMemory',
+            daemon__pb2.AddMemoryRequest.SerializeToString,
+            daemon__pb2.Memory.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def UpdateMemory(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/tom.daemon.v1.TomDaemon/UpdateMemory',
+            daemon__pb2.UpdateMemoryRequest.SerializeToString,
+            daemon__pb2.Memory.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def DeleteMemory(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/tom.daemon.v1.TomDaemon/DeleteMemory',
+            daemon__pb2.DeleteMemoryRequest.SerializeToString,
+            daemon__pb2.DeleteMemoryResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def SubscribeNotifications(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_stream(request, target, '/tom.daemon.v1.TomDaemon/SubscribeNotifications',
+            daemon__pb2.SubscribeNotificationsRequest.SerializeToString,
+            daemon__pb2.Notifications.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
@@ -0,0 +1,129 @@
+-- Neovim plugin for Tom, talking to `memory-tui --nvim`.
+--
+--   require("tom").setup()            -- or setup({ cmd = { "/path/to/memory-tui", "--nvim" } })
+--
+--   :TomAsk QUESTION                  ask Tom, the selected lines (with a range) sent along
+--   :'<,'>TomEdit INSTRUCTION         replace the selected lines with Tom's rewrite
+--   :'<,'>TomRemember                 store the selected lines as a memory
+--   :TomCancel                        cancel the requests in progress
+--   :TomReset                         start a new conversation
+--
+-- The answers open in a Markdown split.
+
+local M = {}
+
+local config = { cmd = { "memory-tui", "--nvim" } }
+local job = nil
+local next_id = 0
+local callbacks = {}
+local partial = ""
+
+local function on_stdout(_, data)
+  -- Lines may come cut between two calls: the last element continues
+  data[1] = partial .. data[1]
+  partial = table.remove(data)
+  for _, line in ipairs(data) do
+    if line ~= "" then
+      local ok, resp = pcall(vim.json.decode, line)
+      if ok and callbacks[resp.id] then
+        local cb = callbacks[resp.id]
+        callbacks[resp.id] = nil
+        vim.schedule(function() cb(resp) end)
+      end
+    end
+  end
+end
+
+local function start()
+  if job then
+    return true
+  end
+  job = vim.fn.jobstart(config.cmd, {
+    on_stdout = on_stdout,
+    on_exit = function()
+      job = nil
+      partial = ""
+    end,
+  })
+  if job <= 0 then
+    job = nil
+    vim.notify("Tom: cannot start " .. table.concat(config.cmd, " "), vim.log.levels.ERROR)
+    return false
+  end
+  return true
+end
+
+-- request sends a request, cb getting the result, errors being notified
+local function request(method, params, cb)
+  if not start() then
+    return
+  end
+  next_id = next_id + 1
+  callbacks[next_id] = function(resp)
+    if resp.error then
+      vim.notify("Tom: " .. resp.error.message, vim.log.levels.ERROR)
+    elseif cb then
+      cb(resp.result)
+    end
+  end
+  vim.fn.chansend(job, vim.json.encode({ id = next_id, method = method, params = params }) .. "\n")
+  return next_id
+end
+
+local function show(text)
+  vim.cmd("botright new")
+  local buf = vim.api.nvim_get_current_buf()
+  vim.bo[buf].buftype = "nofile"
+  vim.bo[buf].bufhidden = "wipe"
+  vim.bo[buf].filetype = "markdown"
+  vim.api.nvim_buf_set_lines(buf, 0, -1, false, vim.split(text, "\n"))
+end
+
+local function range_text(opts)
+  local lines = vim.api.nvim_buf_get_lines(0, opts.line1 - 1, opts.line2, false)
+  return table.concat(lines, "\n") .. "\n"
+end
+
+function M.setup(opts)
+  config = vim.tbl_extend("force", config, opts or {})
+
+  vim.api.nvim_create_user_command("TomAsk", function(o)
+    local params = { prompt = o.args }
+    if o.range > 0 then
+      params.text = range_text(o)
+      params.filetype = vim.bo.filetype
+      params.path = vim.fn.expand("%:.")
+    end
+    vim.notify("Tom: asking…")
+    request("ask", params, function(result) show(result.answer) end)
+  end, { nargs = "+", range = true })
+
+  vim.api.nvim_create_user_command("TomEdit", function(o)
+    local buf, first, last = vim.api.nvim_get_current_buf(), o.line1, o.line2
+    local params = { instruction = o.args, text = range_text(o), filetype = vim.bo.filetype }
+    vim.notify("Tom: rewriting…")
+    request("edit", params, function(result)
+      local lines = vim.split((result.text:gsub("\n$", "")), "\n")
+      vim.api.nvim_buf_set_lines(buf, first - 1, last, false, lines)
+    end)
+  end, { nargs = "+", range = true })
+
+  vim.api.nvim_create_user_command("TomRemember", function(o)
+    request("memory_add", { text = range_text(o) }, function()
+      vim.notify("Tom: memory stored")
+    end)
+  end, { range = true })
+
+  vim.api.nvim_create_user_command("TomCancel", function()
+    local ids = vim.tbl_keys(callbacks)
+    for _, id in ipairs(ids) do
+      request("cancel", { id = id })
+    end
+  end, {})
+
+  vim.api.nvim_create_user_command("TomReset", function()
+    request("reset", {}, function() vim.notify("Tom: new conversation") end)
+  end, {})
+end
+
+return M
@@ -0,0 +1,99 @@
+"""Client of the gRPC service of the memory-tui daemon, for Python integrations.
+
+The daemon (`memory-tui daemon`) serves the TomDaemon service of
+proto/daemon.proto on ~/.tom/daemon.sock with the session it keeps open, so an
+integration asks Tom or manages the memories without logging in. It needs
+grpcio and protobuf (`pip install grpcio protobuf`), and daemon_pb2.py and
+daemon_pb2_grpc.py next to it.
+
+Example Usage:
+
+    from tom_daemon import TomDaemon
+
+    tom = TomDaemon()
+    for chunk in tom.ask("What is on my calendar today?"):
+        print(chunk, end="", flush=True)
+
+    memory = tom.add_memory("The wifi code of the cottage is on the fridge", {"tags": ["home"]})
+    print(tom.search_memories("wifi", limit=5))
+    memory = tom.update_memory(memory.id, "The wifi code of the cottage is in the drawer")
+    tom.delete_memory(memory.id)
+
+    for tasks in tom.notifications(interval=60):  # Blocks, one list per change
+        print([(task.module, task.status) for task in tasks])
+
+The errors are grpc.RpcError, with the status code of the daemon: NOT_FOUND
+for an unknown memory, UNAUTHENTICATED when the daemon is not logged in,
+INVALID_ARGUMENT for a request without its text, id or query.
+"""
+
+import os
+
+import grpc
+from google.protobuf import struct_pb2
+
+import daemon_pb2
+import daemon_pb2_grpc
+
+DEFAULT_SOCKET = os.path.expanduser("~/.tom/daemon.sock")
+
+
+def _struct(metadata):
+    if metadata is None:
+        return None
+    value = struct_pb2.Struct()
+    value.update(metadata)
+    return value
+
+
+class TomDaemon:
+    def __init__(self, path=DEFAULT_SOCKET, timeout=300):
+        self.timeout = timeout
+        self.channel = grpc.insecure_channel("unix:" + path)
+        self.stub = daemon_pb2_grpc.TomDaemonStub(self.channel)
+
+    def close(self):
+        self.channel.close()
+
+    def __enter__(self):
+        return self
+
+    def __exit__(self, *exc):
+        self.close()
+
+    def ask(self, prompt):
+        """Ask Tom, yielding the answer in chunks as it comes."""
+        for response in self.stub.Ask(daemon_pb2.AskRequest(prompt=prompt), timeout=self.timeout):
+            if response.done:
+                return
+            yield response.chunk
+
+    def get_memory(self, memory_id):
+        return self.stub.GetMemory(daemon_pb2.GetMemoryRequest(id=memory_id), timeout=self.timeout)
+
+    def memories(self):
+        """The memories of the daemon cache, without a request to Tom."""
+        return list(self.stub.ListMemories(daemon_pb2.ListMemoriesRequest(), timeout=self.timeout).memories)
+
+    def search_memories(self, query, limit=0):
+        request = daemon_pb2.SearchMemoriesRequest(query=query, limit=limit)
+        return list(self.stub.SearchMemories(request, timeout=self.timeout).memories)
+
+    def add_memory(self, text, metadata=None):
+        """Add a memory, masked as configured, returning it as stored."""
+        request = daemon_pb2.AddMemoryRequest(text=text, metadata=_struct(metadata))
+        return self.stub.AddMemory(request, timeout=self.timeout)
+
+    def update_memory(self, memory_id, text, metadata=None):
+        """Replace a memory, returning the new one: its id changes."""
+        request = daemon_pb2.UpdateMemoryRequest(id=memory_id, text=text, metadata=_struct(metadata))
+        return self.stub.UpdateMemory(request, timeout=self.timeout)
+
+    def delete_memory(self, memory_id):
+        self.stub.DeleteMemory(daemon_pb2.DeleteMemoryRequest(id=memory_id), timeout=self.timeout)
+
+    def notifications(self, interval=30):
+        """Yield the notification status of Tom's modules, at once then at each change."""
+        request = daemon_pb2.SubscribeNotificationsRequest(interval_seconds=interval)
+        for notifications in self.stub.SubscribeNotifications(request):
+            yield list(notifications.tasks)
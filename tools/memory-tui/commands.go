@@ -0,0 +1,306 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbletea"
+)
+
+// Prompt commands registry: help, usage errors and completion are all built
+// from these entries
+type promptCommand struct {
+	Name    string
+	Aliases []string
+	// Args documents the arguments; unless it starts with "[" they are
+	// required and the handler is never called without them
+	Args string
+	Help string
+	// Complete lists the values accepted as first argument, for completion
+	Complete func() []string
+	// Requires names the server module or capability the command needs,
+	// see capabilities.go
+	Requires string
+	// Writes marks the commands changing memories, disabled in read-only
+	// mode
+	Writes bool
+	// SideEffects marks the commands acting outside memory-tui: running
+	// code, changing files or the directory, emails or devices. They are
+	// disabled in read-only mode too
+	SideEffects bool
+	Run         func(m model, args string) (tea.Model, tea.Cmd)
+}
+
+var promptCommands []promptCommand
+
+// Registered in init since some handlers list the commands themselves
+func init() {
+	promptCommands = []promptCommand{
+		{Name: "quit", Aliases: []string{"q"}, Help: "Quit memory-tui", Run: runQuitCommand},
+		{Name: "add", Aliases: []string{"a"}, Args: "[TEXT]", Help: "Add TEXT as a memory, or open the editor", Requires: moduleMemory, Writes: true, Run: runAddCommand},
+		{Name: "search", Aliases: []string{"s"}, Args: "QUERY", Help: "Search the memories", Requires: moduleMemory, Run: runSearchCommand},
+		{Name: "filter", Aliases: []string{"f"}, Args: "WORDS", Help: "List the loaded memories having words starting with WORDS, without a server request", Requires: moduleMemory, Run: runFilterCommand},
+		{Name: "goto", Aliases: []string{"g"}, Args: "ID", Help: "Open the memory ID, or a " + memoryURLScheme + "ID link", Requires: moduleMemory, Run: runGotoCommand},
+		{Name: "template", Aliases: []string{"t"}, Args: "NAME", Help: "Fill a memory template", Complete: templateNames, Requires: moduleMemory, Writes: true, Run: runTemplateCommand},
+		{Name: "import", Aliases: []string{"i"}, Args: "PATH|--resume", Help: "Import a Markdown vault, .enex file or text directory, or resume the interrupted import", Requires: moduleMemory, Writes: true, Run: runImportCommand},
+		{Name: "export", Aliases: []string{"e"}, Args: "[md|json] [PATH]", Help: "Export the marked or listed memories to PATH or the clipboard", Complete: func() []string { return []string{"md", "json"} }, Run: runExportCommand},
+		{Name: "delete", Aliases: []string{"d"}, Help: "Delete the marked memories", Requires: moduleMemory, Writes: true, Run: runDeleteCommand},
+		{Name: "merge", Args: "[summarize]", Help: "Merge the marked memories into one, from their texts or a summary by Tom", Complete: func() []string { return []string{"summarize"} }, Requires: moduleMemory, Writes: true, Run: runMergeCommand},
+		{Name: "summarize", Help: "Have Tom summarize the marked or listed memories into a new one", Requires: moduleMemory, Writes: true, Run: runSummarizeCommand},
+		{Name: "ask", Args: "QUESTION", Help: "Ask Tom about the marked or listed memories", Requires: moduleMemory, Run: runAskCommand},
+		{Name: "digest", Args: "[refresh]", Help: "Show today's briefing: calendar, weather, tasks and news", Complete: func() []string { return []string{"refresh"} }, Run: runDigestCommand},
+		{Name: "ha", Args: "[SHORTCUT]", Help: "Send a home automation shortcut of the \"ha_shortcuts\" setting to Tom, or pick one", Complete: haShortcutNames, SideEffects: true, Run: runHACommand},
+		{Name: "mail", Help: "Triage the unread emails: mark read, archive, or have Tom draft a reply", Requires: moduleMail, SideEffects: true, Run: runMailCommand},
+		{Name: "news", Help: "Read the unread news, summarized by Tom on demand", Requires: moduleNews, Run: runNewsCommand},
+		{Name: "pins", Args: "[QUERY|export [PATH]]", Help: "List the pinned answers, those holding QUERY, or export them as Markdown", Complete: func() []string { return []string{"export"} }, Run: runPinsCommand},
+		{Name: "scratch", Args: "[on|off]", Help: "List the code blocks of Tom's answers saved this session, or toggle their saving", Complete: func() []string { return []string{"on", "off"} }, Run: runScratchCommand},
+		{Name: "run", Args: "N", Help: "Run the code block N of /scratch once confirmed, its output going back to Tom on demand", SideEffects: true, Run: runRunCommand},
+		{Name: "apply", Args: "[N [FILE]]", Help: "Apply the unified diff of the code block N, the last diff by default, once previewed", SideEffects: true, Run: runApplyCommand},
+		{Name: "cd", Args: "[DIR]", Help: "Change the local directory of /run, /apply, /ls and /cat, home by default", SideEffects: true, Run: runCdCommand},
+		{Name: "ls", Args: "[PATH]", Help: "List a local directory, c including the listing in the next prompt to Tom", Run: runLsCommand},
+		{Name: "cat", Args: "FILE", Help: "Show a local file, c including it in the next prompt to Tom", Run: runCatCommand},
+		{Name: "commitmsg", Help: "Have Tom write the commit message of the staged changes of the local directory", Run: runCommitMsgCommand},
+		{Name: "explaindiff", Args: "[REV]", Help: "Have Tom explain the changes of git diff REV, the uncommitted ones by default", Run: runExplainDiffCommand},
+		{Name: "watch", Args: "[GLOB]", Help: "Tell Tom the new content of the local files matching GLOB when they change, or list the globs watched", SideEffects: true, Run: runWatchCommand},
+		{Name: "unwatch", Args: "[GLOB]", Help: "Stop watching GLOB, or every file", Run: runUnwatchCommand},
+		{Name: "calc", Args: "EXPRESSION", Help: "Compute locally, such as /calc 15% of 84", Run: runCalcCommand},
+		{Name: "conv", Args: "VALUE FROM TO", Help: "Convert units locally, such as /conv 10 km to mi", Run: runConvCommand},
+		{Name: "timer", Args: "DURATION [LABEL]", Help: "Set a timer, such as /timer 25m \"review PR\", ringing when it ends", Run: runTimerCommand},
+		{Name: "timers", Help: "List and cancel the running timers", Run: runTimersCommand},
+		{Name: "who", Args: "NAME", Help: "Show what Tom knows about a person: contact, birthday, facts and related memories", Requires: moduleMemory, Run: runWhoCommand},
+		{Name: "lint", Help: "List the memories needing a cleanup: too short or long, JSON dumps, no metadata", Requires: moduleMemory, Run: runLintCommand},
+		{Name: "map", Help: "Experimental: plot the memories by similarity and explore their clusters", Requires: moduleMemory, Run: runMapCommand},
+		{Name: "restore", Args: "ID", Help: "Bring a deleted memory back from the server trash", Requires: capSoftDelete, Writes: true, Run: runRestoreCommand},
+		{Name: "unmark", Help: "Clear the marks", Run: runUnmarkCommand},
+		{Name: "dryrun", Args: "[on|off]", Help: "Toggle the dry run, logging adds and deletes instead of sending them", Complete: func() []string { return []string{"on", "off"} }, Run: runDryRunCommand},
+		{Name: "refresh", Aliases: []string{"r"}, Help: "Reload the memories", Requires: moduleMemory, Run: runRefreshCommand},
+		{Name: "audit", Help: "Show the adds and deletes made from this client", Run: func(m model, args string) (tea.Model, tea.Cmd) { return m.openAudit() }},
+		{Name: "logs", Aliases: []string{"log"}, Help: "Show the client log", Run: func(m model, args string) (tea.Model, tea.Cmd) { return m.openLogs() }},
+		{Name: "help", Aliases: []string{"h"}, Args: "[COMMAND]", Help: "Describe a command", Complete: promptCommandNames, Run: runHelpCommand},
+		{Name: "disconnect", Aliases: []string{"logout"}, Help: "Forget the saved credentials", Run: func(m model, args string) (tea.Model, tea.Cmd) { return m, disconnect }},
+	}
+}
+
+func findPromptCommand(name string) *promptCommand {
+	name = strings.TrimPrefix(name, "/")
+	for i, c := range promptCommands {
+		if c.Name == name {
+			return &promptCommands[i]
+		}
+		for _, alias := range c.Aliases {
+			if alias == name {
+				return &promptCommands[i]
+			}
+		}
+	}
+	return nil
+}
+
+func promptCommandNames() []string {
+	names := make([]string, len(promptCommands))
+	for i, c := range promptCommands {
+		names[i] = c.Name
+	}
+	return names
+}
+
+func (c promptCommand) usage() string {
+	if c.Args == "" {
+		return "/" + c.Name
+	}
+	return "/" + c.Name + " " + c.Args
+}
+
+// promptCommandsSummary lists the usage of every command the server enables,
+// for the prompt placeholder and the unknown command message
+func promptCommandsSummary(api *MemoryAPI) string {
+	var usages []string
+	for _, c := range promptCommands {
+		if c.available(api) {
+			usages = append(usages, c.usage())
+		}
+	}
+	return strings.Join(usages, " ")
+}
+
+// promptSuggestions feeds the prompt completion: every command the server
+// enables, then each of them followed by each of its known arguments
+func promptSuggestions(api *MemoryAPI) []string {
+	var suggestions []string
+	for _, c := range promptCommands {
+		if c.available(api) {
+			suggestions = append(suggestions, "/"+c.Name+" ")
+		}
+	}
+	for _, c := range promptCommands {
+		if c.Complete == nil || !c.available(api) {
+			continue
+		}
+		values := c.Complete()
+		sort.Strings(values)
+		for _, v := range values {
+			suggestions = append(suggestions, "/"+c.Name+" "+v)
+		}
+	}
+	return suggestions
+}
+
+// completePrompt returns the first suggestion extending value, if any
+func completePrompt(api *MemoryAPI, value string) (string, bool) {
+	if value == "" {
+		return "", false
+	}
+	for _, s := range promptSuggestions(api) {
+		if len(s) > len(value) && strings.HasPrefix(strings.ToLower(s), strings.ToLower(value)) {
+			return s, true
+		}
+	}
+	return "", false
+}
+
+func newPromptInput(api *MemoryAPI) textinput.Model {
+	input := textinput.New()
+	input.Placeholder = promptCommandsSummary(api)
+	input.Width = 50
+	input.ShowSuggestions = true
+	input.SetSuggestions(promptSuggestions(api))
+	return input
+}
+
+// Handle prompt commands
+func (m model) handlePromptCommand() (tea.Model, tea.Cmd) {
+	command := strings.TrimSpace(m.promptInput.Value())
+	m.promptInput.SetValue("")
+
+	if command == "" {
+		return m, nil
+	}
+
+	// Split command and arguments
+	parts := strings.SplitN(command, " ", 2)
+	name := parts[0]
+	var args string
+	if len(parts) > 1 {
+		args = strings.TrimSpace(parts[1])
+	}
+
+	c := findPromptCommand(name)
+	if c == nil || !strings.HasPrefix(name, "/") {
+		m.message = tr("Unknown command: %s. Available: %s", name, promptCommandsSummary(m.api))
+		return m, nil
+	}
+	if !c.available(m.api) {
+		m.err = c.unavailable(m.api)
+		return m, nil
+	}
+	if args == "" && c.Args != "" && !strings.HasPrefix(c.Args, "[") {
+		m.message = tr("Usage: %s (%s)", c.usage(), tr(c.Help))
+		if c.Complete != nil {
+			m.message = tr("Usage: %s (%s)", c.usage(), strings.Join(c.Complete(), ", "))
+		}
+		return m, nil
+	}
+	return c.Run(m, args)
+}
+
+func runHelpCommand(m model, args string) (tea.Model, tea.Cmd) {
+	if args == "" {
+		return m.openHelp()
+	}
+	c := findPromptCommand(args)
+	if c == nil {
+		m.message = tr("Unknown command: %s. Available: %s", args, promptCommandsSummary(m.api))
+		return m, nil
+	}
+	m.message = c.usage() + ": " + tr(c.Help)
+	if len(c.Aliases) > 0 {
+		m.message += " (also /" + strings.Join(c.Aliases, ", /") + ")"
+	}
+	if !c.available(m.api) {
+		m.message += " - " + c.unavailable(m.api).Error()
+	}
+	return m, nil
+}
+
+func runQuitCommand(m model, args string) (tea.Model, tea.Cmd) {
+	return m, tea.Quit
+}
+
+func runAddCommand(m model, args string) (tea.Model, tea.Cmd) {
+	if args == "" {
+		// Open the editor for longer memories
+		return m.openComposer("")
+	}
+	m.setFocus(focusContent)
+	return m.submitMemory(args, nil, false)
+}
+
+func runSearchCommand(m model, args string) (tea.Model, tea.Cmd) {
+	m.loading = true
+	m.setFocus(focusContent)
+	return m, m.searchMemories(args)
+}
+
+func runRefreshCommand(m model, args string) (tea.Model, tea.Cmd) {
+	m.loading = true
+	m.linting = false
+	m.filterQuery = ""
+	m.message = tr("Refreshing...")
+	m.setFocus(focusContent)
+	return m, m.loadMemories()
+}
+
+func runTemplateCommand(m model, args string) (tea.Model, tea.Cmd) {
+	var err error
+	if m, err = m.openTemplate(args); err != nil {
+		m.err = err
+		return m, nil
+	}
+	return m, textinput.Blink
+}
+
+func runImportCommand(m model, args string) (tea.Model, tea.Cmd) {
+	if args == "--resume" {
+		m.setFocus(focusContent)
+		return m.resumeImport()
+	}
+	if strings.HasPrefix(args, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			args = filepath.Join(home, args[2:])
+		}
+	}
+	m.loading = true
+	m.setFocus(focusContent)
+	return m, m.startImport(args)
+}
+
+func runExportCommand(m model, args string) (tea.Model, tea.Cmd) {
+	memories := m.selectedMemories()
+	if len(memories) == 0 {
+		m.message = tr("No memory to export")
+		return m, nil
+	}
+	format, path := parseExportArgs(args)
+	m.loading = true
+	m.setFocus(focusContent)
+	return m, exportMemories(memories, format, path)
+}
+
+func runDeleteCommand(m model, args string) (tea.Model, tea.Cmd) {
+	if len(m.marked) == 0 {
+		m.message = tr("Mark memories with Space first, then /delete")
+		return m, nil
+	}
+	m.bulkDelete = m.selectedMemories()
+	m.showView(confirmDeleteView)
+	return m, nil
+}
+
+func runUnmarkCommand(m model, args string) (tea.Model, tea.Cmd) {
+	m.clearMarks()
+	m.message = tr("Selection cleared")
+	return m, nil
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// Client configuration, read from ~/.tom/memory-tui.json
+type config struct {
+	Language   string `json:"language"`   // e.g. "fr", "en"
+	Spellcheck bool   `json:"spellcheck"` // Enable the spellcheck pass in the add view
+	ChunkSize  int    `json:"chunk_size"` // Texts longer than this are offered for splitting
+
+	// Date display, see timefmt.go
+	TimeFormat    string `json:"time_format"`    // Go layout, e.g. "02/01/2006 15:04"
+	RelativeTimes bool   `json:"relative_times"` // "2 hours ago" in the list
+
+	// Encrypt ~/.tom/auth with a passphrase asked at startup
+	EncryptCredentials bool `json:"encrypt_credentials"`
+
+	// Ring when a response arrives unfocused or late, see bell.go
+	Bell      bool   `json:"bell"`       // Terminal bell
+	BellSound string `json:"bell_sound"` // Command played instead, e.g. "paplay ding.oga"
+	BellAfter int    `json:"bell_after"` // Seconds after which a response rings even focused
+
+	// Masked in the memories sent, see redact.go
+	Redact         []string          `json:"redact"`          // email, card, api_key
+	RedactPatterns map[string]string `json:"redact_patterns"` // Name to regexp
+
+	// Request timeouts in seconds by class: interactive, polling, bulk
+	Timeouts map[string]int `json:"timeouts"`
+
+	// Requests per second of the bulk operations on servers without batch
+	// endpoints, 0 for no pacing, see bulk.go
+	BulkRate float64 `json:"bulk_rate"`
+
+	// Minutes without a key press before the lock, 0 to never lock
+	IdleLock int `json:"idle_lock"`
+
+	// Browse only: adds, imports, deletes and restores are disabled
+	ReadOnly bool `json:"read_only"`
+
+	// Questions of the /digest briefing, the default ones when unset
+	Digest []digestSection `json:"digest"`
+
+	// Commands of /ha, see ha.go
+	HAShortcuts []haShortcut `json:"ha_shortcuts"`
+
+	// Write the code blocks of Tom's answers to ~/.tom/scratch, see scratch.go
+	Scratch bool `json:"scratch"`
+
+	// Folder /mail archives the emails to, Archive when unset
+	MailArchive string `json:"mail_archive"`
+
+	// Have the daemon read Tom's reminders for memory-tui status, a request
+	// to Tom every 30 minutes, see status.go
+	StatusReminders bool `json:"status_reminders"`
+
+	// Exchanges with Tom the --kiosk screen shows, 3 when unset
+	KioskExchanges int `json:"kiosk_exchanges"`
+
+	// Fewer redraws and checks, for SSH over a slow link, see lowbandwidth.go
+	LowBandwidth bool `json:"low_bandwidth"`
+
+	// Commands run on events, by event name (see hooks.go)
+	Hooks map[string]string `json:"hooks"`
+}
+
+func defaultConfig() config {
+	return config{
+		Language:      "fr",
+		ChunkSize:     defaultChunkSize,
+		TimeFormat:    defaultTimeFormat,
+		RelativeTimes: true,
+		BellAfter:     defaultBellAfter,
+		BulkRate:      defaultBulkRate,
+	}
+}
+
+func getConfigFilePath() (string, error) {
+	usr, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(usr, ".tom", "memory-tui.json"), nil
+}
+
+// loadConfig returns the default configuration when no file exists
+func loadConfig() (config, error) {
+	cfg := defaultConfig()
+
+	configPath, err := getConfigFilePath()
+	if err != nil {
+		return cfg, err
+	}
+
+	data, err := os.ReadFile(configPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return defaultConfig(), err
+	}
+	return cfg, nil
+}
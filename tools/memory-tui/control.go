@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbletea"
+)
+
+// Control socket of the running interface, so scripts and window manager
+// keybindings can drive it: `memory-tui ctl add "..."`, `memory-tui ctl refresh`
+type controlRequest struct {
+	Command string `json:"command"` // Prompt command line, e.g. "/add TEXT"
+	Last    bool   `json:"last,omitempty"`
+}
+
+type controlResponse struct {
+	Error   string `json:"error,omitempty"`
+	Message string `json:"message,omitempty"`
+	State   string `json:"state,omitempty"`
+	Count   int    `json:"count"`
+}
+
+// controlMsg carries a request into Update, which answers on reply
+type controlMsg struct {
+	req   controlRequest
+	reply chan controlResponse
+}
+
+func controlSocketPath() (string, error) {
+	return tomPath("memory-tui.sock")
+}
+
+// serveControl forwards the requests of each connection to the program
+func serveControl(listener net.Listener, p *tea.Program) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		go func(conn net.Conn) {
+			defer conn.Close()
+			decoder := json.NewDecoder(bufio.NewReader(conn))
+			encoder := json.NewEncoder(conn)
+			for {
+				var req controlRequest
+				if err := decoder.Decode(&req); err != nil {
+					return
+				}
+				reply := make(chan controlResponse, 1)
+				p.Send(controlMsg{req, reply})
+
+				var resp controlResponse
+				select {
+				case resp = <-reply:
+				case <-time.After(5 * time.Second):
+					resp = controlResponse{Error: "the interface did not answer"}
+				}
+				if err := encoder.Encode(resp); err != nil {
+					return
+				}
+			}
+		}(conn)
+	}
+}
+
+func (m model) stateName() string {
+	switch m.state {
+	case connectingView, loginView, passphraseView:
+		return "login"
+	case lockView:
+		return "locked"
+	case listView:
+		return "list"
+	case detailView:
+		return "detail"
+	case batchView:
+		return "batch"
+	}
+	return "editing"
+}
+
+func (m model) handleControl(msg controlMsg) (tea.Model, tea.Cmd) {
+	logf("DEBUG", "control request %+v", msg.req)
+	var cmd tea.Cmd
+	switch {
+	case msg.req.Last:
+	case m.api == nil:
+		msg.reply <- controlResponse{Error: "not logged in", State: m.stateName()}
+		return m, nil
+	case m.state != listView && m.state != detailView:
+		// Never discard what is being typed or confirmed
+		msg.reply <- controlResponse{Error: "the interface is busy (" + m.stateName() + ")", State: m.stateName()}
+		return m, nil
+	default:
+		m.err = nil
+		m.showView(listView)
+		m.promptInput.SetValue(msg.req.Command)
+		var updated tea.Model
+		updated, cmd = m.handlePromptCommand()
+		m = updated.(model)
+	}
+
+	resp := controlResponse{Message: m.message, State: m.stateName(), Count: len(m.memories)}
+	if cmd != nil {
+		// The result arrives later, `ctl last` reports it
+		resp.Message = "Running " + msg.req.Command
+	}
+	if m.err != nil {
+		resp.Error = m.err.Error()
+	}
+	msg.reply <- resp
+	return m, cmd
+}
+
+// controlCommand maps the ctl arguments to a prompt command line
+func controlCommand(args []string) (controlRequest, error) {
+	if len(args) == 0 {
+		return controlRequest{}, errors.New("missing command (add, search, goto, refresh, last or run)")
+	}
+	rest := strings.Join(args[1:], " ")
+	switch args[0] {
+	case "add", "search", "goto":
+		if rest == "" {
+			return controlRequest{}, fmt.Errorf("usage: memory-tui ctl %s TEXT", args[0])
+		}
+		return controlRequest{Command: "/" + args[0] + " " + rest}, nil
+	case "refresh":
+		return controlRequest{Command: "/refresh"}, nil
+	case "last":
+		return controlRequest{Last: true}, nil
+	case "run":
+		if !strings.HasPrefix(rest, "/") {
+			return controlRequest{}, errors.New("usage: memory-tui ctl run /COMMAND [ARGS]")
+		}
+		return controlRequest{Command: rest}, nil
+	}
+	return controlRequest{}, fmt.Errorf("unknown control command %q", args[0])
+}
+
+func runControl(args []string) error {
+	req, err := controlCommand(args)
+	if err != nil {
+		return err
+	}
+	path, err := controlSocketPath()
+	if err != nil {
+		return err
+	}
+	var resp controlResponse
+	if err := callSocket(path, req, &resp); err != nil {
+		return fmt.Errorf("memory-tui is not running: %w", err)
+	}
+	if resp.Error != "" {
+		return errors.New(resp.Error)
+	}
+	if resp.Message != "" {
+		fmt.Println(resp.Message)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// Versioned schema of ~/.tom/auth, shared by the Tom terminal clients.
+// Version 1 is the unversioned 4-field file written by older memory-tui
+// builds; the chat TUI wrote a 2-field file, sometimes as plain JSON.
+const credentialsVersion = 2
+
+type credentials struct {
+	Version       int    `json:"version"`
+	Username      string `json:"username"`
+	Password      string `json:"password"`
+	ServerURL     string `json:"server_url"`
+	SessionCookie string `json:"session_cookie"`
+}
+
+// Keys used by the legacy formats for each field
+var credentialAliases = map[string][]string{
+	"username":       {"username", "user", "login"},
+	"password":       {"password"},
+	"server_url":     {"server_url", "server", "url"},
+	"session_cookie": {"session_cookie", "cookie", "session"},
+}
+
+func marshalCredentials(creds credentials) ([]byte, error) {
+	creds.Version = credentialsVersion
+	return json.Marshal(creds)
+}
+
+// parseCredentials decodes any known format of the file (base64 or plain
+// JSON, versioned or not) and reports whether it must be rewritten
+func parseCredentials(data []byte) (credentials, bool, error) {
+	data = bytes.TrimSpace(data)
+	if !bytes.HasPrefix(data, []byte("{")) {
+		decoded, err := base64.StdEncoding.DecodeString(string(data))
+		if err != nil {
+			return credentials{}, false, fmt.Errorf("unreadable credentials file: %w", err)
+		}
+		data = decoded
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return credentials{}, false, fmt.Errorf("unreadable credentials file: %w", err)
+	}
+
+	version := 1
+	if v, ok := fields["version"].(float64); ok {
+		version = int(v)
+	}
+	if version > credentialsVersion {
+		return credentials{}, false, fmt.Errorf("credentials file version %d was written by a newer client", version)
+	}
+
+	pick := func(field string) string {
+		for _, key := range credentialAliases[field] {
+			if v, ok := fields[key].(string); ok && v != "" {
+				return v
+			}
+		}
+		return ""
+	}
+
+	creds := credentials{
+		Version:       credentialsVersion,
+		Username:      pick("username"),
+		Password:      pick("password"),
+		ServerURL:     pick("server_url"),
+		SessionCookie: pick("session_cookie"),
+	}
+	return creds, version < credentialsVersion, nil
+}
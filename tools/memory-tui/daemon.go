@@ -0,0 +1,365 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/charmbracelet/bubbletea"
+)
+
+// Background daemon keeping the session alive and the memories synced to the
+// local store, see store.go, so the interface attaches to it through a unix socket instead
+// of logging in and loading everything at each start
+const defaultSyncInterval = 5 * time.Minute
+
+func tomPath(elem ...string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(append([]string{home, ".tom"}, elem...)...), nil
+}
+
+func daemonSocketPath() (string, error) {
+	return tomPath("daemon.sock")
+}
+
+// One JSON request per line, answered by one JSON response per line. The
+// integrations use the gRPC service of daemonapi.go on the same socket.
+type daemonRequest struct {
+	Method string `json:"method"`
+}
+
+type daemonResponse struct {
+	Error         string    `json:"error,omitempty"`
+	ServerURL     string    `json:"server_url,omitempty"`
+	Username      string    `json:"username,omitempty"`
+	SessionCookie string    `json:"session_cookie,omitempty"`
+	APIVersion    int       `json:"api_version,omitempty"`
+	Capabilities  []string  `json:"capabilities,omitempty"`
+	Modules       []string  `json:"modules,omitempty"` // Enabled modules, nil when unknown
+	ReadOnly      bool      `json:"read_only,omitempty"`
+	Memories      []Memory  `json:"memories,omitempty"`
+	SyncedAt      time.Time `json:"synced_at"`
+
+	Status *daemonStatus `json:"status,omitempty"` // See status.go
+}
+
+type memoryCache struct {
+	SyncedAt time.Time `json:"synced_at"`
+	Memories []Memory  `json:"memories"`
+}
+
+type daemon struct {
+	mu           sync.Mutex
+	session      *session
+	apiVersion   int
+	capabilities []string
+	modules      []string
+	readOnly     bool
+	cache        memoryCache
+	lastErr      error
+	hooks        map[string]string
+
+	// See status.go
+	status          daemonStatus
+	statusReminders bool
+	tomReminders    []statusReminder
+	remindersReadAt time.Time
+}
+
+func (d *daemon) loadCache() {
+	if cache, err := loadMemoryCache(); err == nil {
+		d.cache = cache
+	}
+}
+
+// sync refreshes the session when needed and pulls the memories
+func (d *daemon) sync() error {
+	d.mu.Lock()
+	sess := d.session
+	d.mu.Unlock()
+
+	if sess == nil {
+		var err error
+		if sess, err = openSession(); err != nil {
+			return err
+		}
+	}
+	sess.API = sess.API.Background(timeoutPolling)
+	sess.API.Hooks = d.hooks
+
+	memories, err := sess.API.GetAllMemories()
+	if errors.Is(err, errSessionExpired) {
+		if sess, err = openSession(); err != nil {
+			return err
+		}
+		sess.API = sess.API.Background(timeoutPolling)
+		sess.API.Hooks = d.hooks
+		memories, err = sess.API.GetAllMemories()
+	}
+	if err != nil {
+		return err
+	}
+
+	info := serverInfo{APIVersion: 1}
+	if fetched, err := fetchServerInfo(sess.API.Client, sess.API.ServerURL); err == nil {
+		info = fetched
+	}
+	sess.API.applyServerInfo(info)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.session = sess
+	d.apiVersion = info.APIVersion
+	d.capabilities = info.Capabilities
+	d.modules = info.enabledModules()
+	d.readOnly = info.ReadOnly
+	d.cache = memoryCache{SyncedAt: time.Now(), Memories: memories}
+	runHook(sess.API.Hooks, hookSynced, map[string]interface{}{"count": len(memories), "synced_at": d.cache.SyncedAt})
+	return saveMemoryCache(d.cache)
+}
+
+func (d *daemon) handle(req daemonRequest) daemonResponse {
+	if req.Method == "sync" {
+		if err := d.sync(); err != nil {
+			return daemonResponse{Error: err.Error()}
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	switch req.Method {
+	case "session", "sync":
+		if d.session == nil {
+			msg := "not logged in"
+			if d.lastErr != nil {
+				msg = d.lastErr.Error()
+			}
+			return daemonResponse{Error: msg}
+		}
+		return daemonResponse{
+			ServerURL:     d.session.API.ServerURL,
+			Username:      d.session.Username,
+			SessionCookie: d.session.SessionCookie,
+			APIVersion:    d.apiVersion,
+			Capabilities:  d.capabilities,
+			Modules:       d.modules,
+			ReadOnly:      d.readOnly,
+			Memories:      d.cache.Memories,
+			SyncedAt:      d.cache.SyncedAt,
+		}
+	case "memories":
+		return daemonResponse{Memories: d.cache.Memories, SyncedAt: d.cache.SyncedAt}
+	case "status":
+		if d.status.UpdatedAt.IsZero() {
+			return daemonResponse{}
+		}
+		status := d.status
+		return daemonResponse{Status: &status}
+	}
+	return daemonResponse{Error: fmt.Sprintf("unknown method %q", req.Method)}
+}
+
+func (d *daemon) serve(conn net.Conn) {
+	defer conn.Close()
+	decoder := json.NewDecoder(bufio.NewReader(conn))
+	encoder := json.NewEncoder(conn)
+	for {
+		var req daemonRequest
+		if err := decoder.Decode(&req); err != nil {
+			return
+		}
+		if err := encoder.Encode(d.handle(req)); err != nil {
+			return
+		}
+	}
+}
+
+// listenUnix listens on path, replacing a socket left by a dead process
+func listenUnix(path string) (net.Listener, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		conn.Close()
+		return nil, fmt.Errorf("already running, %s is in use", path)
+	}
+	os.Remove(path)
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
+
+func runDaemon(socketPath string, interval time.Duration) error {
+	listener, err := listenUnix(socketPath)
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+
+	cfg, err := loadConfig()
+	if err != nil {
+		logf("WARN", "failed to load config, using defaults: %v", err)
+	}
+	d := &daemon{hooks: cfg.Hooks, statusReminders: cfg.StatusReminders}
+	d.loadCache()
+
+	rpc := d.serveRPC(listener.Addr())
+	defer rpc.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go d.route(conn, rpc)
+		}
+	}()
+
+	go func() {
+		for range time.Tick(scheduleCheckInterval) {
+			d.runSchedules()
+		}
+	}()
+
+	go func() {
+		for range time.Tick(timerTickInterval) {
+			d.runTimers()
+		}
+	}()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	logf("INFO", "memory-tui daemon listening on %s, syncing every %s", socketPath, interval)
+	for first := true; ; first = false {
+		err := d.sync()
+		if first {
+			// Once the first sync opened the session
+			go func() {
+				for ; ; time.Sleep(statusRefreshInterval) {
+					d.refreshStatus()
+				}
+			}()
+		}
+		d.mu.Lock()
+		d.lastErr = err
+		count := len(d.cache.Memories)
+		d.mu.Unlock()
+		if err != nil {
+			logf("WARN", "sync failed: %v", err)
+		} else {
+			logf("INFO", "synced %d memories", count)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-signals:
+			return nil
+		}
+	}
+}
+
+// callSocket sends one JSON request to a unix socket and decodes the answer,
+// failing fast when nothing listens
+func callSocket(path string, req, resp interface{}) error {
+	conn, err := net.DialTimeout("unix", path, 200*time.Millisecond)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(30 * time.Second))
+
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return err
+	}
+	return json.NewDecoder(conn).Decode(resp)
+}
+
+func callDaemon(method string) (daemonResponse, error) {
+	path, err := daemonSocketPath()
+	if err != nil {
+		return daemonResponse{}, err
+	}
+	var resp daemonResponse
+	if err := callSocket(path, daemonRequest{Method: method}, &resp); err != nil {
+		return daemonResponse{}, err
+	}
+	if resp.Error != "" {
+		return resp, errors.New(resp.Error)
+	}
+	return resp, nil
+}
+
+type daemonAttachedMsg struct{ daemonResponse }
+
+// attachOrCheckAuth takes the session of a running daemon, and falls back to
+// the stored credentials otherwise
+func attachOrCheckAuth() tea.Msg {
+	resp, err := callDaemon("session")
+	if err != nil {
+		return checkStoredAuth("")
+	}
+	return daemonAttachedMsg{resp}
+}
+
+func (m model) attachDaemon(resp daemonResponse) (tea.Model, tea.Cmd) {
+	if cookie := parseSessionCookie(resp.SessionCookie); cookie != nil {
+		if u, err := url.Parse(resp.ServerURL); err == nil {
+			m.client.Jar.SetCookies(u, []*http.Cookie{cookie})
+		}
+	}
+	m.usernameInput.SetValue(resp.Username)
+	m.serverInput.SetValue(resp.ServerURL)
+	m.serverURL = resp.ServerURL
+	m.api = NewMemoryAPIWithClient(m.serverURL, m.client)
+	m.api.Hooks = m.config.Hooks
+	m.api.DryRun = m.dryRun
+	m.api.ReadOnly = m.config.ReadOnly || resp.ReadOnly
+	m.setScratch(m.scratch)
+	m.api.applyServerInfo(serverInfo{APIVersion: resp.APIVersion, Capabilities: resp.Capabilities})
+	m.api.Modules = resp.Modules
+	m.refreshPromptCommands()
+	m.versionWarning = versionWarning(resp.APIVersion)
+
+	logf("INFO", "attached to the daemon session on %s", resp.ServerURL)
+	m.err = nil
+	m.showView(listView)
+	m.memories = resp.Memories
+	m.list.SetItems(m.memoryItems(resp.Memories))
+	m.message = tr("Loaded %d memories from the daemon (synced %s)", len(resp.Memories), resp.SyncedAt.Local().Format("15:04"))
+	index := m.index.refresh(resp.Memories)
+	if m.openID != "" {
+		id := m.openID
+		m.openID = ""
+		m.loading = true
+		return m, tea.Batch(m.gotoMemory(id), index)
+	}
+	if m.resume {
+		m.resume = false
+		updated, cmd := m.resumeImport()
+		return updated, tea.Batch(cmd, index)
+	}
+	return m, index
+}
@@ -0,0 +1,419 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"time"
+
+	"github.com/Mickael-Roger/tom/tools/memory-tui/pkg/chatclient"
+	"github.com/Mickael-Roger/tom/tools/memory-tui/pkg/memoryclient"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+	"memory-tui/daemonpb"
+)
+
+// gRPC service of the daemon socket, see proto/daemon.proto, for the
+// integrations (editors, window managers, bars) wanting Tom without a process
+// of their own per request. It shares the socket with the JSON requests of
+// the interface: the gRPC connections start with the HTTP/2 preface, the
+// JSON ones with {. clients/tom_daemon.py is a Python client of it. The
+// prompts of Ask go to the "daemon" conversation, a history Tom keeps apart
+// from his apps.
+const (
+	daemonClientType            = "daemon"
+	daemonAskTimeout            = 3 * time.Minute
+	defaultNotificationInterval = 30 * time.Second
+	minNotificationInterval     = 5 * time.Second
+)
+
+var errDaemonLoggedOut = errors.New("not logged in")
+
+// daemonServer answers the gRPC requests with the session of d
+type daemonServer struct {
+	daemonpb.UnimplementedTomDaemonServer
+	d *daemon
+}
+
+// rpcListener hands the gRPC connections of the socket to the server
+type rpcListener struct {
+	addr  net.Addr
+	conns chan net.Conn
+	done  chan struct{}
+}
+
+func (l *rpcListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *rpcListener) Close() error {
+	select {
+	case <-l.done:
+	default:
+		close(l.done)
+	}
+	return nil
+}
+
+func (l *rpcListener) Addr() net.Addr { return l.addr }
+
+// hand gives conn to the server, closing it once the server is gone
+func (l *rpcListener) hand(conn net.Conn) {
+	select {
+	case l.conns <- conn:
+	case <-l.done:
+		conn.Close()
+	}
+}
+
+// bufferedConn is a connection whose first bytes were read ahead by r
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c bufferedConn) Read(p []byte) (int, error) { return c.r.Read(p) }
+
+// serveRPC starts the gRPC server of d, fed by route
+func (d *daemon) serveRPC(addr net.Addr) *rpcListener {
+	listener := &rpcListener{addr: addr, conns: make(chan net.Conn), done: make(chan struct{})}
+	server := grpc.NewServer()
+	daemonpb.RegisterTomDaemonServer(server, &daemonServer{d: d})
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, net.ErrClosed) {
+			logf("WARN", "daemon: gRPC server stopped: %v", err)
+		}
+	}()
+	return listener
+}
+
+// route serves a connection of the socket with the gRPC server when it
+// starts as HTTP/2 ("PRI * HTTP/2.0"), with serve otherwise
+func (d *daemon) route(conn net.Conn, rpc *rpcListener) {
+	r := bufio.NewReader(conn)
+	first, err := r.Peek(1)
+	if err != nil {
+		conn.Close()
+		return
+	}
+	conn = bufferedConn{conn, r}
+	if first[0] == 'P' {
+		rpc.hand(conn)
+		return
+	}
+	d.serve(conn)
+}
+
+// withSession runs a request with the daemon session, syncing first when it
+// expired, which opens a new one
+func (d *daemon) withSession(class timeoutClass, request func(api *MemoryAPI) error) error {
+	for attempt := 0; ; attempt++ {
+		d.mu.Lock()
+		sess := d.session
+		d.mu.Unlock()
+		if sess == nil {
+			return errDaemonLoggedOut
+		}
+		err := request(sess.API.Background(class))
+		if !errors.Is(err, errSessionExpired) || attempt > 0 {
+			return err
+		}
+		if err := d.sync(); err != nil {
+			return err
+		}
+	}
+}
+
+// rpcError gives err the status code of the gRPC clients
+func rpcError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, memoryclient.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, errDaemonLoggedOut), errors.Is(err, errSessionExpired):
+		return status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, errReadOnly):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	}
+	return status.Error(codes.Unknown, err.Error())
+}
+
+func memoryProto(m Memory) (*daemonpb.Memory, error) {
+	pb := &daemonpb.Memory{Id: m.ID, Memory: m.Memory, Hash: m.Hash, CreatedAt: m.CreatedAt, UserId: m.UserID}
+	if m.UpdatedAt != nil {
+		pb.UpdatedAt = *m.UpdatedAt
+	}
+	if m.Metadata != nil {
+		metadata, err := structpb.NewStruct(m.Metadata)
+		if err != nil {
+			return nil, fmt.Errorf("metadata of memory %s: %w", m.ID, err)
+		}
+		pb.Metadata = metadata
+	}
+	return pb, nil
+}
+
+func memoriesProto(memories []Memory) ([]*daemonpb.Memory, error) {
+	list := make([]*daemonpb.Memory, len(memories))
+	for i, m := range memories {
+		pb, err := memoryProto(m)
+		if err != nil {
+			return nil, err
+		}
+		list[i] = pb
+	}
+	return list, nil
+}
+
+// metadataMap returns the metadata of a request, nil when unset
+func metadataMap(metadata *structpb.Struct) map[string]interface{} {
+	if metadata == nil {
+		return nil
+	}
+	return metadata.AsMap()
+}
+
+// getMemory fetches a memory from Tom, the one to update or delete
+func (api *MemoryAPI) getMemory(ctx context.Context, id string) (Memory, error) {
+	memory, err := api.memories().Get(ctx, id)
+	if errors.Is(err, memoryclient.ErrNotFound) {
+		return Memory{}, fmt.Errorf("memory %s %w", id, memoryclient.ErrNotFound)
+	}
+	return memory, clientError(err)
+}
+
+// redactedText masks the sensitive values of a text to store
+func redactedText(text string) string {
+	text, found := redactText(text)
+	if len(found) > 0 {
+		logf("INFO", "daemon: %d values masked", len(found))
+	}
+	return text
+}
+
+// dropCached removes a deleted memory from the cache, the next sync
+// bringing the added ones
+func (d *daemon) dropCached(id string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	kept := d.cache.Memories[:0:0]
+	for _, mem := range d.cache.Memories {
+		if mem.ID != id {
+			kept = append(kept, mem)
+		}
+	}
+	d.cache.Memories = kept
+}
+
+func (s *daemonServer) GetMemory(ctx context.Context, req *daemonpb.GetMemoryRequest) (*daemonpb.Memory, error) {
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "no id")
+	}
+	var memory Memory
+	err := s.d.withSession(timeoutInteractive, func(api *MemoryAPI) error {
+		var err error
+		memory, err = api.getMemory(ctx, req.Id)
+		return err
+	})
+	if err != nil {
+		return nil, rpcError(err)
+	}
+	return memoryProto(memory)
+}
+
+func (s *daemonServer) ListMemories(ctx context.Context, req *daemonpb.ListMemoriesRequest) (*daemonpb.ListMemoriesResponse, error) {
+	s.d.mu.Lock()
+	cache := s.d.cache
+	s.d.mu.Unlock()
+	memories, err := memoriesProto(cache.Memories)
+	if err != nil {
+		return nil, err
+	}
+	resp := &daemonpb.ListMemoriesResponse{Memories: memories}
+	if !cache.SyncedAt.IsZero() {
+		resp.SyncedAt = cache.SyncedAt.Format(time.RFC3339)
+	}
+	return resp, nil
+}
+
+func (s *daemonServer) SearchMemories(ctx context.Context, req *daemonpb.SearchMemoriesRequest) (*daemonpb.ListMemoriesResponse, error) {
+	if req.Query == "" {
+		return nil, status.Error(codes.InvalidArgument, "no query")
+	}
+	var memories []Memory
+	err := s.d.withSession(timeoutInteractive, func(api *MemoryAPI) error {
+		var err error
+		memories, err = api.SearchMemories(req.Query, int(req.Limit))
+		return err
+	})
+	if err != nil {
+		return nil, rpcError(err)
+	}
+	list, err := memoriesProto(memories)
+	if err != nil {
+		return nil, err
+	}
+	return &daemonpb.ListMemoriesResponse{Memories: list}, nil
+}
+
+func (s *daemonServer) AddMemory(ctx context.Context, req *daemonpb.AddMemoryRequest) (*daemonpb.Memory, error) {
+	if req.Text == "" {
+		return nil, status.Error(codes.InvalidArgument, "no text")
+	}
+	var memory Memory
+	err := s.d.withSession(timeoutInteractive, func(api *MemoryAPI) error {
+		var err error
+		memory, err = api.addMemory(redactedText(req.Text), metadataMap(req.Metadata))
+		return err
+	})
+	if err != nil {
+		return nil, rpcError(err)
+	}
+	return memoryProto(memory)
+}
+
+// UpdateMemory adds the new memory then deletes the former one, as a merge
+// does, so that the trash, the audit log and the hooks see both
+func (s *daemonServer) UpdateMemory(ctx context.Context, req *daemonpb.UpdateMemoryRequest) (*daemonpb.Memory, error) {
+	if req.Id == "" || req.Text == "" {
+		return nil, status.Error(codes.InvalidArgument, "no id or no text")
+	}
+	var added Memory
+	err := s.d.withSession(timeoutInteractive, func(api *MemoryAPI) error {
+		former, err := api.getMemory(ctx, req.Id)
+		if err != nil {
+			return err
+		}
+		metadata := former.Metadata
+		if req.Metadata != nil {
+			metadata = req.Metadata.AsMap()
+		}
+		if added, err = api.addMemory(redactedText(req.Text), metadata); err != nil {
+			return err
+		}
+		if err := api.DeleteMemory(former); err != nil {
+			return fmt.Errorf("memory %s added, but deleting %s failed: %w", added.ID, former.ID, err)
+		}
+		s.d.dropCached(former.ID)
+		return nil
+	})
+	if err != nil {
+		return nil, rpcError(err)
+	}
+	return memoryProto(added)
+}
+
+func (s *daemonServer) DeleteMemory(ctx context.Context, req *daemonpb.DeleteMemoryRequest) (*daemonpb.DeleteMemoryResponse, error) {
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "no id")
+	}
+	err := s.d.withSession(timeoutInteractive, func(api *MemoryAPI) error {
+		memory, err := api.getMemory(ctx, req.Id)
+		if err != nil {
+			return err
+		}
+		if err := api.DeleteMemory(memory); err != nil {
+			return err
+		}
+		s.d.dropCached(memory.ID)
+		return nil
+	})
+	if err != nil {
+		return nil, rpcError(err)
+	}
+	return &daemonpb.DeleteMemoryResponse{}, nil
+}
+
+// Ask sends the answer in chunks as it comes, then the whole answer with
+// done set
+func (s *daemonServer) Ask(req *daemonpb.AskRequest, stream daemonpb.TomDaemon_AskServer) error {
+	if req.Prompt == "" {
+		return status.Error(codes.InvalidArgument, "no prompt")
+	}
+	ctx, cancel := context.WithTimeout(stream.Context(), daemonAskTimeout)
+	defer cancel()
+
+	var answer chatclient.Answer
+	var sendErr error
+	err := s.d.withSession(timeoutBulk, func(api *MemoryAPI) error {
+		chat := chatclient.NewWithHTTPClient(api.ServerURL, daemonClientType, api.Client)
+		var err error
+		answer, err = chat.Stream(ctx, req.Prompt, func(chunk string) error {
+			sendErr = stream.Send(&daemonpb.AskResponse{Chunk: chunk})
+			return sendErr
+		})
+		if errors.Is(err, chatclient.ErrUnauthorized) {
+			return errSessionExpired
+		}
+		return err
+	})
+	switch {
+	case sendErr != nil:
+		return sendErr
+	case err != nil:
+		return rpcError(err)
+	}
+	runHook(s.d.hooks, hookResponse, map[string]interface{}{"source": "daemon", "prompt": req.Prompt, "answer": answer.Text})
+	return stream.Send(&daemonpb.AskResponse{Done: true, Answer: answer.Text})
+}
+
+// SubscribeNotifications sends the notification status of the modules at
+// once, then each time it changes, until the client cancels
+func (s *daemonServer) SubscribeNotifications(req *daemonpb.SubscribeNotificationsRequest, stream daemonpb.TomDaemon_SubscribeNotificationsServer) error {
+	interval := time.Duration(req.IntervalSeconds) * time.Second
+	if interval == 0 {
+		interval = defaultNotificationInterval
+	}
+	if interval < minNotificationInterval {
+		interval = minNotificationInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var last []chatclient.Task
+	sent := false
+	for {
+		var tasks chatclient.Tasks
+		err := s.d.withSession(timeoutPolling, func(api *MemoryAPI) error {
+			var err error
+			tasks, err = chatclient.NewWithHTTPClient(api.ServerURL, daemonClientType, api.Client).Tasks(stream.Context())
+			if errors.Is(err, chatclient.ErrUnauthorized) {
+				return errSessionExpired
+			}
+			return err
+		})
+		if err != nil {
+			logf("WARN", "daemon: notifications: %v", err)
+		} else if !sent || !reflect.DeepEqual(tasks.Background, last) {
+			notifications := &daemonpb.Notifications{Tasks: make([]*daemonpb.Task, len(tasks.Background))}
+			for i, task := range tasks.Background {
+				notifications.Tasks[i] = &daemonpb.Task{Module: task.Module, Status: task.Status}
+			}
+			if err := stream.Send(notifications); err != nil {
+				return err
+			}
+			last, sent = tasks.Background, true
+		}
+
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
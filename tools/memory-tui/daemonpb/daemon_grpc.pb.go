@@ -0,0 +1,456 @@
+// gRPC service of the memory-tui daemon, served on its unix socket
+// (~/.tom/daemon.sock) next to the JSON requests of the interface. The
+// requests go through the daemon session, opened again when it expired.
+//
+// After a change, regenerate the stubs from tools/memory-tui:
+//
+//   protoc -I proto --go_out=daemonpb --go_opt=paths=source_relative \
+//     --go-grpc_out=daemonpb --go-grpc_opt=paths=source_relative daemon.proto
+//   python -m grpc_tools.protoc -I proto --python_out=clients \
+//     --grpc_python_out=clients daemon.proto
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v3.21.12
+// source: daemon.proto
+
+package daemonpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	TomDaemon_Ask_FullMethodName                    = "/tom.daemon.v1.TomDaemon/Ask"
+	TomDaemon_GetMemory_FullMethodName              = "/tom.daemon.v1.TomDaemon/GetMemory"
+	TomDaemon_ListMemories_FullMethodName           = "/tom.daemon.v1.TomDaemon/ListMemories"
+	TomDaemon_SearchMemories_FullMethodName         = "/tom.daemon.v1.TomDaemon/SearchMemories"
+	TomDaemon_AddMemory_FullMethodName              = "/tom.daemon.v1.TomDaemon/AddMemory"
+	TomDaemon_UpdateMemory_FullMethodName           = "/tom.daemon.v1.TomDaemon/UpdateMemory"
+	TomDaemon_DeleteMemory_FullMethodName           = "/tom.daemon.v1.TomDaemon/DeleteMemory"
+	TomDaemon_SubscribeNotifications_FullMethodName = "/tom.daemon.v1.TomDaemon/SubscribeNotifications"
+)
+
+// TomDaemonClient is the client API for TomDaemon service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type TomDaemonClient interface {
+	// Ask sends a prompt to Tom, in a conversation of its own (client type
+	// daemon), and streams the answer: chunks as it comes, then the whole
+	// answer with done set
+	Ask(ctx context.Context, in *AskRequest, opts ...grpc.CallOption) (TomDaemon_AskClient, error)
+	GetMemory(ctx context.Context, in *GetMemoryRequest, opts ...grpc.CallOption) (*Memory, error)
+	// ListMemories returns the memories of the daemon cache, without a request
+	// to Tom
+	ListMemories(ctx context.Context, in *ListMemoriesRequest, opts ...grpc.CallOption) (*ListMemoriesResponse, error)
+	SearchMemories(ctx context.Context, in *SearchMemoriesRequest, opts ...grpc.CallOption) (*ListMemoriesResponse, error)
+	// AddMemory stores a memory, the sensitive values masked, and returns it
+	// as stored
+	AddMemory(ctx context.Context, in *AddMemoryRequest, opts ...grpc.CallOption) (*Memory, error)
+	// UpdateMemory adds the new text then deletes the former memory, the
+	// memory server having no update: the ID changes
+	UpdateMemory(ctx context.Context, in *UpdateMemoryRequest, opts ...grpc.CallOption) (*Memory, error)
+	DeleteMemory(ctx context.Context, in *DeleteMemoryRequest, opts ...grpc.CallOption) (*DeleteMemoryResponse, error)
+	// SubscribeNotifications streams the notification status of Tom's
+	// modules at once, then at each change, until the client cancels
+	SubscribeNotifications(ctx context.Context, in *SubscribeNotificationsRequest, opts ...grpc.CallOption) (TomDaemon_SubscribeNotificationsClient, error)
+}
+
+type tomDaemonClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTomDaemonClient(cc grpc.ClientConnInterface) TomDaemonClient {
+	return &tomDaemonClient{cc}
+}
+
+func (c *tomDaemonClient) Ask(ctx context.Context, in *AskRequest, opts ...grpc.CallOption) (TomDaemon_AskClient, error) {
+	stream, err := c.cc.NewStream(ctx, &TomDaemon_ServiceDesc.Streams[0], TomDaemon_Ask_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &tomDaemonAskClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type TomDaemon_AskClient interface {
+	Recv() (*AskResponse, error)
+	grpc.ClientStream
+}
+
+type tomDaemonAskClient struct {
+	grpc.ClientStream
+}
+
+func (x *tomDaemonAskClient) Recv() (*AskResponse, error) {
+	m := new(AskResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *tomDaemonClient) GetMemory(ctx context.Context, in *GetMemoryRequest, opts ...grpc.CallOption) (*Memory, error) {
+	out := new(Memory)
+	err := c.cc.Invoke(ctx, TomDaemon_GetMemory_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tomDaemonClient) ListMemories(ctx context.Context, in *ListMemoriesRequest, opts ...grpc.CallOption) (*ListMemoriesResponse, error) {
+	out := new(ListMemoriesResponse)
+	err := c.cc.Invoke(ctx, TomDaemon_ListMemories_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tomDaemonClient) SearchMemories(ctx context.Context, in *SearchMemoriesRequest, opts ...grpc.CallOption) (*ListMemoriesResponse, error) {
+	out := new(ListMemoriesResponse)
+	err := c.cc.Invoke(ctx, TomDaemon_SearchMemories_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tomDaemonClient) AddMemory(ctx context.Context, in *AddMemoryRequest, opts ...grpc.CallOption) (*Memory, error) {
+	out := new(Memory)
+	err := c.cc.Invoke(ctx, TomDaemon_AddMemory_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tomDaemonClient) UpdateMemory(ctx context.Context, in *UpdateMemoryRequest, opts ...grpc.CallOption) (*Memory, error) {
+	out := new(Memory)
+	err := c.cc.Invoke(ctx, TomDaemon_UpdateMemory_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tomDaemonClient) DeleteMemory(ctx context.Context, in *DeleteMemoryRequest, opts ...grpc.CallOption) (*DeleteMemoryResponse, error) {
+	out := new(DeleteMemoryResponse)
+	err := c.cc.Invoke(ctx, TomDaemon_DeleteMemory_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tomDaemonClient) SubscribeNotifications(ctx context.Context, in *SubscribeNotificationsRequest, opts ...grpc.CallOption) (TomDaemon_SubscribeNotificationsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &TomDaemon_ServiceDesc.Streams[1], TomDaemon_SubscribeNotifications_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &tomDaemonSubscribeNotificationsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type TomDaemon_SubscribeNotificationsClient interface {
+	Recv() (*Notifications, error)
+	grpc.ClientStream
+}
+
+type tomDaemonSubscribeNotificationsClient struct {
+	grpc.ClientStream
+}
+
+func (x *tomDaemonSubscribeNotificationsClient) Recv() (*Notifications, error) {
+	m := new(Notifications)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// TomDaemonServer is the server API for TomDaemon service.
+// All implementations must embed UnimplementedTomDaemonServer
+// for forward compatibility
+type TomDaemonServer interface {
+	// Ask sends a prompt to Tom, in a conversation of its own (client type
+	// daemon), and streams the answer: chunks as it comes, then the whole
+	// answer with done set
+	Ask(*AskRequest, TomDaemon_AskServer) error
+	GetMemory(context.Context, *GetMemoryRequest) (*Memory, error)
+	// ListMemories returns the memories of the daemon cache, without a request
+	// to Tom
+	ListMemories(context.Context, *ListMemoriesRequest) (*ListMemoriesResponse, error)
+	SearchMemories(context.Context, *SearchMemoriesRequest) (*ListMemoriesResponse, error)
+	// AddMemory stores a memory, the sensitive values masked, and returns it
+	// as stored
+	AddMemory(context.Context, *AddMemoryRequest) (*Memory, error)
+	// UpdateMemory adds the new text then deletes the former memory, the
+	// memory server having no update: the ID changes
+	UpdateMemory(context.Context, *UpdateMemoryRequest) (*Memory, error)
+	DeleteMemory(context.Context, *DeleteMemoryRequest) (*DeleteMemoryResponse, error)
+	// SubscribeNotifications streams the notification status of Tom's
+	// modules at once, then at each change, until the client cancels
+	SubscribeNotifications(*SubscribeNotificationsRequest, TomDaemon_SubscribeNotificationsServer) error
+	mustEmbedUnimplementedTomDaemonServer()
+}
+
+// UnimplementedTomDaemonServer must be embedded to have forward compatible implementations.
+type UnimplementedTomDaemonServer struct {
+}
+
+func (UnimplementedTomDaemonServer) Ask(*AskRequest, TomDaemon_AskServer) error {
+	return status.Errorf(codes.Unimplemented, "method Ask not implemented")
+}
+func (UnimplementedTomDaemonServer) GetMemory(context.Context, *GetMemoryRequest) (*Memory, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMemory not implemented")
+}
+func (UnimplementedTomDaemonServer) ListMemories(context.Context, *ListMemoriesRequest) (*ListMemoriesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListMemories not implemented")
+}
+func (UnimplementedTomDaemonServer) SearchMemories(context.Context, *SearchMemoriesRequest) (*ListMemoriesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchMemories not implemented")
+}
+func (UnimplementedTomDaemonServer) AddMemory(context.Context, *AddMemoryRequest) (*Memory, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddMemory not implemented")
+}
+func (UnimplementedTomDaemonServer) UpdateMemory(context.Context, *UpdateMemoryRequest) (*Memory, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateMemory not implemented")
+}
+func (UnimplementedTomDaemonServer) DeleteMemory(context.Context, *DeleteMemoryRequest) (*DeleteMemoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteMemory not implemented")
+}
+func (UnimplementedTomDaemonServer) SubscribeNotifications(*SubscribeNotificationsRequest, TomDaemon_SubscribeNotificationsServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeNotifications not implemented")
+}
+func (UnimplementedTomDaemonServer) mustEmbedUnimplementedTomDaemonServer() {}
+
+// UnsafeTomDaemonServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TomDaemonServer will
+// result in compilation errors.
+type UnsafeTomDaemonServer interface {
+	mustEmbedUnimplementedTomDaemonServer()
+}
+
+func RegisterTomDaemonServer(s grpc.ServiceRegistrar, srv TomDaemonServer) {
+	s.RegisterService(&TomDaemon_ServiceDesc, srv)
+}
+
+func _TomDaemon_Ask_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(AskRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TomDaemonServer).Ask(m, &tomDaemonAskServer{stream})
+}
+
+type TomDaemon_AskServer interface {
+	Send(*AskResponse) error
+	grpc.ServerStream
+}
+
+type tomDaemonAskServer struct {
+	grpc.ServerStream
+}
+
+func (x *tomDaemonAskServer) Send(m *AskResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _TomDaemon_GetMemory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMemoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TomDaemonServer).GetMemory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TomDaemon_GetMemory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TomDaemonServer).GetMemory(ctx, req.(*GetMemoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TomDaemon_ListMemories_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListMemoriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TomDaemonServer).ListMemories(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TomDaemon_ListMemories_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TomDaemonServer).ListMemories(ctx, req.(*ListMemoriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TomDaemon_SearchMemories_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchMemoriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TomDaemonServer).SearchMemories(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TomDaemon_SearchMemories_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TomDaemonServer).SearchMemories(ctx, req.(*SearchMemoriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TomDaemon_AddMemory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddMemoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TomDaemonServer).AddMemory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TomDaemon_AddMemory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TomDaemonServer).AddMemory(ctx, req.(*AddMemoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TomDaemon_UpdateMemory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateMemoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TomDaemonServer).UpdateMemory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TomDaemon_UpdateMemory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TomDaemonServer).UpdateMemory(ctx, req.(*UpdateMemoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TomDaemon_DeleteMemory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteMemoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TomDaemonServer).DeleteMemory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TomDaemon_DeleteMemory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TomDaemonServer).DeleteMemory(ctx, req.(*DeleteMemoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TomDaemon_SubscribeNotifications_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeNotificationsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TomDaemonServer).SubscribeNotifications(m, &tomDaemonSubscribeNotificationsServer{stream})
+}
+
+type TomDaemon_SubscribeNotificationsServer interface {
+	Send(*Notifications) error
+	grpc.ServerStream
+}
+
+type tomDaemonSubscribeNotificationsServer struct {
+	grpc.ServerStream
+}
+
+func (x *tomDaemonSubscribeNotificationsServer) Send(m *Notifications) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// TomDaemon_ServiceDesc is the grpc.ServiceDesc for TomDaemon service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var TomDaemon_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "tom.daemon.v1.TomDaemon",
+	HandlerType: (*TomDaemonServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetMemory",
+			Handler:    _TomDaemon_GetMemory_Handler,
+		},
+		{
+			MethodName: "ListMemories",
+			Handler:    _TomDaemon_ListMemories_Handler,
+		},
+		{
+			MethodName: "SearchMemories",
+			Handler:    _TomDaemon_SearchMemories_Handler,
+		},
+		{
+			MethodName: "AddMemory",
+			Handler:    _TomDaemon_AddMemory_Handler,
+		},
+		{
+			MethodName: "UpdateMemory",
+			Handler:    _TomDaemon_UpdateMemory_Handler,
+		},
+		{
+			MethodName: "DeleteMemory",
+			Handler:    _TomDaemon_DeleteMemory_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Ask",
+			Handler:       _TomDaemon_Ask_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "SubscribeNotifications",
+			Handler:       _TomDaemon_SubscribeNotifications_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "daemon.proto",
+}
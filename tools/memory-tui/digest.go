@@ -0,0 +1,199 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/bubbletea"
+)
+
+// Morning digest: /digest asks Tom the questions of the "digest" setting
+// (calendar, weather, tasks and news by default) at once and shows the
+// answers as one briefing, kept in the local store for the day; /digest
+// refresh or r asks again. A question naming a module the server does not
+// enable is skipped.
+type digestSection struct {
+	Title  string `json:"title"`
+	Module string `json:"module,omitempty"`
+	Prompt string `json:"prompt"`
+}
+
+var defaultDigest = []digestSection{
+	{Title: "Calendar", Module: "calendar", Prompt: "What is on my calendar today? A short list, times first."},
+	{Title: "Weather", Module: "weather", Prompt: "What is the weather forecast for today? Two sentences at most."},
+	{Title: "Tasks", Module: "todo", Prompt: "Which of my tasks are due today or overdue? A short list."},
+	{Title: "News", Module: "news", Prompt: "What are today's main news headlines? Five at most, one line each."},
+}
+
+const digestCacheName = "digest"
+
+type digestAnswer struct {
+	Title  string `json:"title"`
+	Answer string `json:"answer,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type digestMsg struct {
+	answers []digestAnswer
+	at      time.Time
+}
+
+func runDigestCommand(m model, args string) (tea.Model, tea.Cmd) {
+	switch args {
+	case "":
+		var answers []digestAnswer
+		if at, err := loadCache(digestCacheName, &answers); err == nil && sameDay(at, time.Now()) {
+			return m.openDigest(digestMsg{answers, at})
+		} else if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			logf("WARN", "digest cache: %v", err)
+		}
+	case "refresh":
+	default:
+		m.message = tr("Unknown /digest argument %q, expected refresh", args)
+		return m, nil
+	}
+	m.loading = true
+	m.setFocus(focusContent)
+	return m, m.fetchDigest()
+}
+
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Local().Date()
+	by, bm, bd := b.Local().Date()
+	return ay == by && am == bm && ad == bd
+}
+
+// fetchDigest asks the questions of the digest concurrently
+func (m model) fetchDigest() tea.Cmd {
+	api, sections := m.api, m.config.Digest
+	return func() tea.Msg {
+		return askDigest(api, sections)
+	}
+}
+
+// askDigest asks the questions of the digest, the default ones when
+// sections is nil, and keeps the answers for the day
+func askDigest(api *MemoryAPI, sections []digestSection) digestMsg {
+	if sections == nil {
+		sections = defaultDigest
+	}
+	var asked []digestSection
+	for _, section := range sections {
+		if api.supports(section.Module) {
+			asked = append(asked, section)
+		}
+	}
+
+	answers := make([]digestAnswer, len(asked))
+	var wg sync.WaitGroup
+	for i, section := range asked {
+		wg.Add(1)
+		go func(i int, section digestSection) {
+			defer wg.Done()
+			answers[i].Title = section.Title
+			answer, err := api.Process(section.Prompt)
+			if err != nil {
+				answers[i].Error = friendlyError(err).Error()
+				return
+			}
+			answers[i].Answer = strings.TrimSpace(answer)
+		}(i, section)
+	}
+	wg.Wait()
+
+	at := time.Now()
+	if err := saveCache(digestCacheName, at, answers); err != nil {
+		logf("WARN", "digest cache: %v", err)
+	}
+	return digestMsg{answers, at}
+}
+
+func (m model) openDigest(msg digestMsg) (tea.Model, tea.Cmd) {
+	m.loading = false
+	m.digest = msg
+	m.layoutDigest()
+	cmd := m.showView(digestView)
+	return m, cmd
+}
+
+// formatDigest writes the briefing as Markdown, for the pins
+func formatDigest(answers []digestAnswer) string {
+	var b strings.Builder
+	for _, answer := range answers {
+		if answer.Error != "" {
+			continue
+		}
+		fmt.Fprintf(&b, "### %s\n\n%s\n\n", tr(answer.Title), answer.Answer)
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// Renders the Markdown of the answers of the briefing
+var digestMarkdown markdownCache
+
+// layoutDigest renders the briefing to the terminal width
+func (m *model) layoutDigest() {
+	width := max(20, m.width-10)
+	var b strings.Builder
+	for i, answer := range m.digest.answers {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(selectedItemStyle.Render(tr(answer.Title)))
+		b.WriteString("\n")
+		if answer.Error != "" {
+			b.WriteString(loginErrorStyle.Render(wrapText("❌ "+answer.Error, width)))
+		} else {
+			b.WriteString(digestMarkdown.render(answer.Answer, width))
+		}
+		b.WriteString("\n")
+	}
+	if len(m.digest.answers) == 0 {
+		b.WriteString(helpStyle.Render(tr("No digest question for the modules of this server, see \"digest\" in ~/.tom/memory-tui.json")))
+	}
+	m.digestViewport = viewport.New(max(20, m.width-8), max(5, m.height-12))
+	m.digestViewport.SetContent(b.String())
+}
+
+func (m model) updateDigestView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		cmd := m.showView(listView)
+		return m, cmd
+	case "r":
+		m.loading = true
+		return m, m.fetchDigest()
+	case "p":
+		return m.pinAnswer("digest", tr("Digest of %s", m.digest.at.Local().Format("2006-01-02")), formatDigest(m.digest.answers))
+	case "g", "home":
+		m.digestViewport.GotoTop()
+		return m, nil
+	case "G", "end":
+		m.digestViewport.GotoBottom()
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.digestViewport, cmd = m.digestViewport.Update(msg)
+	return m, cmd
+}
+
+func (m model) renderDigestView() string {
+	style := contentBoxStyle.Width(m.width - 4)
+	if m.focus == focusContent {
+		style = contentBoxFocusedStyle.Width(m.width - 4)
+	}
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(tr("☀️ Digest")))
+	b.WriteString(" " + helpStyle.Render(tr("as of %s", m.digest.at.Local().Format("15:04"))))
+	b.WriteString("\n\n")
+	b.WriteString(m.digestViewport.View())
+	b.WriteString("\n\n")
+	b.WriteString(m.keyHint(""))
+	return style.Render(b.String())
+}
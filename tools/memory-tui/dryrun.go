@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+
+	"github.com/charmbracelet/bubbletea"
+)
+
+// Dry run: the add and delete calls, single or from a batch (import, bulk
+// delete, chunks), are written to the log instead of being sent, to rehearse
+// a cleanup against a production store. Started with --dry-run, toggled with
+// /dryrun.
+const maxDryRunPayload = 200 // Characters of the payload written to the log
+
+// skipDryRun logs the call when the API is in dry run, and reports whether it
+// must not be sent
+func (api *MemoryAPI) skipDryRun(method, endpoint string, payload interface{}) bool {
+	if !api.DryRun {
+		return false
+	}
+	body := ""
+	if payload != nil {
+		if data, err := json.Marshal(payload); err == nil {
+			body = " " + truncateString(string(data), maxDryRunPayload)
+		}
+	}
+	logf("INFO", "dry run: %s %s%s", method, api.buildURL(endpoint), body)
+	return true
+}
+
+// dryRunNote is appended to the status of the operations not sent
+func (m model) dryRunNote() string {
+	if m.dryRun {
+		return tr(" (dry run, nothing sent, see /logs)")
+	}
+	return ""
+}
+
+func (m *model) setDryRun(on bool) {
+	m.dryRun = on
+	if m.api != nil {
+		m.api.DryRun = on
+	}
+}
+
+func runDryRunCommand(m model, args string) (tea.Model, tea.Cmd) {
+	switch args {
+	case "on":
+		m.setDryRun(true)
+	case "off":
+		m.setDryRun(false)
+	case "":
+		m.setDryRun(!m.dryRun)
+	default:
+		m.message = tr("Usage: %s", "/dryrun [on|off]")
+		return m, nil
+	}
+	if m.dryRun {
+		logf("INFO", "dry run enabled")
+		m.message = tr("Dry run on: adds and deletes are logged, not sent")
+	} else {
+		logf("INFO", "dry run disabled")
+		m.message = tr("Dry run off: changes are sent to the server")
+	}
+	return m, nil
+}
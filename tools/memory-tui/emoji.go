@@ -0,0 +1,196 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbletea"
+)
+
+// :shortcode: input: typing the closing colon of a known shortcode replaces
+// it with the emoji, and while one is being typed the matching shortcodes are
+// suggested, Tab completing the first. Works in the add view editor and the
+// prompt.
+const maxEmojiSuggestions = 8
+
+var emojiShortcodes = map[string]string{
+	"+1":               "👍",
+	"-1":               "👎",
+	"bell":             "🔔",
+	"book":             "📖",
+	"books":            "📚",
+	"brain":            "🧠",
+	"bug":              "🐛",
+	"bulb":             "💡",
+	"calendar":         "📅",
+	"check":            "✔️",
+	"clap":             "👏",
+	"coffee":           "☕",
+	"cry":              "😢",
+	"eyes":             "👀",
+	"fire":             "🔥",
+	"grin":             "😁",
+	"heart":            "❤️",
+	"house":            "🏠",
+	"hourglass":        "⌛",
+	"joy":              "😂",
+	"key":              "🔑",
+	"laughing":         "😆",
+	"link":             "🔗",
+	"lock":             "🔒",
+	"memo":             "📝",
+	"moneybag":         "💰",
+	"muscle":           "💪",
+	"pushpin":          "📌",
+	"question":         "❓",
+	"rocket":           "🚀",
+	"sad":              "😞",
+	"smile":            "😄",
+	"smiley":           "😃",
+	"sparkles":         "✨",
+	"star":             "⭐",
+	"sunny":            "☀️",
+	"tada":             "🎉",
+	"thinking":         "🤔",
+	"thumbsdown":       "👎",
+	"thumbsup":         "👍",
+	"warning":          "⚠️",
+	"wave":             "👋",
+	"white_check_mark": "✅",
+	"wink":             "😉",
+	"x":                "❌",
+	"zap":              "⚡",
+}
+
+func shortcodeRune(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_' || r == '+' || r == '-'
+}
+
+// shortcodePrefix returns the shortcode being typed at the end of before,
+// without its colon
+func shortcodePrefix(before string) (string, bool) {
+	i := strings.LastIndexByte(before, ':')
+	if i < 0 {
+		return "", false
+	}
+	name := before[i+1:]
+	for _, r := range name {
+		if !shortcodeRune(r) {
+			return "", false
+		}
+	}
+	// The colon must start a word, "12:30" is not a shortcode
+	if i > 0 && !strings.ContainsAny(before[i-1:i], " \t\n(") {
+		return "", false
+	}
+	return name, true
+}
+
+// closedShortcode returns the emoji of the ":name:" ending before, and the
+// number of runes it replaces
+func closedShortcode(before string) (string, int, bool) {
+	rest, ok := strings.CutSuffix(before, ":")
+	if !ok {
+		return "", 0, false
+	}
+	name, ok := shortcodePrefix(rest)
+	if !ok {
+		return "", 0, false
+	}
+	emoji, ok := emojiShortcodes[name]
+	return emoji, len(name) + 2, ok
+}
+
+func emojiMatches(prefix string) []string {
+	var names []string
+	for name := range emojiShortcodes {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// textareaBeforeCursor returns the current line of the editor up to the cursor
+func (m model) textareaBeforeCursor() string {
+	lines := strings.Split(m.textArea.Value(), "\n")
+	if m.textArea.Line() >= len(lines) {
+		return ""
+	}
+	line := []rune(lines[m.textArea.Line()])
+	info := m.textArea.LineInfo()
+	col := min(len(line), info.StartColumn+info.ColumnOffset)
+	return string(line[:col])
+}
+
+func (m model) promptBeforeCursor() string {
+	value := []rune(m.promptInput.Value())
+	return string(value[:min(len(value), m.promptInput.Position())])
+}
+
+// replaceBeforeCursor replaces the n runes before the cursor of the focused
+// input with s
+func (m *model) replaceBeforeCursor(n int, s string) {
+	if m.focus == focusPrompt {
+		value := []rune(m.promptInput.Value())
+		pos := min(len(value), m.promptInput.Position())
+		updated := string(value[:pos-n]) + s + string(value[pos:])
+		m.promptInput.SetValue(updated)
+		m.promptInput.SetCursor(pos - n + len([]rune(s)))
+		return
+	}
+	for i := 0; i < n; i++ {
+		m.textArea, _ = m.textArea.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	}
+	m.textArea.InsertString(s)
+}
+
+func (m model) beforeCursor() string {
+	if m.focus == focusPrompt {
+		return m.promptBeforeCursor()
+	}
+	return m.textareaBeforeCursor()
+}
+
+// expandShortcode replaces a shortcode just closed by the user
+func (m *model) expandShortcode() {
+	if emoji, n, ok := closedShortcode(m.beforeCursor()); ok {
+		m.replaceBeforeCursor(n, emoji)
+	}
+}
+
+// completeShortcode completes the shortcode being typed with the first match
+func (m *model) completeShortcode() bool {
+	prefix, ok := shortcodePrefix(m.beforeCursor())
+	if !ok || prefix == "" {
+		return false
+	}
+	matches := emojiMatches(prefix)
+	if len(matches) == 0 {
+		return false
+	}
+	m.replaceBeforeCursor(len([]rune(prefix))+1, emojiShortcodes[matches[0]])
+	return true
+}
+
+// renderEmojiSuggestions lists the shortcodes matching the one being typed
+func (m model) renderEmojiSuggestions() string {
+	prefix, ok := shortcodePrefix(m.beforeCursor())
+	if !ok || prefix == "" {
+		return ""
+	}
+	matches := emojiMatches(prefix)
+	if len(matches) == 0 {
+		return ""
+	}
+	var parts []string
+	for i, name := range matches {
+		if i == maxEmojiSuggestions {
+			parts = append(parts, "…")
+			break
+		}
+		parts = append(parts, emojiShortcodes[name]+" :"+name+":")
+	}
+	return helpStyle.Render("Tab: " + strings.Join(parts, "  "))
+}
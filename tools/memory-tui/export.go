@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbletea"
+)
+
+// Export of the marked memories, or of the visible (filtered) ones when
+// nothing is marked, in the order shown by the list
+type exportDoneMsg struct {
+	count  int
+	target string
+}
+
+// selectedMemories returns the marked memories, or the visible ones when
+// nothing is marked
+func (m model) selectedMemories() []Memory {
+	var marked, visible []Memory
+	for _, item := range m.list.VisibleItems() {
+		mi, ok := item.(memoryItem)
+		if !ok {
+			continue
+		}
+		visible = append(visible, mi.memory)
+		if m.marked[mi.memory.ID] {
+			marked = append(marked, mi.memory)
+		}
+	}
+	if len(m.marked) > 0 {
+		return marked
+	}
+	return visible
+}
+
+// toggleMark marks or unmarks the memory under the cursor
+func (m *model) toggleMark() {
+	item, ok := m.list.SelectedItem().(memoryItem)
+	if !ok {
+		return
+	}
+	if m.marked == nil {
+		m.marked = map[string]bool{}
+	}
+	if m.marked[item.memory.ID] {
+		delete(m.marked, item.memory.ID)
+	} else {
+		m.marked[item.memory.ID] = true
+	}
+	item.marked = m.marked[item.memory.ID]
+	m.list.SetItem(m.list.Index(), item)
+}
+
+// clearMarks unmarks every memory
+func (m *model) clearMarks() {
+	m.marked = nil
+	for i, item := range m.list.Items() {
+		if mi, ok := item.(memoryItem); ok && mi.marked {
+			mi.marked = false
+			m.list.SetItem(i, mi)
+		}
+	}
+}
+
+// memoryItems builds list items, keeping the marks of memories still present
+func (m model) memoryItems(memories []Memory) []list.Item {
+	items := make([]list.Item, len(memories))
+	for i, mem := range memories {
+		items[i] = memoryItem{memory: mem, marked: m.marked[mem.ID]}
+	}
+	return items
+}
+
+func formatMemoriesMarkdown(memories []Memory) string {
+	var b strings.Builder
+	b.WriteString("# Tom memories\n\n")
+	for _, mem := range memories {
+		title := strings.SplitN(mem.Memory, "\n", 2)[0]
+		b.WriteString("## " + truncateString(title, 60) + "\n\n")
+		b.WriteString(mem.Memory + "\n\n")
+		b.WriteString("- ID: " + mem.ID + "\n")
+		b.WriteString("- Created: " + formatTime(mem.CreatedAt) + "\n")
+		if mem.UpdatedAt != nil {
+			b.WriteString("- Updated: " + formatTime(*mem.UpdatedAt) + "\n")
+		}
+		keys := make([]string, 0, len(mem.Metadata))
+		for k := range mem.Metadata {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			b.WriteString(fmt.Sprintf("- %s: %v\n", k, mem.Metadata[k]))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func formatMemories(memories []Memory, format string) (string, error) {
+	switch format {
+	case "md", "markdown":
+		return formatMemoriesMarkdown(memories), nil
+	case "json":
+		data, err := json.MarshalIndent(memories, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(data) + "\n", nil
+	}
+	return "", fmt.Errorf("unknown export format %q (use md or json)", format)
+}
+
+// exportMemories writes memories to path, or to the clipboard when path is empty
+func exportMemories(memories []Memory, format, path string) tea.Cmd {
+	return func() tea.Msg {
+		content, err := formatMemories(memories, format)
+		if err != nil {
+			return errMsg{err}
+		}
+
+		if path == "" {
+			if err := clipboard.WriteAll(content); err != nil {
+				return errMsg{fmt.Errorf("failed to copy to clipboard: %w", err)}
+			}
+			return exportDoneMsg{len(memories), "clipboard"}
+		}
+
+		if dir := filepath.Dir(path); dir != "" {
+			if err := os.MkdirAll(dir, 0700); err != nil {
+				return errMsg{err}
+			}
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			return errMsg{err}
+		}
+		return exportDoneMsg{len(memories), path}
+	}
+}
+
+// expandHome replaces a leading ~/ with the home directory
+func expandHome(path string) string {
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[2:])
+		}
+	}
+	return path
+}
+
+// parseExportArgs reads "[md|json] [PATH]"; the format defaults to the file
+// extension, then to Markdown
+func parseExportArgs(args string) (format, path string) {
+	fields := strings.Fields(args)
+	if len(fields) > 0 && (fields[0] == "md" || fields[0] == "markdown" || fields[0] == "json") {
+		format, fields = fields[0], fields[1:]
+	}
+	path = expandHome(strings.Join(fields, " "))
+	if format == "" {
+		format = "md"
+		if strings.EqualFold(filepath.Ext(path), ".json") {
+			format = "json"
+		}
+	}
+	return format, path
+}
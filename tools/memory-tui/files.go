@@ -0,0 +1,208 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/bubbletea"
+)
+
+// Local files: /cd DIR changes the directory of the session, where /run
+// runs the blocks and /apply finds the files, /ls [PATH] lists a directory
+// and /cat FILE shows a file of the machine the interface runs on. In their
+// view c includes the listing or the file in the next prompt to Tom, /ask or
+// the output of /run, so he can reason about them.
+const (
+	catMaxSize         = 256 << 10 // Bytes /cat reads at most
+	fileContextLimit   = 20000     // Characters of the included files sent with a prompt
+	fileContextHeading = "Files from my machine, which you may use as well:"
+)
+
+type fileContext struct {
+	title   string // "$ ls DIR" or "$ cat FILE"
+	content string
+}
+
+func runCdCommand(m model, args string) (tea.Model, tea.Cmd) {
+	dir := expandHome(args)
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			m.err = err
+			return m, nil
+		}
+		dir = home
+	}
+	if err := os.Chdir(dir); err != nil {
+		m.err = err
+		return m, nil
+	}
+	cwd, _ := os.Getwd()
+	logf("INFO", "working directory: %s", cwd)
+	m.project = loadProjectContext(cwd)
+	m.message = tr("Now in %s", cwd)
+	if m.project.content != "" {
+		m.message = tr("Now in %s, with the project context of %s", cwd, strings.TrimPrefix(m.project.title, "$ cat "))
+	}
+	return m, nil
+}
+
+func runLsCommand(m model, args string) (tea.Model, tea.Cmd) {
+	dir := expandHome(args)
+	if dir == "" {
+		dir = "."
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		m.err = err
+		return m, nil
+	}
+	return m.openFile(fileContext{title: "$ ls " + dir, content: formatListing(entries)})
+}
+
+// formatListing lists the directories first, then the files with their size
+func formatListing(entries []os.DirEntry) string {
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].IsDir() && !entries[j].IsDir() })
+	var b strings.Builder
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		name := entry.Name()
+		size := formatBytes(info.Size())
+		if entry.IsDir() {
+			name, size = name+"/", ""
+		}
+		fmt.Fprintf(&b, "%-10s %s  %s\n", size, info.ModTime().Format("2006-01-02 15:04"), name)
+	}
+	if b.Len() == 0 {
+		return tr("Empty directory")
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func runCatCommand(m model, args string) (tea.Model, tea.Cmd) {
+	if args == "" {
+		m.message = tr("Usage: %s", "/cat FILE")
+		return m, nil
+	}
+	path := expandHome(args)
+	info, err := os.Stat(path)
+	if err != nil {
+		m.err = err
+		return m, nil
+	}
+	if info.IsDir() {
+		return runLsCommand(m, args)
+	}
+	if info.Size() > catMaxSize {
+		m.err = fmt.Errorf("%s is %s, /cat reads %s at most", path, formatBytes(info.Size()), formatBytes(catMaxSize))
+		return m, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		m.err = err
+		return m, nil
+	}
+	if bytes.IndexByte(data, 0) >= 0 {
+		m.err = fmt.Errorf("%s is a binary file", path)
+		return m, nil
+	}
+	return m.openFile(fileContext{title: "$ cat " + path, content: strings.TrimRight(string(data), "\n")})
+}
+
+func (m model) openFile(f fileContext) (tea.Model, tea.Cmd) {
+	m.file = f
+	m.layoutFile()
+	cmd := m.showView(filesView)
+	return m, cmd
+}
+
+// layoutFile shows the listing or the file in the viewport, line by line as
+// /run shows its output
+func (m *model) layoutFile() {
+	m.fileViewport = viewport.New(max(20, m.width-8), max(5, m.height-12))
+	m.fileViewport.SetContent(hardWrapLines(m.file.content, max(20, m.width-10)))
+}
+
+// withFileContext adds the included files to a prompt
+func withFileContext(prompt string, files []fileContext) string {
+	if len(files) == 0 {
+		return prompt
+	}
+	var b strings.Builder
+	b.WriteString(prompt)
+	b.WriteString("\n\n" + fileContextHeading + "\n")
+	for _, f := range files {
+		fmt.Fprintf(&b, "\n%s\n```\n%s\n```\n", f.title, f.content)
+	}
+	return b.String()
+}
+
+// fileContextSize is the characters the included files take
+func fileContextSize(files []fileContext) int {
+	n := 0
+	for _, f := range files {
+		n += len(f.content)
+	}
+	return n
+}
+
+func (m model) updateFilesView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		cmd := m.showView(listView)
+		return m, cmd
+	case "c":
+		if fileContextSize(m.fileContext)+len(m.file.content) > fileContextLimit {
+			m.message = tr("Too much to include, %d characters at most with a prompt", fileContextLimit)
+			return m, nil
+		}
+		m.fileContext = append(m.fileContext, m.file)
+		m.message = tr("Included in the next prompt to Tom, %d in all", len(m.fileContext))
+		return m, nil
+	case "y":
+		if err := clipboard.WriteAll(m.file.content); err != nil {
+			m.err = fmt.Errorf("failed to copy to clipboard: %w", err)
+			return m, nil
+		}
+		m.message = tr("Copied to the clipboard")
+		return m, nil
+	case "g", "home":
+		m.fileViewport.GotoTop()
+		return m, nil
+	case "G", "end":
+		m.fileViewport.GotoBottom()
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.fileViewport, cmd = m.fileViewport.Update(msg)
+	return m, cmd
+}
+
+func (m model) renderFilesView() string {
+	style := contentBoxStyle.Width(m.width - 4)
+	if m.focus == focusContent {
+		style = contentBoxFocusedStyle.Width(m.width - 4)
+	}
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("📂 " + truncateString(m.file.title, max(20, m.width-20))))
+	if cwd, err := os.Getwd(); err == nil {
+		b.WriteString(" " + helpStyle.Render(cwd))
+	}
+	if len(m.fileContext) > 0 {
+		b.WriteString(" " + helpStyle.Render(tr("%d included in the next prompt", len(m.fileContext))))
+	}
+	b.WriteString("\n\n")
+	b.WriteString(m.fileViewport.View())
+	b.WriteString("\n\n")
+	b.WriteString(m.keyHint(""))
+	return style.Render(b.String())
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/charmbracelet/bubbletea"
+)
+
+// Git helpers: /commitmsg has Tom write a commit message for the staged
+// changes (git diff --staged) of the working directory, /explaindiff [REV]
+// has him explain its changes, those not committed by default. Their answer
+// shows as the ones of /ask. The commitmsg and explaindiff commands do the
+// same without the interface: explaindiff reads a diff piped to it, and
+// commitmsg --apply commits the staged changes with the message, in the
+// editor of git first unless --edit=false is given.
+const (
+	gitDiffLimit      = 30000 // Characters of the diff sent to Tom
+	commitMsgPrompt   = "Write the commit message of these staged changes: a summary line of 72 characters at most, in the imperative mood, then a blank line and a short body saying what changed and why, when the summary is not enough. Reply with the message alone, without a code block or quotes."
+	explainDiffPrompt = "Explain these changes: what they do, file by file when there are several, and what looks wrong or risky in them."
+)
+
+// gitDiff runs git diff with args in the working directory
+func gitDiff(args ...string) (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", append([]string{"diff"}, args...)...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git diff: %s", msg)
+		}
+		return "", fmt.Errorf("git diff: %w", err)
+	}
+	return string(out), nil
+}
+
+// diffPrompt follows the instructions with the diff, cut at gitDiffLimit
+func diffPrompt(instructions, diff string) string {
+	if len(diff) > gitDiffLimit {
+		diff = diff[:gitDiffLimit] + fmt.Sprintf("\n... (cut at %d characters)", gitDiffLimit)
+	}
+	return fmt.Sprintf("%s\n\n```diff\n%s\n```", instructions, strings.TrimRight(diff, "\n"))
+}
+
+func runCommitMsgCommand(m model, args string) (tea.Model, tea.Cmd) {
+	diff, err := gitDiff("--staged")
+	if err != nil {
+		m.err = err
+		return m, nil
+	}
+	if strings.TrimSpace(diff) == "" {
+		m.message = tr("Nothing staged, git add the changes first")
+		return m, nil
+	}
+	return m.askAboutDiff("commitmsg", "/commitmsg", diffPrompt(commitMsgPrompt, diff))
+}
+
+func runExplainDiffCommand(m model, args string) (tea.Model, tea.Cmd) {
+	rev := strings.Fields(args)
+	if len(rev) == 0 {
+		rev = []string{"HEAD"}
+	}
+	diff, err := gitDiff(rev...)
+	if err != nil {
+		m.err = err
+		return m, nil
+	}
+	if strings.TrimSpace(diff) == "" {
+		m.message = tr("No changes to explain")
+		return m, nil
+	}
+	return m.askAboutDiff("explaindiff", strings.TrimSpace("/explaindiff "+args), diffPrompt(explainDiffPrompt, diff))
+}
+
+// askAboutDiff sends the prompt with the project context and the included
+// files, the answer showing as the ones of /ask
+func (m model) askAboutDiff(source, question, prompt string) (tea.Model, tea.Cmd) {
+	m.loading = true
+	m.setFocus(focusContent)
+	api, prompt := m.api, withFileContext(prompt, m.promptFiles())
+	m.fileContext = nil
+	return m, func() tea.Msg {
+		answer, err := api.Process(prompt)
+		if err != nil {
+			return errMsg{err}
+		}
+		answer = strings.TrimSpace(answer)
+		runHook(api.Hooks, hookResponse, map[string]interface{}{"source": source, "prompt": question, "answer": answer})
+		return askAnswerMsg{question: question, answer: answer}
+	}
+}
+
+// askGit sends a git helper prompt from the command line, with the project
+// context of the working directory
+func askGit(source, prompt string) (string, error) {
+	sess, err := openSession()
+	if err != nil {
+		return "", err
+	}
+	cfg, _ := loadConfig()
+	sess.API.Hooks = cfg.Hooks
+	cwd, _ := os.Getwd()
+	if project := loadProjectContext(cwd); project.content != "" {
+		prompt = withFileContext(prompt, []fileContext{project})
+	}
+	answer, err := sess.API.Process(prompt)
+	if err != nil {
+		return "", err
+	}
+	answer = strings.TrimSpace(answer)
+	runHook(cfg.Hooks, hookResponse, map[string]interface{}{"source": source, "prompt": source, "answer": answer})
+	return answer, nil
+}
+
+func runCommitMsg(apply, edit bool) error {
+	diff, err := gitDiff("--staged")
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(diff) == "" {
+		return errors.New("nothing staged, git add the changes first")
+	}
+	message, err := askGit("commitmsg", diffPrompt(commitMsgPrompt, diff))
+	if err != nil {
+		return err
+	}
+	if !apply {
+		fmt.Println(message)
+		return nil
+	}
+
+	f, err := os.CreateTemp("", "memory-tui-commitmsg-*.txt")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString(message + "\n"); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	args := []string{"commit", "--file", f.Name()}
+	if edit {
+		args = append(args, "--edit")
+	}
+	cmd := exec.Command("git", args...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	return cmd.Run()
+}
+
+// runExplainDiff explains the diff piped on stdin, else the one of git diff
+// with args, HEAD by default
+func runExplainDiff(args []string) error {
+	var diff string
+	if info, err := os.Stdin.Stat(); err == nil && info.Mode()&os.ModeCharDevice == 0 && len(args) == 0 {
+		data, err := io.ReadAll(io.LimitReader(os.Stdin, gitDiffLimit+1))
+		if err != nil {
+			return err
+		}
+		diff = string(data)
+	} else {
+		if len(args) == 0 {
+			args = []string{"HEAD"}
+		}
+		if diff, err = gitDiff(args...); err != nil {
+			return err
+		}
+	}
+	if strings.TrimSpace(diff) == "" {
+		return errors.New("no changes to explain")
+	}
+	answer, err := askGit("explaindiff", diffPrompt(explainDiffPrompt, diff))
+	if err != nil {
+		return err
+	}
+	fmt.Println(answer)
+	return nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbletea"
+)
+
+// Deep links to a memory: /goto ID in the prompt, and `memory-tui --open
+// tom-memory://ID` for URL handlers, which hands the link to the running
+// interface when there is one
+const memoryURLScheme = "tom-memory://"
+
+type memoryFetchedMsg struct{ memory Memory }
+
+// parseMemoryLink accepts a tom-memory:// URL or a bare memory ID
+func parseMemoryLink(link string) (string, error) {
+	id := strings.TrimSpace(link)
+	if rest, ok := strings.CutPrefix(id, memoryURLScheme); ok {
+		id = strings.Trim(rest, "/")
+	}
+	if id == "" || strings.ContainsAny(id, "/ ") {
+		return "", fmt.Errorf("invalid memory link %q, expected an ID or %sID", link, memoryURLScheme)
+	}
+	return id, nil
+}
+
+func (m model) gotoMemory(id string) tea.Cmd {
+	return func() tea.Msg {
+		memory, err := m.api.GetMemory(id)
+		if err != nil {
+			return errMsg{err}
+		}
+		if memory.ID == "" {
+			return errMsg{fmt.Errorf("memory %s not found", id)}
+		}
+		return memoryFetchedMsg{memory}
+	}
+}
+
+func (m model) handleMemoryFetched(msg memoryFetchedMsg) (tea.Model, tea.Cmd) {
+	m.loading = false
+	return m.openDetail(msg.memory)
+}
+
+func runGotoCommand(m model, args string) (tea.Model, tea.Cmd) {
+	id, err := parseMemoryLink(args)
+	if err != nil {
+		m.err = err
+		return m, nil
+	}
+	m.loading = true
+	m.setFocus(focusContent)
+	return m, m.gotoMemory(id)
+}
+
+// openInRunning hands the link to the running interface, reporting false
+// when none is listening
+func openInRunning(id string) (bool, error) {
+	path, err := controlSocketPath()
+	if err != nil {
+		return false, nil
+	}
+	var resp controlResponse
+	if err := callSocket(path, controlRequest{Command: "/goto " + id}, &resp); err != nil {
+		return false, nil
+	}
+	if resp.Error != "" {
+		return true, errors.New(resp.Error)
+	}
+	return true, nil
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Home automation shortcuts: the "ha_shortcuts" setting names commands for
+// Tom's home automation modules, such as "movie" for "Dim the living room
+// lights to 20% and close the shutters". /ha NAME sends the command to Tom
+// through /process and shows the answer as the ones of /ask; /ha alone opens
+// a picker of the shortcuts. A shortcut naming a module the server does not
+// enable is refused.
+type haShortcut struct {
+	Name    string `json:"name"`
+	Module  string `json:"module,omitempty"`
+	Command string `json:"command"`
+}
+
+// Shortcuts of the configuration, for the completion too
+var haShortcuts []haShortcut
+
+func applyHAConfig(cfg config) {
+	haShortcuts = nil
+	for _, s := range cfg.HAShortcuts {
+		if s.Name == "" || strings.TrimSpace(s.Command) == "" {
+			logf("WARN", "ha_shortcuts: %q has no name or no command, ignored", s.Name)
+			continue
+		}
+		haShortcuts = append(haShortcuts, s)
+	}
+}
+
+func haShortcutNames() []string {
+	names := make([]string, len(haShortcuts))
+	for i, s := range haShortcuts {
+		names[i] = s.Name
+	}
+	return names
+}
+
+func findHAShortcut(name string) (haShortcut, bool) {
+	for _, s := range haShortcuts {
+		if strings.EqualFold(s.Name, name) {
+			return s, true
+		}
+	}
+	return haShortcut{}, false
+}
+
+func runHACommand(m model, args string) (tea.Model, tea.Cmd) {
+	if args == "" {
+		m.haCursor = 0
+		cmd := m.showView(haView)
+		return m, cmd
+	}
+	s, ok := findHAShortcut(args)
+	if !ok {
+		m.message = tr("Unknown shortcut %q, /ha lists them", args)
+		return m, nil
+	}
+	return m.runHAShortcut(s)
+}
+
+// runHAShortcut sends the command of s to Tom
+func (m model) runHAShortcut(s haShortcut) (tea.Model, tea.Cmd) {
+	if !m.api.supports(s.Module) {
+		m.message = tr("The %s module is not enabled on this server", s.Module)
+		return m, nil
+	}
+	m.loading = true
+	m.setFocus(focusContent)
+	api := m.api
+	return m, func() tea.Msg {
+		answer, err := api.Process(s.Command)
+		if err != nil {
+			return errMsg{err}
+		}
+		answer = strings.TrimSpace(answer)
+		logf("INFO", "ha: shortcut %s sent", s.Name)
+		runHook(api.Hooks, hookResponse, map[string]interface{}{"source": "ha", "prompt": s.Command, "answer": answer})
+		return askAnswerMsg{question: fmt.Sprintf("%s: %s", s.Name, s.Command), answer: answer}
+	}
+}
+
+func (m model) updateHAView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		cmd := m.showView(listView)
+		return m, cmd
+	case "up", "k":
+		if m.haCursor > 0 {
+			m.haCursor--
+		}
+	case "down", "j":
+		if m.haCursor < len(haShortcuts)-1 {
+			m.haCursor++
+		}
+	case "enter":
+		if len(haShortcuts) == 0 {
+			return m, nil
+		}
+		s := haShortcuts[m.haCursor]
+		m.showView(listView)
+		return m.runHAShortcut(s)
+	}
+	return m, nil
+}
+
+func (m model) renderHAModal() string {
+	modalWidth := min(70, m.width-10)
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(tr("🏠 Shortcuts")))
+	b.WriteString("\n\n")
+	if len(haShortcuts) == 0 {
+		b.WriteString(helpStyle.Render(wrapText(tr("No shortcut, the \"ha_shortcuts\" setting lists them"), modalWidth-6)))
+		b.WriteString("\n")
+	}
+	nameWidth := 0
+	for _, s := range haShortcuts {
+		nameWidth = max(nameWidth, len(s.Name))
+	}
+	for i, s := range haShortcuts {
+		line := fmt.Sprintf("%-*s  %s", nameWidth, s.Name, truncateString(s.Command, max(10, modalWidth-nameWidth-10)))
+		if i == m.haCursor {
+			b.WriteString(selectedItemStyle.Render("▸ " + line))
+		} else {
+			b.WriteString("  " + line)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	b.WriteString(m.keyHint(""))
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, modalStyle.Width(modalWidth).Render(b.String()))
+}
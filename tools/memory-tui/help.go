@@ -0,0 +1,200 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Key help: each view lists its bindings in the views table. The hint line
+// at the bottom of a view keeps what fits the width, and ? (F1 in the views
+// taking text) opens a full-screen overlay with every binding of the view,
+// the global keys and the prompt commands.
+func binding(help, desc string, keys ...string) key.Binding {
+	return key.NewBinding(key.WithKeys(keys...), key.WithHelp(help, desc))
+}
+
+var (
+	globalKeys = []key.Binding{
+		binding("Tab", "switch focus between the view and the prompt", "tab"),
+		binding("?/F1", "this help", "?", "f1"),
+		binding("Ctrl+C", "quit", "ctrl+c"),
+	}
+	promptKeys = []key.Binding{
+		binding("Tab", "complete the command, its argument or an emoji", "tab"),
+		binding("Enter", "run the command", "enter"),
+		binding("Esc", "back to the view", "esc"),
+	}
+	scrollKeys = []key.Binding{
+		binding("↑/↓/PgUp/PgDn", "scroll", "up", "down", "pgup", "pgdown"),
+		binding("g/G", "top/bottom", "g", "G", "home", "end"),
+	}
+	spellcheckKeys = []key.Binding{
+		binding("Ctrl+K", "spellcheck", "ctrl+k"),
+		binding("Ctrl+R", "use the first suggestion", "ctrl+r"),
+		binding("Ctrl+N", "next issue", "ctrl+n"),
+	}
+)
+
+var helpHeadingStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#25A065")).Bold(true)
+
+// viewKeys returns the bindings of the current view
+func (m model) viewKeys() []key.Binding {
+	keys := viewComponents[m.state].keys
+	if m.state == addView && m.config.Spellcheck {
+		keys = append(append([]key.Binding{}, keys...), spellcheckKeys...)
+	}
+	return m.writableKeys(keys)
+}
+
+// helpKey is the key opening the help in the current view, ? being text in
+// the views with a text field
+func (m model) helpKey() string {
+	if viewComponents[m.state].focus != nil {
+		return "F1"
+	}
+	return "?"
+}
+
+// opensHelp reports whether a key opens the help overlay
+func (m model) opensHelp(msg tea.KeyMsg) bool {
+	if m.focus != focusContent || m.state == helpView || m.state == batchView {
+		return false
+	}
+	switch msg.String() {
+	case "f1":
+		return true
+	case "?":
+		return m.helpKey() == "?" && m.list.FilterState() != list.Filtering
+	}
+	return false
+}
+
+// keyHint is the one-line help of a view: the bindings that fit the width,
+// then the key opening the full help
+func (m model) keyHint(prefix string) string {
+	width := max(20, m.width-8)
+	var end []string
+	if !viewComponents[m.state].modal {
+		end = append(end, tr("Tab")+": "+tr("switch focus"))
+	}
+	if m.state != helpView && m.state != batchView {
+		end = append(end, m.helpKey()+": "+tr("help"))
+	}
+
+	parts := []string{}
+	if prefix != "" {
+		parts = append(parts, prefix)
+	}
+	used := lipgloss.Width(prefix) + lipgloss.Width(strings.Join(end, " | ")) + 3
+	for _, b := range m.viewKeys() {
+		part := tr(b.Help().Key) + ": " + tr(b.Help().Desc)
+		if used+lipgloss.Width(part)+3 > width {
+			break
+		}
+		parts = append(parts, part)
+		used += lipgloss.Width(part) + 3
+	}
+	return helpStyle.Render(strings.Join(append(parts, end...), " | "))
+}
+
+func writeHelpSection(b *strings.Builder, title string, bindings []key.Binding) {
+	if len(bindings) == 0 {
+		return
+	}
+	width := 0
+	for _, k := range bindings {
+		width = max(width, lipgloss.Width(tr(k.Help().Key)))
+	}
+	b.WriteString(helpHeadingStyle.Render(tr(title)) + "\n")
+	for _, k := range bindings {
+		pad := strings.Repeat(" ", width-lipgloss.Width(tr(k.Help().Key)))
+		b.WriteString(fmt.Sprintf("  %s%s  %s\n", selectedItemStyle.Render(tr(k.Help().Key)), pad, tr(k.Help().Desc)))
+	}
+	b.WriteString("\n")
+}
+
+// openHelp shows the overlay for the current view, or for the list when
+// asked from the prompt
+func (m model) openHelp() (tea.Model, tea.Cmd) {
+	var b strings.Builder
+	name := viewComponents[m.state].name
+	writeHelpSection(&b, name, m.viewKeys())
+	if m.state == listView {
+		// The list keymap, less the bindings the view or the overlay take over
+		taken := map[string]bool{}
+		for _, k := range append(m.viewKeys(), globalKeys...) {
+			for _, name := range k.Keys() {
+				taken[name] = true
+			}
+		}
+		var nav []key.Binding
+		for _, group := range m.list.FullHelp() {
+			for _, k := range group {
+				free := false
+				for _, name := range k.Keys() {
+					free = free || !taken[name]
+				}
+				if k.Enabled() && free {
+					nav = append(nav, k)
+				}
+			}
+		}
+		writeHelpSection(&b, "List navigation", nav)
+	}
+	writeHelpSection(&b, "Everywhere", globalKeys)
+	writeHelpSection(&b, "Prompt", promptKeys)
+
+	b.WriteString(helpHeadingStyle.Render(tr("Prompt commands")) + "\n")
+	width := 0
+	for _, c := range promptCommands {
+		width = max(width, lipgloss.Width(c.usage()))
+	}
+	for _, c := range promptCommands {
+		if !c.available(m.api) {
+			continue
+		}
+		line := fmt.Sprintf("  %s%s  %s", selectedItemStyle.Render(c.usage()), strings.Repeat(" ", width-lipgloss.Width(c.usage())), tr(c.Help))
+		if len(c.Aliases) > 0 {
+			line += helpStyle.Render(" (/" + strings.Join(c.Aliases, ", /") + ")")
+		}
+		b.WriteString(line + "\n")
+	}
+
+	m.helpReturn = m.state
+	m.helpTitle = name
+	m.helpViewport = viewport.New(max(20, m.width-8), max(5, m.height-8))
+	m.helpViewport.SetContent(b.String())
+	return m, m.showView(helpView)
+}
+
+func (m model) updateHelpView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q", "?", "f1":
+		return m, m.showView(m.helpReturn)
+	case "g", "home":
+		m.helpViewport.GotoTop()
+		return m, nil
+	case "G", "end":
+		m.helpViewport.GotoBottom()
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.helpViewport, cmd = m.helpViewport.Update(msg)
+	return m, cmd
+}
+
+func (m model) renderHelpView() string {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(tr("❓ Help: %s", tr(m.helpTitle))))
+	b.WriteString("\n\n")
+	b.WriteString(m.helpViewport.View())
+	b.WriteString("\n\n")
+	b.WriteString(m.keyHint(""))
+	return contentBoxFocusedStyle.Width(m.width - 4).Render(b.String())
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// Hook commands run on client events, configured in the "hooks" object of
+// ~/.tom/memory-tui.json by event name. The command is run by sh with the
+// event payload as JSON on stdin and in $TOM_PAYLOAD, and the event name in
+// $TOM_EVENT, e.g. "memory_added": "dunstify 'Tom' \"$(jq -r .text)\""
+const (
+	hookMemoryAdded     = "memory_added"     // {"text", "metadata"}
+	hookMemoryDeleted   = "memory_deleted"   // {"id"}
+	hookSynced          = "sync"             // {"count", "synced_at"}, run by the daemon
+	hookScheduledAnswer = "scheduled_answer" // {"id", "spec", "prompt", "answer"}, see schedule.go
+	hookResponse        = "response"         // {"source", "prompt", "answer"}: /ask, /run output sent back, daemon ask
+	hookNotification    = "notification"     // {"notifications"}, run by the daemon when the /tasks status changes
+	hookTaskDue         = "task_due"         // {"id", "label", "fires_at"}, a /timer timer firing
+)
+
+const hookTimeout = 30 * time.Second
+
+// pendingHooks are the hook commands running, waited for by waitHooks
+var pendingHooks sync.WaitGroup
+
+// runHook starts the command configured for event without waiting for it;
+// hooks must never slow down or break the interface
+func runHook(hooks map[string]string, event string, payload interface{}) {
+	command := hooks[event]
+	if command == "" {
+		return
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return
+	}
+
+	pendingHooks.Add(1)
+	go func() {
+		defer pendingHooks.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), hookTimeout)
+		defer cancel()
+		cmd := exec.CommandContext(ctx, "sh", "-c", command)
+		cmd.Stdin = bytes.NewReader(data)
+		cmd.Env = append(os.Environ(), "TOM_EVENT="+event, "TOM_PAYLOAD="+string(data))
+		if err := cmd.Run(); err != nil {
+			logf("WARN", "%s hook failed: %v", event, err)
+		}
+	}()
+}
+
+// waitHooks waits for the hooks started to end, hookTimeout at most, so that
+// a command exiting right after an event does not kill its hook
+func waitHooks() {
+	done := make(chan struct{})
+	go func() {
+		pendingHooks.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(hookTimeout):
+	}
+}
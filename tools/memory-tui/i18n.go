@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// UI translations, gettext style: the English text in the code is the key of
+// the catalogs, so a missing translation shows the English text. The catalog
+// follows the "language" setting, the one the assistant speaks. Log lines and
+// the command-line help stay in English.
+var catalogs = map[string]map[string]string{
+	"fr": frenchCatalog,
+}
+
+var uiLanguage = "en"
+
+// setLanguage selects the catalog of a language such as "fr", "fr-FR" or
+// "fr_FR.UTF-8", English when there is none
+func setLanguage(language string) {
+	lang := strings.ToLower(language)
+	if i := strings.IndexAny(lang, "-_."); i >= 0 {
+		lang = lang[:i]
+	}
+	if _, ok := catalogs[lang]; !ok {
+		lang = "en"
+	}
+	uiLanguage = lang
+}
+
+// tr translates a UI text, then formats it with args like fmt.Sprintf
+func tr(text string, args ...interface{}) string {
+	if translated, ok := catalogs[uiLanguage][text]; ok {
+		text = translated
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(text, args...)
+	}
+	return text
+}
@@ -0,0 +1,513 @@
+package main
+
+// French translations of the UI texts, see i18n.go
+var frenchCatalog = map[string]string{
+	// Login and passphrase
+	"Connecting to server...":                         "Connexion au serveur...",
+	"Memory Manager Login":                            "Connexion au gestionnaire de mémoires",
+	"(tab to switch, enter to login, ctrl+c to quit)": "(tab pour changer de champ, entrée pour se connecter, ctrl+c pour quitter)",
+	"Username":            "Utilisateur",
+	"Password":            "Mot de passe",
+	"Server URL":          "URL du serveur",
+	"Passphrase":          "Phrase de passe",
+	"Choose a passphrase": "Choisissez une phrase de passe",
+	"It encrypts the credentials saved in ~/.tom/auth": "Elle chiffre les identifiants enregistrés dans ~/.tom/auth",
+	"and will be asked at each startup.":               "et sera demandée à chaque démarrage.",
+	"Unlock saved credentials":                         "Déverrouiller les identifiants enregistrés",
+	"(enter to continue, esc to go back)":              "(entrée pour continuer, échap pour revenir)",
+	"🔒 Locked after %d minutes idle":                   "🔒 Verrouillé après %d minutes d'inactivité",
+	"Password of %s":                                   "Mot de passe de %s",
+	"(enter to unlock, ctrl+c to quit)":                "(entrée pour déverrouiller, ctrl+c pour quitter)",
+	"(enter to unlock, esc to log in again)":           "(entrée pour déverrouiller, échap pour se reconnecter)",
+
+	// Common texts
+	"Loading...":                                 "Chargement...",
+	"❌ Error: %v":                                "❌ Erreur : %v",
+	"Press Tab to focus, then type: ":            "Tab pour activer, puis tapez : ",
+	"Command: ":                                  "Commande : ",
+	"🧠 Tom Memory Manager":                       "🧠 Gestionnaire de mémoires Tom",
+	"📝 Memory Manager":                           "📝 Mémoires",
+	"Memories":                                   "Mémoires",
+	"session expired, please log in again":       "session expirée, veuillez vous reconnecter",
+	"Could not check the server API version: %v": "Impossible de vérifier la version d'API du serveur : %v",
+
+	// List and details
+	"ID: %s | Created: %s":         "ID : %s | Créée : %s",
+	"📖 Memory Details":             "📖 Détail de la mémoire",
+	"Content:":                     "Contenu :",
+	"Created: ":                    "Créée : ",
+	"Updated: ":                    "Modifiée : ",
+	"Never":                        "Jamais",
+	"User: ":                       "Utilisateur : ",
+	"Metadata:":                    "Métadonnées :",
+	"Last used:":                   "Dernière utilisation :",
+	"Unavailable: %v":              "Indisponible : %v",
+	"Never retrieved nor injected": "Jamais retrouvée ni injectée",
+	"Retrieved: %s (%d times)":     "Retrouvée : %s (%d fois)",
+	"Injected:  %s (%d times)":     "Injectée :  %s (%d fois)",
+	"in %s":                        "dans %s",
+	"never":                        "jamais",
+	"retrieved":                    "retrouvée",
+	"injected":                     "injectée",
+
+	// Dates
+	"just now":       "à l'instant",
+	"1 minute ago":   "il y a 1 minute",
+	"%d minutes ago": "il y a %d minutes",
+	"1 hour ago":     "il y a 1 heure",
+	"%d hours ago":   "il y a %d heures",
+	"yesterday %s":   "hier %s",
+	"1 day ago":      "il y a 1 jour",
+	"%d days ago":    "il y a %d jours",
+
+	// Add, search, delete
+	"➕ Add New Memory":                                   "➕ Nouvelle mémoire",
+	"Enter your memory content:":                         "Saisissez le contenu de la mémoire :",
+	"Enter your memory content here...":                  "Saisissez le contenu de la mémoire ici...",
+	"🔍 Search Memories":                                  "🔍 Rechercher dans les mémoires",
+	"Enter search query:":                                "Saisissez la recherche :",
+	"Enter search query...":                              "Saisissez la recherche...",
+	"⚠️ Confirm Delete":                                  "⚠️ Confirmer la suppression",
+	"Are you sure you want to delete these %d memories?": "Supprimer ces %d mémoires ?",
+	"... and %d more":                                    "... et %d de plus",
+	"Are you sure you want to delete this memory?":       "Supprimer cette mémoire ?",
+	"Memory: ": "Mémoire : ",
+	"This server has no trash: this action cannot be undone.":       "Ce serveur n'a pas de corbeille : cette action est définitive.",
+	"The server keeps it in its trash: /restore ID brings it back.": "Le serveur la garde dans sa corbeille : /restore ID la restaure.",
+	"Nothing to undo":               "Rien à annuler",
+	"Nothing to redo":               "Rien à rétablir",
+	"Paste kept as a single memory": "Collage conservé en une seule mémoire",
+	"No suggestion for %q":          "Aucune suggestion pour %q",
+	"Text changed since the spellcheck, press Ctrl+K again":                      "Le texte a changé depuis la vérification, appuyez de nouveau sur Ctrl+K",
+	"✓ No spelling mistakes found":                                               "✓ Aucune faute d'orthographe",
+	"Ctrl+R: use first suggestion | Ctrl+N: next":                                "Ctrl+R : première suggestion | Ctrl+N : suivante",
+	"Spellcheck is disabled, set \"spellcheck\": true in ~/.tom/memory-tui.json": "La vérification orthographique est désactivée, ajoutez \"spellcheck\": true à ~/.tom/memory-tui.json",
+
+	// Status messages
+	"Loaded %d memories":                             "%d mémoires chargées",
+	"Loaded %d memories from the daemon (synced %s)": "%d mémoires chargées depuis le démon (synchronisé à %s)",
+	"Memory added successfully":                      "Mémoire ajoutée",
+	"Memory deleted successfully":                    "Mémoire supprimée",
+	"Memory moved to the server trash":               "Mémoire mise à la corbeille du serveur",
+	"Memory %s restored":                             "Mémoire %s restaurée",
+	"Found %d memories":                              "%d mémoires trouvées",
+	"Exported %d memories to %s":                     "%d mémoires exportées vers %s",
+	"Refreshing...":                                  "Rafraîchissement...",
+	"No memory to export":                            "Aucune mémoire à exporter",
+	"Mark memories with Space first, then /delete":   "Marquez d'abord des mémoires avec Espace, puis /delete",
+	"Selection cleared":                              "Sélection effacée",
+	"Unknown command: %s. Available: %s":             "Commande inconnue : %s. Disponibles : %s",
+	"Usage: %s (%s)":                                 "Utilisation : %s (%s)",
+	"Usage: %s":                                      "Utilisation : %s",
+	"/%s done":                                       "/%s terminé",
+	"/%s is not enabled on this server":              "/%s n'est pas activé sur ce serveur",
+	"Read-only mode: /%s is disabled":                "Mode lecture seule : /%s est désactivé",
+	"Read-only mode: memories cannot be changed":     "Mode lecture seule : les mémoires ne peuvent pas être modifiées",
+	"READ-ONLY":                                      "LECTURE SEULE",
+	"Nothing selected for import":                    "Rien de sélectionné pour l'import",
+	"This server has no trash: deleted memories cannot be restored": "Ce serveur n'a pas de corbeille : les mémoires supprimées ne peuvent pas être restaurées",
+	" (dry run, nothing sent, see /logs)":                           " (simulation, rien n'a été envoyé, voir /logs)",
+	"Dry run on: adds and deletes are logged, not sent":             "Simulation activée : ajouts et suppressions sont journalisés, pas envoyés",
+	"Dry run off: changes are sent to the server":                   "Simulation désactivée : les changements sont envoyés au serveur",
+
+	// Batches
+	"Deleting memories":                    "Suppression des mémoires",
+	"Mapping memories":                     "Cartographie des mémoires",
+	"%s: %d/%d done":                       "%s : %d/%d traités",
+	", %d failed (last error: %v)":         ", %d en échec (dernière erreur : %v)",
+	", cancelled":                          ", annulé",
+	"Cancelling after the current item...": "Annulation après l'élément en cours...",
+
+	// Local search
+	"Server unreachable, found %d memories in the local index": "Serveur injoignable, %d mémoires trouvées dans l'index local",
+	"%d of %d memories match %q, /refresh to list them all":    "%d mémoires sur %d correspondent à %q, /refresh pour toutes les afficher",
+
+	// Quick capture
+	"➕ Quick memory":                  "➕ Mémoire express",
+	"Ctrl+S save • Esc cancel":        "Ctrl+S enregistrer • Esc annuler",
+	"Saving...":                       "Enregistrement...",
+	"Ctrl+S again to store it anyway": "Ctrl+S à nouveau pour l'enregistrer quand même",
+
+	// Merge
+	"Mark two memories or more with Space first, then /merge": "Marquez d'abord au moins deux mémoires avec Espace, puis /merge",
+	"Unknown /merge argument %q, expected summarize":          "Argument de /merge inconnu %q, summarize attendu",
+	"🔗 Merge %d Memories":                                     "🔗 Fusionner %d mémoires",
+	"Edit the merged memory:":                                 "Modifiez la mémoire fusionnée :",
+	"🔗 Confirm Merge":                                         "🔗 Confirmer la fusion",
+	"Store the merged memory and delete these %d memories?":   "Enregistrer la mémoire fusionnée et supprimer ces %d mémoires ?",
+	"Deleting the merged memories":                            "Suppression des mémoires fusionnées",
+	"Confirm merge":                                           "Confirmation de fusion",
+	"merge":                                                   "fusionner",
+	"Merge the marked memories into one, from their texts or a summary by Tom": "Fusionner les mémoires marquées en une seule, à partir de leurs textes ou d'un résumé de Tom",
+
+	// Summaries
+	"No memory to summarize": "Aucune mémoire à résumer",
+	"%d memories are too many to summarize, mark or /filter %d at most": "%d mémoires, c'est trop pour un résumé : marquez ou filtrez-en %d au plus",
+	"Summary of %d memories by Tom: Ctrl+S stores it, Esc drops it":     "Résumé de %d mémoires par Tom : Ctrl+S l'enregistre, Esc l'abandonne",
+	"Have Tom summarize the marked or listed memories into a new one":   "Faire résumer par Tom les mémoires marquées ou listées en une nouvelle",
+
+	// Questions
+	"No memory to ask about": "Aucune mémoire à interroger",
+	"%d memories are too many to ask about, mark or /filter %d at most": "%d mémoires, c'est trop pour une question : marquez ou filtrez-en %d au plus",
+	"💬 Tom's Answer":   "💬 Réponse de Tom",
+	"From %d memories": "D'après %d mémoires",
+	"Answer":           "Réponse",
+	"Ask Tom about the marked or listed memories": "Interroger Tom sur les mémoires marquées ou listées",
+
+	// Digest
+	"Unknown /digest argument %q, expected refresh": "Argument de /digest inconnu %q, refresh attendu",
+	"Calendar": "Agenda",
+	"Weather":  "Météo",
+	"Tasks":    "Tâches",
+	"News":     "Actualités",
+	"No digest question for the modules of this server, see \"digest\" in ~/.tom/memory-tui.json": "Aucune question du briefing pour les modules de ce serveur, voir \"digest\" dans ~/.tom/memory-tui.json",
+	"☀️ Digest": "☀️ Briefing",
+	"as of %s":  "à %s",
+	"Digest":    "Briefing",
+	"Show today's briefing: calendar, weather, tasks and news": "Afficher le briefing du jour : agenda, météo, tâches et actualités",
+
+	// Timers
+	"⏰ %s in %s, at %s": "⏰ %s dans %s, à %s",
+	"⏰ Time's up: %s":   "⏰ Temps écoulé : %s",
+	"Timer %q canceled": "Minuteur %q annulé",
+	"⏰ Timers":          "⏰ Minuteurs",
+	"Timers":            "Minuteurs",
+	"cancel the timer":  "annuler le minuteur",
+	"No timer running, /timer 25m \"review PR\" sets one":                 "Aucun minuteur en cours, /timer 25m \"relire la PR\" en lance un",
+	"Set a timer, such as /timer 25m \"review PR\", ringing when it ends": "Lancer un minuteur, par exemple /timer 25m \"relire la PR\", qui sonne à son terme",
+	"List and cancel the running timers":                                  "Lister et annuler les minuteurs en cours",
+
+	// Home automation shortcuts
+	"🏠 Shortcuts":                                          "🏠 Raccourcis",
+	"Shortcuts":                                            "Raccourcis",
+	"Unknown shortcut %q, /ha lists them":                  "Raccourci %q inconnu, /ha les liste",
+	"The %s module is not enabled on this server":          "Le module %s n'est pas activé sur ce serveur",
+	"No shortcut, the \"ha_shortcuts\" setting lists them": "Aucun raccourci, le réglage \"ha_shortcuts\" les liste",
+	"Send a home automation shortcut of the \"ha_shortcuts\" setting to Tom, or pick one": "Envoyer à Tom un raccourci domotique du réglage \"ha_shortcuts\", ou en choisir un",
+
+	// Mail
+	"Reply drafted by Tom, y copies it": "Brouillon de réponse rédigé par Tom, y le copie",
+	"Email archived":                    "E-mail archivé",
+	"Email marked read":                 "E-mail marqué comme lu",
+	"Draft copied to the clipboard":     "Brouillon copié dans le presse-papiers",
+	"📬 Unread Emails":                   "📬 E-mails non lus",
+	"%d unread":                         "%d non lus",
+	"No unread email":                   "Aucun e-mail non lu",
+	"Draft reply":                       "Brouillon de réponse",
+	"Mail":                              "Courrier",
+	"mark read":                         "marquer comme lu",
+	"archive":                           "archiver",
+	"draft a reply":                     "rédiger une réponse",
+	"copy the draft":                    "copier le brouillon",
+	"Triage the unread emails: mark read, archive, or have Tom draft a reply": "Trier les e-mails non lus : marquer comme lus, archiver ou faire rédiger une réponse par Tom",
+
+	// News
+	"Article marked read":        "Article marqué comme lu",
+	"No unread news":             "Aucune actualité non lue",
+	"s asks Tom to summarize it": "s demande à Tom de le résumer",
+	"📰 News":                     "📰 Actualités",
+	"summarize":                  "résumer",
+	"open in the browser":        "ouvrir dans le navigateur",
+	"Read the unread news, summarized by Tom on demand": "Lire les actualités non lues, résumées par Tom à la demande",
+
+	// Profiles
+	"Contact":                        "Contact",
+	"Birthday":                       "Anniversaire",
+	"%s, in %d days":                 "%s, dans %d jours",
+	"Today":                          "Aujourd'hui",
+	"(turns %d)":                     "(%d ans)",
+	"Facts":                          "Faits",
+	"Related memories":               "Mémoires associées",
+	"Tom knows nothing about %s yet": "Tom ne sait encore rien de %s",
+	"%d memories":                    "%d mémoires",
+	"Profile":                        "Fiche",
+	"Show what Tom knows about a person: contact, birthday, facts and related memories": "Afficher ce que Tom sait d'une personne : contact, anniversaire, faits et mémoires associées",
+
+	// Calculator
+	"Compute locally, such as /calc 15% of 84":         "Calculer localement, par exemple /calc 15% of 84",
+	"Convert units locally, such as /conv 10 km to mi": "Convertir des unités localement, par exemple /conv 10 km to mi",
+
+	// Pins
+	"Digest of %s":                "Briefing du %s",
+	"Reply to %s":                 "Réponse à %s",
+	"📌 Pinned, see /pins":         "📌 Épinglé, voir /pins",
+	"Exported %d pins to %s":      "%d épingles exportées vers %s",
+	"Pin copied to the clipboard": "Épingle copiée dans le presse-papiers",
+	"Pin removed":                 "Épingle retirée",
+	"📌 Pins":                      "📌 Épingles",
+	"Pins":                        "Épingles",
+	"%d holding %q":               "%d contenant %q",
+	"No pin: p pins the answer shown by /ask, /digest, /mail or /news": "Aucune épingle : p épingle la réponse affichée par /ask, /digest, /mail ou /news",
+	"pin":                     "épingler",
+	"pin the draft":           "épingler le brouillon",
+	"copy":                    "copier",
+	"unpin":                   "retirer",
+	"export to the clipboard": "exporter dans le presse-papiers",
+	"List the pinned answers, those holding QUERY, or export them as Markdown": "Lister les réponses épinglées, celles contenant QUERY, ou les exporter en Markdown",
+
+	// Bookmarks
+	"Bookmarks are letters, a to z": "Les signets sont des lettres, de a à z",
+	"Bookmark %s set":               "Signet %s posé",
+	"No bookmark %s":                "Aucun signet %s",
+	"The bookmarked memory is not in the list, deleted or filtered out": "La mémoire du signet n'est pas dans la liste, supprimée ou filtrée",
+	"m: press a letter to bookmark the memory":                          "m : tapez une lettre pour poser un signet sur la mémoire",
+	"': press the letter of a bookmark, or ' to go back":                "' : tapez la lettre d'un signet, ou ' pour revenir",
+	"bookmark/jump": "signet/saut",
+
+	// Scratch
+	"Scratch on: the code blocks of Tom's answers go to %s": "Blocs de code activés : ceux des réponses de Tom sont écrits dans %s",
+	"Scratch off":                        "Blocs de code désactivés",
+	"Path copied to the clipboard":       "Chemin copié dans le presse-papiers",
+	"📝 Scratch":                          "📝 Blocs de code",
+	"Scratch":                            "Blocs de code",
+	"No code block yet in Tom's answers": "Aucun bloc de code dans les réponses de Tom pour l'instant",
+	"Scratch is off, /scratch on saves the code blocks of Tom's answers": "Blocs de code désactivés, /scratch on enregistre ceux des réponses de Tom",
+	"edit":          "modifier",
+	"copy the path": "copier le chemin",
+	"List the code blocks of Tom's answers saved this session, or toggle their saving": "Lister les blocs de code des réponses de Tom de cette session, ou activer leur enregistrement",
+
+	// Run
+	"Output of block %d":                               "Sortie du bloc %d",
+	"▶ Run block %d?":                                  "▶ Exécuter le bloc %d ?",
+	"in %s, killed after %s":                           "dans %s, arrêté après %s",
+	"%d%% of the code, ↑/↓ to scroll":                  "%d%% du code, ↑/↓ pour défiler",
+	"... %d bytes before dropped":                      "... %d octets précédents supprimés",
+	"⚠️ The code runs with your rights, read it first": "⚠️ Le code s'exécute avec vos droits, lisez-le d'abord",
+	"No output":                      "Aucune sortie",
+	"Output copied to the clipboard": "Sortie copiée dans le presse-papiers",
+	"exit %d, %s":                    "code %d, %s",
+	"run":                            "exécuter",
+	"send to Tom":                    "envoyer à Tom",
+	"Confirm run":                    "Confirmer l'exécution",
+	"Run output":                     "Sortie de l'exécution",
+	"Run the code block N of /scratch once confirmed, its output going back to Tom on demand": "Exécuter le bloc de code N de /scratch après confirmation, sa sortie pouvant être renvoyée à Tom",
+
+	// Apply
+	"Diff applied to %s":                                       "Diff appliqué à %s",
+	"Diff applied to %s, former content in %s":                 "Diff appliqué à %s, ancien contenu dans %s",
+	"Diff applied to %d files, former contents in .orig files": "Diff appliqué à %d fichiers, anciens contenus dans les fichiers .orig",
+	"🩹 Apply block %d?":                                        "🩹 Appliquer le bloc %d ?",
+	"%d hunks, backup to %s":                                   "%d sections, sauvegarde dans %s",
+	"new file":                                                 "nouveau fichier",
+	"%d hunks, %s exists: O to replace it":                     "%d sections, %s existe : O pour le remplacer",
+	"apply, replacing the .orig backups":                       "appliquer en remplaçant les sauvegardes .orig",
+	"apply":                                                    "appliquer",
+	"Apply diff":                                               "Application du diff",
+	"Apply the unified diff of the code block N, the last diff by default, once previewed": "Appliquer le diff unifié du bloc de code N, le dernier diff par défaut, après aperçu",
+
+	// Files
+	"Now in %s": "Répertoire courant : %s",
+	"Now in %s, with the project context of %s": "Répertoire courant : %s, avec le contexte de projet de %s",
+	"Empty directory": "Répertoire vide",
+	"Too much to include, %d characters at most with a prompt": "Trop long à joindre, %d caractères au plus avec une demande",
+	"Included in the next prompt to Tom, %d in all":            "Joint à la prochaine demande à Tom, %d en tout",
+	"Copied to the clipboard":                                  "Copié dans le presse-papiers",
+	"%d included in the next prompt":                           "%d joints à la prochaine demande",
+	"include in the next prompt":                               "joindre à la prochaine demande",
+	"Files":                                                    "Fichiers",
+	"Change the local directory of /run, /apply, /ls and /cat, home by default": "Changer le répertoire local de /run, /apply, /ls et /cat, le dossier personnel par défaut",
+	"List a local directory, c including the listing in the next prompt to Tom": "Lister un répertoire local, c joignant la liste à la prochaine demande à Tom",
+	"Show a local file, c including it in the next prompt to Tom":               "Afficher un fichier local, c le joignant à la prochaine demande à Tom",
+
+	// Watch
+	"Nothing watched, /watch GLOB watches files": "Rien n'est surveillé, /watch MOTIF surveille des fichiers",
+	"Watching %s":                   "Surveillés : %s",
+	"Already watching %s":           "%s est déjà surveillé",
+	"Watching %s, %d files for now": "%s surveillé, %d fichiers pour l'instant",
+	"Nothing watched anymore":       "Plus rien n'est surveillé",
+	"%s not watched anymore":        "%s n'est plus surveillé",
+	"%s is not watched":             "%s n'est pas surveillé",
+	"👁 Tom knows %s changed":        "👁 Tom sait que %s a changé",
+	"Have Tom write the commit message of the staged changes of the local directory": "Faire écrire par Tom le message de commit des changements indexés du répertoire local",
+	"Have Tom explain the changes of git diff REV, the uncommitted ones by default":  "Faire expliquer par Tom les changements de git diff RÉV, ceux non commités par défaut",
+	"Nothing staged, git add the changes first":                                      "Rien d'indexé, faites d'abord git add des changements",
+	"No changes to explain": "Aucun changement à expliquer",
+	"Tell Tom the new content of the local files matching GLOB when they change, or list the globs watched": "Donner à Tom le nouveau contenu des fichiers locaux correspondant à MOTIF quand ils changent, ou lister les motifs surveillés",
+	"Stop watching GLOB, or every file": "Arrêter de surveiller MOTIF, ou tous les fichiers",
+
+	// Kiosk
+	"Ask Tom: ":                  "Demander à Tom : ",
+	"Tom is thinking…":           "Tom réfléchit…",
+	"● Connected":                "● Connecté",
+	"○ Offline, retrying: %v":    "○ Hors ligne, nouvelle tentative : %v",
+	"Asking Tom for the digest…": "Tom prépare le briefing…",
+	"Monday":                     "Lundi",
+	"Tuesday":                    "Mardi",
+	"Wednesday":                  "Mercredi",
+	"Thursday":                   "Jeudi",
+	"Friday":                     "Vendredi",
+	"Saturday":                   "Samedi",
+	"Sunday":                     "Dimanche",
+	"January":                    "janvier",
+	"February":                   "février",
+	"March":                      "mars",
+	"April":                      "avril",
+	"May":                        "mai",
+	"June":                       "juin",
+	"July":                       "juillet",
+	"August":                     "août",
+	"September":                  "septembre",
+	"October":                    "octobre",
+	"November":                   "novembre",
+	"December":                   "décembre",
+
+	// Lint
+	"near-empty":                      "presque vide",
+	"too short":                       "trop courte",
+	"too long (%d chars)":             "trop longue (%d caractères)",
+	"too long":                        "trop longue",
+	"raw JSON":                        "JSON brut",
+	"no metadata":                     "sans métadonnées",
+	"Lint: the %d memories look fine": "Contrôle : les %d mémoires semblent correctes",
+	"Lint: %d of %d memories to review (%s), /refresh to list them all": "Contrôle : %d mémoires sur %d à revoir (%s), /refresh pour toutes les afficher",
+
+	// Map
+	"🗺️ Memory map":                                      "🗺️ Carte des mémoires",
+	"The map needs at least 3 memories":                  "La carte demande au moins 3 mémoires",
+	"Fetching the embeddings...":                         "Récupération des embeddings...",
+	"Map cancelled":                                      "Carte annulée",
+	"Placing the memories...":                            "Placement des mémoires...",
+	"Cluster %d: %d memories, /refresh to list them all": "Ensemble %d : %d mémoires, /refresh pour toutes les afficher",
+	"%d memories • similarity from %s • experimental":    "%d mémoires • similarité d'après les %s • expérimental",
+	"%d ● %d memories: %s":                               "%d ● %d mémoires : %s",
+	"embeddings":                                         "embeddings",
+	"searches":                                           "recherches",
+
+	// Other views
+	"🧾 Audit":                    "🧾 Audit",
+	"%s • %d changes":            "%s • %d changements",
+	"No change recorded yet":     "Aucun changement enregistré",
+	"📜 Logs":                     "📜 Journal",
+	"%s • level %s and above":    "%s • niveau %s et plus",
+	"No log entry at this level": "Aucune entrée à ce niveau",
+	"✂️ Split Memory":            "✂️ Découper la mémoire",
+	"📥 Import (%s)":              "📥 Import (%s)",
+	"Source: ":                   "Source : ",
+
+	// Redaction
+	"🕶️ Redaction":                        "🕶️ Masquage",
+	"%d sensitive values will be masked:": "%d valeurs sensibles seront masquées :",
+	"Text sent:":                          "Texte envoyé :",
+	"Redaction":                           "Masquage",
+	"send masked":                         "envoyer masqué",
+	"send as typed":                       "envoyer tel quel",
+
+	// Secrets
+	"🔑 Possible secret":                            "🔑 Secret possible",
+	"Possible secret":                              "Secret possible",
+	"Looks like it contains: %s":                   "Semble contenir : %s",
+	"Memories are kept for good, store it anyway?": "Les mémoires sont conservées durablement, l'enregistrer quand même ?",
+	"Memory not stored":                            "Mémoire non enregistrée",
+	"private key":                                  "clé privée",
+	"password":                                     "mot de passe",
+	"API key":                                      "clé d'API",
+	"token":                                        "jeton",
+	"store it":                                     "l'enregistrer",
+	"skip it":                                      "l'ignorer",
+
+	// Timeouts
+	"the server did not answer within %s (%s requests), try again or raise \"timeouts\" in ~/.tom/memory-tui.json": "le serveur n'a pas répondu en %s (requêtes %s), réessayez ou augmentez \"timeouts\" dans ~/.tom/memory-tui.json",
+	"interactive": "interactives",
+	"polling":     "de synchronisation",
+	"bulk":        "groupées",
+
+	// Resume
+	"No interrupted import to resume":     "Aucun import interrompu à reprendre",
+	"The interrupted import was for %s":   "L'import interrompu concernait %s",
+	"The interrupted import was complete": "L'import interrompu était terminé",
+
+	// View names
+	"Memory list":    "Liste des mémoires",
+	"Memory details": "Détail de la mémoire",
+	"Add a memory":   "Ajout d'une mémoire",
+	"Search":         "Recherche",
+	"Confirm delete": "Confirmation de suppression",
+	"Template":       "Modèle",
+	"Chunk preview":  "Découpage",
+	"Import":         "Import",
+	"Batch":          "Traitement groupé",
+	"Logs":           "Journal",
+	"Audit":          "Audit",
+	"Memory map":     "Carte des mémoires",
+	"Plugin output":  "Sortie du plugin",
+	"Help":           "Aide",
+
+	// Help overlay
+	"❓ Help: %s":      "❓ Aide : %s",
+	"List navigation": "Navigation dans la liste",
+	"Everywhere":      "Partout",
+	"Prompt":          "Invite",
+	"Prompt commands": "Commandes de l'invite",
+	"help":            "aide",
+	"this help":       "cette aide",
+	"switch focus":    "changer de zone",
+	"switch focus between the view and the prompt":   "passer de la vue à l'invite",
+	"complete the command, its argument or an emoji": "compléter la commande, son argument ou un emoji",
+	"run the command":  "lancer la commande",
+	"back to the view": "revenir à la vue",
+
+	// Key names
+	"Enter":         "Entrée",
+	"Space":         "Espace",
+	"Del":           "Suppr",
+	"Esc":           "Échap",
+	"↑/↓/Enter":     "↑/↓/Entrée",
+	"↑/↓/PgUp/PgDn": "↑/↓/PgPréc/PgSuiv",
+
+	// Key actions
+	"quit":                     "quitter",
+	"scroll":                   "défiler",
+	"top/bottom":               "début/fin",
+	"spellcheck":               "orthographe",
+	"use the first suggestion": "première suggestion",
+	"next issue":               "faute suivante",
+	"add":                      "ajouter",
+	"search":                   "rechercher",
+	"view detail":              "voir le détail",
+	"mark":                     "marquer",
+	"delete":                   "supprimer",
+	"filter":                   "filtrer",
+	"close":                    "fermer",
+	"save":                     "enregistrer",
+	"undo/redo":                "annuler/rétablir",
+	"cancel":                   "annuler",
+	"previous/next field":      "champ précédent/suivant",
+	"select":                   "sélectionner",
+	"merge with next":          "fusionner avec la suivante",
+	"drop":                     "retirer",
+	"chunk size":               "taille des morceaux",
+	"overlap":                  "chevauchement",
+	"keep whole":               "garder entière",
+	"save all":                 "tout enregistrer",
+	"back":                     "retour",
+	"navigate":                 "naviguer",
+	"include/skip":             "inclure/ignorer",
+	"toggle all":               "tout inverser",
+	"import selected":          "importer la sélection",
+	"cancel remaining items":   "annuler les éléments restants",
+	"level":                    "niveau",
+	"reload":                   "recharger",
+	"select a cluster":         "choisir un ensemble",
+	"list its memories":        "lister ses mémoires",
+
+	// Prompt commands
+	"Quit memory-tui":                                         "Quitter memory-tui",
+	"Add TEXT as a memory, or open the editor":                "Ajouter TEXTE comme mémoire, ou ouvrir l'éditeur",
+	"Search the memories":                                     "Rechercher dans les mémoires",
+	"Open the memory ID, or a " + memoryURLScheme + "ID link": "Ouvrir la mémoire ID, ou un lien " + memoryURLScheme + "ID",
+	"Fill a memory template":                                  "Remplir un modèle de mémoire",
+	"List the loaded memories having words starting with WORDS, without a server request":     "Lister les mémoires chargées ayant des mots commençant par MOTS, sans requête au serveur",
+	"Import a Markdown vault, .enex file or text directory, or resume the interrupted import": "Importer un coffre Markdown, un fichier .enex ou un dossier de textes, ou reprendre l'import interrompu",
+	"Export the marked or listed memories to PATH or the clipboard":                           "Exporter les mémoires marquées ou listées vers CHEMIN ou le presse-papiers",
+	"Delete the marked memories": "Supprimer les mémoires marquées",
+	"List the memories needing a cleanup: too short or long, JSON dumps, no metadata": "Lister les mémoires à nettoyer : trop courtes ou longues, dumps JSON, sans métadonnées",
+	"Experimental: plot the memories by similarity and explore their clusters":        "Expérimental : placer les mémoires par similarité et explorer leurs ensembles",
+	"Bring a deleted memory back from the server trash":                               "Restaurer une mémoire supprimée depuis la corbeille du serveur",
+	"Clear the marks": "Effacer les marques",
+	"Toggle the dry run, logging adds and deletes instead of sending them": "Activer ou non la simulation, qui journalise ajouts et suppressions au lieu de les envoyer",
+	"Reload the memories":                             "Recharger les mémoires",
+	"Show the adds and deletes made from this client": "Afficher les ajouts et suppressions faits depuis ce client",
+	"Show the client log":                             "Afficher le journal du client",
+	"Describe a command":                              "Décrire une commande",
+	"Forget the saved credentials":                    "Oublier les identifiants enregistrés",
+}
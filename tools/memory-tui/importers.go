@@ -0,0 +1,423 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbletea"
+)
+
+// Importers for note exports: Markdown vaults (Obsidian...), Evernote ENEX
+// files and directories of plain text or HTML notes (Apple Notes exports...)
+type importEntry struct {
+	Source   string // File the entry was read from
+	Title    string
+	Text     string
+	Metadata map[string]interface{}
+	Include  bool
+}
+
+type importLoadedMsg struct {
+	format  string
+	entries []importEntry
+}
+
+// loadImport detects the export format of path and reads its entries
+func loadImport(path string) (string, []importEntry, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if !info.IsDir() {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".enex":
+			entries, err := importENEX(path)
+			if err == nil && len(entries) == 0 {
+				err = fmt.Errorf("no note found in %s", path)
+			}
+			return "enex", entries, err
+		case ".md", ".markdown":
+			entry, err := importMarkdownFile(path, filepath.Dir(path))
+			return "markdown", []importEntry{entry}, err
+		case ".txt", ".html", ".htm":
+			entry, err := importTextFile(path)
+			return "text", []importEntry{entry}, err
+		}
+		return "", nil, fmt.Errorf("unsupported file type: %s", filepath.Ext(path))
+	}
+
+	var entries []importEntry
+	format := "text"
+	err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			// Skip vault settings and other hidden folders (.obsidian, .trash...)
+			if p != path && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		var entry importEntry
+		switch strings.ToLower(filepath.Ext(p)) {
+		case ".md", ".markdown":
+			format = "markdown"
+			entry, err = importMarkdownFile(p, path)
+		case ".txt", ".html", ".htm":
+			entry, err = importTextFile(p)
+		default:
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if strings.TrimSpace(entry.Text) != "" {
+			entries = append(entries, entry)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	if len(entries) == 0 {
+		return "", nil, fmt.Errorf("no .md, .txt or .html file found in %s", path)
+	}
+	return format, entries, nil
+}
+
+func importTextFile(path string) (importEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return importEntry{}, err
+	}
+	text := string(data)
+	if ext := strings.ToLower(filepath.Ext(path)); ext == ".html" || ext == ".htm" {
+		text = stripHTML(text)
+	}
+	title := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return importEntry{
+		Source: path,
+		Title:  title,
+		Text:   strings.TrimSpace(text),
+		Metadata: map[string]interface{}{
+			"source": "text",
+			"title":  title,
+		},
+		Include: true,
+	}, nil
+}
+
+// importMarkdownFile maps the YAML frontmatter and the vault folder of a note
+// into metadata
+func importMarkdownFile(path, root string) (importEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return importEntry{}, err
+	}
+
+	frontmatter, body := splitFrontmatter(string(data))
+	title := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	metadata := map[string]interface{}{
+		"source": "markdown",
+		"title":  title,
+	}
+	if folder, err := filepath.Rel(root, filepath.Dir(path)); err == nil && folder != "." {
+		metadata["folder"] = filepath.ToSlash(folder)
+	}
+	for k, v := range frontmatter {
+		metadata[k] = v
+	}
+	if t, ok := frontmatter["title"].(string); ok && t != "" {
+		title = t
+	}
+
+	return importEntry{
+		Source:   path,
+		Title:    title,
+		Text:     strings.TrimSpace(body),
+		Metadata: metadata,
+		Include:  true,
+	}, nil
+}
+
+// splitFrontmatter parses the simple "key: value" and "key: [a, b]" / "- item"
+// forms used by note-taking apps, not full YAML
+func splitFrontmatter(content string) (map[string]interface{}, string) {
+	fields := map[string]interface{}{}
+	if !strings.HasPrefix(content, "---\n") {
+		return fields, content
+	}
+	end := strings.Index(content[4:], "\n---")
+	if end < 0 {
+		return fields, content
+	}
+	header := content[4 : 4+end]
+	body := strings.TrimPrefix(content[4+end+4:], "\n")
+
+	var lastKey string
+	for _, line := range strings.Split(header, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "- ") && lastKey != "" {
+			list, _ := fields[lastKey].([]string)
+			fields[lastKey] = append(list, unquote(strings.TrimPrefix(trimmed, "- ")))
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		lastKey = key
+		switch {
+		case value == "":
+			fields[key] = []string{}
+		case strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]"):
+			var list []string
+			for _, item := range strings.Split(value[1:len(value)-1], ",") {
+				if item = unquote(strings.TrimSpace(item)); item != "" {
+					list = append(list, item)
+				}
+			}
+			fields[key] = list
+		default:
+			fields[key] = unquote(value)
+		}
+	}
+	return fields, body
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+type enexExport struct {
+	Notes []struct {
+		Title   string   `xml:"title"`
+		Content string   `xml:"content"`
+		Created string   `xml:"created"`
+		Tags    []string `xml:"tag"`
+	} `xml:"note"`
+}
+
+var (
+	htmlBlockRegex = regexp.MustCompile(`(?i)<(br|/p|/div|/li|/h[1-6])[^>]*>`)
+	htmlTagRegex   = regexp.MustCompile(`<[^>]+>`)
+	blankLineRegex = regexp.MustCompile(`\n{3,}`)
+)
+
+// stripHTML keeps the text of an HTML note, one line per block element
+func stripHTML(content string) string {
+	text := htmlBlockRegex.ReplaceAllString(content, "\n")
+	text = html.UnescapeString(htmlTagRegex.ReplaceAllString(text, ""))
+	return strings.TrimSpace(blankLineRegex.ReplaceAllString(text, "\n\n"))
+}
+
+func importENEX(path string) ([]importEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var export enexExport
+	if err := xml.Unmarshal(data, &export); err != nil {
+		return nil, fmt.Errorf("invalid ENEX file: %w", err)
+	}
+
+	var entries []importEntry
+	for _, note := range export.Notes {
+		text := stripHTML(note.Content)
+		if text == "" {
+			continue
+		}
+
+		metadata := map[string]interface{}{
+			"source": "evernote",
+			"title":  note.Title,
+		}
+		if len(note.Tags) > 0 {
+			metadata["tags"] = note.Tags
+		}
+		if created, err := time.Parse("20060102T150405Z", note.Created); err == nil {
+			metadata["created"] = created.Format(time.RFC3339)
+		}
+
+		entries = append(entries, importEntry{
+			Source:   path,
+			Title:    note.Title,
+			Text:     text,
+			Metadata: metadata,
+			Include:  true,
+		})
+	}
+	return entries, nil
+}
+
+func (m model) startImport(path string) tea.Cmd {
+	return func() tea.Msg {
+		format, entries, err := loadImport(path)
+		if err != nil {
+			return errMsg{err}
+		}
+		return importLoadedMsg{format, entries}
+	}
+}
+
+func (m model) updateImportView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.showView(listView)
+		m.importEntries = nil
+		return m, nil
+	case "up", "k":
+		if m.importIndex > 0 {
+			m.importIndex--
+		}
+	case "down", "j":
+		if m.importIndex < len(m.importEntries)-1 {
+			m.importIndex++
+		}
+	case " ", "x":
+		m.importEntries[m.importIndex].Include = !m.importEntries[m.importIndex].Include
+	case "a":
+		// Include all, or skip all when everything is already included
+		include := false
+		for _, e := range m.importEntries {
+			if !e.Include {
+				include = true
+				break
+			}
+		}
+		for i := range m.importEntries {
+			m.importEntries[i].Include = include
+		}
+	case "ctrl+s", "enter":
+		var selected []importEntry
+		for _, e := range m.importEntries {
+			if e.Include {
+				selected = append(selected, e)
+			}
+		}
+		if len(selected) == 0 {
+			m.message = tr("Nothing selected for import")
+			return m, nil
+		}
+		titles := make([]string, len(selected))
+		texts := make([]string, len(selected))
+		for i, e := range selected {
+			titles[i] = e.Title
+			texts[i], _ = redactText(e.Text)
+		}
+		return m.reviewSecrets(titles, texts, func(m model, keep []bool) (tea.Model, tea.Cmd) {
+			var kept []importEntry
+			for i, e := range selected {
+				if keep[i] {
+					kept = append(kept, e)
+				}
+			}
+			if len(kept) == 0 {
+				m.message = tr("Nothing selected for import")
+				return m, nil
+			}
+			return m.importSelected(kept)
+		})
+	}
+	return m, nil
+}
+
+func (m model) importSelected(selected []importEntry) (tea.Model, tea.Cmd) {
+	m.importEntries = nil
+	var cp *importCheckpoint
+	if !m.api.DryRun {
+		cp = newImportCheckpoint(m.api.ServerURL, selected)
+		if err := cp.save(); err != nil {
+			logf("WARN", "import checkpoint: %v", err)
+		}
+	}
+	return m.runImport(selected, cp)
+}
+
+// runImport sends the entries, recording them in the checkpoint when set
+func (m model) runImport(selected []importEntry, cp *importCheckpoint) (tea.Model, tea.Cmd) {
+	api := m.api.Background(timeoutBulk)
+	job := api.addJob("Importing memories", len(selected), func(i int) batchAddItem {
+		text, found := redactText(selected[i].Text)
+		if len(found) > 0 {
+			logf("INFO", "%s: %d values masked", selected[i].Title, len(found))
+		}
+		return batchAddItem{Text: text, Metadata: selected[i].Metadata, label: selected[i].Title}
+	}, func(i int) {
+		if cp != nil {
+			cp.markSent(selected[i].Text)
+		}
+	})
+	if cp != nil {
+		job.ended = cp.ended
+	}
+	return m.startBatch(job)
+}
+
+func (m model) renderImportView() string {
+	style := contentBoxStyle.Width(m.width - 4)
+	if m.focus == focusContent {
+		style = contentBoxFocusedStyle.Width(m.width - 4)
+	}
+
+	included := 0
+	for _, e := range m.importEntries {
+		if e.Include {
+			included++
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(tr("📥 Import (%s)", m.importFormat)))
+	b.WriteString("\n\n")
+	b.WriteString(fmt.Sprintf("%d/%d entries selected\n\n", included, len(m.importEntries)))
+
+	// Keep the selected entry visible in a window of the list
+	listHeight := max(3, m.height/2-6)
+	start := max(0, min(m.importIndex-listHeight/2, len(m.importEntries)-listHeight))
+	end := min(len(m.importEntries), start+listHeight)
+	for i := start; i < end; i++ {
+		e := m.importEntries[i]
+		box := "[ ]"
+		if e.Include {
+			box = "[x]"
+		}
+		line := fmt.Sprintf("%s %s", box, truncateString(e.Title, max(10, m.width-20)))
+		if i == m.importIndex {
+			b.WriteString(selectedItemStyle.Render("▶ " + line))
+		} else {
+			b.WriteString("  " + line)
+		}
+		b.WriteString("\n")
+	}
+
+	if len(m.importEntries) > 0 {
+		e := m.importEntries[m.importIndex]
+		preview := truncateString(e.Text, 500)
+		b.WriteString("\n")
+		b.WriteString(selectedItemStyle.Render(tr("Source: ")))
+		b.WriteString(e.Source)
+		b.WriteString("\n")
+		b.WriteString(wrapText(preview, max(20, m.width-10)))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(m.keyHint(""))
+	return style.Render(b.String())
+}
@@ -0,0 +1,431 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+
+	"github.com/Mickael-Roger/tom/tools/memory-tui/pkg/chatclient"
+)
+
+// `memory-tui --kiosk`: an always-on screen for a wall-mounted display, such
+// as a Raspberry Pi in the kitchen. A large clock and the day's digest on
+// top, the last exchanges with Tom below and a line to ask him, in the
+// "kiosk" conversation, a history Tom keeps apart from his apps. It never
+// asks to log in: it uses the daemon's session or the saved one, or the
+// credentials file given with --token-file, and when the server cannot be
+// reached it says so and tries again until it can.
+const (
+	kioskClientType       = "kiosk"
+	kioskCacheName        = "kiosk" // The last exchanges, kept across restarts
+	kioskDefaultExchanges = 3
+	kioskPollInterval     = time.Minute      // Connection and notifications
+	kioskRetryInterval    = 15 * time.Second // Once disconnected
+	kioskAskTimeout       = 3 * time.Minute
+)
+
+var (
+	kioskClockStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#25A065")).Bold(true)
+	kioskOnlineStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#25A065"))
+	kioskOfflineStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5F5F"))
+	kioskHeadingStyle = lipgloss.NewStyle().Bold(true)
+)
+
+// kioskGlyphs draws the clock, each glyph 5 rows high
+var kioskGlyphs = map[rune][5]string{
+	'0': {"██████", "██  ██", "██  ██", "██  ██", "██████"},
+	'1': {"  ██  ", "████  ", "  ██  ", "  ██  ", "██████"},
+	'2': {"██████", "    ██", "██████", "██    ", "██████"},
+	'3': {"██████", "    ██", "██████", "    ██", "██████"},
+	'4': {"██  ██", "██  ██", "██████", "    ██", "    ██"},
+	'5': {"██████", "██    ", "██████", "    ██", "██████"},
+	'6': {"██████", "██    ", "██████", "██  ██", "██████"},
+	'7': {"██████", "    ██", "   ██ ", "  ██  ", "  ██  "},
+	'8': {"██████", "██  ██", "██████", "██  ██", "██████"},
+	'9': {"██████", "██  ██", "██████", "    ██", "██████"},
+	':': {"  ", "██", "  ", "██", "  "},
+}
+
+type kioskExchange struct {
+	Question string    `json:"question"`
+	Answer   string    `json:"answer"`
+	At       time.Time `json:"at"`
+}
+
+type (
+	kioskTickMsg   time.Time
+	kioskPollMsg   struct{}
+	kioskStatusMsg struct {
+		tasks chatclient.Tasks
+		err   error
+	}
+	kioskDigestMsg struct {
+		digest digestMsg
+		err    error
+	}
+	kioskAnswerMsg struct {
+		exchange kioskExchange
+		err      error
+	}
+)
+
+type kioskModel struct {
+	session   *sharedSession
+	exchanges int // Shown at most
+
+	input  textinput.Model
+	now    time.Time
+	width  int
+	height int
+
+	connected     bool
+	checked       bool // The first check is done
+	err           error
+	notifications []chatclient.Task
+
+	digest   digestMsg
+	fetching bool // Digest being asked
+
+	history []kioskExchange
+	asking  string // Question waiting for its answer
+}
+
+func runKiosk(tokenFile string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		logf("WARN", "failed to load config, using defaults: %v", err)
+	}
+	setLanguage(cfg.Language)
+	applyTimeoutConfig(cfg)
+	enableLowBandwidth(cfg, lowBandwidth)
+
+	session := &sharedSession{cfg: cfg}
+	if tokenFile != "" {
+		if _, err := os.Stat(tokenFile); err != nil {
+			return err
+		}
+		session.open = func() (*MemoryAPI, error) { return openTokenSession(tokenFile) }
+	}
+
+	input := textinput.New()
+	input.Prompt = tr("Ask Tom: ")
+	input.Focus()
+	k := kioskModel{session: session, exchanges: cfg.KioskExchanges, input: input, now: time.Now()}
+	if k.exchanges <= 0 {
+		k.exchanges = kioskDefaultExchanges
+	}
+	var answers []digestAnswer
+	if at, err := loadCache(digestCacheName, &answers); err == nil && sameDay(at, k.now) && digestAnswered(answers) {
+		k.digest = digestMsg{answers, at}
+	}
+	if _, err := loadCache(kioskCacheName, &k.history); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		logf("WARN", "kiosk cache: %v", err)
+	}
+
+	logf("INFO", "kiosk started")
+	_, err = tea.NewProgram(k, programOptions(tea.WithAltScreen())...).Run()
+	return err
+}
+
+// openTokenSession opens a session with a provisioned credentials file, in
+// the format of ~/.tom/auth, logging in again with its password when the
+// cookie expired and it has one. The file is never written.
+func openTokenSession(path string) (*MemoryAPI, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if isEncryptedAuth(data) {
+		if data, err = decryptAuth(data, os.Getenv("TOM_PASSPHRASE")); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	creds, _, err := parseCredentials(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	api, err := newSessionAPI(creds.ServerURL, "")
+	if err != nil {
+		return nil, err
+	}
+	cookie := creds.SessionCookie
+	if !validateSessionCookie(creds.ServerURL, cookie, api.Client) {
+		if creds.Password == "" {
+			return nil, fmt.Errorf("%w: provision a new token file", errSessionExpired)
+		}
+		if cookie, err = postLogin(api.Client, creds.ServerURL, creds.Username, creds.Password); err != nil {
+			return nil, err
+		}
+	}
+	return newSessionAPI(creds.ServerURL, cookie)
+}
+
+func (k kioskModel) Init() tea.Cmd {
+	return tea.Batch(textinput.Blink, k.tick(), k.checkStatus())
+}
+
+// tick wakes the kiosk on each minute, for the clock
+func (k kioskModel) tick() tea.Cmd {
+	return tea.Every(time.Minute, func(t time.Time) tea.Msg { return kioskTickMsg(t) })
+}
+
+// checkStatus reads the notifications, telling whether Tom can be reached
+func (k kioskModel) checkStatus() tea.Cmd {
+	session := k.session
+	return func() tea.Msg {
+		var tasks chatclient.Tasks
+		err := session.do(func(api *MemoryAPI) error {
+			var err error
+			api = api.Background(timeoutPolling)
+			tasks, err = chatclient.NewWithHTTPClient(api.ServerURL, kioskClientType, api.Client).Tasks(context.Background())
+			return chatError(err)
+		})
+		return kioskStatusMsg{tasks, err}
+	}
+}
+
+func (k kioskModel) fetchDigest() tea.Cmd {
+	session := k.session
+	return func() tea.Msg {
+		var digest digestMsg
+		err := session.do(func(api *MemoryAPI) error {
+			digest = askDigest(api, session.cfg.Digest)
+			return nil
+		})
+		return kioskDigestMsg{digest, err}
+	}
+}
+
+func (k kioskModel) ask(question string) tea.Cmd {
+	session := k.session
+	return func() tea.Msg {
+		var answer chatclient.Answer
+		err := session.do(func(api *MemoryAPI) error {
+			ctx, cancel := context.WithTimeout(context.Background(), kioskAskTimeout)
+			defer cancel()
+			var err error
+			answer, err = chatclient.NewWithHTTPClient(api.ServerURL, kioskClientType, api.Client).Process(ctx, question)
+			return chatError(err)
+		})
+		return kioskAnswerMsg{kioskExchange{question, strings.TrimSpace(answer.Text), time.Now()}, err}
+	}
+}
+
+func (k kioskModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		k.width, k.height = msg.Width, msg.Height
+		k.input.Width = max(10, msg.Width-runewidth.StringWidth(k.input.Prompt)-2)
+		return k, nil
+
+	case kioskTickMsg:
+		day := k.now
+		k.now = time.Time(msg)
+		var cmd tea.Cmd
+		if !sameDay(day, k.now) && k.connected && !k.fetching {
+			k.fetching = true
+			cmd = k.fetchDigest()
+		}
+		return k, tea.Batch(k.tick(), cmd)
+
+	case kioskPollMsg:
+		return k, k.checkStatus()
+
+	case kioskStatusMsg:
+		k.checked = true
+		k.connected, k.err = msg.err == nil, msg.err
+		wait := kioskPollInterval
+		var cmds []tea.Cmd
+		if msg.err != nil {
+			logf("WARN", "kiosk: %v", msg.err)
+			k.fetching = false
+			wait = kioskRetryInterval
+		} else {
+			k.notifications = msg.tasks.Background
+			if !sameDay(k.digest.at, k.now) && !k.fetching {
+				k.fetching = true
+				cmds = append(cmds, k.fetchDigest())
+			}
+		}
+		cmds = append(cmds, tea.Tick(wait, func(time.Time) tea.Msg { return kioskPollMsg{} }))
+		return k, tea.Batch(cmds...)
+
+	case kioskDigestMsg:
+		k.fetching = false
+		switch {
+		case msg.err != nil:
+			k.connected, k.err = false, msg.err
+		case digestAnswered(msg.digest.answers):
+			k.digest = msg.digest
+		}
+		return k, nil
+
+	case kioskAnswerMsg:
+		k.asking = ""
+		if msg.err != nil {
+			msg.exchange.Answer = "❌ " + friendlyError(msg.err).Error()
+			k.connected, k.err = false, msg.err
+		}
+		k.history = append(k.history, msg.exchange)
+		if len(k.history) > k.exchanges {
+			k.history = k.history[len(k.history)-k.exchanges:]
+		}
+		if err := saveCache(kioskCacheName, time.Now(), k.history); err != nil {
+			logf("WARN", "kiosk cache: %v", err)
+		}
+		return k, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c":
+			return k, tea.Quit
+		case "esc":
+			k.input.Reset()
+			return k, nil
+		case "ctrl+r":
+			if k.fetching {
+				return k, nil
+			}
+			k.fetching = true
+			return k, k.fetchDigest()
+		case "enter":
+			question := strings.TrimSpace(k.input.Value())
+			if question == "" || k.asking != "" {
+				return k, nil
+			}
+			k.input.Reset()
+			k.asking = question
+			return k, k.ask(question)
+		}
+	}
+
+	var cmd tea.Cmd
+	k.input, cmd = k.input.Update(msg)
+	return k, cmd
+}
+
+func (k kioskModel) View() string {
+	if k.width == 0 {
+		return ""
+	}
+	width := k.width - 2
+
+	var top []string
+	for _, line := range append(kioskClock(k.now), "", kioskDate(k.now)) {
+		top = append(top, lipgloss.PlaceHorizontal(width, lipgloss.Center, line))
+	}
+	top = append(top, "", k.statusLine(width), "")
+
+	var bottom []string
+	for _, ex := range k.history {
+		bottom = append(bottom, kioskHeadingStyle.Render(truncateString("› "+singleLine(ex.Question), width)))
+		bottom = append(bottom, kioskWrap(ex.Answer, width)...)
+		bottom = append(bottom, "")
+	}
+	if k.asking != "" {
+		bottom = append(bottom, kioskHeadingStyle.Render(truncateString("› "+k.asking, width)), helpStyle.Render(tr("Tom is thinking…")), "")
+	}
+	bottom = append(bottom, k.input.View())
+
+	// The digest takes the room left, the exchanges keep their newest lines
+	room := k.height - len(top)
+	if len(bottom) > room {
+		bottom = bottom[len(bottom)-max(room, 1):]
+	}
+	digest := k.digestLines(width)
+	if free := room - len(bottom); len(digest) > free {
+		digest = digest[:max(free, 0)]
+	}
+
+	lines := append(top, digest...)
+	for i := len(lines) + len(bottom); i < k.height; i++ {
+		lines = append(lines, "")
+	}
+	lines = append(lines, bottom...)
+	if len(lines) > k.height {
+		lines = lines[:k.height]
+	}
+	return lipgloss.NewStyle().PaddingLeft(1).Render(strings.Join(lines, "\n"))
+}
+
+func (k kioskModel) statusLine(width int) string {
+	switch {
+	case !k.checked:
+		return helpStyle.Render(tr("Connecting to server..."))
+	case !k.connected:
+		return kioskOfflineStyle.Render(truncateString(tr("○ Offline, retrying: %v", friendlyError(k.err)), width))
+	}
+	status := tr("● Connected")
+	for _, t := range k.notifications {
+		status += " · " + t.Module + ": " + t.Status
+	}
+	return kioskOnlineStyle.Render(truncateString(status, width))
+}
+
+func (k kioskModel) digestLines(width int) []string {
+	if len(k.digest.answers) == 0 {
+		if k.fetching {
+			return []string{helpStyle.Render(tr("Asking Tom for the digest…")), ""}
+		}
+		return nil
+	}
+	var lines []string
+	for _, answer := range k.digest.answers {
+		if answer.Error != "" {
+			continue
+		}
+		lines = append(lines, kioskHeadingStyle.Render(tr(answer.Title)))
+		lines = append(lines, kioskWrap(answer.Answer, width)...)
+		lines = append(lines, "")
+	}
+	return lines
+}
+
+// digestAnswered tells whether Tom answered a question of the digest at
+// least, the kiosk asking again otherwise
+func digestAnswered(answers []digestAnswer) bool {
+	for _, answer := range answers {
+		if answer.Error == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// kioskClock draws the time in large digits
+func kioskClock(now time.Time) []string {
+	rows := make([]string, 5)
+	for _, r := range now.Format("15:04") {
+		for i, row := range kioskGlyphs[r] {
+			rows[i] += row + "  "
+		}
+	}
+	for i := range rows {
+		rows[i] = kioskClockStyle.Render(strings.TrimRight(rows[i], " "))
+	}
+	return rows
+}
+
+// kioskDate is the date written out, "Thursday 15 October"
+func kioskDate(now time.Time) string {
+	return kioskHeadingStyle.Render(fmt.Sprintf("%s %d %s", tr(now.Weekday().String()), now.Day(), tr(now.Month().String())))
+}
+
+// kioskWrap wraps a text to the width, keeping its line breaks
+func kioskWrap(text string, width int) []string {
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		lines = append(lines, strings.Split(wrapText(line, width), "\n")...)
+	}
+	return lines
+}
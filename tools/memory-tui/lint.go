@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbletea"
+)
+
+// /lint: the memories likely to need a cleanup, listed with their issues as a
+// worklist (mark them, delete them, open them); /refresh shows every memory
+// again. Checks the loaded memories only, without a server request.
+const (
+	lintMinLength   = 15   // Characters below which a memory is too short
+	lintMaxLength   = 2000 // Characters beyond which a memory is too long
+	lintJSONMinKeys = 3    // "key": pairs making a text a JSON dump
+)
+
+// lintMemory returns the issues of a memory, none when it looks fine
+func lintMemory(mem Memory) []string {
+	var issues []string
+	text := strings.TrimSpace(mem.Memory)
+	length := utf8.RuneCountInString(text)
+
+	switch {
+	case !strings.ContainsFunc(text, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }):
+		issues = append(issues, tr("near-empty"))
+	case length < lintMinLength:
+		issues = append(issues, tr("too short"))
+	case length > lintMaxLength:
+		issues = append(issues, tr("too long (%d chars)", length))
+	}
+	if looksLikeJSON(text) {
+		issues = append(issues, tr("raw JSON"))
+	}
+	if len(mem.Metadata) == 0 {
+		issues = append(issues, tr("no metadata"))
+	}
+	return issues
+}
+
+// looksLikeJSON spots a JSON document, or a dump pasted inside some text
+func looksLikeJSON(text string) bool {
+	if strings.HasPrefix(text, "{") || strings.HasPrefix(text, "[") {
+		if json.Valid([]byte(text)) {
+			return true
+		}
+	}
+	return strings.Count(text, `":`) >= lintJSONMinKeys && strings.Contains(text, `{"`)
+}
+
+func runLintCommand(m model, args string) (tea.Model, tea.Cmd) {
+	m.linting = true
+	m.filterQuery = ""
+	m.showView(listView)
+	m.applyLint()
+	return m, nil
+}
+
+// applyLint narrows the list to the flagged memories, again after each
+// reload while the worklist is shown
+func (m *model) applyLint() {
+	var items []list.Item
+	counts := map[string]int{}
+	for _, mem := range m.memories {
+		issues := lintMemory(mem)
+		if len(issues) == 0 {
+			continue
+		}
+		for _, issue := range issues {
+			counts[strings.SplitN(issue, " (", 2)[0]]++
+		}
+		items = append(items, memoryItem{memory: mem, marked: m.marked[mem.ID], issues: issues})
+	}
+
+	m.list.SetItems(items)
+	m.list.ResetSelected()
+	if len(items) == 0 {
+		m.linting = false
+		m.list.SetItems(m.memoryItems(m.memories))
+		m.message = tr("Lint: the %d memories look fine", len(m.memories))
+		return
+	}
+
+	var summary []string
+	for _, issue := range []string{"near-empty", "too short", "too long", "raw JSON", "no metadata"} {
+		if issue = tr(issue); counts[issue] > 0 {
+			summary = append(summary, fmt.Sprintf("%d %s", counts[issue], issue))
+		}
+	}
+	m.message = tr("Lint: %d of %d memories to review (%s), /refresh to list them all",
+		len(items), len(m.memories), strings.Join(summary, ", "))
+	logf("INFO", "lint: %d memories flagged", len(items))
+}
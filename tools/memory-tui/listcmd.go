@@ -0,0 +1,182 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// `memory-tui list`: the memories on stdout, for awk, column, fzf...
+// Tables are for reading and truncate the text, CSV, TSV and JSON keep the
+// raw values (RFC 3339 dates, full text).
+const listTextWidth = 60
+
+var listColumns = []string{"id", "created", "updated", "tags", "text"}
+
+// parseListColumns validates a comma-separated column list
+func parseListColumns(spec string) ([]string, error) {
+	var columns []string
+	for _, c := range strings.Split(spec, ",") {
+		c = strings.ToLower(strings.TrimSpace(c))
+		if c == "" {
+			continue
+		}
+		known := false
+		for _, k := range listColumns {
+			known = known || k == c
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown column %q (available: %s)", c, strings.Join(listColumns, ", "))
+		}
+		columns = append(columns, c)
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("no column selected (available: %s)", strings.Join(listColumns, ", "))
+	}
+	return columns, nil
+}
+
+// memoryTags reads the "tags" metadata, a list or a comma-separated string
+func memoryTags(mem Memory) []string {
+	switch tags := mem.Metadata["tags"].(type) {
+	case []interface{}:
+		var out []string
+		for _, t := range tags {
+			out = append(out, fmt.Sprint(t))
+		}
+		return out
+	case string:
+		var out []string
+		for _, t := range strings.Split(tags, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				out = append(out, t)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+func listValue(mem Memory, column string) string {
+	switch column {
+	case "id":
+		return mem.ID
+	case "created":
+		return mem.CreatedAt
+	case "updated":
+		if mem.UpdatedAt != nil {
+			return *mem.UpdatedAt
+		}
+		return ""
+	case "tags":
+		return strings.Join(memoryTags(mem), ",")
+	case "text":
+		return mem.Memory
+	}
+	return ""
+}
+
+// singleLine keeps a value on one line, for tables and TSV
+func singleLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func writeMemoryList(w io.Writer, memories []Memory, format string, columns []string, header, truncate bool) error {
+	switch format {
+	case "json":
+		rows := make([]map[string]interface{}, len(memories))
+		for i, mem := range memories {
+			row := map[string]interface{}{}
+			for _, c := range columns {
+				if c == "tags" {
+					row[c] = append([]string{}, memoryTags(mem)...)
+				} else {
+					row[c] = listValue(mem, c)
+				}
+			}
+			rows[i] = row
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(rows)
+
+	case "csv":
+		cw := csv.NewWriter(w)
+		if header {
+			cw.Write(columns)
+		}
+		for _, mem := range memories {
+			row := make([]string, len(columns))
+			for i, c := range columns {
+				row[i] = listValue(mem, c)
+			}
+			cw.Write(row)
+		}
+		cw.Flush()
+		return cw.Error()
+
+	case "tsv":
+		if header {
+			fmt.Fprintln(w, strings.Join(columns, "\t"))
+		}
+		for _, mem := range memories {
+			row := make([]string, len(columns))
+			for i, c := range columns {
+				row[i] = singleLine(listValue(mem, c))
+			}
+			fmt.Fprintln(w, strings.Join(row, "\t"))
+		}
+		return nil
+
+	case "table":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		if header {
+			fmt.Fprintln(tw, strings.ToUpper(strings.Join(columns, "\t")))
+		}
+		for _, mem := range memories {
+			row := make([]string, len(columns))
+			for i, c := range columns {
+				v := singleLine(listValue(mem, c))
+				switch {
+				case (c == "created" || c == "updated") && v != "":
+					v = formatTime(v)
+				case c == "text" && truncate:
+					v = truncateString(v, listTextWidth)
+				}
+				row[i] = v
+			}
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+		return tw.Flush()
+	}
+	return fmt.Errorf("unknown format %q (table, csv, tsv or json)", format)
+}
+
+func runList(format, columnSpec string, header, truncate bool) error {
+	columns, err := parseListColumns(columnSpec)
+	if err != nil {
+		return err
+	}
+	// Fail on a bad format before logging in
+	if err := writeMemoryList(io.Discard, nil, format, columns, false, truncate); err != nil {
+		return err
+	}
+
+	if cfg, err := loadConfig(); err == nil {
+		applyTimeConfig(cfg)
+	}
+
+	sess, err := openSession()
+	if err != nil {
+		return err
+	}
+	memories, err := sess.API.GetAllMemories()
+	if err != nil {
+		return err
+	}
+	return writeMemoryList(os.Stdout, memories, format, columns, header, truncate)
+}
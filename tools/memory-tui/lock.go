@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"net/http/cookiejar"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Idle lock: after "idle_lock" minutes without a key press the interface is
+// blanked and the session dropped from the client, the password logging in
+// again to resume where it was. The session of a daemon is left alone.
+const idleCheckInterval = 15 * time.Second
+
+type idleTickMsg struct{}
+
+type unlockMsg struct{ err error }
+
+func isIdleTick(msg tea.Msg) bool {
+	_, ok := msg.(idleTickMsg)
+	return ok
+}
+
+func (m model) idleTimeout() time.Duration {
+	return time.Duration(m.config.IdleLock) * time.Minute
+}
+
+func (m model) idleTick() tea.Cmd {
+	if m.idleTimeout() <= 0 {
+		return nil
+	}
+	return tea.Tick(idleCheckInterval, func(time.Time) tea.Msg { return idleTickMsg{} })
+}
+
+// lockable reports whether the current view shows memories, the login views
+// having nothing to hide
+func (m model) lockable() bool {
+	switch m.state {
+	case connectingView, loginView, passphraseView, lockView:
+		return false
+	}
+	return true
+}
+
+func (m model) lock() model {
+	logf("INFO", "locked after %d minutes idle", m.config.IdleLock)
+	m.lockReturn = m.state
+	m.state = lockView
+	m.err = nil
+	m.passwordInput.Reset()
+	m.passwordInput.Focus()
+	// A fresh cookie jar logs the client out, the server session stays
+	// valid for the daemon and is replaced when unlocking
+	jar, _ := cookiejar.New(nil)
+	m.client.Jar = jar
+	return m
+}
+
+func unlock(m model) tea.Cmd {
+	return func() tea.Msg {
+		sessionCookie, err := postLogin(m.client, m.serverURL, m.usernameInput.Value(), m.passwordInput.Value())
+		if err != nil {
+			return unlockMsg{err}
+		}
+		password := m.passwordInput.Value()
+		if !m.storePassword {
+			password = ""
+		}
+		if err := saveCredentials(m.usernameInput.Value(), password, m.serverURL, sessionCookie, m.passphrase); err != nil {
+			logf("WARN", "failed to save credentials: %v", err)
+		}
+		return unlockMsg{}
+	}
+}
+
+// updateLocked handles the idle checks and every message while locked: the
+// answers of the requests sent before the lock still update the model, but
+// the views they open stay hidden until the unlock
+func (m model) updateLocked(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case idleTickMsg:
+		if m.lockable() && time.Since(m.lastActivity) >= m.idleTimeout() {
+			m = m.lock()
+			return m, tea.Batch(textinput.Blink, m.idleTick())
+		}
+		return m, m.idleTick()
+
+	case unlockMsg:
+		if msg.err != nil {
+			m.err = msg.err
+			m.passwordInput.Reset()
+			return m, nil
+		}
+		logf("INFO", "unlocked")
+		m.err = nil
+		m.passwordInput.Reset()
+		m.passwordInput.Blur()
+		m.state = m.lockReturn
+		m.lastActivity = time.Now()
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.Type {
+		case tea.KeyCtrlC:
+			return m, tea.Quit
+		case tea.KeyEnter:
+			if m.passwordInput.Value() == "" {
+				return m, nil
+			}
+			return m, unlock(m)
+		}
+		var cmd tea.Cmd
+		m.passwordInput, cmd = m.passwordInput.Update(msg)
+		return m, cmd
+	}
+
+	updated, cmd := m.update(msg)
+	next, ok := updated.(model)
+	if !ok {
+		return updated, cmd
+	}
+	if next.state != lockView {
+		next.lockReturn = next.state
+		next.state = lockView
+	}
+	return next, cmd
+}
+
+func (m model) renderLockView() string {
+	b := tr("🔒 Locked after %d minutes idle", m.config.IdleLock) + "\n\n"
+	b += tr("Password of %s", m.usernameInput.Value()) + "\n"
+	b += m.passwordInput.View()
+	if m.err != nil {
+		b += "\n\n" + loginErrorStyle.Render(wrapText(fmt.Sprintf("❌ %v", m.err), 50))
+	}
+	b += "\n\n" + tr("(enter to unlock, ctrl+c to quit)")
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, loginBoxStyle.Render(b))
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Debug log of the interface, written to ~/.tom/memory-tui.log since the
+// terminal belongs to the interface, and shown by the /logs overlay
+const (
+	maxLogSize  = 1 << 20 // Rotated to memory-tui.log.1 beyond this size
+	logTailSize = 1000    // Lines loaded by the viewer
+)
+
+var logLevels = []string{"DEBUG", "INFO", "WARN", "ERROR"}
+
+var logLevelStyles = map[string]lipgloss.Style{
+	"DEBUG": lipgloss.NewStyle().Foreground(lipgloss.Color("#626262")),
+	"WARN":  lipgloss.NewStyle().Foreground(lipgloss.Color("#FFAF00")),
+	"ERROR": lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5F87")),
+}
+
+func logFilePath() (string, error) {
+	return tomPath("memory-tui.log")
+}
+
+// setupLogging sends the log package output to the log file
+func setupLogging() (func(), error) {
+	path, err := logFilePath()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	if info, err := os.Stat(path); err == nil && info.Size() > maxLogSize {
+		os.Rename(path, path+".1")
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	log.SetOutput(f)
+	return func() {
+		log.SetOutput(os.Stderr)
+		f.Close()
+	}, nil
+}
+
+func logf(level, format string, args ...interface{}) {
+	log.Printf("%-5s %s", level, fmt.Sprintf(format, args...))
+}
+
+// lineLevel returns the index in logLevels of a log line, INFO when unknown
+func lineLevel(line string) int {
+	for i, level := range logLevels {
+		if strings.Contains(line, " "+level+" ") {
+			return i
+		}
+	}
+	return 1
+}
+
+type logsLoadedMsg struct {
+	lines []string
+	err   error
+}
+
+type logTickMsg struct{}
+
+func loadLogTail() tea.Msg {
+	path, err := logFilePath()
+	if err != nil {
+		return logsLoadedMsg{err: err}
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return logsLoadedMsg{err: err}
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+		if len(lines) > logTailSize {
+			lines = lines[1:]
+		}
+	}
+	return logsLoadedMsg{lines, scanner.Err()}
+}
+
+// Refresh the overlay while it is open
+func logTick() tea.Cmd {
+	return tea.Tick(tickInterval(2*time.Second), func(time.Time) tea.Msg { return logTickMsg{} })
+}
+
+func (m model) openLogs() (tea.Model, tea.Cmd) {
+	m.showView(logsView)
+	m.logViewport = viewport.New(max(20, m.width-8), max(5, m.height-12))
+	m.logFollow = true
+	return m, tea.Batch(loadLogTail, logTick())
+}
+
+func (m *model) refreshLogViewport() {
+	var b strings.Builder
+	shown := 0
+	for _, line := range m.logLines {
+		level := lineLevel(line)
+		if level < m.logLevel {
+			continue
+		}
+		if style, ok := logLevelStyles[logLevels[level]]; ok {
+			line = style.Render(line)
+		}
+		b.WriteString(line + "\n")
+		shown++
+	}
+	if shown == 0 {
+		b.WriteString(helpStyle.Render(tr("No log entry at this level")))
+	}
+	m.logViewport.SetContent(b.String())
+	if m.logFollow {
+		m.logViewport.GotoBottom()
+	}
+}
+
+func (m model) handleLogsLoaded(msg logsLoadedMsg) (tea.Model, tea.Cmd) {
+	if m.state != logsView {
+		return m, nil
+	}
+	if msg.err != nil && !os.IsNotExist(msg.err) {
+		m.err = msg.err
+	}
+	m.logLines = msg.lines
+	m.refreshLogViewport()
+	return m, nil
+}
+
+func (m model) updateLogsView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		m.showView(listView)
+		m.logLines = nil
+		return m, nil
+	case "l":
+		m.logLevel = (m.logLevel + 1) % len(logLevels)
+		m.refreshLogViewport()
+		return m, nil
+	case "r":
+		return m, loadLogTail
+	case "G", "end":
+		m.logFollow = true
+		m.logViewport.GotoBottom()
+		return m, nil
+	case "g", "home":
+		m.logFollow = false
+		m.logViewport.GotoTop()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.logViewport, cmd = m.logViewport.Update(msg)
+	// Scrolling up stops following new lines until the end is reached again
+	m.logFollow = m.logViewport.AtBottom()
+	return m, cmd
+}
+
+func (m model) renderLogsView() string {
+	style := contentBoxStyle.Width(m.width - 4)
+	if m.focus == focusContent {
+		style = contentBoxFocusedStyle.Width(m.width - 4)
+	}
+
+	path, _ := logFilePath()
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(tr("📜 Logs")))
+	b.WriteString(" " + helpStyle.Render(tr("%s • level %s and above", path, logLevels[m.logLevel])))
+	b.WriteString("\n\n")
+	b.WriteString(m.logViewport.View())
+	b.WriteString("\n\n")
+	b.WriteString(m.keyHint(""))
+	return style.Render(b.String())
+}
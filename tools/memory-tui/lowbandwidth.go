@@ -0,0 +1,68 @@
+package main
+
+import (
+	"time"
+
+	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// Low-bandwidth mode, for SSH over a mobile link (--low-bandwidth or the
+// "low_bandwidth" setting): every redraw crosses the link, so the spinners
+// and cursor blinks redrawing several times a second are stopped, the
+// screen is redrawn at most lowBandwidthFPS times a second, the updates in
+// between going out together, the periodic checks run lowBandwidthSlowdown
+// times less often, and the colors are sent as the short 16-color escapes.
+// Compressing the terminal stream is left to ssh -C or mosh.
+const (
+	lowBandwidthFPS      = 4
+	lowBandwidthSlowdown = 5
+)
+
+var lowBandwidth bool
+
+// enableLowBandwidth turns the mode on from the flag or the setting, before
+// the program starts
+func enableLowBandwidth(cfg config, flag bool) {
+	lowBandwidth = flag || cfg.LowBandwidth
+	if lowBandwidth && lipgloss.ColorProfile() < termenv.ANSI {
+		lipgloss.SetColorProfile(termenv.ANSI)
+	}
+}
+
+// programOptions adds the redraw limits of the mode to the options of a
+// program
+func programOptions(opts ...tea.ProgramOption) []tea.ProgramOption {
+	if !lowBandwidth {
+		return opts
+	}
+	return append(opts, tea.WithFPS(lowBandwidthFPS), tea.WithFilter(func(_ tea.Model, msg tea.Msg) tea.Msg {
+		// Without its blinks the cursor stays shown
+		if _, ok := msg.(cursor.BlinkMsg); ok {
+			return nil
+		}
+		return msg
+	}))
+}
+
+// newSpinner returns the spinner of the loading screens, an ellipsis that
+// never moves in the mode
+func newSpinner() spinner.Model {
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+	if lowBandwidth {
+		s.Spinner = spinner.Spinner{Frames: []string{"…"}, FPS: time.Hour}
+	}
+	return s
+}
+
+// tickInterval is the period of a periodic check, longer in the mode
+func tickInterval(d time.Duration) time.Duration {
+	if lowBandwidth {
+		return d * lowBandwidthSlowdown
+	}
+	return d
+}
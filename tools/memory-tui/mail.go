@@ -0,0 +1,227 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbletea"
+)
+
+// Mail triage: on servers with the mail module, /mail lists the unread
+// emails with a one-line summary each, and marks one read, moves it to the
+// archive folder ("mail_archive", Archive by default) or has Tom draft a
+// reply, never sent, that y copies. The module has no REST endpoint: the
+// requests go through Tom (POST /process), who calls its tools. The email
+// IDs are IMAP sequence numbers, shifted by an archive, so the list reloads
+// after each one.
+const (
+	moduleMail         = "mail"
+	defaultMailArchive = "Archive"
+)
+
+const (
+	mailListPrompt = "List my unread emails with list_new_emails, then read each with get_email_content without " +
+		"marking it read. Reply with a JSON array only, no text around it, one object per email: " +
+		`{"id": "...", "from": "...", "subject": "...", "date": "...", "summary": "one sentence"}. ` +
+		"Reply [] when there is none."
+	mailReadPrompt    = "Mark the email with ID %s as read with mark_email_as_read. Reply OK only."
+	mailArchivePrompt = "Move the email with ID %s to the folder %q with move_email_to_folder. Reply OK only."
+	mailDraftPrompt   = "Read the email with ID %s with get_email_content and draft a reply to it, in its " +
+		"language. Do not send it and do not mark it read. Reply with the draft only."
+)
+
+type mailMessage struct {
+	ID      answerID `json:"id"`
+	From    string   `json:"from"`
+	Subject string   `json:"subject"`
+	Date    string   `json:"date"`
+	Summary string   `json:"summary"`
+}
+
+type mailLoadedMsg struct{ emails []mailMessage }
+
+type mailDoneMsg struct {
+	id     answerID
+	action string // "read", "archive" or "draft"
+	draft  string
+}
+
+func runMailCommand(m model, args string) (tea.Model, tea.Cmd) {
+	m.loading = true
+	m.setFocus(focusContent)
+	return m, m.fetchMail()
+}
+
+func (m model) fetchMail() tea.Cmd {
+	api := m.api
+	return func() tea.Msg {
+		answer, err := api.Process(mailListPrompt)
+		if err != nil {
+			return errMsg{err}
+		}
+		var emails []mailMessage
+		if err := decodeAnswerList(answer, &emails); err != nil {
+			return errMsg{err}
+		}
+		return mailLoadedMsg{emails}
+	}
+}
+
+func (m model) openMail(msg mailLoadedMsg) (tea.Model, tea.Cmd) {
+	m.loading = false
+	m.mail = msg.emails
+	m.mailDraft = mailDoneMsg{}
+	if m.state == mailView {
+		// Reloaded from the view, the selection stays in place
+		m.mailCursor = min(m.mailCursor, max(0, len(m.mail)-1))
+		return m, nil
+	}
+	m.mailCursor = 0
+	cmd := m.showView(mailView)
+	return m, cmd
+}
+
+// mailAction sends the action on the selected email to Tom
+func (m model) mailAction(action string) (tea.Model, tea.Cmd) {
+	if len(m.mail) == 0 {
+		return m, nil
+	}
+	id := m.mail[m.mailCursor].ID
+	var prompt string
+	switch action {
+	case "read":
+		prompt = fmt.Sprintf(mailReadPrompt, id)
+	case "archive":
+		folder := m.config.MailArchive
+		if folder == "" {
+			folder = defaultMailArchive
+		}
+		prompt = fmt.Sprintf(mailArchivePrompt, id, folder)
+	case "draft":
+		prompt = fmt.Sprintf(mailDraftPrompt, id)
+	}
+
+	m.loading = true
+	api := m.api
+	return m, func() tea.Msg {
+		answer, err := api.Process(prompt)
+		if err != nil {
+			return errMsg{err}
+		}
+		return mailDoneMsg{id: id, action: action, draft: strings.TrimSpace(answer)}
+	}
+}
+
+func (m model) handleMailDone(msg mailDoneMsg) (tea.Model, tea.Cmd) {
+	m.loading = false
+	logf("INFO", "mail %s: %s", msg.id, msg.action)
+	switch msg.action {
+	case "draft":
+		m.mailDraft = msg
+		m.message = tr("Reply drafted by Tom, y copies it")
+		return m, nil
+	case "archive":
+		m.message = tr("Email archived")
+		m.loading = true
+		return m, m.fetchMail()
+	}
+	for i, email := range m.mail {
+		if email.ID == msg.id {
+			m.mail = append(m.mail[:i:i], m.mail[i+1:]...)
+			break
+		}
+	}
+	m.mailCursor = min(m.mailCursor, max(0, len(m.mail)-1))
+	m.message = tr("Email marked read")
+	return m, nil
+}
+
+func (m model) updateMailView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.loading && msg.String() != "esc" {
+		return m, nil
+	}
+	switch msg.String() {
+	case "esc", "q":
+		cmd := m.showView(listView)
+		return m, cmd
+	case "up", "k":
+		if m.mailCursor > 0 {
+			m.mailCursor--
+		}
+	case "down", "j":
+		if m.mailCursor < len(m.mail)-1 {
+			m.mailCursor++
+		}
+	case "m":
+		return m.mailAction("read")
+	case "a":
+		return m.mailAction("archive")
+	case "d":
+		return m.mailAction("draft")
+	case "y":
+		if m.mailDraft.draft == "" {
+			return m, nil
+		}
+		if err := clipboard.WriteAll(m.mailDraft.draft); err != nil {
+			m.err = fmt.Errorf("failed to copy to clipboard: %w", err)
+			return m, nil
+		}
+		m.message = tr("Draft copied to the clipboard")
+	case "p":
+		if len(m.mail) == 0 || m.mailDraft.id != m.mail[m.mailCursor].ID {
+			return m, nil
+		}
+		return m.pinAnswer("mail", tr("Reply to %s", m.mail[m.mailCursor].Subject), m.mailDraft.draft)
+	case "r":
+		m.loading = true
+		return m, m.fetchMail()
+	}
+	return m, nil
+}
+
+func (m model) renderMailView() string {
+	style := contentBoxStyle.Width(m.width - 4)
+	if m.focus == focusContent {
+		style = contentBoxFocusedStyle.Width(m.width - 4)
+	}
+	width := max(20, m.width-10)
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(tr("📬 Unread Emails")))
+	b.WriteString(" " + helpStyle.Render(tr("%d unread", len(m.mail))))
+	b.WriteString("\n\n")
+	if len(m.mail) == 0 {
+		b.WriteString(helpStyle.Render(tr("No unread email")))
+		b.WriteString("\n")
+	}
+	for i, email := range m.mail {
+		line := truncateString(fmt.Sprintf("%s — %s", email.From, email.Subject), width-2)
+		if i == m.mailCursor {
+			b.WriteString(selectedItemStyle.Render("▸ " + line))
+		} else {
+			b.WriteString("  " + line)
+		}
+		b.WriteString("\n")
+	}
+
+	if len(m.mail) > 0 {
+		email := m.mail[m.mailCursor]
+		b.WriteString("\n")
+		b.WriteString(helpStyle.Render(email.Date))
+		b.WriteString("\n")
+		b.WriteString(wrapLines(email.Summary, width))
+		b.WriteString("\n")
+		if m.mailDraft.draft != "" && m.mailDraft.id == email.ID {
+			b.WriteString("\n")
+			b.WriteString(selectedItemStyle.Render(tr("Draft reply")))
+			b.WriteString("\n")
+			b.WriteString(wrapLines(m.mailDraft.draft, width))
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString("\n")
+	b.WriteString(m.keyHint(""))
+	return style.Render(b.String())
+}
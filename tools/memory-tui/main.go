@@ -1,9 +1,10 @@
 package main
 
 import (
-	"bytes"
+	"context"
 	"encoding/base64"
-	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"log"
@@ -15,25 +16,22 @@ import (
 	"strings"
 	"time"
 
+	"github.com/Mickael-Roger/tom/tools/memory-tui/pkg/memoryclient"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
 	"github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
 )
 
-// API Models
-type Memory struct {
-	ID        string                 `json:"id"`
-	Memory    string                 `json:"memory"`    // mem0 uses "memory" not "content"
-	Hash      string                 `json:"hash"`      // mem0 specific field
-	CreatedAt string                 `json:"created_at"`
-	UpdatedAt *string                `json:"updated_at"` // Can be null
-	UserID    string                 `json:"user_id"`    // mem0 specific field
-	Metadata  map[string]interface{} `json:"metadata"`
-}
+// API Models, the memory being the one of pkg/memoryclient
+type Memory = memoryclient.Memory
 
+// MemoryResults holds the "results" field, whose shape depends on the API version
 type MemoryResults struct {
 	Results []Memory `json:"results"`
 }
@@ -52,19 +50,25 @@ type (
 	disconnectMsg     struct{}
 	autoLoginMsg      struct{ username, password, serverURL, sessionCookie string; useSession bool }
 	errorMsg          struct{ error }
+	passphraseMsg     struct{ error } // Stored credentials are encrypted
 )
 
-type credentials struct {
-	Username      string `json:"username"`
-	Password      string `json:"password"`
-	ServerURL     string `json:"server_url"`
-	SessionCookie string `json:"session_cookie"`
-}
+// errSessionExpired is returned when the server rejects the session cookie
+var errSessionExpired = errors.New("session expired, please log in again")
 
 // API Client
 type MemoryAPI struct {
-	ServerURL string // Tom server URL (e.g., https://tom.example.com)
-	Client    *http.Client
+	ServerURL  string // Tom server URL (e.g., https://tom.example.com)
+	Client     *http.Client
+	APIVersion int               // Negotiated with the server through /status
+	Hooks      map[string]string // Commands run on memory events, see hooks.go
+	DryRun     bool              // Log the changes instead of sending them, see dryrun.go
+	SoftDelete bool              // The server keeps deleted memories in a trash, see trash.go
+	Embeddings bool              // The server returns memory embeddings, see memorymap.go
+	Modules    []string          // Modules enabled on the server, nil when unknown, see capabilities.go
+	ReadOnly   bool              // Changes are refused, see readonly.go
+	Batch      bool              // The server takes batches of adds and deletes, see bulk.go
+	Scratch    string            // Directory the code blocks of the answers go to, empty when off, see scratch.go
 }
 
 func NewMemoryAPI(serverURL string) *MemoryAPI {
@@ -72,8 +76,8 @@ func NewMemoryAPI(serverURL string) *MemoryAPI {
 	return &MemoryAPI{
 		ServerURL: serverURL,
 		Client: &http.Client{
-			Jar:     jar,
-			Timeout: 30 * time.Second,
+			Jar:       jar,
+			Transport: userAgentTransport{},
 		},
 	}
 }
@@ -91,123 +95,88 @@ func (api *MemoryAPI) buildURL(endpoint string) string {
 	return api.ServerURL + "/memory" + endpoint
 }
 
-func (api *MemoryAPI) GetAllMemories() ([]Memory, error) {
-	resp, err := api.Client.Get(api.buildURL("/memories"))
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	var apiResp APIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
-		return nil, err
-	}
+// memories is the client of the memory store, sharing the session of api
+func (api *MemoryAPI) memories() *memoryclient.Client {
+	return memoryclient.NewWithHTTPClient(api.ServerURL, api.Client)
+}
 
-	if apiResp.Error != "" {
-		return nil, fmt.Errorf("API error: %s", apiResp.Error)
+// clientError turns an expired session reported by memoryclient into the
+// error the interface logs in again on
+func clientError(err error) error {
+	if errors.Is(err, memoryclient.ErrUnauthorized) {
+		return errSessionExpired
 	}
+	return err
+}
 
-	return apiResp.Results.Results, nil
+func (api *MemoryAPI) GetAllMemories() ([]Memory, error) {
+	memories, err := api.memories().List(context.Background())
+	return memories, clientError(err)
 }
 
 func (api *MemoryAPI) GetMemory(id string) (Memory, error) {
-	resp, err := api.Client.Get(api.buildURL("/memory/" + id))
-	if err != nil {
-		return Memory{}, err
-	}
-	defer resp.Body.Close()
-
-	var apiResp APIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
-		return Memory{}, err
+	memory, err := api.memories().Get(context.Background(), id)
+	if errors.Is(err, memoryclient.ErrNotFound) {
+		return Memory{}, fmt.Errorf("memory %s not found", id)
 	}
-
-	if apiResp.Error != "" {
-		return Memory{}, fmt.Errorf("API error: %s", apiResp.Error)
-	}
-
-	return apiResp.Result, nil
+	return memory, clientError(err)
 }
 
 func (api *MemoryAPI) AddMemory(text string, metadata map[string]interface{}) error {
-	payload := map[string]interface{}{
-		"text":     text,
-		"metadata": metadata,
-	}
-
-	jsonData, err := json.Marshal(payload)
-	if err != nil {
-		return err
-	}
-
-	resp, err := api.Client.Post(api.buildURL("/add"), "application/json", bytes.NewBuffer(jsonData))
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	var apiResp APIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
-		return err
-	}
-
-	if apiResp.Error != "" {
-		return fmt.Errorf("API error: %s", apiResp.Error)
-	}
-
-	return nil
+	_, err := api.addMemory(text, metadata)
+	return err
 }
 
-func (api *MemoryAPI) SearchMemories(query string, limit int) ([]Memory, error) {
-	payload := map[string]interface{}{
-		"query": query,
-		"limit": limit,
+// addMemory adds a memory as AddMemory, returning it as stored, empty in
+// dry-run mode
+func (api *MemoryAPI) addMemory(text string, metadata map[string]interface{}) (Memory, error) {
+	if err := api.refuseReadOnly(); err != nil {
+		return Memory{}, err
 	}
-
-	jsonData, err := json.Marshal(payload)
-	if err != nil {
-		return nil, err
+	if api.skipDryRun("POST", "/add", map[string]interface{}{"text": text, "metadata": metadata}) {
+		return Memory{}, nil
 	}
 
-	resp, err := api.Client.Post(api.buildURL("/search"), "application/json", bytes.NewBuffer(jsonData))
+	added, err := api.memories().Add(context.Background(), text, metadata)
 	if err != nil {
-		return nil, err
+		return Memory{}, clientError(err)
 	}
-	defer resp.Body.Close()
 
-	var apiResp APIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
-		return nil, err
-	}
-
-	if apiResp.Error != "" {
-		return nil, fmt.Errorf("API error: %s", apiResp.Error)
-	}
+	api.recordAudit("add", added.ID, "", text)
+	runHook(api.Hooks, hookMemoryAdded, map[string]interface{}{"text": text, "metadata": metadata})
+	return added, nil
+}
 
-	return apiResp.Results.Results, nil
+func (api *MemoryAPI) SearchMemories(query string, limit int) ([]Memory, error) {
+	memories, err := api.memories().Search(context.Background(), query, limit)
+	return memories, clientError(err)
 }
 
-func (api *MemoryAPI) DeleteMemory(id string) error {
-	req, err := http.NewRequest("DELETE", api.buildURL("/delete/"+id), nil)
-	if err != nil {
+func (api *MemoryAPI) DeleteMemory(memory Memory) error {
+	id := memory.ID
+	method, endpoint := api.deleteEndpoint(id)
+	if err := api.refuseReadOnly(); err != nil {
 		return err
 	}
-
-	resp, err := api.Client.Do(req)
-	if err != nil {
-		return err
+	if api.skipDryRun(method, endpoint, nil) {
+		return nil
 	}
-	defer resp.Body.Close()
 
-	var apiResp APIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
-		return err
+	client := api.memories()
+	del := client.Delete
+	if api.SoftDelete {
+		del = client.Trash
 	}
-
-	if apiResp.Error != "" {
-		return fmt.Errorf("API error: %s", apiResp.Error)
+	if err := del(context.Background(), id); err != nil {
+		return clientError(err)
 	}
 
+	action := "delete"
+	if api.SoftDelete {
+		action = "trash"
+	}
+	api.recordAudit(action, id, memory.Memory, "")
+	runHook(api.Hooks, hookMemoryDeleted, map[string]interface{}{"id": id})
 	return nil
 }
 
@@ -230,6 +199,9 @@ var (
 			BorderForeground(lipgloss.Color("63")).
 			Padding(1, 2)
 
+	loginErrorStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#FF5F87"))
+
 	promptBoxStyle = lipgloss.NewStyle().
 			Border(lipgloss.RoundedBorder()).
 			BorderForeground(lipgloss.Color("#626262")).
@@ -263,32 +235,34 @@ var (
 // List Item for memories
 type memoryItem struct {
 	memory Memory
+	marked bool     // Part of the selection for bulk actions
+	issues []string // Found by /lint, see lint.go
 }
 
 func (i memoryItem) FilterValue() string { return i.memory.Memory }
-func (i memoryItem) Title() string       { return truncateString(i.memory.Memory, 50) }
+func (i memoryItem) Title() string {
+	if i.marked {
+		return "● " + truncateString(i.memory.Memory, 48)
+	}
+	return truncateString(i.memory.Memory, 50)
+}
 func (i memoryItem) Description() string {
-	return fmt.Sprintf("ID: %s | Created: %s", 
+	desc := tr("ID: %s | Created: %s", 
 		truncateString(i.memory.ID, 20), 
-		formatTime(i.memory.CreatedAt))
+		listTime(i.memory.CreatedAt))
+	if len(i.issues) > 0 {
+		desc += " | ⚠ " + strings.Join(i.issues, ", ")
+	}
+	return desc
 }
 
+// truncateString cuts s to maxLen terminal columns, emoji and CJK characters
+// taking two
 func truncateString(s string, maxLen int) string {
-	if len(s) <= maxLen {
+	if runewidth.StringWidth(s) <= maxLen {
 		return s
 	}
-	return s[:maxLen-3] + "..."
-}
-
-func formatTime(timeStr string) string {
-	if timeStr == "" {
-		return "Unknown"
-	}
-	t, err := time.Parse(time.RFC3339, timeStr)
-	if err != nil {
-		return timeStr[:10] // Return first 10 chars if parsing fails
-	}
-	return t.Format("2006-01-02 15:04")
+	return runewidth.Truncate(s, maxLen, "...")
 }
 
 // Application states
@@ -297,11 +271,38 @@ type viewState int
 const (
 	connectingView viewState = iota
 	loginView
+	passphraseView
+	lockView
 	listView
 	detailView
 	addView
 	searchView
 	confirmDeleteView
+	templateView
+	chunkPreviewView
+	importView
+	batchView
+	logsView
+	auditView
+	mapView
+	pluginOutputView
+	helpView
+	redactView
+	secretView
+	confirmMergeView
+	askView
+	digestView
+	timersView
+	mailView
+	newsView
+	whoView
+	pinsView
+	scratchView
+	runConfirmView
+	runOutputView
+	applyView
+	filesView
+	haView
 )
 
 // Focus states for tab navigation
@@ -322,6 +323,27 @@ type model struct {
 	client          *http.Client
 	serverURL       string
 	
+	// Passphrase protecting the credentials file, cached for the session
+	passphraseInput  textinput.Model
+	passphrase       string
+	choosePassphrase bool // Prompting for a new passphrase before saving credentials
+
+	// Idle lock: last key press and the view hidden by the lock
+	lastActivity time.Time
+	lockReturn   viewState
+
+	// Memory waiting for the redaction preview
+	redaction pendingRedaction
+
+	// Flagged texts waiting for a confirmation before being stored
+	secrets secretReview
+
+	// When false only the session cookie is saved in ~/.tom/auth
+	storePassword bool
+
+	// Shown in the list header when the server API version isn't supported
+	versionWarning string
+
 	// Original memory app fields
 	api           *MemoryAPI
 	state         viewState
@@ -339,10 +361,184 @@ type model struct {
 	width         int
 	height        int
 	memToDelete   Memory  // Memory to be deleted (for confirmation)
+
+	// Structured memory templates
+	template       memoryTemplate
+	templateInputs []textinput.Model
+	templateFocus  int
+
+	// Client configuration and spellcheck results for the add view
+	config      config
+	spellText   string
+	spellIssues []spellIssue
+	spellIndex  int
+	editHistory editHistory
+
+	// Paste bursts, and the size of a large paste waiting for a decision
+	paste        pasteDetector
+	pasteConfirm int
+
+	// Memory to open once the list is loaded, from --open
+	openID string
+
+	// Log the changes instead of sending them, from --dry-run or /dryrun
+	dryRun bool
+
+	// Resume the interrupted import once logged in, from --resume
+	resume bool
+
+	// Access log of the memory in the detail view
+	accessLog         *memoryAccessLog
+	accessErr         error
+	accessUnsupported bool
+
+	// Chunking preview for long memories
+	chunkSource  string
+	chunks       []string
+	chunkIndex   int
+	chunkSize    int
+	chunkOverlap bool
+
+	// Import preview
+	importFormat  string
+	importEntries []importEntry
+	importIndex   int
+
+	// IDs of the memories marked in the list
+	marked map[string]bool
+
+	// The list shows the /lint worklist
+	linting bool
+
+	// Words of the loaded memories, and the /filter query the list shows
+	index       *searchIndex
+	filterQuery string
+
+	// Running batch operation and memories pending bulk deletion
+	batch      *batchJob
+	bulkDelete []Memory
+
+	// Memories being merged into the one in the editor
+	merging []Memory
+
+	// Metadata of the memory in the editor, such as the IDs a summary covers
+	composeMetadata map[string]interface{}
+
+	// Last /ask answer
+	askAnswer   askAnswerMsg
+	askViewport viewport.Model
+
+	// Briefing of /digest
+	digest         digestMsg
+	digestViewport viewport.Model
+
+	// Running timers, checked every second while any runs
+	timers       []timer
+	timerCursor  int
+	timerTicking bool
+
+	// Selected shortcut of the /ha picker
+	haCursor int
+
+	// Unread emails of /mail and the last reply drafted by Tom
+	mail       []mailMessage
+	mailCursor int
+	mailDraft  mailDoneMsg
+
+	// Unread articles of /news and the summaries Tom gave
+	news          []newsItem
+	newsCursor    int
+	newsSummaries map[answerID]string
+
+	// Profile card of /who
+	profile         whoProfile
+	profileViewport viewport.Model
+
+	// Bookmarked memories by letter, the key awaiting its letter and the
+	// memory selected before the last jump
+	bookmarks       map[rune]string
+	bookmarkPending rune
+	lastJump        string
+
+	// Code blocks of the answers saved to scratchDir, listed by /scratch
+	scratch         bool
+	scratchDir      string
+	scratchFiles    []scratchFile
+	scratchCursor   int
+	scratchViewport viewport.Model
+
+	// Code block /run confirms and runs, and the output of the last run
+	runBlock        scratchFile
+	runArgv         []string // The interpreter, run on a copy of runCode
+	runCode         string   // As shown on the confirmation, what runs
+	runCodeViewport viewport.Model
+	runResult       runResult
+	runViewport     viewport.Model
+
+	// Diff /apply previews, checked against the files
+	applyBlock    scratchFile
+	applyDiff     string
+	applyPatches  []filePatch
+	applyViewport viewport.Model
+
+	// Listing or file shown by /ls or /cat, and the ones included in the
+	// next prompt to Tom
+	file         fileContext
+	fileViewport viewport.Model
+	fileContext  []fileContext
+
+	// .tom-context of the working directory, see project.go
+	project fileContext
+
+	// Globs /watch watches, the files as of the last scan and as last told
+	// to Tom
+	watches      []string
+	watchSeen    map[string]fileStamp
+	watchTold    map[string]fileStamp
+	watchTicking bool
+
+	// Pins listed by /pins and the query they hold
+	pins        []pin
+	pinQuery    string
+	pinCursor   int
+	pinViewport viewport.Model
+
+	// Focus reported by the terminal and start of the running request, for
+	// the bell
+	unfocused bool
+	busySince time.Time
+
+	// End of the server rate limit window, counted down in the status bar
+	rateLimitedUntil time.Time
+
+	// Log viewer
+	logViewport viewport.Model
+	logLines    []string
+	logLevel    int  // Minimum level shown, index in logLevels
+	logFollow   bool // Keep the newest lines in view
+
+	// Memory map
+	mapPoints  []mapPoint
+	mapThemes  [][]string // Frequent words of each cluster
+	mapCluster int        // Selected cluster
+	mapSource  string     // "embeddings" or "searches"
+
+	// Help overlay and the view it was opened from
+	helpViewport viewport.Model
+	helpReturn   viewState
+	helpTitle    string
+
+	// Audit log viewer
+	auditViewport viewport.Model
+	auditEntries  []auditEntry
+
+	// Output of the last plugin run
+	pluginName     string
+	pluginViewport viewport.Model
 }
 
 func (m model) Init() tea.Cmd {
-	return tea.Batch(m.spinner.Tick, checkAuth)
+	return tea.Batch(m.spinner.Tick, attachOrCheckAuth, m.idleTick(), checkTimers)
 }
 
 func (m model) loadMemories() tea.Cmd {
@@ -358,10 +554,13 @@ func (m model) loadMemories() tea.Cmd {
 type memoriesLoadedMsg struct{ memories []Memory }
 type memoryAddedMsg struct{}
 type memoryDeletedMsg struct{}
-type searchResultsMsg struct{ memories []Memory }
+type searchResultsMsg struct {
+	memories []Memory
+	local    bool // From the local index, the server being unreachable
+}
 type errMsg struct{ error }
 
-func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+func (m model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 
 	// Handle authentication messages
@@ -376,6 +575,10 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if msg.useSession && msg.sessionCookie != "" {
 				// Use session cookie for authentication
 				return m, sessionLogin(m, msg.sessionCookie)
+			} else if msg.password == "" {
+				// Only the session was stored and it expired
+				m.err = errSessionExpired
+				return m.relogin(), nil
 			} else {
 				// Use username/password for authentication
 				return m, login(m)
@@ -385,16 +588,49 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 
+	case controlMsg:
+		return m.handleControl(msg)
+
+	case daemonAttachedMsg:
+		return m.attachDaemon(msg.daemonResponse)
+
+	case passphraseMsg:
+		m.state = passphraseView
+		m.choosePassphrase = false
+		m.passphrase = ""
+		m.passphraseInput.Reset()
+		m.passphraseInput.Focus()
+		if msg.error != errPassphraseRequired {
+			m.err = msg.error
+		}
+		return m, textinput.Blink
+
 	case loginSuccessMsg:
 		m.err = nil
-		m.state = listView
+		m.showView(listView)
 		m.serverURL = m.serverInput.Value()
+		logf("INFO", "logged in to %s as %s", m.serverURL, m.usernameInput.Value())
 		
 		// Initialize API client with the authenticated server URL and reuse the auth client
 		m.api = NewMemoryAPIWithClient(m.serverURL, m.client)
+		m.api.Hooks = m.config.Hooks
+		m.api.DryRun = m.dryRun
+		m.api.ReadOnly = m.config.ReadOnly
+		m.setScratch(m.scratch)
 		
 		m.loading = true
-		return m, m.loadMemories()
+		return m, tea.Batch(m.loadMemories(), m.checkServerVersion())
+
+	case serverInfoMsg:
+		if msg.err != nil {
+			// Not fatal: the memory API may still work with an unknown server
+			m.versionWarning = tr("Could not check the server API version: %v", msg.err)
+			return m, nil
+		}
+		m.api.applyServerInfo(msg.info)
+		m.refreshPromptCommands()
+		m.versionWarning = versionWarning(msg.info.APIVersion)
+		return m, nil
 
 	case disconnectMsg:
 		m.state = loginView
@@ -407,11 +643,13 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case errorMsg:
 		m.err = msg
+		logf("WARN", "%v", msg)
 		if m.state == connectingView {
-			return m, tea.Batch(m.spinner.Tick, func() tea.Msg {
-				time.Sleep(3 * time.Second)
-				return checkAuth()
-			})
+			// Back to the form, keeping what was typed, so the user can retry
+			m.state = loginView
+			if !m.usernameInput.Focused() && !m.passwordInput.Focused() && !m.serverInput.Focused() {
+				m.usernameInput.Focus()
+			}
 		}
 		return m, nil
 	}
@@ -426,11 +664,24 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 		
+		if m.state == passphraseView {
+			return m.updatePassphraseView(msg)
+		}
+
 		if m.state == loginView {
 			switch msg.Type {
 			case tea.KeyCtrlC:
 				return m, tea.Quit
 			case tea.KeyEnter:
+				m.err = nil
+				if m.config.EncryptCredentials && m.passphrase == "" {
+					// Ask for the passphrase that will encrypt the saved credentials
+					m.state = passphraseView
+					m.choosePassphrase = true
+					m.passphraseInput.Reset()
+					m.passphraseInput.Focus()
+					return m, textinput.Blink
+				}
 				m.state = connectingView
 				m.serverInput.SetValue(strings.TrimSuffix(m.serverInput.Value(), "/"))
 				return m, tea.Batch(m.spinner.Tick, login(m))
@@ -462,16 +713,42 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 
+		// Keys of a paste are text, even Enter and Tab
+		if m.takesPaste() {
+			if pasting, settle := m.paste.observe(msg); pasting {
+				updated, cmd := m.handlePasteKey(msg)
+				return updated, tea.Batch(cmd, settle)
+			}
+		}
+
+		// Tab completes an emoji shortcode, then the prompt command
+		if msg.String() == "tab" && (m.focus == focusPrompt || m.state == addView) {
+			before := m.textArea.Value()
+			if m.completeShortcode() {
+				if m.focus == focusContent {
+					m.editHistory.record(before, false)
+				}
+				return m, nil
+			}
+		}
+		if msg.String() == "tab" && m.focus == focusPrompt {
+			if completion, ok := completePrompt(m.api, m.promptInput.Value()); ok {
+				m.promptInput.SetValue(completion)
+				m.promptInput.CursorEnd()
+				return m, nil
+			}
+		}
+
 		// Handle global Tab navigation
 		if msg.String() == "tab" {
 			if m.focus == focusContent {
-				m.focus = focusPrompt
-				m.promptInput.Focus()
-			} else {
-				m.focus = focusContent
-				m.promptInput.Blur()
+				return m, m.setFocus(focusPrompt)
 			}
-			return m, nil
+			return m, m.setFocus(focusContent)
+		}
+
+		if m.opensHelp(msg) {
+			return m.openHelp()
 		}
 
 		// Handle prompt commands when focused
@@ -480,72 +757,232 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case "enter":
 				return m.handlePromptCommand()
 			case "esc":
-				m.focus = focusContent
-				m.promptInput.Blur()
-				return m, nil
+				return m, m.setFocus(focusContent)
 			default:
 				m.promptInput, cmd = m.promptInput.Update(msg)
+				if msg.String() == ":" {
+					m.expandShortcode()
+				}
 				return m, cmd
 			}
 		}
 
 		// Handle content area updates when focused
-		switch m.state {
-		case listView:
-			return m.updateListView(msg)
-		case detailView:
-			return m.updateDetailView(msg)
-		case addView:
-			return m.updateAddView(msg)
-		case searchView:
-			return m.updateSearchView(msg)
-		case confirmDeleteView:
-			return m.updateConfirmDeleteView(msg)
+		if c, ok := viewComponents[m.state]; ok {
+			return c.update(m, msg)
 		}
 
 	case memoriesLoadedMsg:
 		m.loading = false
 		m.memories = msg.memories
-		items := make([]list.Item, len(msg.memories))
-		for i, mem := range msg.memories {
-			items[i] = memoryItem{memory: mem}
-		}
+		items := m.memoryItems(msg.memories)
 		// Force complete list recreation to ensure clean display
 		m.list.SetItems([]list.Item{}) // Clear first
 		m.list.SetItems(items)         // Then set new items
 		m.list.ResetSelected()         // Reset selection
-		m.message = fmt.Sprintf("Loaded %d memories", len(msg.memories))
+		m.message = tr("Loaded %d memories", len(msg.memories))
+		if m.linting {
+			m.applyLint()
+		}
+		index := m.index.refresh(msg.memories)
+		if m.openID != "" {
+			// Memory asked with --open, once logged in
+			id := m.openID
+			m.openID = ""
+			m.loading = true
+			return m, tea.Batch(tea.ClearScreen, m.gotoMemory(id), index)
+		}
+		if m.resume {
+			// Import interrupted, resumed with --resume once logged in
+			m.resume = false
+			updated, cmd := m.resumeImport()
+			return updated, tea.Batch(tea.ClearScreen, cmd, index)
+		}
 		// Force a complete screen redraw
-		return m, tea.ClearScreen
+		return m, tea.Batch(tea.ClearScreen, index)
+
+	case indexUpdatedMsg:
+		if m.filterQuery != "" && m.state == listView {
+			m.applyFilter()
+		}
+		return m, nil
+
+	case memoryFetchedMsg:
+		return m.handleMemoryFetched(msg)
+
+	case accessLogMsg:
+		return m.handleAccessLog(msg)
+
+	case pasteSettledMsg:
+		return m.handlePasteSettled()
+
+	case mergeSummaryMsg:
+		m.loading = false
+		return m.openMerge(msg.summary, m.merging)
+
+	case mergedMsg:
+		return m.handleMerged()
+
+	case summaryMsg:
+		return m.openSummary(msg)
+
+	case askAnswerMsg:
+		return m.openAnswer(msg)
+
+	case digestMsg:
+		return m.openDigest(msg)
+
+	case mailLoadedMsg:
+		return m.openMail(msg)
+
+	case mailDoneMsg:
+		return m.handleMailDone(msg)
+
+	case newsLoadedMsg:
+		return m.openNews(msg)
+
+	case newsDoneMsg:
+		return m.handleNewsDone(msg)
+
+	case whoMsg:
+		return m.openProfile(msg)
+
+	case pinsExportedMsg:
+		logf("INFO", "exported %d pins to %s", msg.count, msg.target)
+		m.message = tr("Exported %d pins to %s", msg.count, msg.target)
+		return m, nil
+
+	case scratchEditedMsg:
+		if msg.err != nil {
+			m.err = msg.err
+		}
+		m.layoutScratch()
+		return m, nil
+
+	case runDoneMsg:
+		return m.handleRunDone(msg)
+
+	case runFeedbackMsg:
+		return m.openAnswer(askAnswerMsg{question: tr("Output of block %d", msg.block), answer: msg.answer})
+
+	case watchTickMsg:
+		return m.handleWatchTick()
+
+	case watchScanMsg:
+		return m.handleWatchScan(msg)
+
+	case watchToldMsg:
+		return m.handleWatchTold(msg)
+
+	case timerTickMsg:
+		return m, checkTimers
+
+	case timersFiredMsg:
+		return m.handleTimersFired(msg)
 
 	case memoryAddedMsg:
+		logf("INFO", "memory added")
 		m.loading = false
-		m.state = listView
-		m.message = "Memory added successfully"
+		m.showView(listView)
+		m.message = tr("Memory added successfully") + m.dryRunNote()
 		return m, m.loadMemories()
 
+	case importLoadedMsg:
+		m.loading = false
+		m.importFormat = msg.format
+		m.importEntries = msg.entries
+		m.importIndex = 0
+		m.showView(importView)
+		return m, nil
+
+	case exportDoneMsg:
+		logf("INFO", "exported %d memories to %s", msg.count, msg.target)
+		m.loading = false
+		m.message = tr("Exported %d memories to %s", msg.count, msg.target)
+		return m, nil
+
+	case batchStepMsg:
+		return m.handleBatchStep(msg)
+
+	case rateLimitedMsg:
+		logf("WARN", "rate limited by the server until %s", msg.until.Format("15:04:05"))
+		ticking := m.rateLimitStatus() != ""
+		m.rateLimitedUntil = msg.until
+		if ticking {
+			return m, nil
+		}
+		return m, rateLimitTick()
+
+	case rateLimitTickMsg:
+		if m.rateLimitStatus() == "" {
+			return m, nil
+		}
+		return m, rateLimitTick()
+
+	case pluginOutputMsg:
+		return m.handlePluginOutput(msg)
+
+	case logsLoadedMsg:
+		return m.handleLogsLoaded(msg)
+
+	case mapLayoutMsg:
+		return m.handleMapLayout(msg)
+
+	case auditLoadedMsg:
+		return m.handleAuditLoaded(msg)
+
+	case logTickMsg:
+		if m.state != logsView {
+			return m, nil
+		}
+		return m, tea.Batch(loadLogTail, logTick())
+
 	case memoryDeletedMsg:
+		logf("INFO", "memory deleted")
+		m.loading = false
+		m.message = tr("Memory deleted successfully") + m.dryRunNote()
+		if m.api.SoftDelete {
+			m.message = tr("Memory moved to the server trash") + m.dryRunNote()
+		}
+		return m, m.loadMemories()
+
+	case memoryRestoredMsg:
+		logf("INFO", "memory %s restored", msg.id)
 		m.loading = false
-		m.message = "Memory deleted successfully"
+		m.message = tr("Memory %s restored", msg.id) + m.dryRunNote()
 		return m, m.loadMemories()
 
+	case spellcheckMsg:
+		m.loading = false
+		m.spellText = msg.text
+		m.spellIssues = msg.issues
+		m.spellIndex = 0
+		return m, nil
+
 	case searchResultsMsg:
 		m.loading = false
-		items := make([]list.Item, len(msg.memories))
-		for i, mem := range msg.memories {
-			items[i] = memoryItem{memory: mem}
-		}
+		m.linting = false
+		m.filterQuery = ""
+		items := m.memoryItems(msg.memories)
 		// Force complete list recreation to ensure clean display
 		m.list.SetItems([]list.Item{}) // Clear first
 		m.list.SetItems(items)         // Then set new items
 		m.list.ResetSelected()         // Reset selection
-		m.message = fmt.Sprintf("Found %d memories", len(msg.memories))
+		m.message = tr("Found %d memories", len(msg.memories))
+		if msg.local {
+			m.message = tr("Server unreachable, found %d memories in the local index", len(msg.memories))
+		}
+		m.showView(listView)
 		// Force a complete screen redraw
 		return m, tea.ClearScreen
 
 	case errMsg:
 		m.loading = false
-		m.err = msg.error
+		m.err = friendlyError(msg.error)
+		logf("ERROR", "%v", msg.error)
+		if errors.Is(msg.error, errSessionExpired) {
+			return m.relogin(), nil
+		}
 		return m, nil
 
 	case tea.WindowSizeMsg:
@@ -556,6 +993,23 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.textArea.SetWidth(msg.Width - 8) // Adjust for box padding and borders
 		m.searchInput.Width = msg.Width - 20 // Adjust for box padding and "Command: " text
 		m.promptInput.Width = msg.Width - 20 // Adjust for box padding and "Command: " text
+		m.logViewport.Width = max(20, msg.Width-8)
+		m.logViewport.Height = max(5, msg.Height-12)
+		m.helpViewport.Width = max(20, msg.Width-8)
+		m.helpViewport.Height = max(5, msg.Height-8)
+		m.auditViewport.Width = max(20, msg.Width-8)
+		m.auditViewport.Height = max(5, msg.Height-12)
+		m.pluginViewport.Width = max(20, msg.Width-8)
+		m.pluginViewport.Height = max(5, msg.Height-12)
+		m.layoutAnswer()
+		m.layoutDigest()
+		m.layoutProfile()
+		m.layoutPin()
+		m.layoutScratch()
+		m.layoutRunConfirm()
+		m.layoutRunOutput()
+		m.layoutApply()
+		m.layoutFile()
 		// Force a refresh of the list display when window size changes
 		if m.state == listView {
 			m.list.ResetSelected()
@@ -571,105 +1025,61 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	}
 
 	// Update the active component
-	switch m.state {
-	case listView:
-		m.list, cmd = m.list.Update(msg)
-	case addView:
-		m.textArea, cmd = m.textArea.Update(msg)
-	case searchView:
-		m.searchInput, cmd = m.searchInput.Update(msg)
-	case confirmDeleteView:
-		// No component to update in confirmation view
+	if c := viewComponents[m.state]; c.forward != nil {
+		m, cmd = c.forward(m, msg)
 	}
 
 	return m, cmd
 }
 
-// Handle prompt commands
-func (m model) handlePromptCommand() (tea.Model, tea.Cmd) {
-	command := strings.TrimSpace(m.promptInput.Value())
-	m.promptInput.SetValue("")
-
-	if command == "" {
-		return m, nil
+func (m model) updateListView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	// The filter input takes every key while it is open
+	if m.list.FilterState() == list.Filtering {
+		m.list, cmd = m.list.Update(msg)
+		return m, cmd
 	}
-
-	// Split command and arguments
-	parts := strings.SplitN(command, " ", 2)
-	cmd := parts[0]
-	var args string
-	if len(parts) > 1 {
-		args = strings.TrimSpace(parts[1])
+	if m.bookmarkPending != 0 {
+		return m.bookmarkKey(msg)
 	}
 
-	switch cmd {
-	case "/quit", "/q":
-		return m, tea.Quit
-	case "/add", "/a":
-		if args == "" {
-			m.message = "Usage: /add YOUR_MEMORY_TEXT"
-			return m, nil
-		}
-		m.loading = true
-		m.focus = focusContent
-		m.promptInput.Blur()
-		return m, tea.Cmd(func() tea.Msg {
-			err := m.api.AddMemory(args, nil)
-			if err != nil {
-				return errMsg{err}
-			}
-			return memoryAddedMsg{}
-		})
-	case "/search", "/s":
-		if args == "" {
-			m.message = "Usage: /search YOUR_SEARCH_QUERY"
-			return m, nil
-		}
-		m.loading = true
-		m.focus = focusContent
-		m.promptInput.Blur()
-		return m, tea.Cmd(func() tea.Msg {
-			results, err := m.api.SearchMemories(args, 20)
-			if err != nil {
-				return errMsg{err}
-			}
-			return searchResultsMsg{results}
-		})
-	case "/refresh", "/r":
-		m.loading = true
-		m.message = "Refreshing..."
-		m.focus = focusContent
-		m.promptInput.Blur()
-		return m, m.loadMemories()
-	case "/disconnect", "/logout":
-		return m, disconnect
-	default:
-		m.message = fmt.Sprintf("Unknown command: %s. Available: /quit /add TEXT /search QUERY /refresh /disconnect", cmd)
-		return m, nil
-	}
-}
-
-func (m model) updateListView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "q", "ctrl+c":
 		return m, tea.Quit
+	case "m":
+		m.bookmarkPending = 'm'
+		m.message = tr("m: press a letter to bookmark the memory")
+		return m, nil
+	case "'":
+		m.bookmarkPending = '\''
+		m.message = tr("': press the letter of a bookmark, or ' to go back")
+		return m, nil
+	case "a":
+		return m.openComposer("")
+	case "/":
+		m.searchInput.Reset()
+		return m, m.showView(searchView)
 	case "enter":
-		if len(m.memories) > 0 {
-			selected := m.list.SelectedItem().(memoryItem)
-			m.currentMem = selected.memory
-			m.state = detailView
+		// Nothing is selected when /filter matches nothing
+		if selected, ok := m.list.SelectedItem().(memoryItem); ok {
+			return m.openDetail(selected.memory)
 		}
 		return m, nil
 	case "delete", "backspace":
-		if len(m.memories) > 0 {
-			selected := m.list.SelectedItem().(memoryItem)
+		if m.readOnly() {
+			m.err = readOnlyError()
+			return m, nil
+		}
+		if selected, ok := m.list.SelectedItem().(memoryItem); ok {
 			m.memToDelete = selected.memory
-			m.state = confirmDeleteView
+			m.showView(confirmDeleteView)
 		}
 		return m, nil
+	case " ":
+		m.toggleMark()
+		return m, nil
 	}
 
-	var cmd tea.Cmd
 	m.list, cmd = m.list.Update(msg)
 	return m, cmd
 }
@@ -677,34 +1087,65 @@ func (m model) updateListView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 func (m model) updateDetailView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "q", "esc":
-		m.state = listView
+		m.showView(listView)
 		return m, nil
 	}
 	return m, nil
 }
 
 func (m model) updateAddView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.pasteConfirm > 0 {
+		return m.updatePasteConfirm(msg)
+	}
 	switch msg.String() {
 	case "ctrl+s":
+		if strings.TrimSpace(m.textArea.Value()) == "" {
+			return m, nil
+		}
+		if len(m.merging) > 0 {
+			m.showView(confirmMergeView)
+			return m, nil
+		}
+		return m.submitMemory(m.textArea.Value(), m.composeMetadata, true)
+	case "esc":
+		m.showView(listView)
+		return m, nil
+	case "ctrl+k":
+		if !m.config.Spellcheck {
+			m.message = tr("Spellcheck is disabled, set \"spellcheck\": true in ~/.tom/memory-tui.json")
+			return m, nil
+		}
 		if strings.TrimSpace(m.textArea.Value()) != "" {
 			m.loading = true
-			text := m.textArea.Value()
-			return m, tea.Cmd(func() tea.Msg {
-				err := m.api.AddMemory(text, nil)
-				if err != nil {
-					return errMsg{err}
-				}
-				return memoryAddedMsg{}
-			})
+			return m, m.spellcheck()
 		}
 		return m, nil
-	case "esc":
-		m.state = listView
+	case "ctrl+r":
+		m.applySuggestion()
+		return m, nil
+	case "ctrl+n":
+		if len(m.spellIssues) > 0 {
+			m.spellIndex = (m.spellIndex + 1) % len(m.spellIssues)
+		}
+		return m, nil
+	case "ctrl+z":
+		m.undoEdit()
+		return m, nil
+	case "ctrl+y", "ctrl+shift+z":
+		// Most terminals send Ctrl+Shift+Z as Ctrl+Z, hence Ctrl+Y
+		m.redoEdit()
 		return m, nil
 	}
 
+	before := m.textArea.Value()
 	var cmd tea.Cmd
 	m.textArea, cmd = m.textArea.Update(msg)
+	if msg.String() == ":" {
+		m.expandShortcode()
+	}
+	if m.textArea.Value() != before {
+		m.editHistory.record(before, m.groupEdit(msg))
+	}
 	return m, cmd
 }
 
@@ -713,18 +1154,11 @@ func (m model) updateSearchView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "enter":
 		if strings.TrimSpace(m.searchInput.Value()) != "" {
 			m.loading = true
-			query := m.searchInput.Value()
-			return m, tea.Cmd(func() tea.Msg {
-				results, err := m.api.SearchMemories(query, 20)
-				if err != nil {
-					return errMsg{err}
-				}
-				return searchResultsMsg{results}
-			})
+			return m, m.searchMemories(m.searchInput.Value())
 		}
 		return m, nil
 	case "esc":
-		m.state = listView
+		m.showView(listView)
 		return m, nil
 	}
 
@@ -736,17 +1170,23 @@ func (m model) updateSearchView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 func (m model) updateConfirmDeleteView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "y", "Y":
+		if len(m.bulkDelete) > 0 {
+			memories, api := m.bulkDelete, m.api.Background(timeoutBulk)
+			m.bulkDelete = nil
+			return m.startBatch(api.deleteJob(tr("Deleting memories"), memories))
+		}
 		m.loading = true
-		m.state = listView
+		m.showView(listView)
 		return m, tea.Cmd(func() tea.Msg {
-			err := m.api.DeleteMemory(m.memToDelete.ID)
+			err := m.api.DeleteMemory(m.memToDelete)
 			if err != nil {
 				return errMsg{err}
 			}
 			return memoryDeletedMsg{}
 		})
 	case "n", "N", "esc":
-		m.state = listView
+		m.showView(listView)
+		m.bulkDelete = nil
 		return m, nil
 	}
 	return m, nil
@@ -754,65 +1194,70 @@ func (m model) updateConfirmDeleteView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 func (m model) View() string {
 	// Handle authentication views first
-	if m.err != nil && (m.state == connectingView || m.state == loginView) {
-		return fmt.Sprintf("\nError: %v\n\nPress Ctrl+C to quit.\n", m.err)
-	}
-
 	if m.state == connectingView {
 		var s strings.Builder
 		s.WriteString(m.spinner.View())
-		s.WriteString(" Connecting to server...")
-		if m.err != nil {
-			s.WriteString("\n\nConnection failed. Retrying...")
-		}
+		s.WriteString(" " + tr("Connecting to server..."))
 		ui := loginBoxStyle.Render(s.String())
 		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, ui)
 	}
 
+	if m.state == passphraseView {
+		return m.renderPassphraseView()
+	}
+
+	if m.state == lockView {
+		return m.renderLockView()
+	}
+
 	if m.state == loginView {
 		var b strings.Builder
-		b.WriteString("Memory Manager Login\n\n")
+		b.WriteString(tr("Memory Manager Login") + "\n\n")
 		b.WriteString(m.usernameInput.View())
 		b.WriteString("\n")
 		b.WriteString(m.passwordInput.View())
 		b.WriteString("\n")
 		b.WriteString(m.serverInput.View())
-		b.WriteString("\n\n(tab to switch, enter to login)")
+		if m.err != nil {
+			b.WriteString("\n\n")
+			b.WriteString(loginErrorStyle.Render(wrapText("❌ "+m.err.Error(), 50)))
+		}
+		b.WriteString("\n\n" + tr("(tab to switch, enter to login, ctrl+c to quit)"))
 		ui := loginBoxStyle.Render(b.String())
 		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, ui)
 	}
 
 	if m.loading {
-		return "\n  Loading...\n\n"
+		if status := m.rateLimitStatus(); status != "" {
+			return "\n  " + tr("Loading...") + "\n\n  " + status + "\n"
+		}
+		if status := transferStatus(); status != "" {
+			return "\n  " + tr("Loading...") + "\n\n  " + helpStyle.Render(status) + "\n"
+		}
+		return "\n  " + tr("Loading...") + "\n\n"
 	}
 
 	var content string
-
-	switch m.state {
-	case listView:
-		content = m.renderListView()
-	case detailView:
-		content = m.renderDetailModal()
-	case addView:
-		content = m.renderAddView()
-	case searchView:
-		content = m.renderSearchView()
-	case confirmDeleteView:
-		content = m.renderConfirmDeleteModal()
+	c := viewComponents[m.state]
+	if c.render != nil {
+		content = c.render(m)
 	}
 
 	// Add status messages
 	statusBar := ""
 	if m.err != nil {
-		statusBar = fmt.Sprintf("❌ Error: %v", m.err)
+		statusBar = tr("❌ Error: %v", tr(m.err.Error()))
 		m.err = nil // Clear error after showing
 	} else if m.message != "" {
 		statusBar = fmt.Sprintf("✅ %s", m.message)
 		m.message = "" // Clear message after showing
 	}
+	if status := m.rateLimitStatus(); status != "" {
+		statusBar = strings.TrimSpace(status + "\n" + statusBar)
+	}
 
 	// For modal states, don't show prompt box
-	if m.state == detailView || m.state == confirmDeleteView {
+	if c.modal {
 		if statusBar != "" {
 			return content + "\n\n" + statusBar
 		}
@@ -839,10 +1284,15 @@ func (m model) renderPromptBox() string {
 
 	promptText := m.promptInput.View()
 	if promptText == "" && m.focus != focusPrompt {
-		promptText = "Press Tab to focus, then type: /quit /add TEXT /search QUERY /refresh /disconnect"
+		promptText = tr("Press Tab to focus, then type: ") + promptCommandsSummary(m.api)
+	}
+	if m.focus == focusPrompt {
+		if emoji := m.renderEmojiSuggestions(); emoji != "" {
+			promptText += "\n" + emoji
+		}
 	}
 
-	return style.Render("Command: " + promptText)
+	return style.Render(tr("Command: ") + promptText)
 }
 
 func (m model) renderListView() string {
@@ -853,8 +1303,22 @@ func (m model) renderListView() string {
 		style = contentBoxStyle.Width(m.width - 4) // Full width minus small margins
 	}
 
-	title := titleStyle.Render("🧠 Tom Memory Manager")
-	help := helpStyle.Render("📝 Memory Manager | Tab: switch focus | Enter: view detail | Del: delete")
+	title := titleStyle.Render(tr("🧠 Tom Memory Manager"))
+	ver, _, _ := buildInfo()
+	if m.api != nil && m.api.APIVersion > 0 {
+		ver += fmt.Sprintf(" • API v%d", m.api.APIVersion)
+	}
+	if m.dryRun {
+		ver += " • " + meterWarningStyle.Render("DRY RUN")
+	}
+	if m.readOnly() {
+		ver += " • " + meterWarningStyle.Render(tr("READ-ONLY"))
+	}
+	title += " " + helpStyle.Render(ver)
+	if m.versionWarning != "" {
+		title += " " + loginErrorStyle.Render("⚠ "+m.versionWarning)
+	}
+	help := m.keyHint(tr("📝 Memory Manager"))
 	
 	// Get the list view
 	listView := m.list.View()
@@ -898,14 +1362,14 @@ func (m model) renderDetailModal() string {
 	modalWidth := min(80, m.width-10) // Max 80 chars wide, but leave margin
 	
 	var b strings.Builder
-	b.WriteString(titleStyle.Render("📖 Memory Details"))
+	b.WriteString(titleStyle.Render(tr("📖 Memory Details")))
 	b.WriteString("\n\n")
 	
 	b.WriteString(selectedItemStyle.Render("ID: "))
 	b.WriteString(m.currentMem.ID)
 	b.WriteString("\n\n")
 	
-	b.WriteString(selectedItemStyle.Render("Content:"))
+	b.WriteString(selectedItemStyle.Render(tr("Content:")))
 	b.WriteString("\n")
 	// Wrap content to fit modal width
 	memoryContent := m.currentMem.Memory
@@ -916,19 +1380,19 @@ func (m model) renderDetailModal() string {
 	}
 	b.WriteString("\n\n")
 	
-	b.WriteString(selectedItemStyle.Render("Created: "))
+	b.WriteString(selectedItemStyle.Render(tr("Created: ")))
 	b.WriteString(formatTime(m.currentMem.CreatedAt))
 	b.WriteString("\n")
 	
-	b.WriteString(selectedItemStyle.Render("Updated: "))
+	b.WriteString(selectedItemStyle.Render(tr("Updated: ")))
 	if m.currentMem.UpdatedAt != nil {
 		b.WriteString(formatTime(*m.currentMem.UpdatedAt))
 	} else {
-		b.WriteString("Never")
+		b.WriteString(tr("Never"))
 	}
 	b.WriteString("\n")
 	
-	b.WriteString(selectedItemStyle.Render("User: "))
+	b.WriteString(selectedItemStyle.Render(tr("User: ")))
 	b.WriteString(m.currentMem.UserID)
 	b.WriteString("\n")
 	
@@ -937,15 +1401,20 @@ func (m model) renderDetailModal() string {
 	b.WriteString("\n\n")
 	
 	if len(m.currentMem.Metadata) > 0 {
-		b.WriteString(selectedItemStyle.Render("Metadata:"))
+		b.WriteString(selectedItemStyle.Render(tr("Metadata:")))
 		b.WriteString("\n")
 		for k, v := range m.currentMem.Metadata {
 			b.WriteString(fmt.Sprintf("  %s: %v\n", k, v))
 		}
 		b.WriteString("\n")
 	}
+
+	if access := m.renderAccessLog(); access != "" {
+		b.WriteString(access)
+		b.WriteString("\n")
+	}
 	
-	b.WriteString(helpStyle.Render("Esc: close"))
+	b.WriteString(m.keyHint(""))
 	
 	// Center the modal content
 	modalContent := modalStyle.Width(modalWidth).Render(b.String())
@@ -954,7 +1423,7 @@ func (m model) renderDetailModal() string {
 
 // Helper function to wrap text
 func wrapText(text string, width int) string {
-	if len(text) <= width {
+	if runewidth.StringWidth(text) <= width {
 		return text
 	}
 	
@@ -963,7 +1432,7 @@ func wrapText(text string, width int) string {
 	currentLine := ""
 	
 	for _, word := range words {
-		if len(currentLine)+len(word)+1 <= width {
+		if runewidth.StringWidth(currentLine)+runewidth.StringWidth(word)+1 <= width {
 			if currentLine != "" {
 				currentLine += " "
 			}
@@ -983,6 +1452,16 @@ func wrapText(text string, width int) string {
 	return result.String()
 }
 
+// wrapLines wraps each line of text as wrapText does, keeping the line
+// breaks and blank lines that wrapText folds into one paragraph
+func wrapLines(text string, width int) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = wrapText(line, width)
+	}
+	return strings.Join(lines, "\n")
+}
+
 // Helper function for min
 func min(a, b int) int {
 	if a < b {
@@ -991,6 +1470,14 @@ func min(a, b int) int {
 	return b
 }
 
+// Helper function for max
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
 // Helper function to create a blank line of specified width
 func createBlankLine(width int) string {
 	if width <= 0 {
@@ -1017,12 +1504,32 @@ func (m model) renderAddView() string {
 	}
 
 	var b strings.Builder
-	b.WriteString(titleStyle.Render("➕ Add New Memory"))
-	b.WriteString("\n\n")
-	b.WriteString("Enter your memory content:\n\n")
+	if len(m.merging) > 0 {
+		b.WriteString(titleStyle.Render(tr("🔗 Merge %d Memories", len(m.merging))))
+		b.WriteString("\n\n")
+		b.WriteString(tr("Edit the merged memory:") + "\n\n")
+	} else {
+		b.WriteString(titleStyle.Render(tr("➕ Add New Memory")))
+		b.WriteString("\n\n")
+		b.WriteString(tr("Enter your memory content:") + "\n\n")
+	}
 	b.WriteString(m.textArea.View())
+	b.WriteString("\n")
+	if emoji := m.renderEmojiSuggestions(); emoji != "" {
+		b.WriteString(emoji)
+	}
+	b.WriteString("\n")
+	b.WriteString(m.renderDraftMeter(m.textArea.Value()))
 	b.WriteString("\n\n")
-	b.WriteString(helpStyle.Render("Tab: switch focus | Ctrl+S: save | Esc: cancel"))
+	if m.pasteConfirm > 0 {
+		b.WriteString(m.renderPasteConfirm())
+		b.WriteString("\n\n")
+	}
+	if spelling := m.renderSpellcheck(); spelling != "" {
+		b.WriteString(spelling)
+		b.WriteString("\n\n")
+	}
+	b.WriteString(m.keyHint(""))
 	return style.Render(b.String())
 }
 
@@ -1035,12 +1542,12 @@ func (m model) renderSearchView() string {
 	}
 
 	var b strings.Builder
-	b.WriteString(titleStyle.Render("🔍 Search Memories"))
+	b.WriteString(titleStyle.Render(tr("🔍 Search Memories")))
 	b.WriteString("\n\n")
-	b.WriteString("Enter search query:\n\n")
+	b.WriteString(tr("Enter search query:") + "\n\n")
 	b.WriteString(m.searchInput.View())
 	b.WriteString("\n\n")
-	b.WriteString(helpStyle.Render("Tab: switch focus | Enter: search | Esc: cancel"))
+	b.WriteString(m.keyHint(""))
 	return style.Render(b.String())
 }
 
@@ -1048,11 +1555,26 @@ func (m model) renderConfirmDeleteModal() string {
 	modalWidth := min(60, m.width-10) // Max 60 chars wide, but leave margin
 
 	var b strings.Builder
-	b.WriteString(titleStyle.Render("⚠️ Confirm Delete"))
+	b.WriteString(titleStyle.Render(tr("⚠️ Confirm Delete")))
 	b.WriteString("\n\n")
-	b.WriteString("Are you sure you want to delete this memory?\n\n")
+	if len(m.bulkDelete) > 0 {
+		b.WriteString(tr("Are you sure you want to delete these %d memories?", len(m.bulkDelete)) + "\n\n")
+		for i, mem := range m.bulkDelete {
+			if i == 5 {
+				b.WriteString(tr("... and %d more", len(m.bulkDelete)-5) + "\n")
+				break
+			}
+			b.WriteString("• " + truncateString(mem.Memory, modalWidth-10) + "\n")
+		}
+		b.WriteString("\n" + m.deleteWarning() + "\n\n")
+		b.WriteString(m.keyHint(""))
+		modalContent := modalStyle.Width(modalWidth).Render(b.String())
+		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, modalContent)
+	}
+
+	b.WriteString(tr("Are you sure you want to delete this memory?") + "\n\n")
 	
-	b.WriteString(selectedItemStyle.Render("Memory: "))
+	b.WriteString(selectedItemStyle.Render(tr("Memory: ")))
 	// Wrap memory content to fit modal
 	memoryText := m.memToDelete.Memory
 	if len(memoryText) > modalWidth-10 {
@@ -1066,8 +1588,8 @@ func (m model) renderConfirmDeleteModal() string {
 	b.WriteString(truncateString(m.memToDelete.ID, 20))
 	b.WriteString("\n\n")
 	
-	b.WriteString("This action cannot be undone.\n\n")
-	b.WriteString(helpStyle.Render("Y: delete | N: cancel | Esc: cancel"))
+	b.WriteString(m.deleteWarning() + "\n\n")
+	b.WriteString(m.keyHint(""))
 	
 	// Center the modal content
 	modalContent := modalStyle.Width(modalWidth).Render(b.String())
@@ -1083,7 +1605,9 @@ func getAuthFilePath() (string, error) {
 	return filepath.Join(usr, ".tom", "auth"), nil
 }
 
-func saveCredentials(username, password, serverURL, sessionCookie string) error {
+// saveCredentials encrypts the file when a passphrase is given, and only
+// base64-encodes it otherwise
+func saveCredentials(username, password, serverURL, sessionCookie, passphrase string) error {
 	authPath, err := getAuthFilePath()
 	if err != nil {
 		return err
@@ -1100,17 +1624,25 @@ func saveCredentials(username, password, serverURL, sessionCookie string) error
 		SessionCookie: sessionCookie,
 	}
 
-	data, err := json.Marshal(creds)
+	data, err := marshalCredentials(creds)
 	if err != nil {
 		return err
 	}
 
+	if passphrase != "" {
+		encrypted, err := encryptAuth(data, passphrase)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(authPath, encrypted, 0600)
+	}
+
 	encodedData := base64.StdEncoding.EncodeToString(data)
 
 	return os.WriteFile(authPath, []byte(encodedData), 0600)
 }
 
-func loadCredentials() (string, string, string, string, error) {
+func loadCredentials(passphrase string) (string, string, string, string, error) {
 	authPath, err := getAuthFilePath()
 	if err != nil {
 		return "", "", "", "", err
@@ -1121,15 +1653,22 @@ func loadCredentials() (string, string, string, string, error) {
 		return "", "", "", "", err
 	}
 
-	decodedData, err := base64.StdEncoding.DecodeString(string(encodedData))
-	if err != nil {
-		return "", "", "", "", err	
+	decodedData := encodedData
+	if isEncryptedAuth(encodedData) {
+		if decodedData, err = decryptAuth(encodedData, passphrase); err != nil {
+			return "", "", "", "", err
+		}
 	}
 
-	var creds credentials
-	if err := json.Unmarshal(decodedData, &creds); err != nil {
+	creds, legacy, err := parseCredentials(decodedData)
+	if err != nil {
 		return "", "", "", "", err
 	}
+	if legacy {
+		// Rewrite legacy files in the current schema; on failure the legacy
+		// file is simply read again next time
+		_ = saveCredentials(creds.Username, creds.Password, creds.ServerURL, creds.SessionCookie, passphrase)
+	}
 
 	return creds.Username, creds.Password, creds.ServerURL, creds.SessionCookie, nil
 }
@@ -1147,6 +1686,11 @@ func validateSessionCookie(serverURL, sessionCookie string, client *http.Client)
 		return false
 	}
 	
+	// Whatever the timeouts of the configuration, none included
+	bounded := *client
+	bounded.Timeout = sessionCheckTimeout
+	client = &bounded
+
 	// Create a test request to verify the session cookie - use a simple endpoint
 	req, err := http.NewRequest("GET", serverURL+"/status", nil)
 	if err != nil {
@@ -1166,8 +1710,17 @@ func validateSessionCookie(serverURL, sessionCookie string, client *http.Client)
 	return resp.StatusCode == http.StatusOK
 }
 
-func checkAuth() tea.Msg {
-	username, password, serverURL, sessionCookie, err := loadCredentials()
+func checkAuth(passphrase string) tea.Cmd {
+	return func() tea.Msg {
+		return checkStoredAuth(passphrase)
+	}
+}
+
+func checkStoredAuth(passphrase string) tea.Msg {
+	username, password, serverURL, sessionCookie, err := loadCredentials(passphrase)
+	if err == errPassphraseRequired || err == errWrongPassphrase {
+		return passphraseMsg{err}
+	}
 	if err != nil {
 		return autoLoginMsg{} // No credentials, stay on login view
 	}
@@ -1175,8 +1728,8 @@ func checkAuth() tea.Msg {
 	// Create a temporary client to test the session cookie
 	jar, _ := cookiejar.New(nil)
 	client := &http.Client{
-		Jar:     jar,
-		Timeout: 30 * time.Second,
+		Jar:       jar,
+		Transport: userAgentTransport{},
 	}
 	
 	// First, try to use the session cookie if it exists
@@ -1188,6 +1741,18 @@ func checkAuth() tea.Msg {
 	return autoLoginMsg{username, password, serverURL, sessionCookie, false}
 }
 
+// relogin shows the login form with the known username and server, asking
+// only for the password
+func (m model) relogin() model {
+	m.state = loginView
+	m.loading = false
+	m.passwordInput.Reset()
+	m.usernameInput.Blur()
+	m.serverInput.Blur()
+	m.passwordInput.Focus()
+	return m
+}
+
 func disconnect() tea.Msg {
 	if err := deleteCredentials(); err != nil {
 		return errorMsg{fmt.Errorf("failed to disconnect: %w", err)}
@@ -1222,6 +1787,35 @@ func sessionLogin(m model, sessionCookie string) tea.Cmd {
 	}
 }
 
+// postLogin logs in with a password and returns the new session cookie
+func postLogin(client *http.Client, serverURL, username, password string) (string, error) {
+	resp, err := client.PostForm(serverURL+"/login", url.Values{
+		"username": {username},
+		"password": {password},
+	})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	bodyBytes, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("login failed: %s (%s)", resp.Status, string(bodyBytes))
+	}
+	// The server answers bad credentials with a 200 page instead of redirecting
+	if strings.Contains(string(bodyBytes), "Invalid credentials") {
+		return "", fmt.Errorf("login failed: invalid username or password")
+	}
+
+	// Extract session cookie from response
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name == "session_id" {
+			return cookie.String(), nil
+		}
+	}
+	return "", nil
+}
+
 func login(m model) tea.Cmd {
 	return func() tea.Msg {
 		serverURL := m.serverInput.Value()
@@ -1229,30 +1823,16 @@ func login(m model) tea.Cmd {
 			return errorMsg{fmt.Errorf("server URL is required")}
 		}
 
-		resp, err := m.client.PostForm(serverURL+"/login", url.Values{
-			"username": {m.usernameInput.Value()},
-			"password": {m.passwordInput.Value()},
-		})
+		sessionCookie, err := postLogin(m.client, serverURL, m.usernameInput.Value(), m.passwordInput.Value())
 		if err != nil {
 			return errorMsg{err}
 		}
-		defer resp.Body.Close()
 
-		if resp.StatusCode != http.StatusOK {
-			bodyBytes, _ := io.ReadAll(resp.Body)
-			return errorMsg{fmt.Errorf("login failed: %s (%s)", resp.Status, string(bodyBytes))}
-		}
-
-		// Extract session cookie from response
-		sessionCookie := ""
-		for _, cookie := range resp.Cookies() {
-			if cookie.Name == "session_id" {
-				sessionCookie = cookie.String()
-				break
-			}
+		password := m.passwordInput.Value()
+		if !m.storePassword {
+			password = ""
 		}
-
-		if err := saveCredentials(m.usernameInput.Value(), m.passwordInput.Value(), serverURL, sessionCookie); err != nil {
+		if err := saveCredentials(m.usernameInput.Value(), password, serverURL, sessionCookie, m.passphrase); err != nil {
 			return errorMsg{fmt.Errorf("failed to save credentials: %w", err)}
 		}
 
@@ -1261,40 +1841,58 @@ func login(m model) tea.Cmd {
 }
 
 func initialModel() model {
+	// Read first: the language applies to the placeholders below
+	cfg, err := loadConfig()
+	if err != nil {
+		logf("WARN", "failed to load config, using defaults: %v", err)
+	}
+	applyTimeConfig(cfg)
+	applyRedactionConfig(cfg)
+	applyTimeoutConfig(cfg)
+	applyBulkConfig(cfg)
+	applyHAConfig(cfg)
+	setLanguage(cfg.Language)
+	enableLowBandwidth(cfg, lowBandwidth) // Already set by --low-bandwidth
+
 	jar, _ := cookiejar.New(nil)
 	client := &http.Client{
-		Jar:     jar,
-		Timeout: 5 * time.Minute,
+		Jar:       jar,
+		Transport: userAgentTransport{},
 	}
 
 	// Auth inputs
 	username := textinput.New()
-	username.Placeholder = "Username"
+	username.Placeholder = tr("Username")
 	username.Focus()
 	username.Width = 20
 
 	password := textinput.New()
-	password.Placeholder = "Password"
+	password.Placeholder = tr("Password")
 	password.EchoMode = textinput.EchoPassword
 	password.Width = 20
 
 	server := textinput.New()
-	server.Placeholder = "Server URL"
+	server.Placeholder = tr("Server URL")
 	server.Width = 40
 
+	passphrase := textinput.New()
+	passphrase.Placeholder = tr("Passphrase")
+	passphrase.EchoMode = textinput.EchoPassword
+	passphrase.Width = 30
+
 	// Memory app inputs
 	searchInput := textinput.New()
-	searchInput.Placeholder = "Enter search query..."
+	searchInput.Placeholder = tr("Enter search query...")
 	searchInput.Width = 50
 
 	textArea := textarea.New()
-	textArea.Placeholder = "Enter your memory content here..."
+	textArea.Placeholder = tr("Enter your memory content here...")
 	textArea.SetWidth(80)
 	textArea.SetHeight(10)
+	textArea.CharLimit = maxDraftLength
 
-	promptInput := textinput.New()
-	promptInput.Placeholder = "/quit /add TEXT /search QUERY /refresh /disconnect"
-	promptInput.Width = 50
+	loadPlugins()
+	promptInput := newPromptInput(nil)
 
 	// Create list
 	items := []list.Item{}
@@ -1303,13 +1901,18 @@ func initialModel() model {
 	delegate.Styles.SelectedDesc = selectedItemStyle
 
 	memoryList := list.New(items, delegate, 80, 20)
-	memoryList.Title = "Memories"
+	memoryList.Title = tr("Memories")
 	memoryList.SetShowStatusBar(false)
+	// "/" opens the server-side search, the list filter moves to "f"
+	memoryList.KeyMap.Filter = key.NewBinding(key.WithKeys("f"), key.WithHelp("f", "filter"))
 
-	s := spinner.New()
-	s.Spinner = spinner.Dot
+
+	s := newSpinner()
 	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
 
+	scratchDir, _ := scratchSessionDir(time.Now())
+	cwd, _ := os.Getwd()
+
 	return model{
 		// Auth fields
 		spinner:       s,
@@ -1317,6 +1920,8 @@ func initialModel() model {
 		passwordInput: password,
 		serverInput:   server,
 		client:        client,
+
+		passphraseInput: passphrase,
 		
 		// Memory app fields
 		state:       connectingView,
@@ -1326,12 +1931,541 @@ func initialModel() model {
 		textArea:    textArea,
 		promptInput: promptInput,
 		loading:     false,
+		lastActivity: time.Now(),
+		config:      cfg,
+		chunkSize:   cfg.ChunkSize,
+		index:       newSearchIndex(),
+		// Init checks the timers left running, the tick stopping if none is
+		timerTicking: true,
+		scratch:      cfg.Scratch,
+		scratchDir:   scratchDir,
+		project:      loadProjectContext(cwd),
 	}
 }
 
+func rootCommand() *command {
+	root := &command{
+		Name:  "memory-tui",
+		Short: "Browse and edit Tom's memories",
+		Long: "memory-tui is a terminal interface to the memories stored by the Tom assistant.\n\n" +
+			"Without a command it starts the interactive interface, reusing the credentials saved in ~/.tom/auth.",
+		Flags: func(fs *flag.FlagSet) func(args []string) error {
+			noStorePassword := fs.Bool("no-store-password", false, "Save only the session cookie in ~/.tom/auth, never the password")
+			dryRun := fs.Bool("dry-run", false, "Log the adds and deletes, imports and bulk deletes included, instead of sending them")
+			resume := fs.Bool("resume", false, "Resume the interrupted import, skipping the entries already sent")
+			open := fs.String("open", "", "Open the detail of a memory, given as ID or "+memoryURLScheme+"ID, in the running interface or a new one")
+			nvim := fs.Bool("nvim", false, "Serve an editor plugin: JSON requests on stdin, one per line, answered on stdout")
+			lowBW := fs.Bool("low-bandwidth", false, "Redraw less and check less often, for SSH over a slow or mobile link")
+			kiosk := fs.Bool("kiosk", false, "Show the always-on screen of a wall display: clock, digest and the last exchanges with Tom")
+			tokenFile := fs.String("token-file", "", "With --kiosk, credentials file to use instead of the saved ones, in the format of ~/.tom/auth")
+			return func(args []string) error {
+				if len(args) > 0 {
+					return fmt.Errorf("unknown command %q\n\nRun 'memory-tui --help' for usage", args[0])
+				}
+				if *nvim {
+					// stdout carries the protocol only
+					if closeLog, err := setupLogging(); err == nil {
+						defer closeLog()
+					} else {
+						log.SetOutput(io.Discard)
+					}
+					return runNvim(os.Stdin, os.Stdout)
+				}
+				lowBandwidth = *lowBW
+				if *tokenFile != "" && !*kiosk {
+					return errors.New("--token-file needs --kiosk")
+				}
+				if *kiosk {
+					if closeLog, err := setupLogging(); err == nil {
+						defer closeLog()
+					} else {
+						log.SetOutput(io.Discard)
+					}
+					return runKiosk(*tokenFile)
+				}
+				var openID string
+				if *open != "" {
+					id, err := parseMemoryLink(*open)
+					if err != nil {
+						return err
+					}
+					if handled, err := openInRunning(id); handled {
+						return err
+					}
+					openID = id
+				}
+				if closeLog, err := setupLogging(); err == nil {
+					defer closeLog()
+				} else {
+					// Never print over the interface
+					log.SetOutput(io.Discard)
+				}
+				logf("INFO", "starting %s", versionString())
+
+				m := initialModel()
+				m.storePassword = !*noStorePassword
+				m.openID = openID
+				m.dryRun = *dryRun
+				m.resume = *resume
+
+				p := tea.NewProgram(m, programOptions(tea.WithAltScreen())...)
+				defer reportFocus(m.config)()
+				serverRateLimit.notify = func(until time.Time) { p.Send(rateLimitedMsg{until}) }
+
+				// Another running instance keeps the control socket
+				if path, err := controlSocketPath(); err == nil {
+					if listener, err := listenUnix(path); err == nil {
+						defer listener.Close()
+						go serveControl(listener, p)
+					}
+				}
+
+				_, err := p.Run()
+				return err
+			}
+		},
+	}
+	root.Subcommands = []*command{
+		{
+			Name:  "version",
+			Short: "Print the client version and the supported server API versions",
+			Flags: func(fs *flag.FlagSet) func(args []string) error {
+				return func(args []string) error {
+					fmt.Println(versionString())
+					fmt.Printf("Server API: v%d to v%d\n", minAPIVersion, maxAPIVersion)
+					return nil
+				}
+			},
+		},
+		{
+			Name:  "ctl",
+			Args:  "add TEXT | search QUERY | goto ID | refresh | last | run /COMMAND",
+			Short: "Drive the running interface through its control socket",
+			Long: "Drive the running interface through its control socket ~/.tom/memory-tui.sock, " +
+				"e.g. from window manager keybindings.\n\n" +
+				"add, search and goto run the matching prompt command, refresh reloads the list, " +
+				"last prints the last status message and run sends any prompt command.",
+			Flags: func(fs *flag.FlagSet) func(args []string) error {
+				return runControl
+			},
+		},
+		{
+			Name:  "list",
+			Short: "Print the memories as a table, CSV, TSV or JSON",
+			Long: "Print the memories on stdout, to feed awk, column or fzf.\n\n" +
+				"The table truncates the text to fit a terminal unless --no-truncate is given; " +
+				"CSV, TSV and JSON keep the full text and the RFC 3339 dates. " +
+				"TSV puts each memory on a single line. " +
+				"It reuses the credentials saved by the interface, unlocked with TOM_PASSPHRASE when encrypted.",
+			Flags: func(fs *flag.FlagSet) func(args []string) error {
+				format := fs.String("format", "table", "Output format: table, csv, tsv or json")
+				columns := fs.String("columns", "id,created,text", "Comma-separated columns among "+strings.Join(listColumns, ", "))
+				noTruncate := fs.Bool("no-truncate", false, "Print the full text in the table")
+				noHeader := fs.Bool("no-header", false, "Omit the header line of table, CSV and TSV")
+				return func(args []string) error {
+					if len(args) > 0 {
+						return fmt.Errorf("unexpected argument %q", args[0])
+					}
+					return runList(*format, *columns, !*noHeader, !*noTruncate)
+				}
+			},
+		},
+		{
+			Name:  "pick",
+			Short: "Choose a memory with a fuzzy finder and print its ID or text",
+			Long: "Open a fuzzy finder over the memories and print the chosen one on stdout, " +
+				"the finder itself being drawn on the terminal, so it works inside pipes and $(...).\n\n" +
+				"Type to filter, ↑/↓ or Ctrl+P/Ctrl+N to move, Enter to choose, Esc to cancel (exit status 1). " +
+				"The daemon cache shows up at once when it runs, then the server list replaces it.\n\n" +
+				"Examples:\n" +
+				"  memory-tui pick | xargs -r memory-tui ctl goto\n" +
+				"  memory-tui pick --print text | wl-copy",
+			Flags: func(fs *flag.FlagSet) func(args []string) error {
+				print := fs.String("print", "id", "What to print: id or text")
+				query := fs.String("query", "", "Initial filter")
+				return func(args []string) error {
+					if len(args) > 0 {
+						return fmt.Errorf("unexpected argument %q", args[0])
+					}
+					err := runPick(*query, *print)
+					if err == errNothingPicked {
+						os.Exit(1)
+					}
+					return err
+				}
+			},
+		},
+		{
+			Name:  "quick",
+			Args:  "[TEXT]",
+			Short: "Store a memory at once, or type it in a bare editor",
+			Long: "Store TEXT as a memory and exit, to jot a fact down from a hotkey. " +
+				"Without TEXT a full-screen editor opens: Ctrl+S stores the memory and exits, Esc exits.\n\n" +
+				"The session of the daemon is reused when it runs. The redactions of the configuration apply, " +
+				"and a text looking like it contains a secret is refused unless --force is given " +
+				"(in the editor, a second Ctrl+S stores it).\n\n" +
+				"Examples:\n" +
+				"  memory-tui quick \"The wifi code of the cottage is on the fridge\" --tags home,wifi\n" +
+				"  foot -e memory-tui quick --tags inbox",
+			Flags: func(fs *flag.FlagSet) func(args []string) error {
+				tags := fs.String("tags", "", "Comma-separated tags, stored in the \"tags\" metadata")
+				force := fs.Bool("force", false, "Store the text even if it looks like it contains a secret")
+				return func(args []string) error {
+					// The flags may follow the text too, as in quick "TEXT" --tags a,b
+					words, err := parseInterspersed(fs, args)
+					if err != nil {
+						return err
+					}
+					return runQuick(strings.Join(words, " "), parseTags(*tags), *force)
+				}
+			},
+		},
+		{
+			Name:  "daemon",
+			Short: "Keep the session alive and the memories synced in the background",
+			Long: "Keep the session alive and sync the memories to ~/.tom/cache in the background.\n\n" +
+				"The interface attaches to the daemon through its unix socket at startup, skipping the login and the initial load. " +
+				"Encrypted credentials are unlocked with the TOM_PASSPHRASE environment variable.",
+			Flags: func(fs *flag.FlagSet) func(args []string) error {
+				interval := fs.Duration("interval", defaultSyncInterval, "Delay between two syncs")
+				socket := fs.String("socket", "", "Socket path (default ~/.tom/daemon.sock)")
+				return func(args []string) error {
+					path := *socket
+					if path == "" {
+						var err error
+						if path, err = daemonSocketPath(); err != nil {
+							return err
+						}
+					}
+					return runDaemon(path, *interval)
+				}
+			},
+		},
+		{
+			Name:  "status",
+			Short: "Print the state of Tom for a desktop bar, from the daemon",
+			Long: "Print the state of Tom the daemon refreshes every minute: the connection, the notification status of Tom's modules " +
+				"and the next reminder, among the /timer timers and, with \"status_reminders\" in the configuration, Tom's reminders.\n\n" +
+				"It prints one line, for Polybar, or with --waybar the JSON of a Waybar custom module " +
+				"(text, tooltip, and class and alt among offline, disconnected, notifications and idle).\n\n" +
+				"Waybar module:\n" +
+				"  \"custom/tom\": {\"exec\": \"memory-tui status --waybar\", \"return-type\": \"json\", \"interval\": 30}",
+			Flags: func(fs *flag.FlagSet) func(args []string) error {
+				waybar := fs.Bool("waybar", false, "Print the JSON of a Waybar custom module")
+				return func(args []string) error {
+					if len(args) > 0 {
+						return fmt.Errorf("unexpected argument %q", args[0])
+					}
+					return runStatus(*waybar)
+				}
+			},
+		},
+		{
+			Name:  "serve-local",
+			Short: "Serve a local REST API in front of the Tom session, for automations",
+			Long: "Serve a small REST API on localhost, documented at /openapi.json: POST /v1/ask, " +
+				"GET and POST /v1/memories (q to search), GET and DELETE /v1/memories/ID and GET /v1/notifications. " +
+				"Shortcuts, Tasker or curl use Tom through it without handling the login and its cookie.\n\n" +
+				"The session of the daemon is reused when it runs, else the saved credentials, and is opened again when it expires. " +
+				"Requests carry the token of ~/.tom/serve-local.token, created at the first start, as \"Authorization: Bearer TOKEN\". " +
+				"The prompts go in a conversation of their own, and the redactions of the configuration apply to the memories added.\n\n" +
+				"Example:\n" +
+				"  curl -H \"Authorization: Bearer $(cat ~/.tom/serve-local.token)\" -d '{\"prompt\":\"weather tomorrow?\"}' http://127.0.0.1:8765/v1/ask",
+			Flags: func(fs *flag.FlagSet) func(args []string) error {
+				listen := fs.String("listen", defaultServeLocalAddr, "Address to listen on; keep it on localhost unless the network is trusted")
+				return func(args []string) error {
+					if len(args) > 0 {
+						return fmt.Errorf("unexpected argument %q", args[0])
+					}
+					return runServeLocal(*listen)
+				}
+			},
+		},
+		{
+			Name:  "mock-server",
+			Short: "Serve a mock Tom server injecting faults, to test the clients",
+			Long: "Serve a stand-in for a Tom server, its memories held in memory: /login, /logout, /status, /process (echoing the request), " +
+				"/reset, /tasks and the memory service under /memory. No backend or model is needed.\n\n" +
+				"Faults test how a client copes with a bad server: --fail-rate answers with an error status, --slow-rate answers after --latency " +
+				"(or when the client gives up), --malformed-rate cuts the JSON of the answer, --expire-rate ends the session with a 401, " +
+				"and sessions expire after --session-ttl. Logging in always works. The rates go from 0 to 1, and the faults are drawn from --seed, " +
+				"so the same seed gives the same faults for the same sequence of requests. " +
+				"GET /mock/chaos returns the faults and POST /mock/chaos replaces them, to change them during a test.\n\n" +
+				"Example:\n" +
+				"  memory-tui mock-server --seed 42 --fail-rate 0.2 --slow-rate 0.1 --latency 5s --session-ttl 1m\n" +
+				"  curl -d '{\"expire_rate\":1,\"paths\":[\"/memory/\"]}' http://127.0.0.1:8766/mock/chaos",
+			Flags: func(fs *flag.FlagSet) func(args []string) error {
+				listen := fs.String("listen", defaultMockAddr, "Address to listen on")
+				user := fs.String("user", defaultMockUser, "Username to log in with")
+				password := fs.String("password", defaultMockUser, "Password to log in with")
+				seed := fs.Int64("seed", 0, "Seed of the faults, from the time when 0")
+				failRate := fs.Float64("fail-rate", 0, "Share of the requests answered with --fail-status")
+				failStatus := fs.Int("fail-status", http.StatusInternalServerError, "Status of the failures")
+				slowRate := fs.Float64("slow-rate", 0, "Share of the requests answered after --latency")
+				latency := fs.Duration("latency", 10*time.Second, "Delay of the slow answers")
+				malformedRate := fs.Float64("malformed-rate", 0, "Share of the requests answered with truncated JSON")
+				expireRate := fs.Float64("expire-rate", 0, "Share of the requests ending the session, answered 401")
+				sessionTTL := fs.Duration("session-ttl", 0, "Lifetime of the sessions, 0 for no limit")
+				paths := fs.String("paths", "", "Comma-separated path prefixes the faults apply to, all but /login when empty")
+				return func(args []string) error {
+					if len(args) > 0 {
+						return fmt.Errorf("unexpected argument %q", args[0])
+					}
+					for _, rate := range []float64{*failRate, *slowRate, *malformedRate, *expireRate} {
+						if rate < 0 || rate > 1 {
+							return fmt.Errorf("invalid rate %v, expected 0 to 1", rate)
+						}
+					}
+					chaos := mockChaos{
+						FailRate:      *failRate,
+						FailStatus:    *failStatus,
+						SlowRate:      *slowRate,
+						LatencyMS:     latency.Milliseconds(),
+						MalformedRate: *malformedRate,
+						ExpireRate:    *expireRate,
+						SessionTTLMS:  sessionTTL.Milliseconds(),
+					}
+					if *paths != "" {
+						chaos.Paths = strings.Split(*paths, ",")
+					}
+					return runMockServer(*listen, *user, *password, *seed, chaos)
+				}
+			},
+		},
+		{
+			Name:  "store",
+			Short: "Manage the local store ~/.tom/tom.db",
+			Long: "The daemon cache, the audit log and the interrupted import are kept in a SQLite database, ~/.tom/tom.db, " +
+				"shared by the daemon and the interface. Its schema is migrated at opening, the files used before it being moved in.",
+			Subcommands: []*command{
+				{
+					Name:  "export",
+					Short: "Dump the local store as JSON",
+					Long:  "Dump the cache, the audit log and the interrupted import of the local store as a JSON document, on stdout or into a file.",
+					Flags: func(fs *flag.FlagSet) func(args []string) error {
+						output := fs.String("output", "", "File to write instead of stdout")
+						return func(args []string) error {
+							if len(args) > 0 {
+								return fmt.Errorf("unexpected argument %q", args[0])
+							}
+							return runStoreExport(*output)
+						}
+					},
+				},
+			},
+		},
+		{
+			Name:  "schedule",
+			Short: "Have the daemon send prompts to Tom on schedule",
+			Long: "Scheduled prompts are sent to Tom by the daemon, which must be running, and the answers shown " +
+				"as desktop notifications (notify-send, or osascript on macOS) and/or appended to a digest file. " +
+				"The \"scheduled_answer\" hook gets them too.\n\n" +
+				"Schedules: \"hourly\", \"every DURATION\" (5m at least), or days and a time: \"daily 8am\", " +
+				"\"weekdays 7:30\", \"weekends 10h\", \"mon,thu 18:15\". A run missed while the daemon was stopped " +
+				"happens once when it starts again.",
+			Subcommands: []*command{
+				{
+					Name:  "add",
+					Args:  "SCHEDULE PROMPT",
+					Short: "Schedule a prompt",
+					Long: "Schedule a prompt, e.g.\n\n" +
+						"  memory-tui schedule add \"daily 8am\" \"summarize my calendar and unread notifications\"\n" +
+						"  memory-tui schedule add \"fri 17h\" \"what did I plan for the weekend?\" --notify=false --digest ~/tom-digest.md",
+					Flags: func(fs *flag.FlagSet) func(args []string) error {
+						notify := fs.Bool("notify", true, "Show the answers as desktop notifications")
+						digest := fs.String("digest", "", "Markdown file the answers are appended to")
+						return func(args []string) error {
+							args, err := parseInterspersed(fs, args)
+							if err != nil {
+								return err
+							}
+							if len(args) != 2 {
+								return fmt.Errorf("expected SCHEDULE and PROMPT, quoted, got %d arguments", len(args))
+							}
+							return runScheduleAdd(args[0], args[1], *notify, *digest)
+						}
+					},
+				},
+				{
+					Name:  "list",
+					Short: "List the scheduled prompts and their next run",
+					Flags: func(fs *flag.FlagSet) func(args []string) error {
+						return func(args []string) error {
+							if len(args) > 0 {
+								return fmt.Errorf("unexpected argument %q", args[0])
+							}
+							return runScheduleList()
+						}
+					},
+				},
+				{
+					Name:  "remove",
+					Args:  "ID",
+					Short: "Remove a scheduled prompt",
+					Flags: func(fs *flag.FlagSet) func(args []string) error {
+						return func(args []string) error {
+							id, err := scheduleID(args)
+							if err != nil {
+								return err
+							}
+							if err := removeSchedule(id); err != nil {
+								return err
+							}
+							fmt.Printf("Scheduled prompt %d removed\n", id)
+							return nil
+						}
+					},
+				},
+				{
+					Name:  "run",
+					Args:  "ID",
+					Short: "Run a scheduled prompt now, printing the answer",
+					Long:  "Send a scheduled prompt to Tom now and deliver the answer as the daemon would, printing it too. The schedule is left as is.",
+					Flags: func(fs *flag.FlagSet) func(args []string) error {
+						return func(args []string) error {
+							id, err := scheduleID(args)
+							if err != nil {
+								return err
+							}
+							return runScheduleRun(id)
+						}
+					},
+				},
+			},
+		},
+		{
+			Name:  "bench",
+			Short: "Load test the memory API of a staging server",
+			Long: "Measure the throughput and latency of the memory API, to size the mem0 and Qdrant backends. " +
+				"Workers send --requests requests of each operation of --ops, --concurrency at a time, one operation after the other: " +
+				"add stores synthetic memories tagged with the run, search queries them, and delete measures the cleanup. " +
+				"The memories of the run are deleted at the end, after Ctrl+C too. Point it at a staging server, not at your own memories.\n\n" +
+				"The password is read from $" + smokePasswordEnv + " or --password-file, as for smoke. " +
+				"The report gives, for each operation, the errors, the successful requests per second and the p50, p90, p95, p99 and max latencies.\n\n" +
+				"Example:\n" +
+				"  " + smokePasswordEnv + "=... memory-tui bench --server https://staging.tom.example.com --user bench --concurrency 8 --ops add,search,delete",
+			Flags: func(fs *flag.FlagSet) func(args []string) error {
+				server := fs.String("server", "", "Tom server URL")
+				user := fs.String("user", "", "User to log in as")
+				passwordFile := fs.String("password-file", "", "File holding the password, instead of $"+smokePasswordEnv)
+				concurrency := fs.Int("concurrency", 4, "Number of requests in flight")
+				ops := fs.String("ops", benchDefaultOps, "Operations to measure, in order: add, search, delete")
+				requests := fs.Int("requests", benchDefaultRequests, "Number of requests of each operation")
+				format := fs.String("format", smokeFormatText, "Report format: text or json")
+				return func(args []string) error {
+					if len(args) > 0 {
+						return fmt.Errorf("unexpected argument %q", args[0])
+					}
+					return runBench(*server, *user, *passwordFile, *ops, *concurrency, *requests, *format)
+				}
+			},
+		},
+		{
+			Name:  "smoke",
+			Short: "Check a Tom server after a deployment and print a report",
+			Long: "Check a server from CI or Ansible after deploying an update: log in, send a trivial prompt to Tom, " +
+				"add a memory, find it with a search and delete it, and read /tasks. " +
+				"The saved credentials and the configuration are not used, and nothing is written to the local store.\n\n" +
+				"The password is read from $" + smokePasswordEnv + " or --password-file. The report goes to stdout, " +
+				"JSON by default with the outcome and duration of each step, and the exit status is 1 when a step failed.\n\n" +
+				"Example:\n" +
+				"  " + smokePasswordEnv + "=... memory-tui smoke --server https://tom.example.com --user ci | jq -e .ok",
+			Flags: func(fs *flag.FlagSet) func(args []string) error {
+				server := fs.String("server", "", "Tom server URL")
+				user := fs.String("user", "", "User to log in as")
+				passwordFile := fs.String("password-file", "", "File holding the password, instead of $"+smokePasswordEnv)
+				format := fs.String("format", smokeFormatJSON, "Report format: json or text")
+				timeout := fs.Duration("timeout", smokeStepTimeout, "Time limit of each step")
+				return func(args []string) error {
+					if len(args) > 0 {
+						return fmt.Errorf("unexpected argument %q", args[0])
+					}
+					return runSmoke(*server, *user, *passwordFile, *format, *timeout)
+				}
+			},
+		},
+		{
+			Name:  "bugreport",
+			Short: "Bundle the versions, terminal, configuration and log into a tarball for an issue",
+			Long: "Write a .tar.gz to attach to an issue: the client and server versions, the terminal, " +
+				"the configuration without its secrets, the last errors and the log.\n\n" +
+				"Emails, card numbers, API keys, tokens, passwords, session cookies and the patterns of \"redact_patterns\" " +
+				"are masked in every file, and the hooks and redaction patterns are left out of the configuration. " +
+				"Look through the bundle before sharing it all the same.",
+			Flags: func(fs *flag.FlagSet) func(args []string) error {
+				output := fs.String("output", "", "Tarball path (default memory-tui-bugreport-DATE.tar.gz in the current directory)")
+				return func(args []string) error {
+					if len(args) > 0 {
+						return fmt.Errorf("unexpected argument %q", args[0])
+					}
+					path := *output
+					if path == "" {
+						path = "memory-tui-bugreport-" + time.Now().Format("20060102-150405") + ".tar.gz"
+					}
+					if err := writeBugReport(path); err != nil {
+						return err
+					}
+					fmt.Println("Bug report written to", path)
+					return nil
+				}
+			},
+		},
+		{
+			Name:  "commitmsg",
+			Short: "Have Tom write the commit message of the staged changes",
+			Long: "Send the staged changes of the current repository (git diff --staged) to Tom and print the commit message he writes. " +
+				"The .tom-context of the directory goes with them.\n\n" +
+				"With --apply the changes are committed with the message, opened first in the editor of git: " +
+				"an empty message cancels the commit, as for git commit.\n\n" +
+				"Example:\n" +
+				"  git add -p && memory-tui commitmsg --apply",
+			Flags: func(fs *flag.FlagSet) func(args []string) error {
+				apply := fs.Bool("apply", false, "Commit the staged changes with the message")
+				edit := fs.Bool("edit", true, "With --apply, edit the message before committing")
+				return func(args []string) error {
+					if len(args) > 0 {
+						return fmt.Errorf("unexpected argument %q", args[0])
+					}
+					return runCommitMsg(*apply, *edit)
+				}
+			},
+		},
+		{
+			Name:  "explaindiff",
+			Args:  "[REV...]",
+			Short: "Have Tom explain a diff",
+			Long: "Print Tom's explanation of a diff: the one piped on stdin, else git diff REV of the current repository, " +
+				"the uncommitted changes (git diff HEAD) by default. The .tom-context of the directory goes with it.\n\n" +
+				"Examples:\n" +
+				"  memory-tui explaindiff main...HEAD\n" +
+				"  curl -sL https://github.com/OWNER/REPO/pull/1.diff | memory-tui explaindiff",
+			Flags: func(fs *flag.FlagSet) func(args []string) error {
+				return runExplainDiff
+			},
+		},
+		{
+			Name:   "gen-docs",
+			Args:   "DIR",
+			Short:  "Write the man pages of every command into DIR",
+			Hidden: true,
+			Flags: func(fs *flag.FlagSet) func(args []string) error {
+				return func(args []string) error {
+					if len(args) != 1 {
+						return fmt.Errorf("usage: memory-tui gen-docs DIR")
+					}
+					return writeManPages(root, args[0])
+				}
+			},
+		},
+	}
+	return root.link()
+}
+
 func main() {
-	p := tea.NewProgram(initialModel(), tea.WithAltScreen())
-	if _, err := p.Run(); err != nil {
-		log.Fatal(err)
+	err := rootCommand().execute(os.Args[1:])
+	waitHooks()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
 	}
 }
\ No newline at end of file
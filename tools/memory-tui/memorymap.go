@@ -0,0 +1,477 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// /map (experimental): the memories placed on a 2D braille scatter plot by
+// similarity, grouped in clusters named after their frequent words, to see
+// which themes dominate the store. Distances come from the embeddings when
+// the server advertises the "embeddings" capability, otherwise from one
+// search per memory, memories found by each other's searches being close.
+// Classical MDS projects them to 2D and k-means groups the points.
+const (
+	capEmbeddings    = "embeddings"
+	mapMaxMemories   = 80 // Memories placed, one search each without embeddings
+	mapNeighbours    = 8  // Results of each search
+	mapMaxClusters   = 6
+	mapThemeWords    = 3
+	mapPowerSteps    = 100 // Power iterations of the projection
+	mapKMeansRounds  = 20
+	mapMinWordLength = 4
+)
+
+var mapClusterColors = []lipgloss.Color{"#FF5F87", "#5FD7FF", "#AFFF5F", "#FFAF00", "#D787FF", "#FFFFFF"}
+
+var mapDimStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#4E4E4E"))
+
+// Frequent words left out of the cluster themes
+var mapStopWords = map[string]bool{
+	"that": true, "this": true, "with": true, "have": true, "from": true, "they": true,
+	"will": true, "when": true, "what": true, "their": true, "there": true, "about": true,
+	"pour": true, "dans": true, "avec": true, "sont": true, "mais": true, "elle": true,
+	"nous": true, "vous": true, "leur": true, "plus": true, "tout": true, "être": true,
+}
+
+type mapPoint struct {
+	memory  Memory
+	x, y    float64
+	cluster int
+}
+
+type mapLayoutMsg struct {
+	points []mapPoint
+	themes [][]string
+	source string
+	err    error
+}
+
+// GetEmbeddings returns the embedding vectors of memories, by ID
+func (api *MemoryAPI) GetEmbeddings(ids []string) (map[string][]float64, error) {
+	data, err := json.Marshal(map[string]interface{}{"ids": ids})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := api.Client.Post(api.buildURL("/embeddings"), "application/json", bytes.NewBuffer(data))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, errSessionExpired
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("embeddings: server returned %s", resp.Status)
+	}
+	var result struct {
+		Embeddings map[string][]float64 `json:"embeddings"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	return result.Embeddings, nil
+}
+
+func runMapCommand(m model, args string) (tea.Model, tea.Cmd) {
+	memories := m.memories
+	if len(memories) > mapMaxMemories {
+		memories = memories[:mapMaxMemories]
+	}
+	if len(memories) < 3 {
+		m.message = tr("The map needs at least 3 memories")
+		return m, nil
+	}
+	api := m.api.Background(timeoutBulk)
+
+	if api.Embeddings {
+		m.loading = true
+		m.message = tr("Fetching the embeddings...")
+		return m, func() tea.Msg {
+			ids := make([]string, len(memories))
+			for i, mem := range memories {
+				ids[i] = mem.ID
+			}
+			vectors, err := api.GetEmbeddings(ids)
+			if err != nil {
+				return mapLayoutMsg{err: err}
+			}
+			return layoutMap(memories, embeddingDistances(memories, vectors), "embeddings")
+		}
+	}
+
+	// One search per memory, in the background with progress
+	found := make([][]string, len(memories))
+	job := newBatchJob(tr("Mapping memories"), len(memories), func(i int) error {
+		bulkPace.wait()
+		results, err := api.SearchMemories(memories[i].Memory, mapNeighbours)
+		for _, r := range results {
+			found[i] = append(found[i], r.ID)
+		}
+		return err
+	})
+	job.finish = func(m model) (tea.Model, tea.Cmd) {
+		if job.cancelled {
+			m.showView(listView)
+			m.message = tr("Map cancelled")
+			return m, nil
+		}
+		m.loading = true
+		m.message = tr("Placing the memories...")
+		return m, func() tea.Msg {
+			return layoutMap(memories, searchDistances(memories, found), "searches")
+		}
+	}
+	return m.startBatch(job)
+}
+
+// embeddingDistances is the cosine distance between memory embeddings, the
+// memories without one being far from everything
+func embeddingDistances(memories []Memory, vectors map[string][]float64) [][]float64 {
+	n := len(memories)
+	dist := squareMatrix(n, 1)
+	for i := 0; i < n; i++ {
+		dist[i][i] = 0
+		for j := i + 1; j < n; j++ {
+			a, b := vectors[memories[i].ID], vectors[memories[j].ID]
+			if len(a) == 0 || len(a) != len(b) {
+				continue
+			}
+			var dot, na, nb float64
+			for k := range a {
+				dot += a[k] * b[k]
+				na += a[k] * a[k]
+				nb += b[k] * b[k]
+			}
+			if na > 0 && nb > 0 {
+				dist[i][j] = 1 - dot/math.Sqrt(na*nb)
+				dist[j][i] = dist[i][j]
+			}
+		}
+	}
+	return dist
+}
+
+// searchDistances turns the search results into distances: a memory found
+// early in the search of another is close to it
+func searchDistances(memories []Memory, found [][]string) [][]float64 {
+	n := len(memories)
+	index := make(map[string]int, n)
+	for i, mem := range memories {
+		index[mem.ID] = i
+	}
+	sim := squareMatrix(n, 0)
+	for i, ids := range found {
+		for rank, id := range ids {
+			if j, ok := index[id]; ok && j != i {
+				sim[i][j] += float64(mapNeighbours-rank) / mapNeighbours
+			}
+		}
+	}
+	dist := squareMatrix(n, 0)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if i != j {
+				dist[i][j] = 1 - (sim[i][j]+sim[j][i])/2
+			}
+		}
+	}
+	return dist
+}
+
+func squareMatrix(n int, value float64) [][]float64 {
+	matrix := make([][]float64, n)
+	for i := range matrix {
+		matrix[i] = make([]float64, n)
+		for j := range matrix[i] {
+			matrix[i][j] = value
+		}
+	}
+	return matrix
+}
+
+// layoutMap projects the distances to 2D with classical MDS and groups the
+// points in clusters
+func layoutMap(memories []Memory, dist [][]float64, source string) mapLayoutMsg {
+	n := len(memories)
+
+	// Double-centred squared distances
+	b := squareMatrix(n, 0)
+	rowMean := make([]float64, n)
+	total := 0.0
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			b[i][j] = dist[i][j] * dist[i][j]
+			rowMean[i] += b[i][j] / float64(n)
+		}
+		total += rowMean[i] / float64(n)
+	}
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			b[i][j] = -0.5 * (b[i][j] - rowMean[i] - rowMean[j] + total)
+		}
+	}
+
+	// The two main axes, by power iteration with deflation
+	axes := make([][]float64, 2)
+	for a := range axes {
+		v := make([]float64, n)
+		for i := range v {
+			v[i] = math.Sin(float64(i*(a+1)) + 1) // Deterministic start
+		}
+		lambda := 0.0
+		for step := 0; step < mapPowerSteps; step++ {
+			next := make([]float64, n)
+			for i := 0; i < n; i++ {
+				for j := 0; j < n; j++ {
+					next[i] += b[i][j] * v[j]
+				}
+			}
+			norm := 0.0
+			for _, x := range next {
+				norm += x * x
+			}
+			norm = math.Sqrt(norm)
+			if norm == 0 {
+				break
+			}
+			for i := range next {
+				next[i] /= norm
+			}
+			v, lambda = next, norm
+		}
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				b[i][j] -= lambda * v[i] * v[j]
+			}
+		}
+		scale := math.Sqrt(math.Max(lambda, 0))
+		for i := range v {
+			v[i] *= scale
+		}
+		axes[a] = v
+	}
+
+	points := make([]mapPoint, n)
+	for i, mem := range memories {
+		points[i] = mapPoint{memory: mem, x: axes[0][i], y: axes[1][i]}
+	}
+	k := int(math.Round(math.Sqrt(float64(n) / 2)))
+	k = max(2, min(k, mapMaxClusters))
+	clusterPoints(points, k)
+	return mapLayoutMsg{points: points, themes: clusterThemes(points, k), source: source}
+}
+
+// clusterPoints runs k-means, starting from points far from each other
+func clusterPoints(points []mapPoint, k int) {
+	centres := [][2]float64{{points[0].x, points[0].y}}
+	for len(centres) < k {
+		best, bestDist := 0, -1.0
+		for i, p := range points {
+			d := math.Inf(1)
+			for _, c := range centres {
+				d = math.Min(d, math.Hypot(p.x-c[0], p.y-c[1]))
+			}
+			if d > bestDist {
+				best, bestDist = i, d
+			}
+		}
+		centres = append(centres, [2]float64{points[best].x, points[best].y})
+	}
+
+	for round := 0; round < mapKMeansRounds; round++ {
+		for i, p := range points {
+			best := 0
+			for c := range centres {
+				if math.Hypot(p.x-centres[c][0], p.y-centres[c][1]) < math.Hypot(p.x-centres[best][0], p.y-centres[best][1]) {
+					best = c
+				}
+			}
+			points[i].cluster = best
+		}
+		sums := make([][3]float64, k)
+		for _, p := range points {
+			sums[p.cluster][0] += p.x
+			sums[p.cluster][1] += p.y
+			sums[p.cluster][2]++
+		}
+		for c := range centres {
+			if sums[c][2] > 0 {
+				centres[c] = [2]float64{sums[c][0] / sums[c][2], sums[c][1] / sums[c][2]}
+			}
+		}
+	}
+}
+
+// clusterThemes names each cluster after its most frequent words
+func clusterThemes(points []mapPoint, k int) [][]string {
+	counts := make([]map[string]int, k)
+	for c := range counts {
+		counts[c] = map[string]int{}
+	}
+	for _, p := range points {
+		seen := map[string]bool{}
+		for _, word := range strings.FieldsFunc(strings.ToLower(p.memory.Memory), func(r rune) bool { return !unicode.IsLetter(r) }) {
+			if len([]rune(word)) < mapMinWordLength || mapStopWords[word] || seen[word] {
+				continue
+			}
+			seen[word] = true
+			counts[p.cluster][word]++
+		}
+	}
+
+	themes := make([][]string, k)
+	for c, words := range counts {
+		for word := range words {
+			themes[c] = append(themes[c], word)
+		}
+		sort.Slice(themes[c], func(i, j int) bool {
+			a, b := themes[c][i], themes[c][j]
+			if words[a] != words[b] {
+				return words[a] > words[b]
+			}
+			return a < b
+		})
+		if len(themes[c]) > mapThemeWords {
+			themes[c] = themes[c][:mapThemeWords]
+		}
+	}
+	return themes
+}
+
+func (m model) handleMapLayout(msg mapLayoutMsg) (tea.Model, tea.Cmd) {
+	m.loading = false
+	if msg.err != nil {
+		m.err = msg.err
+		return m, nil
+	}
+	m.mapPoints, m.mapThemes, m.mapSource = msg.points, msg.themes, msg.source
+	m.mapCluster = 0
+	m.message = ""
+	m.showView(mapView)
+	logf("INFO", "map of %d memories in %d clusters from %s", len(msg.points), len(msg.themes), msg.source)
+	return m, nil
+}
+
+func (m model) clusterMemories(cluster int) []Memory {
+	var memories []Memory
+	for _, p := range m.mapPoints {
+		if p.cluster == cluster {
+			memories = append(memories, p.memory)
+		}
+	}
+	return memories
+}
+
+func (m model) updateMapView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	clusters := len(m.mapThemes)
+	switch key := msg.String(); key {
+	case "esc", "q":
+		m.showView(listView)
+		m.mapPoints, m.mapThemes = nil, nil
+	case "right", "l", "down", "j":
+		m.mapCluster = (m.mapCluster + 1) % clusters
+	case "left", "h", "up", "k":
+		m.mapCluster = (m.mapCluster + clusters - 1) % clusters
+	case "enter":
+		// The list shows the cluster, like search results
+		memories := m.clusterMemories(m.mapCluster)
+		m.linting = false
+		m.filterQuery = ""
+		m.list.SetItems(m.memoryItems(memories))
+		m.list.ResetSelected()
+		m.message = tr("Cluster %d: %d memories, /refresh to list them all", m.mapCluster+1, len(memories))
+		m.showView(listView)
+	default:
+		if len(key) == 1 && key[0] >= '1' && int(key[0]-'1') < clusters {
+			m.mapCluster = int(key[0] - '1')
+		}
+	}
+	return m, nil
+}
+
+// renderScatter draws the points on a braille canvas, 2×4 dots per cell,
+// a cell taking the colour of its last point and the other clusters dimmed
+func (m model) renderScatter(width, height int) string {
+	minX, maxX, minY, maxY := math.Inf(1), math.Inf(-1), math.Inf(1), math.Inf(-1)
+	for _, p := range m.mapPoints {
+		minX, maxX = math.Min(minX, p.x), math.Max(maxX, p.x)
+		minY, maxY = math.Min(minY, p.y), math.Max(maxY, p.y)
+	}
+	spanX, spanY := math.Max(maxX-minX, 1e-9), math.Max(maxY-minY, 1e-9)
+
+	dots := [4][2]rune{{0x01, 0x08}, {0x02, 0x10}, {0x04, 0x20}, {0x40, 0x80}}
+	cells := make([][]rune, height)
+	owner := make([][]int, height)
+	for r := range cells {
+		cells[r] = make([]rune, width)
+		owner[r] = make([]int, width)
+		for c := range owner[r] {
+			owner[r][c] = -1
+		}
+	}
+	for _, p := range m.mapPoints {
+		dx := int((p.x - minX) / spanX * float64(width*2-1))
+		dy := int((p.y - minY) / spanY * float64(height*4-1))
+		row, col := dy/4, dx/2
+		cells[row][col] |= dots[dy%4][dx%2]
+		// The selected cluster stays visible over the others
+		if owner[row][col] != m.mapCluster {
+			owner[row][col] = p.cluster
+		}
+	}
+
+	var b strings.Builder
+	for r := range cells {
+		for c, bits := range cells[r] {
+			switch {
+			case owner[r][c] < 0:
+				b.WriteRune(' ')
+			case owner[r][c] == m.mapCluster:
+				b.WriteString(lipgloss.NewStyle().Foreground(mapClusterColors[owner[r][c]]).Bold(true).Render(string(0x2800 + bits)))
+			default:
+				b.WriteString(mapDimStyle.Render(string(0x2800 + bits)))
+			}
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func (m model) renderMapView() string {
+	style := contentBoxStyle.Width(m.width - 4)
+	if m.focus == focusContent {
+		style = contentBoxFocusedStyle.Width(m.width - 4)
+	}
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(tr("🗺️ Memory map")))
+	b.WriteString(" " + helpStyle.Render(tr("%d memories • similarity from %s • experimental", len(m.mapPoints), tr(m.mapSource))))
+	b.WriteString("\n\n")
+	legendHeight := len(m.mapThemes) + 1
+	b.WriteString(m.renderScatter(max(20, m.width-10), max(4, m.height-14-legendHeight)))
+	b.WriteString("\n")
+	for c, theme := range m.mapThemes {
+		count := len(m.clusterMemories(c))
+		line := tr("%d ● %d memories: %s", c+1, count, strings.Join(theme, ", "))
+		if c == m.mapCluster {
+			b.WriteString(lipgloss.NewStyle().Foreground(mapClusterColors[c]).Bold(true).Render("▶ " + line))
+		} else {
+			b.WriteString(helpStyle.Render("  " + line))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	b.WriteString(m.keyHint(""))
+	return style.Render(b.String())
+}
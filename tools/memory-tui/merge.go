@@ -0,0 +1,135 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Merge of the marked memories into one, the manual counterpart of a
+// deduplication: /merge opens the editor on their texts, one paragraph each,
+// and /merge summarize on a summary Tom writes of them. Once saved and
+// confirmed, the merged memory is added, keeping the tags of the originals
+// and their IDs in "merged_from", then the originals are deleted; they stay
+// if the add fails.
+const mergeSummaryPrompt = "Merge these notes from my memory into a single note, keeping every fact " +
+	"and dropping the repetitions. Do not use any tool. Reply with the note only, in the language of the notes."
+
+type mergeSummaryMsg struct{ summary string }
+
+type mergedMsg struct{}
+
+func runMergeCommand(m model, args string) (tea.Model, tea.Cmd) {
+	if len(m.marked) < 2 {
+		m.message = tr("Mark two memories or more with Space first, then /merge")
+		return m, nil
+	}
+	originals := m.selectedMemories()
+
+	switch args {
+	case "":
+		return m.openMerge(mergedText(originals), originals)
+	case "summarize":
+		m.merging = originals
+		m.loading = true
+		m.setFocus(focusContent)
+		api := m.api
+		return m, func() tea.Msg {
+			summary, err := api.Process(memoriesPrompt(mergeSummaryPrompt, originals))
+			if err != nil {
+				return errMsg{err}
+			}
+			return mergeSummaryMsg{strings.TrimSpace(summary)}
+		}
+	}
+	m.message = tr("Unknown /merge argument %q, expected summarize", args)
+	return m, nil
+}
+
+// mergedText joins the texts of the memories, one paragraph each
+func mergedText(memories []Memory) string {
+	texts := make([]string, len(memories))
+	for i, mem := range memories {
+		texts[i] = strings.TrimSpace(mem.Memory)
+	}
+	return strings.Join(texts, "\n\n")
+}
+
+// mergedMetadata keeps the tags of the memories and records their IDs
+func mergedMetadata(memories []Memory) map[string]interface{} {
+	ids := make([]string, len(memories))
+	seen := map[string]bool{}
+	var tags []string
+	for i, mem := range memories {
+		ids[i] = mem.ID
+		for _, tag := range memoryTags(mem) {
+			if !seen[tag] {
+				seen[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+	}
+	metadata := map[string]interface{}{"merged_from": ids}
+	if len(tags) > 0 {
+		metadata["tags"] = tags
+	}
+	return metadata
+}
+
+// openMerge opens the editor on the merged text of the originals
+func (m model) openMerge(text string, originals []Memory) (tea.Model, tea.Cmd) {
+	m, cmd := m.openComposer(text)
+	if m.state == addView {
+		m.merging = originals
+	}
+	return m, cmd
+}
+
+func (m model) updateConfirmMergeView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "Y":
+		text, metadata := m.textArea.Value(), mergedMetadata(m.merging)
+		api := m.api
+		m.loading = true
+		m.showView(listView)
+		return m, func() tea.Msg {
+			if err := api.AddMemory(text, metadata); err != nil {
+				return errMsg{err}
+			}
+			return mergedMsg{}
+		}
+	case "n", "N", "esc":
+		cmd := m.showView(addView)
+		return m, cmd
+	}
+	return m, nil
+}
+
+// handleMerged deletes the originals once the merged memory is added
+func (m model) handleMerged() (tea.Model, tea.Cmd) {
+	logf("INFO", "merged memory added, deleting %d originals", len(m.merging))
+	m.loading = false
+	originals, api := m.merging, m.api.Background(timeoutBulk)
+	m.merging = nil
+	return m.startBatch(api.deleteJob(tr("Deleting the merged memories"), originals))
+}
+
+func (m model) renderConfirmMergeModal() string {
+	modalWidth := min(60, m.width-10)
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(tr("🔗 Confirm Merge")))
+	b.WriteString("\n\n")
+	b.WriteString(tr("Store the merged memory and delete these %d memories?", len(m.merging)) + "\n\n")
+	for i, mem := range m.merging {
+		if i == 5 {
+			b.WriteString(tr("... and %d more", len(m.merging)-5) + "\n")
+			break
+		}
+		b.WriteString("• " + truncateString(mem.Memory, modalWidth-10) + "\n")
+	}
+	b.WriteString("\n" + m.deleteWarning() + "\n\n")
+	b.WriteString(m.keyHint(""))
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, modalStyle.Width(modalWidth).Render(b.String()))
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Draft length meter of the add view. Token counts are estimated at four
+// characters per token, close enough for the embedding models behind the
+// memory server.
+const (
+	maxDraftLength = 20000 // The textarea silently drops input beyond this
+	charsPerToken  = 4
+)
+
+var meterWarningStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#FFAF00"))
+
+func estimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + charsPerToken - 1) / charsPerToken
+}
+
+func (m model) renderDraftMeter(text string) string {
+	length := utf8.RuneCountInString(text)
+	meter := fmt.Sprintf("%d/%d chars • ~%d tokens", length, maxDraftLength, estimateTokens(text))
+
+	switch {
+	case length >= maxDraftLength:
+		return loginErrorStyle.Render(meter + " • limit reached, further input is dropped")
+	case length >= maxDraftLength*9/10:
+		return meterWarningStyle.Render(meter + " • close to the limit")
+	case m.chunkSize > 0 && length > m.chunkSize:
+		return meterWarningStyle.Render(fmt.Sprintf("%s • over %d chars, saving offers to split it", meter, m.chunkSize))
+	}
+	return helpStyle.Render(meter)
+}
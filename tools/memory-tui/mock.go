@@ -0,0 +1,456 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	mathrand "math/rand"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// `memory-tui mock-server`: a stand-in for a Tom server, holding its
+// memories in memory, to develop and test the clients without a backend:
+// login, /status, /process, /reset, /tasks and the memory service under
+// /memory. Faults are injected to test the resilience of the clients
+// (retries, login again, backoff, cancellation): failures, slow answers,
+// truncated JSON and sessions that expire. Drawn from a seeded generator,
+// the same seed replays the same faults for the same sequence of requests.
+// The faults can be changed while it runs through /mock/chaos.
+const (
+	defaultMockAddr     = "127.0.0.1:8766"
+	defaultMockUser     = "mock"
+	mockSessionCookie   = "session_id"
+	mockChaosPath       = "/mock/chaos"
+	mockMalformedPrefix = 12 // Bytes of the answer kept when it is truncated
+)
+
+// mockChaos is the fault injection, the rates going from 0 to 1. The JSON
+// is that of /mock/chaos.
+type mockChaos struct {
+	FailRate      float64  `json:"fail_rate"`      // Answered with FailStatus
+	FailStatus    int      `json:"fail_status"`    // 500 when 0
+	SlowRate      float64  `json:"slow_rate"`      // Answered after LatencyMS
+	LatencyMS     int64    `json:"latency_ms"`     // Of the slow answers
+	MalformedRate float64  `json:"malformed_rate"` // Answered with truncated JSON
+	ExpireRate    float64  `json:"expire_rate"`    // Session ended, answered 401
+	SessionTTLMS  int64    `json:"session_ttl_ms"` // Sessions expire after it, never when 0
+	Paths         []string `json:"paths"`          // Prefixes of the paths affected, all but /login when empty
+}
+
+// applies tells whether the faults apply to a path; logging in always works,
+// or the clients could never recover
+func (c mockChaos) applies(path string) bool {
+	if path == "/login" || path == mockChaosPath {
+		return false
+	}
+	if len(c.Paths) == 0 {
+		return true
+	}
+	for _, prefix := range c.Paths {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// mockFault is what is done to a request
+type mockFault struct {
+	slow      bool
+	expire    bool
+	fail      bool
+	malformed bool
+}
+
+func (f mockFault) String() string {
+	var names []string
+	for _, n := range []struct {
+		on   bool
+		name string
+	}{{f.slow, "slow"}, {f.expire, "expire"}, {f.fail, "fail"}, {f.malformed, "malformed"}} {
+		if n.on {
+			names = append(names, n.name)
+		}
+	}
+	return strings.Join(names, "+")
+}
+
+type mockServer struct {
+	user, password string
+
+	mu       sync.Mutex
+	chaos    mockChaos
+	rng      *mathrand.Rand
+	sessions map[string]time.Time // Creation of each session
+	memories map[string]Memory
+	order    []string // IDs of the memories, oldest first
+}
+
+// newMockServer returns an empty store, the UI tests serving it themselves
+func newMockServer(user, password string, seed int64, chaos mockChaos) *mockServer {
+	return &mockServer{
+		user:     user,
+		password: password,
+		chaos:    chaos,
+		rng:      mathrand.New(mathrand.NewSource(seed)),
+		sessions: map[string]time.Time{},
+		memories: map[string]Memory{},
+	}
+}
+
+func runMockServer(addr, user, password string, seed int64, chaos mockChaos) error {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	s := newMockServer(user, password, seed, chaos)
+
+	srv := &http.Server{Addr: addr, Handler: s.routes(), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		<-signals
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		srv.Shutdown(ctx)
+	}()
+
+	fmt.Printf("Mock Tom server on http://%s, log in as %s, seed %d\n", addr, user, seed)
+	logf("INFO", "mock-server listening on %s, seed %d", addr, seed)
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func (s *mockServer) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", s.handleLogin)
+	mux.HandleFunc(mockChaosPath, s.handleChaos)
+	mux.Handle("/logout", s.authenticated(s.handleLogout))
+	mux.Handle("/status", s.authenticated(s.handleStatus))
+	mux.Handle("/process", s.authenticated(s.handleProcess))
+	mux.Handle("/reset", s.authenticated(s.handleReset))
+	mux.Handle("/tasks", s.authenticated(s.handleTasks))
+	mux.Handle("/memory/", s.authenticated(s.handleMemory))
+	return s.injectFaults(mux)
+}
+
+// draw picks the faults of a request, under the lock so that a seed gives
+// the same draws in the same order
+func (s *mockServer) draw(path string) (mockFault, mockChaos) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := s.chaos
+	var f mockFault
+	if !c.applies(path) {
+		return f, c
+	}
+	f.slow = s.rng.Float64() < c.SlowRate
+	f.expire = s.rng.Float64() < c.ExpireRate
+	f.fail = s.rng.Float64() < c.FailRate
+	f.malformed = s.rng.Float64() < c.MalformedRate && !f.fail // A failure has no body to cut
+	return f, c
+}
+
+// injectFaults applies the faults drawn for each request before or after
+// the handler
+func (s *mockServer) injectFaults(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f, c := s.draw(r.URL.Path)
+		if f != (mockFault{}) {
+			logf("INFO", "mock-server: %s %s: %s", r.Method, r.URL.Path, f)
+		}
+		if f.slow {
+			// A client giving up ends the wait, as with a real server
+			select {
+			case <-time.After(time.Duration(c.LatencyMS) * time.Millisecond):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		if f.expire {
+			if cookie, err := r.Cookie(mockSessionCookie); err == nil {
+				s.mu.Lock()
+				delete(s.sessions, cookie.Value)
+				s.mu.Unlock()
+			}
+		}
+		if f.fail {
+			status := c.FailStatus
+			if status == 0 {
+				status = http.StatusInternalServerError
+			}
+			writeLocalError(w, status, errors.New("injected failure"))
+			return
+		}
+		if f.malformed {
+			next.ServeHTTP(&truncatedWriter{ResponseWriter: w, left: mockMalformedPrefix}, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// truncatedWriter sends only the first bytes of the body, a JSON answer cut
+// in the middle
+type truncatedWriter struct {
+	http.ResponseWriter
+	left int
+}
+
+func (w *truncatedWriter) Write(p []byte) (int, error) {
+	if w.left <= 0 {
+		return len(p), nil
+	}
+	n := min(len(p), w.left)
+	w.left -= n
+	if _, err := w.ResponseWriter.Write(p[:n]); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// authenticated answers 401, as the server does, without a live session
+func (s *mockServer) authenticated(handler http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie(mockSessionCookie)
+		s.mu.Lock()
+		ok := false
+		if err == nil {
+			created, found := s.sessions[cookie.Value]
+			ok = found && (s.chaos.SessionTTLMS == 0 || time.Since(created).Milliseconds() < s.chaos.SessionTTLMS)
+			if found && !ok {
+				delete(s.sessions, cookie.Value)
+			}
+		}
+		s.mu.Unlock()
+		if !ok {
+			http.Error(w, "Authentication required", http.StatusUnauthorized)
+			return
+		}
+		handler(w, r)
+	})
+}
+
+func (s *mockServer) handleLogin(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodPost) {
+		return
+	}
+	// Bad credentials get a 200 page, as from the server
+	if r.FormValue("username") != s.user || r.FormValue("password") != s.password {
+		io.WriteString(w, "<html><body>Invalid credentials</body></html>")
+		return
+	}
+	random := make([]byte, 16)
+	rand.Read(random)
+	id := hex.EncodeToString(random)
+	s.mu.Lock()
+	s.sessions[id] = time.Now()
+	s.mu.Unlock()
+	http.SetCookie(w, &http.Cookie{Name: mockSessionCookie, Value: id, Path: "/", HttpOnly: true})
+	io.WriteString(w, "<html><body>Logged in</body></html>")
+}
+
+func (s *mockServer) handleLogout(w http.ResponseWriter, r *http.Request) {
+	if cookie, err := r.Cookie(mockSessionCookie); err == nil {
+		s.mu.Lock()
+		delete(s.sessions, cookie.Value)
+		s.mu.Unlock()
+	}
+	writeLocalJSON(w, http.StatusOK, map[string]string{"status": "OK"})
+}
+
+// handleChaos returns the faults, and replaces them with those POSTed
+func (s *mockServer) handleChaos(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet, http.MethodPost) {
+		return
+	}
+	if r.Method == http.MethodPost {
+		var chaos mockChaos
+		if !decodeLocalBody(w, r, &chaos) {
+			return
+		}
+		s.mu.Lock()
+		s.chaos = chaos
+		s.mu.Unlock()
+		logf("INFO", "mock-server: chaos set to %+v", chaos)
+	}
+	s.mu.Lock()
+	chaos := s.chaos
+	s.mu.Unlock()
+	writeLocalJSON(w, http.StatusOK, chaos)
+}
+
+func (s *mockServer) handleStatus(w http.ResponseWriter, r *http.Request) {
+	writeLocalJSON(w, http.StatusOK, serverInfo{APIVersion: 1, Capabilities: []string{}})
+}
+
+// handleProcess echoes the request, Tom's answer of the mock
+func (s *mockServer) handleProcess(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodPost) {
+		return
+	}
+	var req struct {
+		Request      string `json:"request"`
+		SoundEnabled bool   `json:"sound_enabled"`
+	}
+	if !decodeLocalBody(w, r, &req) {
+		return
+	}
+	answer := "You said: " + req.Request
+	if req.SoundEnabled {
+		writeLocalJSON(w, http.StatusOK, map[string]string{"status": "OK", "text_display": answer, "text_tts": answer})
+		return
+	}
+	writeLocalJSON(w, http.StatusOK, map[string]string{"status": "OK", "response": answer})
+}
+
+func (s *mockServer) handleReset(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodPost) {
+		return
+	}
+	writeLocalJSON(w, http.StatusOK, map[string]string{"status": "OK"})
+}
+
+func (s *mockServer) handleTasks(w http.ResponseWriter, r *http.Request) {
+	writeLocalJSON(w, http.StatusOK, map[string]interface{}{"id": time.Now().Unix(), "background_tasks": []interface{}{}})
+}
+
+// handleMemory serves the memory service: memories, memory/ID, add, search
+// and delete/ID
+func (s *mockServer) handleMemory(w http.ResponseWriter, r *http.Request) {
+	endpoint, id, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/memory/"), "/")
+	switch endpoint {
+	case "memories":
+		if !allowMethods(w, r, http.MethodGet) {
+			return
+		}
+		s.mu.Lock()
+		list := make([]Memory, 0, len(s.order))
+		for _, id := range s.order {
+			list = append(list, s.memories[id])
+		}
+		s.mu.Unlock()
+		writeLocalJSON(w, http.StatusOK, map[string]interface{}{"results": list})
+
+	case "memory":
+		if !allowMethods(w, r, http.MethodGet) {
+			return
+		}
+		s.mu.Lock()
+		m, ok := s.memories[id]
+		s.mu.Unlock()
+		if !ok {
+			writeLocalError(w, http.StatusNotFound, errors.New("memory not found"))
+			return
+		}
+		writeLocalJSON(w, http.StatusOK, map[string]interface{}{"result": m})
+
+	case "add":
+		if !allowMethods(w, r, http.MethodPost) {
+			return
+		}
+		var req struct {
+			Text     string                 `json:"text"`
+			Metadata map[string]interface{} `json:"metadata"`
+		}
+		if !decodeLocalBody(w, r, &req) {
+			return
+		}
+		if strings.TrimSpace(req.Text) == "" {
+			writeLocalError(w, http.StatusBadRequest, errors.New("text is empty"))
+			return
+		}
+		random := make([]byte, 16)
+		rand.Read(random)
+		m := Memory{ID: hex.EncodeToString(random), Memory: req.Text, CreatedAt: time.Now().Format(time.RFC3339), UserID: s.user, Metadata: req.Metadata}
+		s.mu.Lock()
+		s.memories[m.ID] = m
+		s.order = append(s.order, m.ID)
+		s.mu.Unlock()
+		writeLocalJSON(w, http.StatusOK, map[string]interface{}{"result": m})
+
+	case "search":
+		if !allowMethods(w, r, http.MethodPost) {
+			return
+		}
+		var req struct {
+			Query string `json:"query"`
+			Limit int    `json:"limit"`
+		}
+		if !decodeLocalBody(w, r, &req) {
+			return
+		}
+		writeLocalJSON(w, http.StatusOK, map[string]interface{}{"results": s.search(req.Query, req.Limit)})
+
+	case "delete":
+		if !allowMethods(w, r, http.MethodDelete) {
+			return
+		}
+		s.mu.Lock()
+		_, ok := s.memories[id]
+		if ok {
+			delete(s.memories, id)
+			for i, other := range s.order {
+				if other == id {
+					s.order = append(s.order[:i], s.order[i+1:]...)
+					break
+				}
+			}
+		}
+		s.mu.Unlock()
+		if !ok {
+			writeLocalError(w, http.StatusNotFound, errors.New("memory not found"))
+			return
+		}
+		writeLocalJSON(w, http.StatusOK, map[string]string{"status": "OK"})
+
+	default:
+		writeLocalError(w, http.StatusNotFound, fmt.Errorf("unknown endpoint %q", endpoint))
+	}
+}
+
+// search ranks the memories by the words of the query they hold, in place
+// of the embeddings of mem0
+func (s *mockServer) search(query string, limit int) []Memory {
+	words := strings.Fields(strings.ToLower(query))
+	type scored struct {
+		m     Memory
+		score int
+	}
+	var found []scored
+	s.mu.Lock()
+	for _, id := range s.order {
+		m := s.memories[id]
+		text := strings.ToLower(m.Memory)
+		score := 0
+		for _, w := range words {
+			if strings.Contains(text, w) {
+				score++
+			}
+		}
+		if score > 0 {
+			found = append(found, scored{m, score})
+		}
+	}
+	s.mu.Unlock()
+	sort.SliceStable(found, func(i, j int) bool { return found[i].score > found[j].score })
+	if limit > 0 && len(found) > limit {
+		found = found[:limit]
+	}
+	results := make([]Memory, len(found))
+	for i, f := range found {
+		results[i] = f.m
+	}
+	return results
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// News reader: on servers with the news module, /news lists the unread
+// articles next to a preview of the selected one. s has Tom summarize it
+// (the module keeps the summaries, the next one comes at once), m marks it
+// read and o opens it in the browser. Like /mail the requests go through
+// Tom, the module having no REST endpoint. The preview, summary included,
+// is Markdown rendered with glamour.
+const moduleNews = "news"
+
+var newsPreviewStyle = lipgloss.NewStyle().PaddingLeft(1).BorderStyle(lipgloss.NormalBorder()).BorderLeft(true)
+
+const (
+	newsListPrompt = "List my unread news with get_all_news. Reply with a JSON array only, no text around it, " +
+		`one object per article: {"id": "...", "category": "...", "author": "...", "title": "...", "url": "..."}. ` +
+		"Reply [] when there is none."
+	newsSummaryPrompt = "Summarize the news with ID %s with get_news_summary. Reply with the summary only, in Markdown."
+	newsReadPrompt    = "Mark the news with ID %s as read with mark_news_as_read. Reply OK only."
+)
+
+type newsItem struct {
+	ID       answerID `json:"id"`
+	Category string   `json:"category"`
+	Author   string   `json:"author"`
+	Title    string   `json:"title"`
+	URL      string   `json:"url"`
+}
+
+type newsLoadedMsg struct{ items []newsItem }
+
+type newsDoneMsg struct {
+	id      answerID
+	action  string // "read" or "summary"
+	summary string
+}
+
+func runNewsCommand(m model, args string) (tea.Model, tea.Cmd) {
+	m.loading = true
+	m.setFocus(focusContent)
+	return m, m.fetchNews()
+}
+
+func (m model) fetchNews() tea.Cmd {
+	api := m.api
+	return func() tea.Msg {
+		answer, err := api.Process(newsListPrompt)
+		if err != nil {
+			return errMsg{err}
+		}
+		var items []newsItem
+		if err := decodeAnswerList(answer, &items); err != nil {
+			return errMsg{err}
+		}
+		return newsLoadedMsg{items}
+	}
+}
+
+func (m model) openNews(msg newsLoadedMsg) (tea.Model, tea.Cmd) {
+	m.loading = false
+	m.news = msg.items
+	if m.newsSummaries == nil {
+		m.newsSummaries = make(map[answerID]string)
+	}
+	if m.state == newsView {
+		m.newsCursor = min(m.newsCursor, max(0, len(m.news)-1))
+		return m, nil
+	}
+	m.newsCursor = 0
+	cmd := m.showView(newsView)
+	return m, cmd
+}
+
+func (m model) newsAction(action string) (tea.Model, tea.Cmd) {
+	if len(m.news) == 0 {
+		return m, nil
+	}
+	id := m.news[m.newsCursor].ID
+	prompt := fmt.Sprintf(newsReadPrompt, id)
+	if action == "summary" {
+		prompt = fmt.Sprintf(newsSummaryPrompt, id)
+	}
+
+	m.loading = true
+	api := m.api
+	return m, func() tea.Msg {
+		answer, err := api.Process(prompt)
+		if err != nil {
+			return errMsg{err}
+		}
+		return newsDoneMsg{id: id, action: action, summary: strings.TrimSpace(answer)}
+	}
+}
+
+func (m model) handleNewsDone(msg newsDoneMsg) (tea.Model, tea.Cmd) {
+	m.loading = false
+	logf("INFO", "news %s: %s", msg.id, msg.action)
+	if msg.action == "summary" {
+		m.newsSummaries[msg.id] = msg.summary
+		return m, nil
+	}
+	for i, item := range m.news {
+		if item.ID == msg.id {
+			m.news = append(m.news[:i:i], m.news[i+1:]...)
+			break
+		}
+	}
+	delete(m.newsSummaries, msg.id)
+	m.newsCursor = min(m.newsCursor, max(0, len(m.news)-1))
+	m.message = tr("Article marked read")
+	return m, nil
+}
+
+// openURL opens the link in the default browser
+func openURL(url string) error {
+	if url == "" {
+		return errors.New("no link to open")
+	}
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("open", url).Start()
+	case "windows":
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", url).Start()
+	}
+	return exec.Command("xdg-open", url).Start()
+}
+
+func (m model) updateNewsView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.loading && msg.String() != "esc" {
+		return m, nil
+	}
+	switch msg.String() {
+	case "esc", "q":
+		cmd := m.showView(listView)
+		return m, cmd
+	case "up", "k":
+		if m.newsCursor > 0 {
+			m.newsCursor--
+		}
+	case "down", "j":
+		if m.newsCursor < len(m.news)-1 {
+			m.newsCursor++
+		}
+	case "s":
+		if len(m.news) > 0 && m.newsSummaries[m.news[m.newsCursor].ID] == "" {
+			return m.newsAction("summary")
+		}
+	case "m":
+		return m.newsAction("read")
+	case "o":
+		if len(m.news) == 0 {
+			return m, nil
+		}
+		if err := openURL(m.news[m.newsCursor].URL); err != nil {
+			m.err = err
+		}
+	case "p":
+		if len(m.news) == 0 {
+			return m, nil
+		}
+		item := m.news[m.newsCursor]
+		return m.pinAnswer("news", item.Title, strings.TrimSpace(m.newsSummaries[item.ID]+"\n\n"+item.URL))
+	case "r":
+		m.loading = true
+		return m, m.fetchNews()
+	}
+	return m, nil
+}
+
+func (m model) renderNewsView() string {
+	style := contentBoxStyle.Width(m.width - 4)
+	if m.focus == focusContent {
+		style = contentBoxFocusedStyle.Width(m.width - 4)
+	}
+	inner := max(40, m.width-10)
+	listWidth := inner * 2 / 5
+	previewWidth := inner - listWidth - 3
+
+	var list strings.Builder
+	if len(m.news) == 0 {
+		list.WriteString(helpStyle.Render(tr("No unread news")))
+	}
+	for i, item := range m.news {
+		if i > 0 {
+			list.WriteString("\n")
+		}
+		line := truncateString(item.Title, listWidth-2)
+		if i == m.newsCursor {
+			list.WriteString(selectedItemStyle.Render("▸ " + line))
+		} else {
+			list.WriteString("  " + line)
+		}
+	}
+
+	var preview strings.Builder
+	if len(m.news) > 0 {
+		item := m.news[m.newsCursor]
+		summary := m.newsSummaries[item.ID]
+		preview.WriteString(newsPreviews.render(newsMarkdown(item, summary), previewWidth))
+		preview.WriteString("\n\n")
+		if item.URL != "" {
+			preview.WriteString(helpStyle.Render(truncateString(item.URL, previewWidth)))
+			preview.WriteString("\n")
+		}
+		if summary == "" {
+			preview.WriteString(helpStyle.Render(tr("s asks Tom to summarize it")))
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(tr("📰 News")))
+	b.WriteString(" " + helpStyle.Render(tr("%d unread", len(m.news))))
+	b.WriteString("\n\n")
+	b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top,
+		lipgloss.NewStyle().Width(listWidth).MarginRight(1).Render(list.String()),
+		newsPreviewStyle.Width(previewWidth+1).Render(preview.String()),
+	))
+	b.WriteString("\n\n")
+	b.WriteString(m.keyHint(""))
+	return style.Render(b.String())
+}
+
+// newsMarkdown is the preview of the article: its title, category and
+// author, and summary. The link stays out, cut to the width of the pane.
+func newsMarkdown(item newsItem, summary string) string {
+	var b strings.Builder
+	b.WriteString("# " + item.Title + "\n\n")
+	var byline []string
+	for _, part := range []string{item.Category, item.Author} {
+		if part != "" {
+			byline = append(byline, part)
+		}
+	}
+	if len(byline) > 0 {
+		b.WriteString("*" + strings.Join(byline, " · ") + "*\n\n")
+	}
+	b.WriteString(summary)
+	return b.String()
+}
+
+// markdownCache keeps the glamour renderer of a width and what it rendered,
+// View running at every frame. Each pane showing Markdown has its own.
+type markdownCache struct {
+	mu       sync.Mutex
+	width    int
+	renderer *glamour.TermRenderer
+	rendered map[string]string
+}
+
+var newsPreviews markdownCache
+
+// render returns markdown rendered width columns wide, wrapped as plain text
+// if glamour fails. Single line breaks are kept, for the plain texts. The
+// style follows the color profile of lipgloss, without asking the terminal
+// for its background while the program reads its keys.
+func (c *markdownCache) render(markdown string, width int) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.renderer == nil || c.width != width {
+		style := glamour.DarkStyleConfig
+		if lipgloss.ColorProfile() == termenv.Ascii {
+			style = glamour.NoTTYStyleConfig
+		}
+		// The pane has a padding of its own
+		noMargin := uint(0)
+		style.Document.Margin = &noMargin
+		renderer, err := glamour.NewTermRenderer(glamour.WithStyles(style), glamour.WithColorProfile(lipgloss.ColorProfile()),
+			glamour.WithWordWrap(width), glamour.WithPreservedNewLines(), glamour.WithEmoji())
+		if err != nil {
+			logf("WARN", "markdown renderer: %v", err)
+			return wrapLines(markdown, width)
+		}
+		c.renderer, c.width, c.rendered = renderer, width, map[string]string{}
+	}
+	if out, ok := c.rendered[markdown]; ok {
+		return out
+	}
+	out, err := c.renderer.Render(markdown)
+	if err != nil {
+		logf("WARN", "markdown: %v", err)
+		return wrapLines(markdown, width)
+	}
+	out = strings.Trim(out, "\n")
+	c.rendered[markdown] = out
+	return out
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Mickael-Roger/tom/tools/memory-tui/pkg/chatclient"
+)
+
+// `memory-tui --nvim`: the backend of an editor plugin, speaking JSON over
+// stdin and stdout, one object per line, with nothing else ever written to
+// stdout. Requests carry an id their response repeats, and are answered as
+// they complete, not in order:
+//
+//	→ {"id": 1, "method": "ask", "params": {"prompt": "...", "text": "...", "filetype": "go"}}
+//	← {"id": 1, "result": {"answer": "..."}}
+//	← {"id": 2, "error": {"code": "tom", "message": "..."}}
+//
+// The fields are only ever added to, hello telling the protocol version.
+// Requests to Tom go in the "nvim" conversation, a history Tom keeps apart
+// from his apps that reset clears, and can be cancelled.
+const (
+	nvimProtocolVersion = 1
+	nvimClientType      = "nvim"
+	nvimAskTimeout      = 3 * time.Minute
+	nvimEditPrompt      = "Rewrite the following %s according to the instruction, changing nothing else. " +
+		"Answer with the whole rewritten text in a single code block, without explanation.\n\nInstruction: %s\n\n%s"
+)
+
+type nvimRequest struct {
+	ID     int64           `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+type nvimResponse struct {
+	ID     int64       `json:"id"`
+	Result interface{} `json:"result,omitempty"`
+	Error  *nvimError  `json:"error,omitempty"`
+}
+
+type nvimError struct {
+	Code    string `json:"code"` // invalid_request, unknown_method, cancelled, read_only, session or tom
+	Message string `json:"message"`
+}
+
+// nvimParams are the parameters of every method, each using its own
+type nvimParams struct {
+	Prompt      string                 `json:"prompt"`
+	Instruction string                 `json:"instruction"`
+	Text        string                 `json:"text"`     // Buffer or selection
+	Filetype    string                 `json:"filetype"` // Of the text, for its code block
+	Path        string                 `json:"path"`
+	Query       string                 `json:"query"`
+	Limit       int                    `json:"limit"`
+	Metadata    map[string]interface{} `json:"metadata"`
+	ID          int64                  `json:"id"` // Request to cancel
+}
+
+type nvimServer struct {
+	sharedSession
+
+	out     sync.Mutex
+	encoder *json.Encoder
+
+	mu      sync.Mutex
+	pending map[int64]context.CancelFunc // Requests to Tom in progress
+}
+
+func runNvim(in io.Reader, out io.Writer) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		logf("WARN", "failed to load config, using defaults: %v", err)
+	}
+	s := &nvimServer{
+		sharedSession: sharedSession{cfg: cfg},
+		encoder:       json.NewEncoder(out),
+		pending:       map[int64]context.CancelFunc{},
+	}
+	logf("INFO", "nvim backend started")
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	r := bufio.NewReader(in)
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(strings.TrimSpace(string(line))) > 0 {
+			var req nvimRequest
+			if jsonErr := json.Unmarshal(line, &req); jsonErr != nil {
+				s.send(nvimResponse{Error: &nvimError{"invalid_request", jsonErr.Error()}})
+			} else {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					s.send(s.handle(req))
+				}()
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (s *nvimServer) send(resp nvimResponse) {
+	s.out.Lock()
+	defer s.out.Unlock()
+	if err := s.encoder.Encode(resp); err != nil {
+		logf("WARN", "nvim: %v", err)
+	}
+}
+
+func (s *nvimServer) handle(req nvimRequest) nvimResponse {
+	var p nvimParams
+	if len(req.Params) > 0 {
+		if err := json.Unmarshal(req.Params, &p); err != nil {
+			return nvimResponse{ID: req.ID, Error: &nvimError{"invalid_request", err.Error()}}
+		}
+	}
+	result, err := s.call(req.ID, req.Method, p)
+	if err != nil {
+		return nvimResponse{ID: req.ID, Error: toNvimError(err)}
+	}
+	return nvimResponse{ID: req.ID, Result: result}
+}
+
+var errNvimUnknownMethod = errors.New("unknown method")
+
+// nvimInvalid is a request missing what its method needs
+type nvimInvalid string
+
+func (e nvimInvalid) Error() string { return string(e) }
+
+func toNvimError(err error) *nvimError {
+	var invalid nvimInvalid
+	switch {
+	case errors.As(err, &invalid):
+		return &nvimError{"invalid_request", err.Error()}
+	case errors.Is(err, errNvimUnknownMethod):
+		return &nvimError{"unknown_method", err.Error()}
+	case errors.Is(err, context.Canceled):
+		return &nvimError{"cancelled", "cancelled"}
+	case errors.Is(err, errReadOnly):
+		return &nvimError{"read_only", err.Error()}
+	case errors.Is(err, errSessionExpired), errors.Is(err, errPassphraseRequired):
+		return &nvimError{"session", err.Error()}
+	}
+	return &nvimError{"tom", err.Error()}
+}
+
+func (s *nvimServer) call(id int64, method string, p nvimParams) (interface{}, error) {
+	switch method {
+	case "hello":
+		return map[string]interface{}{"protocol": nvimProtocolVersion, "version": versionString()}, nil
+	case "ask":
+		if strings.TrimSpace(p.Prompt) == "" {
+			return nil, nvimInvalid("no prompt")
+		}
+		answer, err := s.ask(id, nvimPrompt(p.Prompt, p))
+		return map[string]string{"answer": answer}, err
+	case "edit":
+		if strings.TrimSpace(p.Instruction) == "" || p.Text == "" {
+			return nil, nvimInvalid("edit needs an instruction and a text")
+		}
+		kind := "text"
+		if p.Filetype != "" {
+			kind = p.Filetype + " code"
+		}
+		answer, err := s.ask(id, fmt.Sprintf(nvimEditPrompt, kind, p.Instruction, fenced(p.Text, p.Filetype)))
+		if err != nil {
+			return nil, err
+		}
+		return map[string]string{"text": editedText(answer, p.Text), "answer": answer}, nil
+	case "cancel":
+		s.mu.Lock()
+		cancel, ok := s.pending[p.ID]
+		s.mu.Unlock()
+		if ok {
+			cancel()
+		}
+		return map[string]bool{"cancelled": ok}, nil
+	case "reset":
+		err := s.do(func(api *MemoryAPI) error {
+			return chatError(chatclient.NewWithHTTPClient(api.ServerURL, nvimClientType, api.Client).Reset(context.Background()))
+		})
+		return map[string]bool{"reset": err == nil}, err
+	case "memory_add":
+		if strings.TrimSpace(p.Text) == "" {
+			return nil, nvimInvalid("no text")
+		}
+		text, _ := redactText(p.Text)
+		var memory Memory
+		err := s.do(func(api *MemoryAPI) error {
+			var err error
+			memory, err = api.addMemory(text, p.Metadata)
+			return err
+		})
+		return map[string]interface{}{"memory": memory}, err
+	case "memory_search":
+		if strings.TrimSpace(p.Query) == "" {
+			return nil, nvimInvalid("no query")
+		}
+		var memories []Memory
+		err := s.do(func(api *MemoryAPI) error {
+			var err error
+			memories, err = api.SearchMemories(p.Query, p.Limit)
+			return err
+		})
+		if memories == nil {
+			memories = []Memory{}
+		}
+		return map[string]interface{}{"memories": memories}, err
+	}
+	return nil, fmt.Errorf("%w %q", errNvimUnknownMethod, method)
+}
+
+// ask sends a prompt to Tom, cancelled by a cancel request with its id
+func (s *nvimServer) ask(id int64, prompt string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), nvimAskTimeout)
+	defer cancel()
+	s.mu.Lock()
+	s.pending[id] = cancel
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.pending, id)
+		s.mu.Unlock()
+	}()
+
+	var answer chatclient.Answer
+	err := s.do(func(api *MemoryAPI) error {
+		var err error
+		answer, err = chatclient.NewWithHTTPClient(api.ServerURL, nvimClientType, api.Client).Process(ctx, prompt)
+		return chatError(err)
+	})
+	return answer.Text, err
+}
+
+// nvimPrompt follows the prompt with the text sent from the editor, if any
+func nvimPrompt(prompt string, p nvimParams) string {
+	if p.Text == "" {
+		return prompt
+	}
+	var b strings.Builder
+	b.WriteString(prompt)
+	b.WriteString("\n\n")
+	if p.Path != "" {
+		fmt.Fprintf(&b, "From %s:\n", p.Path)
+	}
+	b.WriteString(fenced(p.Text, p.Filetype))
+	return b.String()
+}
+
+// fenced puts a text in a code block, with a fence longer than those inside
+func fenced(text, lang string) string {
+	fence := "```"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+	return fence + lang + "\n" + strings.TrimRight(text, "\n") + "\n" + fence
+}
+
+// editedText is the text of an edit answer: its code block, or the answer
+// when it has none, with the final newline of the original
+func editedText(answer, original string) string {
+	text := strings.TrimSpace(answer)
+	if blocks := extractCodeBlocks(answer); len(blocks) > 0 {
+		text = strings.TrimRight(blocks[0].code, "\n")
+	}
+	if strings.HasSuffix(original, "\n") {
+		text += "\n"
+	}
+	return text
+}
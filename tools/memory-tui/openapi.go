@@ -0,0 +1,153 @@
+package main
+
+// serveLocalOpenAPI documents the API of serve-local, served at
+// /openapi.json for the tools importing it
+const serveLocalOpenAPI = `{
+  "openapi": "3.0.3",
+  "info": {
+    "title": "Tom local API",
+    "version": "1.0.0",
+    "description": "Local REST API of memory-tui serve-local, in front of the Tom session. Send the token of ~/.tom/serve-local.token as a bearer token."
+  },
+  "servers": [{"url": "http://127.0.0.1:8765"}],
+  "security": [{"bearer": []}],
+  "paths": {
+    "/v1/ask": {
+      "post": {
+        "summary": "Ask Tom",
+        "description": "Sends a prompt to Tom, in the conversation of the local API, and returns his answer.",
+        "requestBody": {
+          "required": true,
+          "content": {"application/json": {"schema": {
+            "type": "object",
+            "properties": {
+              "prompt": {"type": "string", "description": "The request; may be empty with reset"},
+              "reset": {"type": "boolean", "description": "Start a new conversation first"}
+            }
+          }}}
+        },
+        "responses": {
+          "200": {"description": "The answer", "content": {"application/json": {"schema": {
+            "type": "object",
+            "properties": {
+              "answer": {"type": "string"},
+              "conversation_reset": {"type": "boolean"}
+            }
+          }}}},
+          "400": {"$ref": "#/components/responses/Error"},
+          "401": {"$ref": "#/components/responses/Error"},
+          "502": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/v1/memories": {
+      "get": {
+        "summary": "List or search the memories",
+        "parameters": [
+          {"name": "q", "in": "query", "schema": {"type": "string"}, "description": "Search query; all the memories without it"},
+          {"name": "limit", "in": "query", "schema": {"type": "integer", "minimum": 1}}
+        ],
+        "responses": {
+          "200": {"description": "The memories", "content": {"application/json": {"schema": {
+            "type": "object",
+            "properties": {"memories": {"type": "array", "items": {"$ref": "#/components/schemas/Memory"}}}
+          }}}},
+          "401": {"$ref": "#/components/responses/Error"},
+          "502": {"$ref": "#/components/responses/Error"}
+        }
+      },
+      "post": {
+        "summary": "Add a memory",
+        "description": "The redactions of the memory-tui configuration apply to the text.",
+        "requestBody": {
+          "required": true,
+          "content": {"application/json": {"schema": {
+            "type": "object",
+            "required": ["text"],
+            "properties": {
+              "text": {"type": "string"},
+              "metadata": {"type": "object", "additionalProperties": true}
+            }
+          }}}
+        },
+        "responses": {
+          "201": {"description": "Added", "content": {"application/json": {"schema": {
+            "type": "object",
+            "properties": {
+              "text": {"type": "string", "description": "The text stored, masked"},
+              "masked": {"type": "integer", "description": "Values masked"}
+            }
+          }}}},
+          "400": {"$ref": "#/components/responses/Error"},
+          "401": {"$ref": "#/components/responses/Error"},
+          "403": {"$ref": "#/components/responses/Error"},
+          "502": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/v1/memories/{id}": {
+      "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}],
+      "get": {
+        "summary": "Read a memory",
+        "responses": {
+          "200": {"description": "The memory", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Memory"}}}},
+          "401": {"$ref": "#/components/responses/Error"},
+          "404": {"$ref": "#/components/responses/Error"},
+          "502": {"$ref": "#/components/responses/Error"}
+        }
+      },
+      "delete": {
+        "summary": "Delete a memory",
+        "description": "Moved to the trash when the server has one.",
+        "responses": {
+          "204": {"description": "Deleted"},
+          "401": {"$ref": "#/components/responses/Error"},
+          "403": {"$ref": "#/components/responses/Error"},
+          "404": {"$ref": "#/components/responses/Error"},
+          "502": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/v1/notifications": {
+      "get": {
+        "summary": "Notification status of Tom's modules",
+        "responses": {
+          "200": {"description": "The modules having something to report", "content": {"application/json": {"schema": {
+            "type": "object",
+            "properties": {"notifications": {"type": "array", "items": {
+              "type": "object",
+              "properties": {"module": {"type": "string"}, "status": {"type": "string"}}
+            }}}
+          }}}},
+          "401": {"$ref": "#/components/responses/Error"},
+          "502": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    }
+  },
+  "components": {
+    "securitySchemes": {"bearer": {"type": "http", "scheme": "bearer"}},
+    "schemas": {
+      "Memory": {
+        "type": "object",
+        "properties": {
+          "id": {"type": "string"},
+          "memory": {"type": "string", "description": "The text"},
+          "hash": {"type": "string"},
+          "created_at": {"type": "string"},
+          "updated_at": {"type": "string", "nullable": true},
+          "user_id": {"type": "string"},
+          "metadata": {"type": "object", "additionalProperties": true, "nullable": true}
+        }
+      },
+      "Error": {
+        "type": "object",
+        "properties": {"error": {"type": "string"}}
+      }
+    },
+    "responses": {
+      "Error": {"description": "The request failed", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}}
+    }
+  }
+}
+`
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbletea"
+)
+
+// Paste detection. Bubble Tea does not decode bracketed paste yet, so a paste
+// arrives as a burst of keys (words, spaces, Enters) read in one go; a burst
+// is recognized by keys following each other faster than anyone types.
+// Enter in a burst never submits the prompt: the text moves to the add view
+// editor with its newlines, and a paste longer than chunk_size asks whether to
+// split it into several memories.
+const (
+	pasteBurstGap = 25 * time.Millisecond  // Max delay between the keys of a paste
+	pasteSettle   = 100 * time.Millisecond // Quiet time ending a paste
+)
+
+type pasteDetector struct {
+	last     time.Time
+	lastText bool // The previous key inserted text
+	active   bool // Within a burst
+	started  bool // The last key observed started a burst
+	runes    int  // Characters of the last burst, until it settles
+}
+
+type pasteSettledMsg struct{}
+
+func keyText(msg tea.KeyMsg) (string, bool) {
+	switch msg.Type {
+	case tea.KeyRunes:
+		return string(msg.Runes), true
+	case tea.KeySpace:
+		return " ", true
+	case tea.KeyEnter:
+		return "\n", true
+	case tea.KeyTab:
+		return "\t", true
+	}
+	return "", false
+}
+
+// observe reports whether msg is part of a paste, and schedules the end of a
+// burst it starts
+func (p *pasteDetector) observe(msg tea.KeyMsg) (bool, tea.Cmd) {
+	now := time.Now()
+	fast := now.Sub(p.last) < pasteBurstGap
+	text, isText := keyText(msg)
+	prevText := p.lastText
+	p.last, p.lastText, p.started = now, isText, false
+
+	switch {
+	case !isText || msg.Alt:
+	case p.active && (fast || utf8.RuneCountInString(text) > 1):
+		p.runes += utf8.RuneCountInString(text)
+		return true, nil
+	case utf8.RuneCountInString(text) > 1 || (fast && prevText):
+		p.active, p.started = true, true
+		p.runes = utf8.RuneCountInString(text)
+		return true, tea.Tick(pasteSettle, func(time.Time) tea.Msg { return pasteSettledMsg{} })
+	}
+	p.active = false
+	return false, nil
+}
+
+// pasteKey turns the Tabs of a paste into spaces, they would switch the focus
+func pasteKey(msg tea.KeyMsg) tea.KeyMsg {
+	if msg.Type == tea.KeyTab {
+		return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("    ")}
+	}
+	return msg
+}
+
+// openComposer opens the add view editor with text, dropping an /add prefix
+func (m model) openComposer(text string) (model, tea.Cmd) {
+	if m.readOnly() {
+		m.err = readOnlyError()
+		return m, nil
+	}
+	for _, prefix := range []string{"/add ", "/a "} {
+		if rest, ok := strings.CutPrefix(text, prefix); ok {
+			text = rest
+			break
+		}
+	}
+	m.promptInput.SetValue("")
+	m.textArea.Reset()
+	m.textArea.SetValue(text)
+	m.editHistory.reset()
+	m.spellText, m.spellIssues, m.spellIndex = "", nil, 0
+	m.pasteConfirm = 0
+	m.merging = nil
+	m.composeMetadata = nil
+	return m, m.showView(addView)
+}
+
+// takesPaste reports whether the focused widget is a text field, elsewhere
+// keys are shortcuts and go through unchanged
+func (m model) takesPaste() bool {
+	if m.focus == focusPrompt {
+		return true
+	}
+	return m.state == addView || m.state == searchView || m.state == templateView
+}
+
+// handlePasteKey routes the keys of a paste
+func (m model) handlePasteKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.focus == focusPrompt && msg.Type == tea.KeyEnter {
+		// A multi-line paste, continue it in the editor
+		return m.openComposer(m.promptInput.Value() + "\n")
+	}
+	msg = pasteKey(msg)
+	if m.focus == focusPrompt {
+		var cmd tea.Cmd
+		m.promptInput, cmd = m.promptInput.Update(msg)
+		return m, cmd
+	}
+
+	switch m.state {
+	case addView:
+		return m.updateAddView(msg)
+	case searchView:
+		if msg.Type != tea.KeyEnter {
+			return m.updateSearchView(msg)
+		}
+	case templateView:
+		if msg.Type != tea.KeyEnter {
+			return m.updateTemplateView(msg)
+		}
+	}
+	return m, nil
+}
+
+func (m model) handlePasteSettled() (tea.Model, tea.Cmd) {
+	if m.paste.active {
+		if wait := pasteSettle - time.Since(m.paste.last); wait > 0 {
+			return m, tea.Tick(wait, func(time.Time) tea.Msg { return pasteSettledMsg{} })
+		}
+	}
+	size := m.paste.runes
+	m.paste.active, m.paste.runes = false, 0
+	m.editHistory.grouping = false
+	if size <= m.chunkSize {
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	if m.focus == focusPrompt {
+		m, cmd = m.openComposer(m.promptInput.Value())
+	}
+	if m.state == addView {
+		m.pasteConfirm = size
+	}
+	return m, cmd
+}
+
+// updatePasteConfirm answers the large paste question of the add view
+func (m model) updatePasteConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.pasteConfirm = 0
+	switch msg.String() {
+	case "c", "enter":
+		return m.openChunkPreview(m.textArea.Value()), nil
+	}
+	m.message = tr("Paste kept as a single memory")
+	return m, nil
+}
+
+func (m model) renderPasteConfirm() string {
+	return meterWarningStyle.Render(fmt.Sprintf(
+		"Pasted %d characters, over the %d of a chunk. C/Enter: split into memories | other keys: keep editing",
+		m.pasteConfirm, m.chunkSize))
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sahilm/fuzzy"
+)
+
+// `memory-tui pick`: a fuzzy finder over the memories drawn on the terminal
+// while stdout only gets the choice, for shell workflows such as
+// `memory-tui pick | xargs -r memory-tui ctl goto`. The daemon cache fills it
+// at once when available, then the server list replaces it.
+var errNothingPicked = errors.New("nothing picked")
+
+var pickMatchStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#FFAF00")).Bold(true)
+
+type pickLoadedMsg struct {
+	memories []Memory
+	cached   bool // From the daemon, the server list may follow
+	err      error
+}
+
+type pickerModel struct {
+	input    textinput.Model
+	spinner  spinner.Model
+	memories []Memory
+	matches  []fuzzy.Match
+	cursor   int
+	loading  bool
+	err      error
+	width    int
+	height   int
+	chosen   *Memory
+}
+
+// pickSource lets fuzzy search the memories as single lines
+type pickSource []Memory
+
+func (s pickSource) String(i int) string { return singleLine(s[i].Memory) }
+func (s pickSource) Len() int            { return len(s) }
+
+func newPicker(query string) pickerModel {
+	input := textinput.New()
+	input.Prompt = "> "
+	input.Placeholder = "type to filter"
+	input.SetValue(query)
+	input.Focus()
+
+	return pickerModel{input: input, spinner: newSpinner(), loading: true}
+}
+
+func pickFromDaemon() tea.Msg {
+	resp, err := callDaemon("memories")
+	if err != nil {
+		return nil
+	}
+	return pickLoadedMsg{memories: resp.Memories, cached: true}
+}
+
+func pickFromServer() tea.Msg {
+	sess, err := openSession()
+	if err != nil {
+		return pickLoadedMsg{err: err}
+	}
+	memories, err := sess.API.GetAllMemories()
+	return pickLoadedMsg{memories: memories, err: err}
+}
+
+func (p pickerModel) Init() tea.Cmd {
+	return tea.Batch(textinput.Blink, p.spinner.Tick, pickFromDaemon, pickFromServer)
+}
+
+// filter matches the memories against the query, every memory when empty
+func (p *pickerModel) filter() {
+	query := strings.TrimSpace(p.input.Value())
+	if query == "" {
+		p.matches = make([]fuzzy.Match, len(p.memories))
+		for i, mem := range p.memories {
+			p.matches[i] = fuzzy.Match{Str: singleLine(mem.Memory), Index: i}
+		}
+	} else {
+		p.matches = fuzzy.FindFrom(query, pickSource(p.memories))
+	}
+	p.cursor = min(p.cursor, max(0, len(p.matches)-1))
+}
+
+func (p pickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		p.width, p.height = msg.Width, msg.Height
+		p.input.Width = max(10, msg.Width-4)
+		return p, nil
+
+	case pickLoadedMsg:
+		if msg.err != nil {
+			// Keep the cached list when the server fails
+			p.err = msg.err
+			p.loading = false
+			return p, nil
+		}
+		if msg.cached && !p.loading {
+			return p, nil // The server answered first
+		}
+		p.memories = msg.memories
+		p.loading = msg.cached
+		p.filter()
+		return p, nil
+
+	case spinner.TickMsg:
+		if !p.loading {
+			return p, nil
+		}
+		var cmd tea.Cmd
+		p.spinner, cmd = p.spinner.Update(msg)
+		return p, cmd
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "esc":
+			return p, tea.Quit
+		case "enter":
+			if len(p.matches) > 0 {
+				chosen := p.memories[p.matches[p.cursor].Index]
+				p.chosen = &chosen
+			}
+			return p, tea.Quit
+		case "up", "ctrl+p", "ctrl+k":
+			if p.cursor > 0 {
+				p.cursor--
+			}
+			return p, nil
+		case "down", "ctrl+n", "ctrl+j":
+			if p.cursor < len(p.matches)-1 {
+				p.cursor++
+			}
+			return p, nil
+		}
+	}
+
+	before := p.input.Value()
+	var cmd tea.Cmd
+	p.input, cmd = p.input.Update(msg)
+	if p.input.Value() != before {
+		p.cursor = 0
+		p.filter()
+	}
+	return p, cmd
+}
+
+// highlight shows the matched characters of a line cut to width
+func highlight(line string, matched []int, width int) string {
+	isMatch := make(map[int]bool, len(matched))
+	for _, i := range matched {
+		isMatch[i] = true
+	}
+	var b strings.Builder
+	used := 0
+	for i, r := range line {
+		w := lipgloss.Width(string(r))
+		if used+w > width {
+			b.WriteString("…")
+			break
+		}
+		used += w
+		if isMatch[i] {
+			b.WriteString(pickMatchStyle.Render(string(r)))
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func (p pickerModel) View() string {
+	var b strings.Builder
+	b.WriteString(p.input.View())
+	b.WriteString("\n")
+
+	status := fmt.Sprintf("  %d/%d", len(p.matches), len(p.memories))
+	if p.loading {
+		status += " " + p.spinner.View()
+	}
+	if p.err != nil {
+		status += " " + loginErrorStyle.Render(p.err.Error())
+	}
+	b.WriteString(helpStyle.Render(status))
+	b.WriteString("\n")
+
+	rows := max(1, p.height-3)
+	start := max(0, p.cursor-rows+1)
+	width := max(20, p.width-4)
+	for i := start; i < len(p.matches) && i < start+rows; i++ {
+		line := highlight(p.matches[i].Str, p.matches[i].MatchedIndexes, width)
+		if i == p.cursor {
+			b.WriteString(selectedItemStyle.Render("▌ ") + line)
+		} else {
+			b.WriteString("  " + line)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// runPick draws the finder on the terminal and prints the choice on stdout
+func runPick(query, print string) error {
+	if print != "id" && print != "text" {
+		return fmt.Errorf("unknown --print value %q (id or text)", print)
+	}
+
+	// Never log over the finder
+	if closeLog, err := setupLogging(); err == nil {
+		defer closeLog()
+	} else {
+		log.SetOutput(io.Discard)
+	}
+
+	if cfg, err := loadConfig(); err == nil {
+		enableLowBandwidth(cfg, false)
+	}
+
+	// stdout may be a pipe: draw on stderr and read the keys from the tty
+	program := tea.NewProgram(newPicker(query), programOptions(tea.WithOutput(os.Stderr), tea.WithInputTTY(), tea.WithAltScreen())...)
+	final, err := program.Run()
+	if err != nil {
+		return err
+	}
+	chosen := final.(pickerModel).chosen
+	if chosen == nil {
+		return errNothingPicked
+	}
+	if print == "text" {
+		fmt.Println(chosen.Memory)
+	} else {
+		fmt.Println(chosen.ID)
+	}
+	return nil
+}
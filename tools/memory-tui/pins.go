@@ -0,0 +1,258 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/bubbletea"
+)
+
+// Pins: p pins the answer in view, from /ask, /digest, /mail (the draft) or
+// /news (the summary), in the local store with the server it came from, so
+// a recipe or a code Tom gave stays at hand. /pins [QUERY] lists the pins of
+// the server, those holding QUERY when given; /pins export [PATH] writes
+// them as Markdown to PATH or the clipboard.
+const pinListHeight = 8 // Pins listed above the preview
+
+var pinSourceIcons = map[string]string{"ask": "💬", "digest": "☀️", "mail": "📬", "news": "📰"}
+
+type pin struct {
+	ID        int64     `json:"id"`
+	Server    string    `json:"server"`
+	Source    string    `json:"source"` // "ask", "digest", "mail" or "news"
+	Title     string    `json:"title"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func addPin(p pin) (int64, error) {
+	db, err := openStore()
+	if err != nil {
+		return 0, err
+	}
+	res, err := db.Exec(`INSERT INTO pin (server, source, title, text, created_at) VALUES (?, ?, ?, ?, ?)`,
+		p.Server, p.Source, p.Title, p.Text, p.CreatedAt.Format(time.RFC3339))
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+// loadPins returns the pins of the server, newest first, all of them when
+// server is empty
+func loadPins(server string) ([]pin, error) {
+	db, err := openStore()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.Query(`SELECT id, server, source, title, text, created_at FROM pin
+		WHERE ? = '' OR server = ? ORDER BY id DESC`, server, server)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pins []pin
+	for rows.Next() {
+		var p pin
+		var createdAt string
+		if err := rows.Scan(&p.ID, &p.Server, &p.Source, &p.Title, &p.Text, &createdAt); err != nil {
+			return nil, err
+		}
+		p.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+		pins = append(pins, p)
+	}
+	return pins, rows.Err()
+}
+
+func removePin(id int64) error {
+	db, err := openStore()
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`DELETE FROM pin WHERE id = ?`, id)
+	return err
+}
+
+// searchPins keeps the pins holding the query in their title or text
+func searchPins(pins []pin, query string) []pin {
+	if query == "" {
+		return pins
+	}
+	var found []pin
+	for _, p := range pins {
+		if containsFold(p.Title, query) || containsFold(p.Text, query) {
+			found = append(found, p)
+		}
+	}
+	return found
+}
+
+func formatPinsMarkdown(pins []pin) string {
+	var b strings.Builder
+	b.WriteString("# Tom pins\n\n")
+	for _, p := range pins {
+		b.WriteString("## " + truncateString(p.Title, 80) + "\n\n")
+		b.WriteString(fmt.Sprintf("_%s, %s_\n\n", p.Source, p.CreatedAt.Local().Format("2006-01-02 15:04")))
+		b.WriteString(p.Text + "\n\n")
+	}
+	return b.String()
+}
+
+// pinAnswer pins an answer shown in a view
+func (m model) pinAnswer(source, title, text string) (tea.Model, tea.Cmd) {
+	if strings.TrimSpace(text) == "" {
+		return m, nil
+	}
+	p := pin{Server: m.api.ServerURL, Source: source, Title: title, Text: text, CreatedAt: time.Now()}
+	if _, err := addPin(p); err != nil {
+		m.err = err
+		return m, nil
+	}
+	logf("INFO", "%s answer pinned", source)
+	m.message = tr("📌 Pinned, see /pins")
+	return m, nil
+}
+
+func runPinsCommand(m model, args string) (tea.Model, tea.Cmd) {
+	pins, err := loadPins(m.api.ServerURL)
+	if err != nil {
+		m.err = err
+		return m, nil
+	}
+	if rest, ok := strings.CutPrefix(args, "export"); ok && (rest == "" || rest[0] == ' ') {
+		return m, exportPins(pins, expandHome(strings.TrimSpace(rest)))
+	}
+
+	m.pins = searchPins(pins, args)
+	m.pinQuery = args
+	m.pinCursor = 0
+	m.layoutPin()
+	cmd := m.showView(pinsView)
+	return m, cmd
+}
+
+// exportPins writes the pins to path, or to the clipboard when path is empty
+func exportPins(pins []pin, path string) tea.Cmd {
+	return func() tea.Msg {
+		content := formatPinsMarkdown(pins)
+		if path == "" {
+			if err := clipboard.WriteAll(content); err != nil {
+				return errMsg{fmt.Errorf("failed to copy to clipboard: %w", err)}
+			}
+			return pinsExportedMsg{len(pins), "clipboard"}
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			return errMsg{err}
+		}
+		return pinsExportedMsg{len(pins), path}
+	}
+}
+
+type pinsExportedMsg struct {
+	count  int
+	target string
+}
+
+// Renders the Markdown of the pinned answers
+var pinMarkdown markdownCache
+
+// layoutPin shows the selected pin in the preview
+func (m *model) layoutPin() {
+	m.pinViewport = viewport.New(max(20, m.width-8), max(3, m.height-14-min(len(m.pins), pinListHeight)))
+	if len(m.pins) > 0 {
+		m.pinViewport.SetContent(pinMarkdown.render(m.pins[m.pinCursor].Text, max(20, m.width-10)))
+	}
+}
+
+func (m model) updatePinsView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		cmd := m.showView(listView)
+		return m, cmd
+	case "up", "k":
+		if m.pinCursor > 0 {
+			m.pinCursor--
+			m.layoutPin()
+		}
+		return m, nil
+	case "down", "j":
+		if m.pinCursor < len(m.pins)-1 {
+			m.pinCursor++
+			m.layoutPin()
+		}
+		return m, nil
+	case "y":
+		if len(m.pins) == 0 {
+			return m, nil
+		}
+		if err := clipboard.WriteAll(m.pins[m.pinCursor].Text); err != nil {
+			m.err = fmt.Errorf("failed to copy to clipboard: %w", err)
+			return m, nil
+		}
+		m.message = tr("Pin copied to the clipboard")
+		return m, nil
+	case "x", "delete":
+		if len(m.pins) == 0 {
+			return m, nil
+		}
+		if err := removePin(m.pins[m.pinCursor].ID); err != nil {
+			m.err = err
+			return m, nil
+		}
+		m.pins = append(m.pins[:m.pinCursor:m.pinCursor], m.pins[m.pinCursor+1:]...)
+		m.pinCursor = min(m.pinCursor, max(0, len(m.pins)-1))
+		m.layoutPin()
+		m.message = tr("Pin removed")
+		return m, nil
+	case "e":
+		return m, exportPins(m.pins, "")
+	}
+	var cmd tea.Cmd
+	m.pinViewport, cmd = m.pinViewport.Update(msg)
+	return m, cmd
+}
+
+func (m model) renderPinsView() string {
+	style := contentBoxStyle.Width(m.width - 4)
+	if m.focus == focusContent {
+		style = contentBoxFocusedStyle.Width(m.width - 4)
+	}
+	width := max(20, m.width-10)
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(tr("📌 Pins")))
+	if m.pinQuery != "" {
+		b.WriteString(" " + helpStyle.Render(tr("%d holding %q", len(m.pins), m.pinQuery)))
+	}
+	b.WriteString("\n\n")
+	if len(m.pins) == 0 {
+		b.WriteString(helpStyle.Render(tr("No pin: p pins the answer shown by /ask, /digest, /mail or /news")))
+		b.WriteString("\n\n")
+		b.WriteString(m.keyHint(""))
+		return style.Render(b.String())
+	}
+
+	// The list scrolls to keep the selected pin in view
+	start := max(0, m.pinCursor-pinListHeight+1)
+	for i := start; i < min(len(m.pins), start+pinListHeight); i++ {
+		p := m.pins[i]
+		line := fmt.Sprintf("%s %s  %s", pinSourceIcons[p.Source], p.CreatedAt.Local().Format("01-02 15:04"), p.Title)
+		line = truncateString(line, width-2)
+		if i == m.pinCursor {
+			b.WriteString(selectedItemStyle.Render("▸ " + line))
+		} else {
+			b.WriteString("  " + line)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	b.WriteString(m.pinViewport.View())
+	b.WriteString("\n\n")
+	b.WriteString(m.keyHint(""))
+	return style.Render(b.String())
+}
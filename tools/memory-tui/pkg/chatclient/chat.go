@@ -0,0 +1,128 @@
+package chatclient
+
+import (
+	"context"
+	"net/http"
+)
+
+// Answer is Tom's answer to a request
+type Answer struct {
+	Text string
+
+	// Speech is the answer rewritten to be read aloud, sent when the
+	// request was made WithSound(true)
+	Speech string
+
+	// ConversationReset is set when the request asked for a new
+	// conversation, which Tom started instead of answering
+	ConversationReset bool
+}
+
+// Position is where the user is, for the requests depending on it
+type Position struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// Option sets a field of a request to /process
+type Option func(map[string]interface{})
+
+// WithPosition sends the position of the user with the request
+func WithPosition(p Position) Option {
+	return func(payload map[string]interface{}) { payload["position"] = p }
+}
+
+// WithSound tells Tom the client plays sounds, such as spoken answers
+func WithSound(enabled bool) Option {
+	return func(payload map[string]interface{}) { payload["sound_enabled"] = enabled }
+}
+
+// WithoutHistory makes the request stateless: Tom answers it without the
+// conversation of the client type, and keeps neither the request nor the
+// answer in it
+func WithoutHistory() Option {
+	return func(payload map[string]interface{}) { payload["history"] = false }
+}
+
+// Streamer hands an answer over in chunks, onChunk being called for each of
+// them in order; an error from onChunk stops the answer and is returned
+type Streamer interface {
+	Stream(ctx context.Context, request string, onChunk func(chunk string) error, opts ...Option) (Answer, error)
+}
+
+var _ Streamer = (*Client)(nil)
+
+type processResponse struct {
+	Status            string `json:"status"`
+	Response          string `json:"response"`
+	TextDisplay       string `json:"text_display"` // Instead of response with sound_enabled
+	TextTTS           string `json:"text_tts"`
+	Message           string `json:"message"` // Set on errors
+	ConversationReset bool   `json:"conversation_reset"`
+}
+
+// Process sends a request to Tom, in the conversation of the client type,
+// and returns his answer
+func (c *Client) Process(ctx context.Context, request string, opts ...Option) (Answer, error) {
+	payload := map[string]interface{}{"request": request, "client_type": c.ClientType}
+	for _, opt := range opts {
+		opt(payload)
+	}
+	var r processResponse
+	if err := c.do(ctx, http.MethodPost, "/process", payload, &r); err != nil {
+		return Answer{}, err
+	}
+	if r.Status == "ERROR" {
+		return Answer{}, &APIError{StatusCode: http.StatusOK, Message: r.Message}
+	}
+	text := r.Response
+	if text == "" {
+		text = r.TextDisplay
+	}
+	return Answer{Text: text, Speech: r.TextTTS, ConversationReset: r.ConversationReset}, nil
+}
+
+// Stream sends a request as Process does, handing the answer to onChunk as
+// it comes. The server sends each answer whole, so it comes in one chunk.
+func (c *Client) Stream(ctx context.Context, request string, onChunk func(chunk string) error, opts ...Option) (Answer, error) {
+	answer, err := c.Process(ctx, request, opts...)
+	if err != nil {
+		return Answer{}, err
+	}
+	if err := onChunk(answer.Text); err != nil {
+		return Answer{}, err
+	}
+	return answer, nil
+}
+
+// Reset clears the conversation history of the client type, Tom starting
+// the next request afresh
+func (c *Client) Reset(ctx context.Context) error {
+	var r processResponse
+	if err := c.do(ctx, http.MethodPost, "/reset", map[string]interface{}{"client_type": c.ClientType}, &r); err != nil {
+		return err
+	}
+	if r.Status == "ERROR" {
+		return &APIError{StatusCode: http.StatusOK, Message: r.Message}
+	}
+	return nil
+}
+
+// Task is the status a module reports, such as unread mails or reminders
+type Task struct {
+	Module string `json:"module"`
+	Status string `json:"status"`
+}
+
+// Tasks is the status of the modules at a time
+type Tasks struct {
+	ID         int64  `json:"id"` // Time of the status, in seconds since the epoch
+	Background []Task `json:"background_tasks"`
+}
+
+// Tasks returns the status of the modules having something to report
+func (c *Client) Tasks(ctx context.Context) (Tasks, error) {
+	var t Tasks
+	err := c.do(ctx, http.MethodGet, "/tasks", nil, &t)
+	return t, err
+}
@@ -0,0 +1,194 @@
+package chatclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// sessionCookieName is the cookie of a Tom session
+const sessionCookieName = "session_id"
+
+// Credentials log the client in again when its session expires
+type Credentials struct {
+	Username string
+	Password string
+}
+
+// Client talks to Tom as a chat client. Its methods are safe to call from
+// several goroutines.
+type Client struct {
+	// BaseURL is the Tom server, such as https://tom.example.com
+	BaseURL string
+
+	// ClientType names the conversation of the client on the server, whose
+	// history Tom keeps per client type: web, android, tui, pwa, or a name
+	// of lowercase letters, digits, - and _, 32 characters at most
+	ClientType string
+
+	// HTTPClient sends the requests and keeps the session cookie in its jar
+	HTTPClient *http.Client
+
+	// Credentials, when set, log in on the first request and again once
+	// the session expired
+	Credentials *Credentials
+
+	login sync.Mutex // One login at a time
+}
+
+// New returns a client of the server for the client type, not logged in yet
+func New(baseURL, clientType string) *Client {
+	jar, _ := cookiejar.New(nil)
+	return NewWithHTTPClient(baseURL, clientType, &http.Client{Jar: jar})
+}
+
+// NewWithHTTPClient returns a client sending its requests through hc, which
+// needs a cookie jar to log in
+func NewWithHTTPClient(baseURL, clientType string, hc *http.Client) *Client {
+	return &Client{BaseURL: strings.TrimRight(baseURL, "/"), ClientType: clientType, HTTPClient: hc}
+}
+
+// Login opens a session with a Tom username and password
+func (c *Client) Login(ctx context.Context, username, password string) error {
+	c.login.Lock()
+	defer c.login.Unlock()
+	c.ensureJar()
+
+	form := url.Values{"username": {username}, "password": {password}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.BaseURL+"/login", strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return &APIError{StatusCode: resp.StatusCode, Message: "login failed: " + strings.TrimSpace(string(body))}
+	}
+	// The server answers bad credentials with a 200 page instead of redirecting
+	if strings.Contains(string(body), "Invalid credentials") {
+		return ErrInvalidCredentials
+	}
+	return nil
+}
+
+// Logout closes the session
+func (c *Client) Logout(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.BaseURL+"/logout", nil)
+	if err != nil {
+		return err
+	}
+	// The server redirects to its login page, which is not worth loading
+	hc := *c.HTTPClient
+	hc.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// SessionCookie returns the session cookie as "session_id=VALUE", to keep
+// and give to SetSessionCookie later, empty when not logged in
+func (c *Client) SessionCookie() string {
+	u, err := url.Parse(c.BaseURL)
+	if err != nil || c.HTTPClient.Jar == nil {
+		return ""
+	}
+	for _, cookie := range c.HTTPClient.Jar.Cookies(u) {
+		if cookie.Name == sessionCookieName {
+			return cookie.Name + "=" + cookie.Value
+		}
+	}
+	return ""
+}
+
+// SetSessionCookie reuses a session, given as "session_id=VALUE" with
+// optional attributes after a semicolon
+func (c *Client) SetSessionCookie(raw string) error {
+	pair, _, _ := strings.Cut(raw, ";")
+	name, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+	if !ok {
+		return fmt.Errorf("invalid session cookie %q", raw)
+	}
+	u, err := url.Parse(c.BaseURL)
+	if err != nil {
+		return fmt.Errorf("invalid server URL: %w", err)
+	}
+	c.ensureJar()
+	c.HTTPClient.Jar.SetCookies(u, []*http.Cookie{{Name: name, Value: value}})
+	return nil
+}
+
+func (c *Client) ensureJar() {
+	if c.HTTPClient.Jar == nil {
+		jar, _ := cookiejar.New(nil)
+		c.HTTPClient.Jar = jar
+	}
+}
+
+// do sends a request to Tom, payload going as JSON when not nil, and decodes
+// the answer into v. Without a session, or once it expired, the client logs
+// in with its credentials and sends the request again.
+func (c *Client) do(ctx context.Context, method, endpoint string, payload, v interface{}) error {
+	if c.Credentials != nil && c.SessionCookie() == "" {
+		if err := c.Login(ctx, c.Credentials.Username, c.Credentials.Password); err != nil {
+			return err
+		}
+	}
+	err := c.send(ctx, method, endpoint, payload, v)
+	if errors.Is(err, ErrUnauthorized) && c.Credentials != nil {
+		if err := c.Login(ctx, c.Credentials.Username, c.Credentials.Password); err != nil {
+			return err
+		}
+		err = c.send(ctx, method, endpoint, payload, v)
+	}
+	return err
+}
+
+func (c *Client) send(ctx context.Context, method, endpoint string, payload, v interface{}) error {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+endpoint, body)
+	if err != nil {
+		return err
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return ErrUnauthorized
+	}
+	if resp.StatusCode != http.StatusOK {
+		return &APIError{StatusCode: resp.StatusCode}
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
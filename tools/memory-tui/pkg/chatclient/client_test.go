@@ -0,0 +1,231 @@
+package chatclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// newTestClient returns a client of type "test" of a test server answering
+// with handler
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return New(server.URL, "test")
+}
+
+// decodePayload reads the JSON payload of a request
+func decodePayload(t *testing.T, r *http.Request) map[string]interface{} {
+	t.Helper()
+	var payload map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		t.Errorf("%s: invalid JSON payload: %v", r.URL.Path, err)
+	}
+	return payload
+}
+
+func TestLogin(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/login" || r.Method != http.MethodPost {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if r.FormValue("username") != "alice" || r.FormValue("password") != "secret" {
+			w.Write([]byte("<p>Invalid credentials</p>"))
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: sessionCookieName, Value: "s1", Path: "/"})
+	})
+
+	if err := c.Login(context.Background(), "alice", "wrong"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("Login with a wrong password: got %v, want ErrInvalidCredentials", err)
+	}
+	if got := c.SessionCookie(); got != "" {
+		t.Errorf("SessionCookie after a failed login = %q, want none", got)
+	}
+	if err := c.Login(context.Background(), "alice", "secret"); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if got, want := c.SessionCookie(), "session_id=s1"; got != want {
+		t.Errorf("SessionCookie = %q, want %q", got, want)
+	}
+}
+
+func TestRelogin(t *testing.T) {
+	var mu sync.Mutex
+	logins, session := 0, ""
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.URL.Path == "/login" {
+			logins++
+			session = fmt.Sprintf("s%d", logins)
+			http.SetCookie(w, &http.Cookie{Name: sessionCookieName, Value: session, Path: "/"})
+			return
+		}
+		if cookie, err := r.Cookie(sessionCookieName); err != nil || cookie.Value != session {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"id": 1, "background_tasks": []}`))
+	})
+
+	// Without credentials the expired session is an error
+	if err := c.SetSessionCookie("session_id=expired"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Tasks(context.Background()); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("Tasks without credentials: got %v, want ErrUnauthorized", err)
+	}
+
+	c.Credentials = &Credentials{Username: "alice", Password: "secret"}
+	if _, err := c.Tasks(context.Background()); err != nil {
+		t.Fatalf("Tasks with an expired session: %v", err)
+	}
+	if _, err := c.Tasks(context.Background()); err != nil {
+		t.Fatalf("Tasks: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if logins != 1 {
+		t.Errorf("logged in %d times, want 1", logins)
+	}
+	if got, want := c.SessionCookie(), "session_id=s1"; got != want {
+		t.Errorf("SessionCookie = %q, want %q", got, want)
+	}
+}
+
+func TestProcess(t *testing.T) {
+	var payload map[string]interface{}
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/process" || r.Method != http.MethodPost {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		payload = decodePayload(t, r)
+		if payload["sound_enabled"] == true {
+			w.Write([]byte(`{"status": "OK", "text_display": "Sunny", "text_tts": "It is sunny"}`))
+			return
+		}
+		w.Write([]byte(`{"status": "OK", "response": "Sunny"}`))
+	})
+
+	answer, err := c.Process(context.Background(), "Weather?")
+	if err != nil {
+		t.Fatalf("Process: %v", err)
+	}
+	if answer.Text != "Sunny" {
+		t.Errorf("Text = %q, want Sunny", answer.Text)
+	}
+	if payload["request"] != "Weather?" || payload["client_type"] != "test" {
+		t.Errorf("payload = %v, want the request and the client type", payload)
+	}
+	if _, ok := payload["history"]; ok {
+		t.Errorf("payload = %v, want no history field by default", payload)
+	}
+
+	answer, err = c.Process(context.Background(), "Weather?", WithSound(true), WithPosition(Position{Latitude: 48.85, Longitude: 2.35}), WithoutHistory())
+	if err != nil {
+		t.Fatalf("Process with options: %v", err)
+	}
+	if answer.Text != "Sunny" || answer.Speech != "It is sunny" {
+		t.Errorf("answer = %+v, want the text_display and text_tts", answer)
+	}
+	if payload["history"] != false {
+		t.Errorf("history = %v, want false with WithoutHistory", payload["history"])
+	}
+	if position, _ := payload["position"].(map[string]interface{}); position["latitude"] != 48.85 || position["longitude"] != 2.35 {
+		t.Errorf("position = %v, want 48.85, 2.35", payload["position"])
+	}
+}
+
+func TestProcessError(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		message string
+	}{
+		{"error status", http.StatusOK, `{"status": "ERROR", "message": "Invalid 'client_type'"}`, "Invalid 'client_type'"},
+		{"HTTP status", http.StatusInternalServerError, "Internal Server Error", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			})
+
+			_, err := c.Process(context.Background(), "Weather?")
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("got %v, want an *APIError", err)
+			}
+			if apiErr.StatusCode != tt.status || apiErr.Message != tt.message {
+				t.Errorf("got %+v, want status %d and message %q", apiErr, tt.status, tt.message)
+			}
+		})
+	}
+}
+
+func TestStream(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status": "OK", "response": "Sunny"}`))
+	})
+
+	var chunks []string
+	answer, err := c.Stream(context.Background(), "Weather?", func(chunk string) error {
+		chunks = append(chunks, chunk)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	if len(chunks) != 1 || chunks[0] != "Sunny" || answer.Text != "Sunny" {
+		t.Errorf("chunks = %q, answer = %q, want one chunk Sunny", chunks, answer.Text)
+	}
+
+	stop := errors.New("stop")
+	if _, err := c.Stream(context.Background(), "Weather?", func(string) error { return stop }); !errors.Is(err, stop) {
+		t.Errorf("Stream with a failing onChunk: got %v, want its error", err)
+	}
+}
+
+func TestReset(t *testing.T) {
+	var payload map[string]interface{}
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/reset" || r.Method != http.MethodPost {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		payload = decodePayload(t, r)
+		w.Write([]byte(`{"status": "OK", "client_type": "test"}`))
+	})
+
+	if err := c.Reset(context.Background()); err != nil {
+		t.Fatalf("Reset: %v", err)
+	}
+	if payload["client_type"] != "test" {
+		t.Errorf("client_type = %v, want test", payload["client_type"])
+	}
+}
+
+func TestTasks(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/tasks" || r.Method != http.MethodGet {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		w.Write([]byte(`{"id": 1700000000, "background_tasks": [{"module": "mail", "status": "3 unread"}]}`))
+	})
+
+	tasks, err := c.Tasks(context.Background())
+	if err != nil {
+		t.Fatalf("Tasks: %v", err)
+	}
+	if tasks.ID != 1700000000 || len(tasks.Background) != 1 || tasks.Background[0] != (Task{Module: "mail", Status: "3 unread"}) {
+		t.Errorf("Tasks = %+v, want the mail status", tasks)
+	}
+}
@@ -0,0 +1,31 @@
+// Package chatclient talks to Tom the way its chat clients do: log in, send
+// requests to /process, reset the conversation with /reset and read the
+// background tasks of the modules with /tasks, so bots, bridges (Matrix,
+// Telegram) or other interfaces can be built on Tom.
+//
+// Each client type has its own conversation history on the server: web,
+// android, tui and pwa, and any other name of lowercase letters, digits, -
+// and _, 32 characters at most, such as telegram. Servers older than the
+// per-type histories keep the requests of the other names in the web
+// conversation.
+//
+//	c := chatclient.New("https://tom.example.com", "telegram")
+//	c.Credentials = &chatclient.Credentials{Username: "alice", Password: "secret"}
+//	answer, err := c.Process(ctx, "What's on my calendar today?")
+//
+// A request made WithoutHistory neither sees nor extends the conversation,
+// for the probes and polls that would clutter it.
+//
+// With Credentials set, the client logs in on the first request and again
+// when the session expires. Stream hands the answer over in chunks through
+// the Streamer interface, so a caller written against it gets partial
+// answers from the servers that send them; the current server sends each
+// answer whole, in a single chunk.
+//
+// Failures are typed: errors.Is(err, ErrUnauthorized) when the session
+// expired and cannot be renewed, ErrInvalidCredentials for a failed login,
+// and an *APIError for the errors Tom reports.
+//
+// The package only needs the standard library. It is a module of its own,
+// go get github.com/Mickael-Roger/tom/tools/memory-tui/pkg/chatclient.
+package chatclient
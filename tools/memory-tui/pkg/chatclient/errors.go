@@ -0,0 +1,31 @@
+package chatclient
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var (
+	// ErrUnauthorized is returned when the server rejects the session and
+	// the client has no credentials to log in again
+	ErrUnauthorized = errors.New("unauthorized: not logged in or session expired")
+
+	// ErrInvalidCredentials is returned by Login for a wrong username or
+	// password
+	ErrInvalidCredentials = errors.New("invalid username or password")
+)
+
+// APIError is an error Tom reported, by an ERROR status in its answer or by
+// the HTTP status
+type APIError struct {
+	StatusCode int    // HTTP status of the answer
+	Message    string // Reported by Tom, empty when it sent none
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("Tom answered %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return "Tom error: " + e.Message
+}
@@ -0,0 +1,179 @@
+package memoryclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+)
+
+// sessionCookieName is the cookie of a Tom session
+const sessionCookieName = "session_id"
+
+// Client talks to the memory store of a Tom server. Its methods are safe to
+// call from several goroutines.
+type Client struct {
+	// BaseURL is the Tom server, such as https://tom.example.com
+	BaseURL string
+
+	// HTTPClient sends the requests and keeps the session cookie in its jar
+	HTTPClient *http.Client
+}
+
+// New returns a client of the server, not logged in yet
+func New(baseURL string) *Client {
+	jar, _ := cookiejar.New(nil)
+	return &Client{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		HTTPClient: &http.Client{Jar: jar},
+	}
+}
+
+// NewWithHTTPClient returns a client sending its requests through hc, which
+// needs a cookie jar to log in
+func NewWithHTTPClient(baseURL string, hc *http.Client) *Client {
+	return &Client{BaseURL: strings.TrimRight(baseURL, "/"), HTTPClient: hc}
+}
+
+// Login opens a session with a Tom username and password
+func (c *Client) Login(ctx context.Context, username, password string) error {
+	if c.HTTPClient.Jar == nil {
+		jar, _ := cookiejar.New(nil)
+		c.HTTPClient.Jar = jar
+	}
+	form := url.Values{"username": {username}, "password": {password}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.BaseURL+"/login", strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return &APIError{StatusCode: resp.StatusCode, Message: "login failed: " + strings.TrimSpace(string(body))}
+	}
+	// The server answers bad credentials with a 200 page instead of redirecting
+	if strings.Contains(string(body), "Invalid credentials") {
+		return ErrInvalidCredentials
+	}
+	return nil
+}
+
+// SessionCookie returns the session cookie as "session_id=VALUE", to keep
+// and give to SetSessionCookie later, empty when not logged in
+func (c *Client) SessionCookie() string {
+	u, err := url.Parse(c.BaseURL)
+	if err != nil || c.HTTPClient.Jar == nil {
+		return ""
+	}
+	for _, cookie := range c.HTTPClient.Jar.Cookies(u) {
+		if cookie.Name == sessionCookieName {
+			return cookie.Name + "=" + cookie.Value
+		}
+	}
+	return ""
+}
+
+// SetSessionCookie reuses a session, given as "session_id=VALUE" with
+// optional attributes after a semicolon
+func (c *Client) SetSessionCookie(raw string) error {
+	pair, _, _ := strings.Cut(raw, ";")
+	name, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+	if !ok {
+		return fmt.Errorf("invalid session cookie %q", raw)
+	}
+	u, err := url.Parse(c.BaseURL)
+	if err != nil {
+		return fmt.Errorf("invalid server URL: %w", err)
+	}
+	if c.HTTPClient.Jar == nil {
+		jar, _ := cookiejar.New(nil)
+		c.HTTPClient.Jar = jar
+	}
+	c.HTTPClient.Jar.SetCookies(u, []*http.Cookie{{Name: name, Value: value}})
+	return nil
+}
+
+// response is the answer of the memory service
+type response struct {
+	Status  string  `json:"status"`
+	Results results `json:"results"`
+	Result  Memory  `json:"result"`
+	Count   int     `json:"count"`
+	Error   string  `json:"error"`
+}
+
+// results is a list of memories, sent bare by the version 2 of the API and
+// in a "results" object by the version 1
+type results []Memory
+
+func (r *results) UnmarshalJSON(data []byte) error {
+	var list []Memory
+	if err := json.Unmarshal(data, &list); err == nil {
+		*r = list
+		return nil
+	}
+	var nested struct {
+		Results []Memory `json:"results"`
+	}
+	if err := json.Unmarshal(data, &nested); err != nil {
+		return err
+	}
+	*r = nested.Results
+	return nil
+}
+
+// do sends a request to the memory service, under /memory on the server,
+// payload going as JSON when not nil
+func (c *Client) do(ctx context.Context, method, endpoint string, payload interface{}) (response, error) {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return response{}, err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+"/memory"+endpoint, body)
+	if err != nil {
+		return response{}, err
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return response{}, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusUnauthorized:
+		return response{}, ErrUnauthorized
+	case http.StatusNotFound:
+		return response{}, ErrNotFound
+	}
+	var r response
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		if resp.StatusCode >= 300 {
+			return response{}, &APIError{StatusCode: resp.StatusCode}
+		}
+		return response{}, err
+	}
+	if r.Error != "" || resp.StatusCode >= 300 {
+		return response{}, &APIError{StatusCode: resp.StatusCode, Message: r.Error}
+	}
+	return r, nil
+}
@@ -0,0 +1,109 @@
+package memoryclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newTestClient returns a client of a test server answering with handler
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return New(server.URL)
+}
+
+func TestLogin(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/login" || r.Method != http.MethodPost {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if r.FormValue("username") != "alice" || r.FormValue("password") != "secret" {
+			w.Write([]byte("<p>Invalid credentials</p>"))
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: sessionCookieName, Value: "s1", Path: "/"})
+	})
+
+	if err := c.Login(context.Background(), "alice", "wrong"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("Login with a wrong password: got %v, want ErrInvalidCredentials", err)
+	}
+	if got := c.SessionCookie(); got != "" {
+		t.Errorf("SessionCookie after a failed login = %q, want none", got)
+	}
+	if err := c.Login(context.Background(), "alice", "secret"); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if got, want := c.SessionCookie(), "session_id=s1"; got != want {
+		t.Errorf("SessionCookie = %q, want %q", got, want)
+	}
+}
+
+func TestLoginStatusError(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down for maintenance", http.StatusServiceUnavailable)
+	})
+
+	err := c.Login(context.Background(), "alice", "secret")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("Login: got %v, want an *APIError with status 503", err)
+	}
+}
+
+func TestAPIError(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		message string
+	}{
+		{"error field", http.StatusBadRequest, `{"status": "error", "error": "text is required"}`, "text is required"},
+		{"error field on a 200", http.StatusOK, `{"error": "mem0 unavailable"}`, "mem0 unavailable"},
+		{"status code only", http.StatusInternalServerError, "Internal Server Error", ""},
+		{"JSON without error", http.StatusBadGateway, `{"status": "error"}`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			})
+
+			_, err := c.Add(context.Background(), "text", nil)
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("got %v, want an *APIError", err)
+			}
+			if apiErr.StatusCode != tt.status || apiErr.Message != tt.message {
+				t.Errorf("got status %d message %q, want %d %q", apiErr.StatusCode, apiErr.Message, tt.status, tt.message)
+			}
+		})
+	}
+}
+
+func TestSetSessionCookie(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if cookie, err := r.Cookie(sessionCookieName); err != nil || cookie.Value != "s2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"results": []}`))
+	})
+
+	if _, err := c.List(context.Background()); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("List without a session: got %v, want ErrUnauthorized", err)
+	}
+	if err := c.SetSessionCookie("session_id=s2; Path=/; HttpOnly"); err != nil {
+		t.Fatalf("SetSessionCookie: %v", err)
+	}
+	if _, err := c.List(context.Background()); err != nil {
+		t.Fatalf("List with the session: %v", err)
+	}
+	if err := c.SetSessionCookie("no-value"); err == nil {
+		t.Error("SetSessionCookie accepted a cookie without a value")
+	}
+}
@@ -0,0 +1,23 @@
+// Package memoryclient drives the memory store of a Tom server: list, page,
+// get, add, update, search, delete, trash and restore memories, the way
+// memory-tui does.
+//
+// The client logs in with a Tom username and password, or reuses a session
+// cookie, and sends every request with the given context:
+//
+//	c := memoryclient.New("https://tom.example.com")
+//	if err := c.Login(ctx, "alice", "secret"); err != nil {
+//		log.Fatal(err)
+//	}
+//	if _, err := c.Add(ctx, "The spare key is under the blue pot", nil); err != nil {
+//		log.Fatal(err)
+//	}
+//	found, err := c.Search(ctx, "spare key", 5)
+//
+// Failures are typed: errors.Is(err, ErrUnauthorized) when the session
+// expired, errors.Is(err, ErrNotFound) for a missing memory, and an
+// *APIError for the other errors the server reports.
+//
+// The package only needs the standard library. It is a module of its own,
+// go get github.com/Mickael-Roger/tom/tools/memory-tui/pkg/memoryclient.
+package memoryclient
@@ -0,0 +1,33 @@
+package memoryclient
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrUnauthorized is returned when the server rejects the session:
+	// never logged in, or the cookie expired
+	ErrUnauthorized = errors.New("unauthorized: not logged in or session expired")
+
+	// ErrNotFound is returned for a memory the server does not have
+	ErrNotFound = errors.New("not found")
+
+	// ErrInvalidCredentials is returned by Login for a wrong username or
+	// password
+	ErrInvalidCredentials = errors.New("invalid username or password")
+)
+
+// APIError is an error the server reported, in the "error" field of its
+// answer or by a status code
+type APIError struct {
+	StatusCode int    // HTTP status of the answer
+	Message    string // Reported by the server, empty when it sent none
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("API error: server returned status %d", e.StatusCode)
+	}
+	return "API error: " + e.Message
+}
@@ -0,0 +1,3 @@
+module github.com/Mickael-Roger/tom/tools/memory-tui/pkg/memoryclient
+
+go 1.21
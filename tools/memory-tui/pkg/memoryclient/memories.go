@@ -0,0 +1,124 @@
+package memoryclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Memory is a memory of the store, as mem0 keeps it
+type Memory struct {
+	ID        string                 `json:"id"`
+	Memory    string                 `json:"memory"` // The text
+	Hash      string                 `json:"hash"`
+	CreatedAt string                 `json:"created_at"`
+	UpdatedAt *string                `json:"updated_at"` // Nil when never updated
+	UserID    string                 `json:"user_id"`
+	Metadata  map[string]interface{} `json:"metadata"`
+}
+
+// Page is a slice of the memories, as returned by ListPage
+type Page struct {
+	Memories []Memory
+	Offset   int // Of the first memory of the page
+	Total    int // Memories in the store
+}
+
+// Next returns the offset of the next page, and false after the last one
+func (p Page) Next() (int, bool) {
+	next := p.Offset + len(p.Memories)
+	return next, len(p.Memories) > 0 && next < p.Total
+}
+
+// List returns every memory
+func (c *Client) List(ctx context.Context) ([]Memory, error) {
+	r, err := c.do(ctx, http.MethodGet, "/memories", nil)
+	if err != nil {
+		return nil, err
+	}
+	return r.Results, nil
+}
+
+// ListPage returns limit memories from offset on. The memory service sends
+// every memory at once, so the pages are cut on the client from one listing
+// per call.
+func (c *Client) ListPage(ctx context.Context, offset, limit int) (Page, error) {
+	if offset < 0 || limit <= 0 {
+		return Page{}, fmt.Errorf("invalid page: offset %d, limit %d", offset, limit)
+	}
+	memories, err := c.List(ctx)
+	if err != nil {
+		return Page{}, err
+	}
+	page := Page{Offset: offset, Total: len(memories)}
+	if offset < len(memories) {
+		page.Memories = memories[offset:min(offset+limit, len(memories))]
+	}
+	return page, nil
+}
+
+// Get returns a memory by its ID
+func (c *Client) Get(ctx context.Context, id string) (Memory, error) {
+	r, err := c.do(ctx, http.MethodGet, "/memory/"+url.PathEscape(id), nil)
+	if err != nil {
+		return Memory{}, err
+	}
+	return r.Result, nil
+}
+
+// Add stores a memory, metadata being optional, and returns it. mem0 may
+// rewrite the text, merging it with a memory it already holds.
+func (c *Client) Add(ctx context.Context, text string, metadata map[string]interface{}) (Memory, error) {
+	r, err := c.do(ctx, http.MethodPost, "/add", map[string]interface{}{"text": text, "metadata": metadata})
+	if err != nil {
+		return Memory{}, err
+	}
+	return r.Result, nil
+}
+
+// Update replaces the text and metadata of a memory. The memory service has
+// no update, so the new memory is added before the former one is deleted:
+// it gets a new ID, returned with it.
+func (c *Client) Update(ctx context.Context, id, text string, metadata map[string]interface{}) (Memory, error) {
+	added, err := c.Add(ctx, text, metadata)
+	if err != nil {
+		return Memory{}, err
+	}
+	if err := c.Delete(ctx, id); err != nil && !errors.Is(err, ErrNotFound) {
+		return added, fmt.Errorf("memory %s added, but deleting %s failed: %w", added.ID, id, err)
+	}
+	return added, nil
+}
+
+// Search returns the memories closest in meaning to the query, limit at most
+func (c *Client) Search(ctx context.Context, query string, limit int) ([]Memory, error) {
+	r, err := c.do(ctx, http.MethodPost, "/search", map[string]interface{}{"query": query, "limit": limit})
+	if err != nil {
+		return nil, err
+	}
+	return r.Results, nil
+}
+
+// Delete deletes a memory for good
+func (c *Client) Delete(ctx context.Context, id string) error {
+	_, err := c.do(ctx, http.MethodDelete, "/delete/"+url.PathEscape(id), nil)
+	return err
+}
+
+// Trash moves a memory to the trash of the servers advertising the
+// "soft_delete" capability in /status, where Restore brings it back
+func (c *Client) Trash(ctx context.Context, id string) error {
+	_, err := c.do(ctx, http.MethodPost, "/trash/"+url.PathEscape(id), nil)
+	return err
+}
+
+// Restore brings a memory back from the trash and returns it
+func (c *Client) Restore(ctx context.Context, id string) (Memory, error) {
+	r, err := c.do(ctx, http.MethodPost, "/restore/"+url.PathEscape(id), nil)
+	if err != nil {
+		return Memory{}, err
+	}
+	return r.Result, nil
+}
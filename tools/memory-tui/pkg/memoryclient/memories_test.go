@@ -0,0 +1,215 @@
+package memoryclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// listHandler answers /memory/memories with n memories, m0 to m(n-1), in
+// the nested form of the version 1 of the API
+func listHandler(t *testing.T, n int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/memory/memories" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		memories := make([]Memory, n)
+		for i := range memories {
+			memories[i] = Memory{ID: fmt.Sprintf("m%d", i), Memory: fmt.Sprintf("memory %d", i)}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"results": map[string]interface{}{"results": memories}})
+	}
+}
+
+func ids(memories []Memory) string {
+	list := make([]string, len(memories))
+	for i, m := range memories {
+		list[i] = m.ID
+	}
+	return strings.Join(list, ",")
+}
+
+func TestList(t *testing.T) {
+	c := newTestClient(t, listHandler(t, 3))
+
+	memories, err := c.List(context.Background())
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if got, want := ids(memories), "m0,m1,m2"; got != want {
+		t.Errorf("List = %s, want %s", got, want)
+	}
+}
+
+func TestListBare(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"results": [{"id": "a"}, {"id": "b"}]}`))
+	})
+
+	memories, err := c.List(context.Background())
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if got, want := ids(memories), "a,b"; got != want {
+		t.Errorf("List = %s, want %s", got, want)
+	}
+}
+
+func TestListPage(t *testing.T) {
+	c := newTestClient(t, listHandler(t, 5))
+
+	tests := []struct {
+		offset, limit int
+		want          string
+		next          int
+		more          bool
+	}{
+		{0, 2, "m0,m1", 2, true},
+		{2, 2, "m2,m3", 4, true},
+		{4, 2, "m4", 5, false},
+		{3, 10, "m3,m4", 5, false},
+		{5, 2, "", 5, false},
+		{9, 2, "", 9, false},
+	}
+	for _, tt := range tests {
+		page, err := c.ListPage(context.Background(), tt.offset, tt.limit)
+		if err != nil {
+			t.Fatalf("ListPage(%d, %d): %v", tt.offset, tt.limit, err)
+		}
+		if got := ids(page.Memories); got != tt.want || page.Offset != tt.offset || page.Total != 5 {
+			t.Errorf("ListPage(%d, %d) = %s offset %d total %d, want %s offset %d total 5",
+				tt.offset, tt.limit, got, page.Offset, page.Total, tt.want, tt.offset)
+		}
+		if next, more := page.Next(); next != tt.next || more != tt.more {
+			t.Errorf("ListPage(%d, %d).Next() = %d, %v, want %d, %v", tt.offset, tt.limit, next, more, tt.next, tt.more)
+		}
+	}
+
+	for _, bounds := range [][2]int{{-1, 2}, {0, 0}, {0, -3}} {
+		if _, err := c.ListPage(context.Background(), bounds[0], bounds[1]); err == nil {
+			t.Errorf("ListPage(%d, %d) accepted an invalid page", bounds[0], bounds[1])
+		}
+	}
+}
+
+func TestPageWalk(t *testing.T) {
+	c := newTestClient(t, listHandler(t, 7))
+
+	var seen []Memory
+	for offset, more := 0, true; more; {
+		page, err := c.ListPage(context.Background(), offset, 3)
+		if err != nil {
+			t.Fatalf("ListPage(%d, 3): %v", offset, err)
+		}
+		seen = append(seen, page.Memories...)
+		offset, more = page.Next()
+	}
+	if got, want := ids(seen), "m0,m1,m2,m3,m4,m5,m6"; got != want {
+		t.Errorf("walking the pages gave %s, want %s", got, want)
+	}
+}
+
+func TestGet(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/memory/memory/m1":
+			w.Write([]byte(`{"status": "success", "result": {"id": "m1", "memory": "The spare key is under the blue pot"}}`))
+		case "/memory/memory/expired":
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	memory, err := c.Get(context.Background(), "m1")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if memory.ID != "m1" || memory.Memory != "The spare key is under the blue pot" {
+		t.Errorf("Get = %+v", memory)
+	}
+	if _, err := c.Get(context.Background(), "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get of a missing memory: got %v, want ErrNotFound", err)
+	}
+	if _, err := c.Get(context.Background(), "expired"); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Get with an expired session: got %v, want ErrUnauthorized", err)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	tests := []struct {
+		name         string
+		deleteStatus int
+		wantErr      bool
+	}{
+		{"deleted", http.StatusOK, false},
+		{"already gone", http.StatusNotFound, false},
+		{"delete failed", http.StatusInternalServerError, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var deleted string
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.Method == http.MethodPost && r.URL.Path == "/memory/add":
+					var payload struct {
+						Text string `json:"text"`
+					}
+					json.NewDecoder(r.Body).Decode(&payload)
+					json.NewEncoder(w).Encode(map[string]interface{}{"status": "success", "result": Memory{ID: "new", Memory: payload.Text}})
+				case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/memory/delete/"):
+					deleted = strings.TrimPrefix(r.URL.Path, "/memory/delete/")
+					w.WriteHeader(tt.deleteStatus)
+					if tt.deleteStatus == http.StatusOK {
+						w.Write([]byte(`{"status": "success"}`))
+					} else {
+						w.Write([]byte(`{"status": "error", "error": "qdrant unreachable"}`))
+					}
+				default:
+					t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+				}
+			})
+
+			added, err := c.Update(context.Background(), "old", "updated text", nil)
+			if deleted != "old" {
+				t.Errorf("deleted %q, want old", deleted)
+			}
+			// The new memory is returned even when the former one stays
+			if added.ID != "new" || added.Memory != "updated text" {
+				t.Errorf("Update returned %+v", added)
+			}
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("Update: %v", err)
+				}
+				return
+			}
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusInternalServerError {
+				t.Fatalf("Update: got %v, want the *APIError of the delete", err)
+			}
+			if !strings.Contains(err.Error(), "memory new added") {
+				t.Errorf("Update error %q does not say the new memory was added", err)
+			}
+		})
+	}
+}
+
+func TestUpdateAddFails(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			t.Errorf("deleted the memory after a failed add")
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error": "text is required"}`))
+	})
+
+	var apiErr *APIError
+	if _, err := c.Update(context.Background(), "old", "", nil); !errors.As(err, &apiErr) {
+		t.Fatalf("Update: got %v, want an *APIError", err)
+	}
+}
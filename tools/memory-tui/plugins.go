@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/bubbletea"
+)
+
+// Plugins are executables dropped in ~/.tom/plugins, each registering the
+// prompt command named after the file. They get the arguments on the command
+// line and the session context as JSON on stdin (and in TOM_* variables);
+// their stdout, Markdown or plain text, is shown in an overlay.
+//
+// A "description: ..." comment in the first lines of a script becomes the
+// command help.
+const pluginTimeout = 2 * time.Minute
+
+var pluginMarkdown markdownCache
+
+type pluginContext struct {
+	ServerURL     string   `json:"server_url"`
+	Username      string   `json:"username"`
+	SessionCookie string   `json:"session_cookie"`
+	Selected      *Memory  `json:"selected,omitempty"` // Memory under the cursor
+	Marked        []Memory `json:"marked,omitempty"`
+}
+
+type pluginOutputMsg struct {
+	name   string
+	output string
+	err    error
+}
+
+func pluginsDir() (string, error) {
+	return tomPath("plugins")
+}
+
+// pluginDescription reads the "description:" comment of a script
+func pluginDescription(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for i := 0; i < 20 && scanner.Scan(); i++ {
+		line := strings.TrimLeft(scanner.Text(), "#/-; \t")
+		if desc, ok := strings.CutPrefix(line, "description:"); ok {
+			return strings.TrimSpace(desc)
+		}
+	}
+	return ""
+}
+
+// loadPlugins registers the executables of the plugins directory, after the
+// built-in commands which they cannot replace
+func loadPlugins() {
+	dir, err := pluginsDir()
+	if err != nil {
+		return
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || info.IsDir() || info.Mode()&0111 == 0 {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		name := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+		if strings.HasPrefix(name, ".") || findPromptCommand(name) != nil {
+			logf("WARN", "plugin %s skipped: command /%s already exists", path, name)
+			continue
+		}
+
+		help := pluginDescription(path)
+		if help == "" {
+			help = "Plugin " + path
+		}
+		promptCommands = append(promptCommands, promptCommand{
+			Name: name,
+			Args: "[ARGS]",
+			Help: help,
+			// A plugin may do anything
+			SideEffects: true,
+			Run: func(m model, args string) (tea.Model, tea.Cmd) {
+				m.loading = true
+				m.setFocus(focusContent)
+				return m, m.runPlugin(name, path, args)
+			},
+		})
+		logf("INFO", "plugin /%s registered from %s", name, path)
+	}
+}
+
+func (m model) pluginContext() pluginContext {
+	ctx := pluginContext{
+		ServerURL: m.serverURL,
+		Username:  m.usernameInput.Value(),
+	}
+	if u, err := url.Parse(m.serverURL); err == nil && m.client.Jar != nil {
+		for _, c := range m.client.Jar.Cookies(u) {
+			if c.Name == "session_id" {
+				ctx.SessionCookie = c.String()
+			}
+		}
+	}
+	if item, ok := m.list.SelectedItem().(memoryItem); ok {
+		ctx.Selected = &item.memory
+	}
+	if len(m.marked) > 0 {
+		ctx.Marked = m.selectedMemories()
+	}
+	return ctx
+}
+
+func (m model) runPlugin(name, path, args string) tea.Cmd {
+	pctx := m.pluginContext()
+	return func() tea.Msg {
+		input, err := json.Marshal(pctx)
+		if err != nil {
+			return pluginOutputMsg{name: name, err: err}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), pluginTimeout)
+		defer cancel()
+		cmd := exec.CommandContext(ctx, path, strings.Fields(args)...)
+		cmd.Stdin = bytes.NewReader(input)
+		cmd.Env = append(os.Environ(),
+			"TOM_SERVER_URL="+pctx.ServerURL,
+			"TOM_USERNAME="+pctx.Username,
+			"TOM_SESSION_COOKIE="+pctx.SessionCookie,
+		)
+		var stdout, stderr bytes.Buffer
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+
+		if err := cmd.Run(); err != nil {
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				err = fmt.Errorf("%w: %s", err, msg)
+			}
+			return pluginOutputMsg{name: name, output: stdout.String(), err: err}
+		}
+		return pluginOutputMsg{name: name, output: stdout.String()}
+	}
+}
+
+func (m model) handlePluginOutput(msg pluginOutputMsg) (tea.Model, tea.Cmd) {
+	m.loading = false
+	if msg.err != nil {
+		logf("WARN", "plugin /%s failed: %v", msg.name, msg.err)
+		m.err = fmt.Errorf("/%s: %w", msg.name, msg.err)
+	}
+	output := strings.TrimRight(msg.output, "\n")
+	if output == "" {
+		if msg.err == nil {
+			m.message = tr("/%s done", msg.name)
+		}
+		return m, nil
+	}
+
+	m.pluginName = msg.name
+	m.pluginViewport = viewport.New(max(20, m.width-8), max(5, m.height-12))
+	m.pluginViewport.SetContent(pluginMarkdown.render(output, max(20, m.width-10)))
+	m.showView(pluginOutputView)
+	return m, nil
+}
+
+func (m model) updatePluginOutputView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q", "enter":
+		m.showView(listView)
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.pluginViewport, cmd = m.pluginViewport.Update(msg)
+	return m, cmd
+}
+
+func (m model) renderPluginOutputView() string {
+	style := contentBoxStyle.Width(m.width - 4)
+	if m.focus == focusContent {
+		style = contentBoxFocusedStyle.Width(m.width - 4)
+	}
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("🔌 /" + m.pluginName))
+	b.WriteString("\n\n")
+	b.WriteString(m.pluginViewport.View())
+	b.WriteString("\n\n")
+	b.WriteString(m.keyHint(""))
+	return style.Render(b.String())
+}
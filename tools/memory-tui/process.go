@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Mickael-Roger/tom/tools/memory-tui/pkg/chatclient"
+)
+
+// Requests to Tom itself: POST /process, the endpoint the chat clients talk
+// to, runs a request through the assistant and returns its answer. The
+// requests go in the history of the "tui" client, through pkg/chatclient.
+const (
+	processClientType  = "tui"
+	maxProcessMemories = 100 // Memories sent per request, past that the prompt gets too long
+)
+
+// memoriesPrompt follows the instructions with the numbered memories
+func memoriesPrompt(instructions string, memories []Memory) string {
+	var b strings.Builder
+	b.WriteString(instructions)
+	b.WriteString("\n\n")
+	for i, mem := range memories {
+		fmt.Fprintf(&b, "%d. %s\n", i+1, mem.Memory)
+	}
+	return b.String()
+}
+
+// answerID is an ID in an answer, which Tom may write as a number or a string
+type answerID string
+
+func (id *answerID) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*id = answerID(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return err
+	}
+	*id = answerID(n)
+	return nil
+}
+
+// decodeAnswerList decodes the JSON array of an answer, which may come
+// wrapped in a code block or a sentence
+func decodeAnswerList(answer string, v interface{}) error {
+	start, end := strings.Index(answer, "["), strings.LastIndex(answer, "]")
+	if start < 0 || end < start {
+		return fmt.Errorf("unexpected answer from Tom: %s", truncateString(strings.TrimSpace(answer), 200))
+	}
+	if err := json.Unmarshal([]byte(answer[start:end+1]), v); err != nil {
+		return fmt.Errorf("unexpected answer from Tom: %w", err)
+	}
+	return nil
+}
+
+// chatError turns an expired session reported by chatclient into the error
+// the interface logs in again on, as clientError does for memoryclient
+func chatError(err error) error {
+	if errors.Is(err, chatclient.ErrUnauthorized) {
+		return errSessionExpired
+	}
+	return err
+}
+
+// Process sends a request to Tom and returns the answer
+func (api *MemoryAPI) Process(request string) (string, error) {
+	chat := chatclient.NewWithHTTPClient(api.ServerURL, processClientType, api.Client)
+	answer, err := chat.Process(context.Background(), request)
+	if err != nil {
+		return "", chatError(err)
+	}
+	if api.Scratch != "" {
+		if _, err := saveScratch(api.Scratch, answer.Text); err != nil {
+			logf("WARN", "scratch: %v", err)
+		}
+	}
+	return answer.Text, nil
+}
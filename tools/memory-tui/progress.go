@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/progress"
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Batch operations run one item per command so the UI can show progress and
+// stop cleanly between two items when cancelled
+type batchJob struct {
+	id        int
+	title     string
+	total     int
+	step      func(i int) error                  // Processes the stepSize items from item i
+	stepSize  int                                // Items per step, several for batch requests
+	finish    func(m model) (tea.Model, tea.Cmd) // Replaces the reload of the list when set
+	ended     func(j *batchJob)                  // Called when the job ends, cancelled or not
+	done      int
+	failed    int
+	lastErr   error
+	started   time.Time
+	cancelled bool
+}
+
+type batchStepMsg struct {
+	id  int
+	err error
+}
+
+var batchCounter int
+
+func newBatchJob(title string, total int, step func(i int) error) *batchJob {
+	batchCounter++
+	return &batchJob{
+		id:       batchCounter,
+		title:    title,
+		total:    total,
+		step:     step,
+		stepSize: 1,
+		started:  time.Now(),
+	}
+}
+
+func (j *batchJob) next() tea.Cmd {
+	i := j.done + j.failed
+	id, step := j.id, j.step
+	return func() tea.Msg {
+		return batchStepMsg{id, step(i)}
+	}
+}
+
+func (j *batchJob) finished() bool {
+	return j.cancelled || j.done+j.failed >= j.total
+}
+
+// rate returns the processed items per second
+func (j *batchJob) rate() float64 {
+	elapsed := time.Since(j.started).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(j.done+j.failed) / elapsed
+}
+
+func (j *batchJob) eta() time.Duration {
+	rate := j.rate()
+	if rate == 0 {
+		return 0
+	}
+	remaining := j.total - j.done - j.failed
+	return time.Duration(float64(remaining) / rate * float64(time.Second)).Round(time.Second)
+}
+
+func (j *batchJob) summary() string {
+	s := tr("%s: %d/%d done", j.title, j.done, j.total)
+	if j.failed > 0 {
+		s += tr(", %d failed (last error: %v)", j.failed, j.lastErr)
+	}
+	if j.cancelled {
+		s += tr(", cancelled")
+	}
+	return s
+}
+
+// startBatch switches to the progress modal and processes the first item
+func (m model) startBatch(job *batchJob) (model, tea.Cmd) {
+	m.batch = job
+	m.showView(batchView)
+	if job.total == 0 {
+		return m, func() tea.Msg { return batchStepMsg{id: job.id} }
+	}
+	return m, job.next()
+}
+
+func (m model) handleBatchStep(msg batchStepMsg) (tea.Model, tea.Cmd) {
+	job := m.batch
+	if job == nil || msg.id != job.id {
+		return m, nil
+	}
+
+	if job.total > 0 {
+		n := min(job.stepSize, job.total-job.done-job.failed)
+		if msg.err != nil {
+			job.failed += n
+			job.lastErr = friendlyError(msg.err)
+		} else {
+			job.done += n
+		}
+	}
+
+	if !job.finished() {
+		return m, job.next()
+	}
+
+	m.batch = nil
+	if job.ended != nil {
+		job.ended(job)
+	}
+	if job.finish != nil {
+		logf("INFO", "%s", job.summary())
+		return job.finish(m)
+	}
+	m.showView(listView)
+	m.clearMarks()
+	logf("INFO", "%s", job.summary())
+	if job.failed > 0 {
+		m.err = fmt.Errorf("%s", job.summary())
+	} else {
+		m.message = job.summary() + m.dryRunNote()
+	}
+	m.loading = true
+	return m, m.loadMemories()
+}
+
+func (m model) updateBatchView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "c", "ctrl+c":
+		// The item in flight completes, the remaining ones are skipped
+		m.batch.cancelled = true
+	}
+	return m, nil
+}
+
+func (m model) renderBatchModal() string {
+	job := m.batch
+	modalWidth := min(70, m.width-10)
+
+	percent := 0.0
+	if job.total > 0 {
+		percent = float64(job.done+job.failed) / float64(job.total)
+	}
+	bar := progress.New(progress.WithDefaultGradient(), progress.WithWidth(modalWidth-8))
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("⏳ " + job.title))
+	b.WriteString("\n\n")
+	b.WriteString(bar.ViewAs(percent))
+	b.WriteString("\n\n")
+	b.WriteString(fmt.Sprintf("%d/%d", job.done+job.failed, job.total))
+	b.WriteString(fmt.Sprintf(" • %.1f items/s", job.rate()))
+	if eta := job.eta(); eta > 0 {
+		b.WriteString(fmt.Sprintf(" • ETA %s", eta))
+	}
+	if job.failed > 0 {
+		b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5F87")).Render(fmt.Sprintf(" • %d failed", job.failed)))
+	}
+	if status := transferStatus(); status != "" {
+		b.WriteString("\n" + helpStyle.Render(status))
+	}
+	b.WriteString("\n\n")
+	if job.cancelled {
+		b.WriteString(helpStyle.Render(tr("Cancelling after the current item...")))
+	} else {
+		b.WriteString(m.keyHint(""))
+	}
+
+	modalContent := modalStyle.Width(modalWidth).Render(b.String())
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, modalContent)
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Project context: a .tom-context file in the working directory, or in one
+// of its parents, describes the project worked on (goals, glossary, files
+// that matter). Read at start and after /cd, it goes first in the files of
+// each prompt to Tom, /ask or the output of /run, so he knows the project
+// without being told.
+const (
+	projectContextName  = ".tom-context"
+	projectContextLimit = 8000 // Characters of the file sent with a prompt
+)
+
+// loadProjectContext reads the .tom-context nearest to dir, the zero
+// fileContext when there is none
+func loadProjectContext(dir string) fileContext {
+	for {
+		path := filepath.Join(dir, projectContextName)
+		data, err := os.ReadFile(path)
+		if err == nil {
+			content := truncateString(strings.TrimSpace(string(data)), projectContextLimit)
+			logf("INFO", "project context: %s", path)
+			return fileContext{title: "$ cat " + path, content: content}
+		}
+		if !os.IsNotExist(err) {
+			logf("WARN", "project context: %v", err)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return fileContext{}
+		}
+		dir = parent
+	}
+}
+
+// promptFiles are the files sent with the next prompt to Tom: the project
+// context, then the included ones
+func (m model) promptFiles() []fileContext {
+	if m.project.content == "" {
+		return m.fileContext
+	}
+	return append([]fileContext{m.project}, m.fileContext...)
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbletea"
+)
+
+// `memory-tui quick`: jot a memory down without the full interface, from a
+// hotkey. With TEXT it is stored at once; without it a bare editor fills the
+// terminal, Ctrl+S storing and quitting, Esc quitting. The session of a
+// running daemon is reused, sparing the login check, and otherwise opened
+// while typing. The configured redactions apply; having no preview, a text
+// looking like it holds a secret is refused without --force (or a second
+// Ctrl+S in the editor).
+var errQuickEmpty = errors.New("nothing to store")
+
+type quickSessionMsg struct {
+	api *MemoryAPI
+	err error
+}
+
+type quickSavedMsg struct{ err error }
+
+type quickModel struct {
+	textArea textarea.Model
+	tags     []string
+	api      *MemoryAPI
+	err      error
+	opening  bool // Session being opened
+	saving   bool // Ctrl+S pressed, sent once the session is open
+	force    bool // Store even if the text looks like a secret
+	saved    bool
+}
+
+// parseTags splits the --tags value, dropping the empty ones
+func parseTags(spec string) []string {
+	var tags []string
+	for _, t := range strings.Split(spec, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
+
+// quickSession takes the session of the daemon, or opens one
+func quickSession(cfg config) (*MemoryAPI, error) {
+	var api *MemoryAPI
+	if resp, err := callDaemon("session"); err == nil {
+		if api, err = newSessionAPI(resp.ServerURL, resp.SessionCookie); err == nil {
+			api.ReadOnly = cfg.ReadOnly || resp.ReadOnly
+			api.applyServerInfo(serverInfo{APIVersion: resp.APIVersion, Capabilities: resp.Capabilities})
+			api.Modules = resp.Modules
+		}
+	}
+	if api == nil {
+		sess, err := openSession()
+		if err != nil {
+			return nil, err
+		}
+		api = sess.API
+		api.ReadOnly = cfg.ReadOnly
+	}
+	api.Hooks = cfg.Hooks
+	if !api.supports(moduleMemory) {
+		return nil, errors.New("the memory module is not enabled on this server")
+	}
+	return api, nil
+}
+
+// storeQuick masks and stores a memory, refusing a likely secret unless
+// forced
+func storeQuick(api *MemoryAPI, text string, tags []string, force bool) error {
+	if strings.TrimSpace(text) == "" {
+		return errQuickEmpty
+	}
+	masked, found := redactText(text)
+	if len(found) > 0 {
+		logf("INFO", "quick: %d values masked", len(found))
+	}
+	if kinds := findSecrets(masked); len(kinds) > 0 && !force {
+		return fmt.Errorf("the text looks like it contains a %s, not stored (--force to store it anyway)", strings.Join(kinds, ", "))
+	}
+	var metadata map[string]interface{}
+	if len(tags) > 0 {
+		metadata = map[string]interface{}{"tags": tags}
+	}
+	return api.AddMemory(masked, metadata)
+}
+
+// openQuickSession opens the session in the background of the editor
+func openQuickSession() tea.Msg {
+	cfg, _ := loadConfig()
+	api, err := quickSession(cfg)
+	return quickSessionMsg{api, err}
+}
+
+func newQuickModel(tags []string) quickModel {
+	ta := textarea.New()
+	ta.Placeholder = tr("Enter your memory content here...")
+	ta.CharLimit = maxDraftLength
+	ta.ShowLineNumbers = false
+	ta.Focus()
+	return quickModel{textArea: ta, tags: tags, opening: true}
+}
+
+func (q quickModel) Init() tea.Cmd {
+	return tea.Batch(textarea.Blink, openQuickSession)
+}
+
+// save sends the text once the session is open
+func (q quickModel) save() tea.Cmd {
+	api, text, tags, force := q.api, q.textArea.Value(), q.tags, q.force
+	return func() tea.Msg {
+		return quickSavedMsg{storeQuick(api, text, tags, force)}
+	}
+}
+
+func (q quickModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		q.textArea.SetWidth(max(20, msg.Width))
+		q.textArea.SetHeight(max(3, msg.Height-2))
+		return q, nil
+
+	case quickSessionMsg:
+		q.api, q.err, q.opening = msg.api, msg.err, false
+		if q.saving && q.api != nil {
+			return q, q.save()
+		}
+		q.saving = false
+		return q, nil
+
+	case quickSavedMsg:
+		q.saving = false
+		if msg.err != nil {
+			q.err = msg.err
+			// A second Ctrl+S stores the likely secret
+			q.force = findSecrets(q.textArea.Value()) != nil
+			return q, nil
+		}
+		q.saved = true
+		return q, tea.Quit
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc", "ctrl+c":
+			return q, tea.Quit
+		case "ctrl+s":
+			if q.saving {
+				return q, nil
+			}
+			if strings.TrimSpace(q.textArea.Value()) == "" {
+				q.err = errQuickEmpty
+				return q, nil
+			}
+			q.err = nil
+			q.saving = true
+			switch {
+			case q.opening:
+				// Sent when the session opens
+				return q, nil
+			case q.api == nil:
+				// The session failed to open, try again
+				q.opening = true
+				return q, openQuickSession
+			}
+			return q, q.save()
+		}
+		q.force = false
+	}
+
+	var cmd tea.Cmd
+	q.textArea, cmd = q.textArea.Update(msg)
+	return q, cmd
+}
+
+func (q quickModel) View() string {
+	title := titleStyle.Render(tr("➕ Quick memory"))
+	if len(q.tags) > 0 {
+		title += " " + helpStyle.Render("#"+strings.Join(q.tags, " #"))
+	}
+
+	status := helpStyle.Render(tr("Ctrl+S save • Esc cancel"))
+	switch {
+	case q.saving:
+		status = helpStyle.Render(tr("Saving..."))
+	case q.err != nil && q.force:
+		status = loginErrorStyle.Render("❌ "+q.err.Error()) + helpStyle.Render(" • "+tr("Ctrl+S again to store it anyway"))
+	case q.err != nil:
+		status = loginErrorStyle.Render("❌ " + q.err.Error())
+	}
+	return title + "\n" + q.textArea.View() + "\n" + status
+}
+
+// runQuick stores text at once, or opens the editor when it is empty
+func runQuick(text string, tags []string, force bool) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		logf("WARN", "failed to load config, using defaults: %v", err)
+	}
+	applyRedactionConfig(cfg)
+	setLanguage(cfg.Language)
+	enableLowBandwidth(cfg, false)
+
+	if text != "" {
+		api, err := quickSession(cfg)
+		if err != nil {
+			return err
+		}
+		if err := storeQuick(api, text, tags, force); err != nil {
+			return err
+		}
+		fmt.Println("Memory stored")
+		return nil
+	}
+
+	// Never log over the editor
+	if closeLog, err := setupLogging(); err == nil {
+		defer closeLog()
+	} else {
+		log.SetOutput(io.Discard)
+	}
+	final, err := tea.NewProgram(newQuickModel(tags), programOptions(tea.WithAltScreen())...).Run()
+	if err != nil {
+		return err
+	}
+	if final.(quickModel).saved {
+		fmt.Println("Memory stored")
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/bubbletea"
+)
+
+// Rate limit handling shared by every request to the server: on a 429 or an
+// exhausted X-RateLimit-Remaining, requests wait for the window announced by
+// Retry-After / X-RateLimit-Reset and go out one at a time, in order, instead
+// of failing
+const (
+	maxRateLimitRetries = 3
+	defaultRetryAfter   = 5 * time.Second
+	maxRetryAfter       = 5 * time.Minute
+)
+
+type rateLimiter struct {
+	queue sync.Mutex // Held while waiting for the window to end, keeps requests in order
+	mu    sync.Mutex
+	until time.Time
+
+	// notify is called when a wait window starts, to show a countdown
+	notify func(until time.Time)
+}
+
+var serverRateLimit rateLimiter
+
+type rateLimitedMsg struct{ until time.Time }
+
+type rateLimitTickMsg struct{}
+
+// parseRetryAfter reads Retry-After in seconds or as an HTTP date, then
+// X-RateLimit-Reset as a delay or a Unix timestamp
+func parseRetryAfter(h http.Header, now time.Time) (time.Duration, bool) {
+	if v := h.Get("Retry-After"); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil {
+			return time.Duration(secs) * time.Second, true
+		}
+		if t, err := http.ParseTime(v); err == nil {
+			return t.Sub(now), true
+		}
+	}
+	if v := h.Get("X-RateLimit-Reset"); v != "" {
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			// Large values are timestamps, small ones delays
+			if n > 1e9 {
+				return time.Unix(n, 0).Sub(now), true
+			}
+			return time.Duration(n) * time.Second, true
+		}
+	}
+	return 0, false
+}
+
+func clampRetryAfter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return time.Second
+	}
+	if d > maxRetryAfter {
+		return maxRetryAfter
+	}
+	return d
+}
+
+func (l *rateLimiter) limit(d time.Duration) {
+	l.mu.Lock()
+	l.until = time.Now().Add(clampRetryAfter(d))
+	until := l.until
+	l.mu.Unlock()
+	if l.notify != nil {
+		l.notify(until)
+	}
+}
+
+// wait blocks until the rate limit window ends
+func (l *rateLimiter) wait(req *http.Request) error {
+	l.queue.Lock()
+	defer l.queue.Unlock()
+	for {
+		l.mu.Lock()
+		wait := time.Until(l.until)
+		l.mu.Unlock()
+		if wait <= 0 {
+			return nil
+		}
+		select {
+		case <-time.After(wait):
+		case <-req.Context().Done():
+			return req.Context().Err()
+		}
+	}
+}
+
+func (l *rateLimiter) roundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		if err := l.wait(req); err != nil {
+			return nil, err
+		}
+
+		resp, err := http.DefaultTransport.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode != http.StatusTooManyRequests {
+			// Wait before the next request when this one used the last slot
+			if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+				if d, ok := parseRetryAfter(resp.Header, time.Now()); ok {
+					l.limit(d)
+				}
+			}
+			return resp, nil
+		}
+
+		d, ok := parseRetryAfter(resp.Header, time.Now())
+		if !ok {
+			d = defaultRetryAfter
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		if attempt == maxRateLimitRetries || (req.Body != nil && req.GetBody == nil) {
+			return nil, fmt.Errorf("rate limited by the server, retry in %s", clampRetryAfter(d).Round(time.Second))
+		}
+		l.limit(d)
+
+		// Replay the body for the retry
+		if req.Body != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+func rateLimitTick() tea.Cmd {
+	return tea.Tick(tickInterval(time.Second), func(time.Time) tea.Msg { return rateLimitTickMsg{} })
+}
+
+// rateLimitStatus is the countdown shown in the status bar
+func (m model) rateLimitStatus() string {
+	remaining := time.Until(m.rateLimitedUntil).Round(time.Second)
+	if remaining <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("⏳ Rate limited by the server, queued requests resume in %s", remaining)
+}
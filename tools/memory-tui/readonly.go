@@ -0,0 +1,52 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// Read-only mode: with "read_only" in the configuration, or when the server
+// grants the session a read-only role in /status, memories can be browsed,
+// searched and exported but not added, imported, deleted or restored, to
+// demo memory-tui or let the family look through the store safely. The
+// commands with side effects (SideEffects in commands.go), such as /run or
+// /mail, are disabled as well. The API client refuses the changes too,
+// whatever view sends them.
+var errReadOnly = errors.New("read-only mode: memories cannot be changed")
+
+// Keys of the list changing memories, hidden from its hint line
+var readOnlyListKeys = map[string]bool{"a": true, "delete": true}
+
+// refuseReadOnly returns errReadOnly in read-only mode
+func (api *MemoryAPI) refuseReadOnly() error {
+	if api.ReadOnly {
+		logf("INFO", "read-only mode: change refused")
+		return errReadOnly
+	}
+	return nil
+}
+
+func (m model) readOnly() bool {
+	return m.api != nil && m.api.ReadOnly
+}
+
+// readOnlyError is the message of a change refused by the interface
+func readOnlyError() error {
+	return errors.New(tr("Read-only mode: memories cannot be changed"))
+}
+
+// writableKeys drops the keys changing memories from the list bindings in
+// read-only mode
+func (m model) writableKeys(keys []key.Binding) []key.Binding {
+	if !m.readOnly() || m.state != listView {
+		return keys
+	}
+	var kept []key.Binding
+	for _, b := range keys {
+		if !readOnlyListKeys[b.Keys()[0]] {
+			kept = append(kept, b)
+		}
+	}
+	return kept
+}
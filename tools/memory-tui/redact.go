@@ -0,0 +1,192 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Redaction of the texts sent as memories: the entities named in "redact"
+// (email, card, api_key) and the regexps of "redact_patterns" are masked
+// before they reach the server logs and mem0. Interactive adds show what
+// was masked first, and can still be sent as typed; imports are masked
+// silently.
+type redactionRule struct {
+	name  string
+	re    *regexp.Regexp
+	valid func(match string) bool // Optional check of a match, e.g. Luhn
+}
+
+// A masked match of the text
+type redaction struct {
+	rule  string
+	match string
+}
+
+var builtinRedactions = map[string]redactionRule{
+	"email": {re: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)},
+	"card":  {re: regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`), valid: luhnValid},
+	"api_key": {re: regexp.MustCompile(`\b(?:sk-[A-Za-z0-9_-]{20,}|gh[pousr]_[A-Za-z0-9]{30,}|AKIA[0-9A-Z]{16}|xox[abprs]-[A-Za-z0-9-]{10,}|AIza[0-9A-Za-z_-]{35})\b` +
+		`|(?i:bearer)\s+[A-Za-z0-9._~+/-]{20,}=*`)},
+}
+
+// Rules of the configuration, see applyRedactionConfig
+var redactionRules []redactionRule
+
+// applyRedactionConfig compiles the rules of the configuration, skipping
+// the unknown entities and invalid patterns with a warning
+func applyRedactionConfig(cfg config) {
+	redactionRules = nil
+	for _, name := range cfg.Redact {
+		rule, ok := builtinRedactions[name]
+		if !ok {
+			logf("WARN", "unknown redaction %q, expected email, card or api_key", name)
+			continue
+		}
+		rule.name = name
+		redactionRules = append(redactionRules, rule)
+	}
+
+	names := make([]string, 0, len(cfg.RedactPatterns))
+	for name := range cfg.RedactPatterns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		re, err := regexp.Compile(cfg.RedactPatterns[name])
+		if err != nil {
+			logf("WARN", "redaction pattern %s: %v", name, err)
+			continue
+		}
+		redactionRules = append(redactionRules, redactionRule{name: name, re: re})
+	}
+}
+
+func luhnValid(number string) bool {
+	sum, double := 0, false
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// redactText masks the matches of the rules, in order, as [name]
+func redactText(text string) (string, []redaction) {
+	var found []redaction
+	for _, rule := range redactionRules {
+		text = rule.re.ReplaceAllStringFunc(text, func(match string) string {
+			if rule.valid != nil && !rule.valid(match) {
+				return match
+			}
+			found = append(found, redaction{rule.name, match})
+			return "[" + rule.name + "]"
+		})
+	}
+	return text, found
+}
+
+// A memory waiting for the redaction preview
+type pendingRedaction struct {
+	text     string
+	masked   string
+	metadata map[string]interface{}
+	split    bool // Offer to split the text when long, as the add view does
+	found    []redaction
+	from     viewState
+}
+
+// submitMemory sends a memory typed by the user, showing the redaction
+// preview first when a rule matches
+func (m model) submitMemory(text string, metadata map[string]interface{}, split bool) (tea.Model, tea.Cmd) {
+	if masked, found := redactText(text); len(found) > 0 {
+		m.redaction = pendingRedaction{text, masked, metadata, split, found, m.state}
+		cmd := m.showView(redactView)
+		return m, cmd
+	}
+	return m.sendMemory(text, metadata, split)
+}
+
+// sendMemory stores a memory once its possible secrets are confirmed
+func (m model) sendMemory(text string, metadata map[string]interface{}, split bool) (tea.Model, tea.Cmd) {
+	return m.reviewSecrets([]string{""}, []string{text}, func(m model, keep []bool) (tea.Model, tea.Cmd) {
+		if !keep[0] {
+			m.message = tr("Memory not stored")
+			return m, nil
+		}
+		return m.storeMemory(text, metadata, split)
+	})
+}
+
+func (m model) storeMemory(text string, metadata map[string]interface{}, split bool) (tea.Model, tea.Cmd) {
+	if split && utf8.RuneCountInString(text) > m.chunkSize {
+		// Offer to split long texts into several memories
+		return m.openChunkPreview(text), nil
+	}
+	m.loading = true
+	return m, tea.Cmd(func() tea.Msg {
+		err := m.api.AddMemory(text, metadata)
+		if err != nil {
+			return errMsg{err}
+		}
+		return memoryAddedMsg{}
+	})
+}
+
+func (m model) updateRedactView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	r := m.redaction
+	switch msg.String() {
+	case "enter", "y":
+		m.redaction = pendingRedaction{}
+		m.showView(r.from)
+		return m.sendMemory(r.masked, r.metadata, r.split)
+	case "o":
+		// Sent as typed, for this memory only
+		logf("INFO", "redaction overridden for %d matches", len(r.found))
+		m.redaction = pendingRedaction{}
+		m.showView(r.from)
+		return m.sendMemory(r.text, r.metadata, r.split)
+	case "esc", "n":
+		m.redaction = pendingRedaction{}
+		cmd := m.showView(r.from)
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m model) renderRedactView() string {
+	modalWidth := min(70, m.width-10)
+	r := m.redaction
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(tr("🕶️ Redaction")))
+	b.WriteString("\n\n")
+	b.WriteString(tr("%d sensitive values will be masked:", len(r.found)) + "\n\n")
+	for i, f := range r.found {
+		if i == 8 {
+			b.WriteString(tr("... and %d more", len(r.found)-8) + "\n")
+			break
+		}
+		b.WriteString(fmt.Sprintf("• %s  %s\n", selectedItemStyle.Render("["+f.rule+"]"), truncateString(f.match, modalWidth-20)))
+	}
+	b.WriteString("\n" + helpStyle.Render(tr("Text sent:")) + "\n")
+	b.WriteString(wrapText(truncateString(r.masked, 600), modalWidth-6) + "\n\n")
+	b.WriteString(m.keyHint(""))
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, modalStyle.Width(modalWidth).Render(b.String()))
+}
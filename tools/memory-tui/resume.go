@@ -0,0 +1,142 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/charmbracelet/bubbletea"
+)
+
+// Resumable imports: the entries of an import and the hashes of those sent
+// are saved in the local store every few items, see store.go, and removed
+// once everything went through. After a dropped connection or a Ctrl+C,
+// `memory-tui --resume` (or /import --resume) sends the rest, skipping the
+// entries already sent and the texts already on the server, which covers
+// the items sent after the last checkpoint.
+const checkpointEvery = 10
+
+type importCheckpoint struct {
+	Server  string        `json:"server"`
+	Started string        `json:"started"`
+	Entries []importEntry `json:"entries"`
+	Done    []string      `json:"done"` // Hashes of the texts sent
+
+	sent int // Entries sent since the last save
+}
+
+func (cp *importCheckpoint) save() error {
+	db, err := openStore()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	if _, err := db.Exec(`INSERT INTO import_checkpoint (id, data) VALUES (1, ?)
+		ON CONFLICT (id) DO UPDATE SET data = excluded.data`, string(data)); err != nil {
+		return err
+	}
+	cp.sent = 0
+	return nil
+}
+
+// loadCheckpoint returns sql.ErrNoRows when no import was interrupted
+func loadCheckpoint() (*importCheckpoint, error) {
+	db, err := openStore()
+	if err != nil {
+		return nil, err
+	}
+	var data string
+	if err := db.QueryRow(`SELECT data FROM import_checkpoint WHERE id = 1`).Scan(&data); err != nil {
+		return nil, err
+	}
+	var cp importCheckpoint
+	if err := json.Unmarshal([]byte(data), &cp); err != nil {
+		return nil, err
+	}
+	return &cp, nil
+}
+
+func removeCheckpoint() {
+	db, err := openStore()
+	if err == nil {
+		_, err = db.Exec(`DELETE FROM import_checkpoint`)
+	}
+	if err != nil {
+		logf("WARN", "import checkpoint: %v", err)
+	}
+}
+
+// markSent records an entry sent, saving every checkpointEvery entries; it
+// runs in the batch steps, one at a time
+func (cp *importCheckpoint) markSent(text string) {
+	cp.Done = append(cp.Done, textHash(text))
+	cp.sent++
+	if cp.sent >= checkpointEvery {
+		if err := cp.save(); err != nil {
+			logf("WARN", "import checkpoint: %v", err)
+		}
+	}
+}
+
+// ended keeps the checkpoint of an incomplete import, removing it otherwise
+func (cp *importCheckpoint) ended(job *batchJob) {
+	if job.failed == 0 && !job.cancelled {
+		removeCheckpoint()
+		return
+	}
+	if err := cp.save(); err != nil {
+		logf("WARN", "import checkpoint: %v", err)
+	}
+}
+
+// resumeImport sends the entries of the saved import not sent yet, once the
+// memories are loaded to recognise those already on the server
+func (m model) resumeImport() (tea.Model, tea.Cmd) {
+	cp, err := loadCheckpoint()
+	if errors.Is(err, sql.ErrNoRows) {
+		m.message = tr("No interrupted import to resume")
+		return m, nil
+	}
+	if err != nil {
+		m.err = err
+		return m, nil
+	}
+	if cp.Server != m.api.ServerURL {
+		m.err = errors.New(tr("The interrupted import was for %s", cp.Server))
+		return m, nil
+	}
+
+	skip := map[string]bool{}
+	for _, hash := range cp.Done {
+		skip[hash] = true
+	}
+	for _, mem := range m.memories {
+		skip[textHash(mem.Memory)] = true
+	}
+	var remaining []importEntry
+	for _, e := range cp.Entries {
+		text, _ := redactText(e.Text)
+		if !skip[textHash(e.Text)] && !skip[textHash(text)] {
+			remaining = append(remaining, e)
+		}
+	}
+	logf("INFO", "resuming the import of %s: %d of %d entries left", cp.Started, len(remaining), len(cp.Entries))
+	if len(remaining) == 0 {
+		removeCheckpoint()
+		m.message = tr("The interrupted import was complete")
+		return m, nil
+	}
+	return m.runImport(remaining, cp)
+}
+
+func newImportCheckpoint(server string, entries []importEntry) *importCheckpoint {
+	return &importCheckpoint{
+		Server:  server,
+		Started: time.Now().Format(time.RFC3339),
+		Entries: entries,
+	}
+}
@@ -0,0 +1,419 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+// Running code blocks: /run N runs the block N saved by /scratch, in a
+// subshell of the current directory (see /cd), once confirmed on a modal
+// showing the exact command and the whole code. What runs is the code shown,
+// from a copy in a temporary file, not the block read again. Its output
+// shows in a view where s posts it back to Tom, asking what it means and
+// what to do next.
+//
+// This is no sandbox: the code runs with the rights of the user. It only
+// gets no input, an environment without the secrets of runSecretEnv, and a
+// process group of its own, killed as a whole after runTimeout.
+const (
+	runTimeout        = 2 * time.Minute
+	runWaitDelay      = 5 * time.Second // Output left open by a process in the background, at most
+	runOutputLimit    = 64 << 10        // Bytes kept of the output, the last ones
+	runTabWidth       = 4               // Columns of a tab in the code shown
+	runFeedbackLimit  = 8000            // Characters of the output posted back to Tom
+	runFeedbackPrompt = "I ran block %d you gave me with `%s`, exit status %d. Tell me briefly what the output " +
+		"means and the next step, if any.\n\nOutput:\n```\n%s\n```"
+)
+
+// runSecretEnv are the words of the environment variables kept from the
+// code, such as TOM_PASSPHRASE or GITHUB_TOKEN
+var runSecretEnv = []string{"PASSPHRASE", "PASSWORD", "PASSWD", "TOKEN", "SECRET", "API_KEY", "CREDENTIALS"}
+
+// runEnv is the environment of the code, without the secrets
+func runEnv() []string {
+	var env []string
+	for _, kv := range os.Environ() {
+		name, _, _ := strings.Cut(kv, "=")
+		secret := false
+		for _, word := range runSecretEnv {
+			if strings.Contains(strings.ToUpper(name), word) {
+				secret = true
+				break
+			}
+		}
+		if !secret {
+			env = append(env, kv)
+		}
+	}
+	return env
+}
+
+// runInterpreters are the commands running a block by its extension
+var runInterpreters = map[string][]string{
+	"sh": {"sh"},
+	"py": {"python3"},
+	"js": {"node"},
+	"rb": {"ruby"},
+}
+
+type runResult struct {
+	block    scratchFile
+	command  string
+	output   string
+	dropped  int64 // Bytes of output before the runOutputLimit last ones
+	exitCode int   // -1 when the command could not start or timed out
+	duration time.Duration
+	err      error
+}
+
+type runDoneMsg struct{ result runResult }
+
+type runFeedbackMsg struct {
+	block  int
+	answer string
+}
+
+// scratchBlock returns the block N of dir
+func scratchBlock(dir string, n int) (scratchFile, error) {
+	files, err := listScratch(dir)
+	if err != nil {
+		return scratchFile{}, err
+	}
+	for _, f := range files {
+		if f.n == n {
+			return f, nil
+		}
+	}
+	return scratchFile{}, fmt.Errorf("no code block %d, see /scratch", n)
+}
+
+// runInterpreter returns the command running a block by its extension
+func runInterpreter(path string) ([]string, error) {
+	ext := strings.TrimPrefix(filepath.Ext(path), ".")
+	interpreter, ok := runInterpreters[ext]
+	if !ok {
+		return nil, fmt.Errorf("%s blocks do not run, only sh, py, js and rb ones", ext)
+	}
+	return interpreter, nil
+}
+
+// runCommandLine is the command shown and logged, the block standing for
+// the copy of its code that runs
+func runCommandLine(block scratchFile, interpreter []string) string {
+	return strings.Join(interpreter, " ") + " " + filepath.Base(block.path)
+}
+
+// tailWriter keeps the last limit bytes written, so a command printing
+// without end does not fill the memory
+type tailWriter struct {
+	limit   int
+	buf     []byte
+	dropped int64
+}
+
+func (w *tailWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	// Cut once twice the limit is reached rather than at every write
+	if len(w.buf) > 2*w.limit {
+		cut := len(w.buf) - w.limit
+		w.dropped += int64(cut)
+		w.buf = append(w.buf[:0], w.buf[cut:]...)
+	}
+	return len(p), nil
+}
+
+func (w *tailWriter) tail() (string, int64) {
+	if len(w.buf) <= w.limit {
+		return string(w.buf), w.dropped
+	}
+	cut := runeStart(w.buf, len(w.buf)-w.limit)
+	return string(w.buf[cut:]), w.dropped + int64(cut)
+}
+
+// runeStart moves i forward to the start of a rune, so that a cut at i does
+// not split a character
+func runeStart(b []byte, i int) int {
+	for i < len(b) && !utf8.RuneStart(b[i]) {
+		i++
+	}
+	return i
+}
+
+func runRunCommand(m model, args string) (tea.Model, tea.Cmd) {
+	n, err := strconv.Atoi(args)
+	if err != nil {
+		m.message = tr("Usage: %s", "/run N")
+		return m, nil
+	}
+	block, err := scratchBlock(m.scratchDir, n)
+	if err != nil {
+		m.err = err
+		return m, nil
+	}
+	interpreter, err := runInterpreter(block.path)
+	if err != nil {
+		m.err = err
+		return m, nil
+	}
+	data, err := os.ReadFile(block.path)
+	if err != nil {
+		m.err = err
+		return m, nil
+	}
+	m.runBlock = block
+	m.runArgv = interpreter
+	m.runCode = string(data)
+	m.layoutRunConfirm()
+	cmd := m.showView(runConfirmView)
+	return m, cmd
+}
+
+// runBlockCmd runs the code shown from a temporary copy, killed with the
+// processes it started after runTimeout
+func runBlockCmd(block scratchFile, interpreter []string, code string) tea.Cmd {
+	return func() tea.Msg {
+		result := runResult{block: block, command: runCommandLine(block, interpreter), exitCode: -1}
+		file, err := os.CreateTemp("", "memory-tui-run-*"+filepath.Ext(block.path))
+		if err != nil {
+			result.err = err
+			return runDoneMsg{result}
+		}
+		defer os.Remove(file.Name())
+		_, err = file.WriteString(code)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			result.err = err
+			return runDoneMsg{result}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), runTimeout)
+		defer cancel()
+		argv := append(append([]string{}, interpreter...), file.Name())
+		cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+		cmd.Env = runEnv()
+		isolateRun(cmd)
+		// A process left in the background may keep the output open: Wait
+		// stops reading it after runWaitDelay
+		cmd.WaitDelay = runWaitDelay
+		// The same writer for both, so exec copies them from one goroutine
+		output := &tailWriter{limit: runOutputLimit}
+		cmd.Stdout = output
+		cmd.Stderr = output
+
+		start := time.Now()
+		err = cmd.Run()
+		result.duration = time.Since(start)
+		out, dropped := output.tail()
+		result.output, result.dropped = strings.TrimRight(out, "\n"), dropped
+		result.exitCode = 0
+
+		var exitErr *exec.ExitError
+		switch {
+		case ctx.Err() != nil:
+			result.exitCode, result.err = -1, fmt.Errorf("killed after %s", runTimeout)
+		case errors.As(err, &exitErr):
+			result.exitCode = exitErr.ExitCode()
+		case errors.Is(err, exec.ErrWaitDelay):
+			logf("INFO", "code block %d left processes writing in the background", block.n)
+			result.exitCode = cmd.ProcessState.ExitCode()
+		case err != nil:
+			result.exitCode, result.err = -1, err
+		}
+		return runDoneMsg{result}
+	}
+}
+
+func (m model) updateRunConfirmView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "Y":
+		logf("INFO", "running code block %d: %s", m.runBlock.n, runCommandLine(m.runBlock, m.runArgv))
+		m.loading = true
+		m.showView(listView)
+		return m, runBlockCmd(m.runBlock, m.runArgv, m.runCode)
+	case "n", "N", "esc":
+		cmd := m.showView(listView)
+		return m, cmd
+	case "g", "home":
+		m.runCodeViewport.GotoTop()
+		return m, nil
+	case "G", "end":
+		m.runCodeViewport.GotoBottom()
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.runCodeViewport, cmd = m.runCodeViewport.Update(msg)
+	return m, cmd
+}
+
+func runModalWidth(width int) int {
+	return min(80, width-10)
+}
+
+// layoutRunConfirm shows the whole code on the confirmation, the long
+// lines wrapped rather than cut
+func (m *model) layoutRunConfirm() {
+	width := max(20, runModalWidth(m.width)-6)
+	content := hardWrapLines(strings.TrimRight(m.runCode, "\n"), width)
+	m.runCodeViewport = viewport.New(width, max(3, min(strings.Count(content, "\n")+1, m.height-18)))
+	m.runCodeViewport.SetContent(content)
+}
+
+// hardWrapLines hard wraps each line of text, its tabs expanded
+func hardWrapLines(text string, width int) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = hardWrap(strings.ReplaceAll(line, "\t", strings.Repeat(" ", runTabWidth)), width)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// hardWrap cuts a line every width columns, keeping its spaces
+func hardWrap(line string, width int) string {
+	var b strings.Builder
+	col := 0
+	for _, r := range line {
+		w := runewidth.RuneWidth(r)
+		if col+w > width && col > 0 {
+			b.WriteString("\n")
+			col = 0
+		}
+		b.WriteRune(r)
+		col += w
+	}
+	return b.String()
+}
+
+func (m model) renderRunConfirmModal() string {
+	modalWidth := runModalWidth(m.width)
+	width := max(20, modalWidth-6)
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(tr("▶ Run block %d?", m.runBlock.n)))
+	b.WriteString("\n\n")
+	b.WriteString(selectedItemStyle.Render(wrapText("$ "+runCommandLine(m.runBlock, m.runArgv), width)))
+	b.WriteString("\n")
+	cwd, _ := os.Getwd()
+	b.WriteString(helpStyle.Render(tr("in %s, killed after %s", cwd, runTimeout)))
+	b.WriteString("\n\n")
+	b.WriteString(m.runCodeViewport.View())
+	b.WriteString("\n")
+	if lines := strings.Count(m.runCodeViewport.View(), "\n") + 1; m.runCodeViewport.TotalLineCount() > lines {
+		b.WriteString(helpStyle.Render(tr("%d%% of the code, ↑/↓ to scroll", int(m.runCodeViewport.ScrollPercent()*100))) + "\n")
+	}
+	b.WriteString("\n" + loginErrorStyle.Render(tr("⚠️ The code runs with your rights, read it first")) + "\n\n")
+	b.WriteString(m.keyHint(""))
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, modalStyle.Width(modalWidth).Render(b.String()))
+}
+
+func (m model) handleRunDone(msg runDoneMsg) (tea.Model, tea.Cmd) {
+	m.loading = false
+	r := msg.result
+	logf("INFO", "code block %d exited with %d in %s", r.block.n, r.exitCode, r.duration.Round(time.Millisecond))
+	if r.err != nil {
+		m.err = fmt.Errorf("block %d: %w", r.block.n, r.err)
+	}
+	m.runResult = r
+	m.layoutRunOutput()
+	cmd := m.showView(runOutputView)
+	return m, cmd
+}
+
+// layoutRunOutput shows the output of the last run
+func (m *model) layoutRunOutput() {
+	m.runViewport = viewport.New(max(20, m.width-8), max(5, m.height-12))
+	output := hardWrapLines(m.runResult.output, max(20, m.width-10))
+	if output == "" {
+		output = helpStyle.Render(tr("No output"))
+	}
+	if m.runResult.dropped > 0 {
+		output = helpStyle.Render(tr("... %d bytes before dropped", m.runResult.dropped)) + "\n" + output
+	}
+	m.runViewport.SetContent(output)
+	m.runViewport.GotoBottom()
+}
+
+// postRunOutput sends the output to Tom, the answer showing as the ones of /ask
+func (m model) postRunOutput() tea.Cmd {
+	r, api := m.runResult, m.api
+	output := r.output
+	if len(output) > runFeedbackLimit {
+		output = "...\n" + output[runeStart([]byte(output), len(output)-runFeedbackLimit):]
+	}
+	prompt := withFileContext(fmt.Sprintf(runFeedbackPrompt, r.block.n, r.command, r.exitCode, output), m.promptFiles())
+	return func() tea.Msg {
+		answer, err := api.Process(prompt)
+		if err != nil {
+			return errMsg{err}
+		}
+		answer = strings.TrimSpace(answer)
+		runHook(api.Hooks, hookResponse, map[string]interface{}{"source": "run", "prompt": r.command, "answer": answer})
+		return runFeedbackMsg{r.block.n, answer}
+	}
+}
+
+func (m model) updateRunOutputView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		cmd := m.showView(listView)
+		return m, cmd
+	case "s":
+		m.loading = true
+		cmd := m.postRunOutput()
+		m.fileContext = nil
+		return m, cmd
+	case "y":
+		if err := clipboard.WriteAll(m.runResult.output); err != nil {
+			m.err = fmt.Errorf("failed to copy to clipboard: %w", err)
+			return m, nil
+		}
+		m.message = tr("Output copied to the clipboard")
+		return m, nil
+	case "g", "home":
+		m.runViewport.GotoTop()
+		return m, nil
+	case "G", "end":
+		m.runViewport.GotoBottom()
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.runViewport, cmd = m.runViewport.Update(msg)
+	return m, cmd
+}
+
+func (m model) renderRunOutputView() string {
+	style := contentBoxStyle.Width(m.width - 4)
+	if m.focus == focusContent {
+		style = contentBoxFocusedStyle.Width(m.width - 4)
+	}
+	r := m.runResult
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("▶ $ " + truncateString(r.command, max(20, m.width-20))))
+	status := tr("exit %d, %s", r.exitCode, r.duration.Round(time.Millisecond))
+	if r.exitCode == 0 {
+		b.WriteString(" " + helpStyle.Render(status))
+	} else {
+		b.WriteString(" " + loginErrorStyle.Render(status))
+	}
+	b.WriteString("\n\n")
+	b.WriteString(m.runViewport.View())
+	b.WriteString("\n\n")
+	b.WriteString(m.keyHint(""))
+	return style.Render(b.String())
+}
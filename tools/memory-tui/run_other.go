@@ -0,0 +1,9 @@
+//go:build !unix
+
+package main
+
+import "os/exec"
+
+// isolateRun leaves the command as is: without process groups, the timeout
+// kills the interpreter alone
+func isolateRun(cmd *exec.Cmd) {}
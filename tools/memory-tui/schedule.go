@@ -0,0 +1,393 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Scheduled prompts: `memory-tui schedule add "daily 8am" PROMPT` stores a
+// prompt the daemon sends to Tom (POST /process) on schedule, the answer
+// going to a desktop notification and/or the end of a digest file, and to
+// the "scheduled_answer" hook. The daemon checks the schedules every minute;
+// a run missed while it was stopped happens once when it starts again.
+const (
+	scheduleCheckInterval = time.Minute
+	minScheduleEvery      = 5 * time.Minute
+)
+
+type scheduledPrompt struct {
+	ID        int64     `json:"id"`
+	Spec      string    `json:"spec"`
+	Prompt    string    `json:"prompt"`
+	Notify    bool      `json:"notify"`
+	Digest    string    `json:"digest,omitempty"` // File the answers are appended to
+	CreatedAt time.Time `json:"created_at"`
+	LastRun   time.Time `json:"last_run"` // Zero before the first run
+}
+
+// scheduleSpec is a parsed spec: "hourly", "every 2h", or days and a time
+// such as "daily 8am", "weekdays 7:30", "mon,thu 18h"
+type scheduleSpec struct {
+	every        time.Duration
+	days         [7]bool // By time.Weekday
+	hour, minute int
+}
+
+var scheduleDays = map[string][]time.Weekday{
+	"daily":    {time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday},
+	"weekdays": {time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+	"weekends": {time.Saturday, time.Sunday},
+}
+
+func init() {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		name := strings.ToLower(day.String())
+		scheduleDays[name] = []time.Weekday{day}
+		scheduleDays[name[:3]] = []time.Weekday{day}
+	}
+}
+
+func parseScheduleSpec(spec string) (scheduleSpec, error) {
+	var s scheduleSpec
+	fields := strings.Fields(strings.ToLower(spec))
+	switch {
+	case len(fields) == 1 && fields[0] == "hourly":
+		s.every = time.Hour
+		return s, nil
+	case len(fields) == 2 && fields[0] == "every":
+		d, err := time.ParseDuration(fields[1])
+		if err != nil {
+			return s, fmt.Errorf("invalid schedule %q: %w", spec, err)
+		}
+		if d < minScheduleEvery {
+			return s, fmt.Errorf("invalid schedule %q: 5m at least between two runs", spec)
+		}
+		s.every = d
+		return s, nil
+	case len(fields) != 2:
+		return s, fmt.Errorf("invalid schedule %q, expected e.g. \"daily 8am\", \"weekdays 7:30\", \"mon,thu 18h\" or \"every 2h\"", spec)
+	}
+
+	for _, name := range strings.Split(fields[0], ",") {
+		days, ok := scheduleDays[name]
+		if !ok {
+			return s, fmt.Errorf("invalid schedule %q: unknown day %q", spec, name)
+		}
+		for _, day := range days {
+			s.days[day] = true
+		}
+	}
+	var err error
+	if s.hour, s.minute, err = parseClock(fields[1]); err != nil {
+		return s, fmt.Errorf("invalid schedule %q: %w", spec, err)
+	}
+	return s, nil
+}
+
+// parseClock reads "8am", "8:30pm", "20:15", "18h", "18h30" or "8"
+func parseClock(clock string) (hour, minute int, err error) {
+	text := clock
+	offset := 0
+	if rest, ok := strings.CutSuffix(text, "am"); ok {
+		text = rest
+	} else if rest, ok := strings.CutSuffix(text, "pm"); ok {
+		text, offset = rest, 12
+	}
+	h, m, found := strings.Cut(strings.Replace(text, "h", ":", 1), ":")
+	if hour, err = strconv.Atoi(h); err != nil {
+		return 0, 0, fmt.Errorf("invalid time %q", clock)
+	}
+	if found && m != "" {
+		if minute, err = strconv.Atoi(m); err != nil {
+			return 0, 0, fmt.Errorf("invalid time %q", clock)
+		}
+	}
+	if text != clock {
+		// 12am is midnight and 12pm noon
+		if hour < 1 || hour > 12 {
+			return 0, 0, fmt.Errorf("invalid time %q", clock)
+		}
+		hour = hour%12 + offset
+	}
+	if hour > 23 || minute > 59 {
+		return 0, 0, fmt.Errorf("invalid time %q", clock)
+	}
+	return hour, minute, nil
+}
+
+// next returns the first run after t
+func (s scheduleSpec) next(t time.Time) time.Time {
+	if s.every > 0 {
+		return t.Add(s.every)
+	}
+	t = t.Local()
+	for i := 0; i <= 7; i++ {
+		day := t.AddDate(0, 0, i)
+		at := time.Date(day.Year(), day.Month(), day.Day(), s.hour, s.minute, 0, 0, t.Location())
+		if at.After(t) && s.days[at.Weekday()] {
+			return at
+		}
+	}
+	return time.Time{}
+}
+
+// due reports whether the prompt has a run pending at now
+func (p scheduledPrompt) due(now time.Time) bool {
+	spec, err := parseScheduleSpec(p.Spec)
+	if err != nil {
+		return false
+	}
+	since := p.LastRun
+	if since.IsZero() {
+		since = p.CreatedAt
+	}
+	next := spec.next(since)
+	return !next.IsZero() && !next.After(now)
+}
+
+func addSchedule(p scheduledPrompt) (int64, error) {
+	db, err := openStore()
+	if err != nil {
+		return 0, err
+	}
+	res, err := db.Exec(`INSERT INTO schedule (spec, prompt, notify, digest, created_at) VALUES (?, ?, ?, ?, ?)`,
+		p.Spec, p.Prompt, p.Notify, p.Digest, p.CreatedAt.Format(time.RFC3339))
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+func loadSchedules() ([]scheduledPrompt, error) {
+	db, err := openStore()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.Query(`SELECT id, spec, prompt, notify, digest, created_at, last_run FROM schedule ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var schedules []scheduledPrompt
+	for rows.Next() {
+		var p scheduledPrompt
+		var createdAt, lastRun string
+		if err := rows.Scan(&p.ID, &p.Spec, &p.Prompt, &p.Notify, &p.Digest, &createdAt, &lastRun); err != nil {
+			return nil, err
+		}
+		p.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+		p.LastRun, _ = time.Parse(time.RFC3339, lastRun)
+		schedules = append(schedules, p)
+	}
+	return schedules, rows.Err()
+}
+
+func removeSchedule(id int64) error {
+	db, err := openStore()
+	if err != nil {
+		return err
+	}
+	res, err := db.Exec(`DELETE FROM schedule WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("no scheduled prompt %d", id)
+	}
+	return nil
+}
+
+func markScheduleRun(id int64, at time.Time) error {
+	db, err := openStore()
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`UPDATE schedule SET last_run = ? WHERE id = ?`, at.Format(time.RFC3339), id)
+	return err
+}
+
+// runScheduledPrompt asks Tom and delivers the answer
+func runScheduledPrompt(api *MemoryAPI, hooks map[string]string, p scheduledPrompt) (string, error) {
+	answer, err := api.Process(p.Prompt)
+	if err != nil {
+		return "", err
+	}
+	answer = strings.TrimSpace(answer)
+
+	var errs []error
+	if p.Notify {
+		if err := desktopNotify("Tom: "+truncateString(p.Prompt, 60), answer); err != nil {
+			errs = append(errs, fmt.Errorf("notification: %w", err))
+		}
+	}
+	if p.Digest != "" {
+		if err := appendDigest(p.Digest, p, answer); err != nil {
+			errs = append(errs, fmt.Errorf("digest: %w", err))
+		}
+	}
+	runHook(hooks, hookScheduledAnswer, map[string]interface{}{"id": p.ID, "spec": p.Spec, "prompt": p.Prompt, "answer": answer})
+	return answer, errors.Join(errs...)
+}
+
+// desktopNotify shows a notification with notify-send, or osascript on macOS
+func desktopNotify(title, body string) error {
+	if runtime.GOOS == "darwin" {
+		return exec.Command("osascript", "-e", fmt.Sprintf("display notification %q with title %q", body, title)).Run()
+	}
+	path, err := exec.LookPath("notify-send")
+	if err != nil {
+		return err
+	}
+	return exec.Command(path, "--app-name=Tom", title, body).Run()
+}
+
+func appendDigest(path string, p scheduledPrompt, answer string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(f, "## %s — %s\n\n%s\n\n", time.Now().Format("2006-01-02 15:04"), p.Prompt, answer)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// runSchedules runs the due prompts with the daemon session, one at a time
+func (d *daemon) runSchedules() {
+	d.mu.Lock()
+	sess := d.session
+	d.mu.Unlock()
+	if sess == nil {
+		return
+	}
+
+	schedules, err := loadSchedules()
+	if err != nil {
+		logf("WARN", "scheduled prompts: %v", err)
+		return
+	}
+	now := time.Now()
+	for _, p := range schedules {
+		if !p.due(now) {
+			continue
+		}
+		// Marked first, a failing prompt waits for its next run
+		if err := markScheduleRun(p.ID, now); err != nil {
+			logf("WARN", "scheduled prompt %d: %v", p.ID, err)
+			continue
+		}
+		api := sess.API.Background(timeoutBulk)
+		if _, err := runScheduledPrompt(api, d.hooks, p); err != nil {
+			logf("WARN", "scheduled prompt %d: %v", p.ID, err)
+		} else {
+			logf("INFO", "scheduled prompt %d answered", p.ID)
+		}
+	}
+}
+
+// scheduleID reads the ID argument of the schedule commands
+func scheduleID(args []string) (int64, error) {
+	if len(args) != 1 {
+		return 0, errors.New("expected the ID of a scheduled prompt, see memory-tui schedule list")
+	}
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid ID %q", args[0])
+	}
+	return id, nil
+}
+
+func runScheduleAdd(spec, prompt string, notify bool, digest string) error {
+	parsed, err := parseScheduleSpec(spec)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(prompt) == "" {
+		return errors.New("empty prompt")
+	}
+	if !notify && digest == "" {
+		return errors.New("nothing would deliver the answers, keep --notify or give --digest")
+	}
+	if digest != "" {
+		if digest, err = filepath.Abs(digest); err != nil {
+			return err
+		}
+	}
+
+	p := scheduledPrompt{Spec: spec, Prompt: prompt, Notify: notify, Digest: digest, CreatedAt: time.Now()}
+	id, err := addSchedule(p)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Scheduled prompt %d added, next run %s\n", id, parsed.next(p.CreatedAt).Format("Mon 2006-01-02 15:04"))
+	if _, err := callDaemon("session"); err != nil {
+		fmt.Fprintln(os.Stderr, "The daemon is not running: start `memory-tui daemon` for the prompt to run")
+	}
+	return nil
+}
+
+func runScheduleList() error {
+	schedules, err := loadSchedules()
+	if err != nil {
+		return err
+	}
+	if len(schedules) == 0 {
+		fmt.Println("No scheduled prompt")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSCHEDULE\tNEXT RUN\tDELIVERY\tPROMPT")
+	for _, p := range schedules {
+		next := "-"
+		if spec, err := parseScheduleSpec(p.Spec); err == nil {
+			since := p.LastRun
+			if since.IsZero() {
+				since = p.CreatedAt
+			}
+			next = spec.next(since).Format("Mon 01-02 15:04")
+		}
+		var delivery []string
+		if p.Notify {
+			delivery = append(delivery, "notification")
+		}
+		if p.Digest != "" {
+			delivery = append(delivery, p.Digest)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", p.ID, p.Spec, next, strings.Join(delivery, ", "), truncateString(p.Prompt, 50))
+	}
+	return w.Flush()
+}
+
+// runScheduleRun runs a scheduled prompt now, printing the answer
+func runScheduleRun(id int64) error {
+	schedules, err := loadSchedules()
+	if err != nil {
+		return err
+	}
+	for _, p := range schedules {
+		if p.ID != id {
+			continue
+		}
+		sess, err := openSession()
+		if err != nil {
+			return err
+		}
+		cfg, _ := loadConfig()
+		answer, err := runScheduledPrompt(sess.API, cfg.Hooks, p)
+		if answer != "" {
+			fmt.Println(answer)
+		}
+		return err
+	}
+	return fmt.Errorf("no scheduled prompt %d", id)
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Client-side request scheduler: caps the requests in flight per endpoint and
+// lets interactive requests go before background ones (version checks, batch
+// operations, daemon syncs), so a long import never starves a search
+type requestPriority int
+
+const (
+	priorityInteractive requestPriority = iota
+	priorityBackground
+)
+
+const (
+	defaultEndpointCap = 4
+	maxBackground      = 1 // Background requests in flight, all endpoints together
+)
+
+var endpointCaps = map[string]int{
+	"/memory/memories": 1, // Full list, heavy for the server
+	"/memory/search":   2,
+	"/status":          1,
+}
+
+type requestScheduler struct {
+	mu         sync.Mutex
+	freed      chan struct{} // Closed and replaced whenever a slot frees
+	active     map[string]int
+	background int
+	waiting    int // Interactive requests waiting for a slot
+}
+
+var scheduler = newRequestScheduler()
+
+func newRequestScheduler() *requestScheduler {
+	return &requestScheduler{active: map[string]int{}, freed: make(chan struct{})}
+}
+
+// endpointKey groups the paths of an endpoint, dropping IDs:
+// /memory/memory/42 -> /memory/memory
+func endpointKey(path string) string {
+	parts := strings.SplitN(strings.Trim(path, "/"), "/", 3)
+	if len(parts) > 2 {
+		parts = parts[:2]
+	}
+	return "/" + strings.Join(parts, "/")
+}
+
+func endpointCap(key string) int {
+	if c, ok := endpointCaps[key]; ok {
+		return c
+	}
+	return defaultEndpointCap
+}
+
+// acquire waits for a slot and returns the function releasing it, or the
+// error of ctx when it ends first: the wait counts in the timeout of the
+// request, and a hung request never blocks the others for good
+func (s *requestScheduler) acquire(ctx context.Context, key string, priority requestPriority) (func(), error) {
+	s.mu.Lock()
+	if priority == priorityInteractive {
+		s.waiting++
+	}
+	for {
+		free := s.active[key] < endpointCap(key)
+		if priority == priorityBackground {
+			free = free && s.waiting == 0 && s.background < maxBackground
+		}
+		if free {
+			break
+		}
+		freed := s.freed
+		s.mu.Unlock()
+		select {
+		case <-freed:
+			s.mu.Lock()
+		case <-ctx.Done():
+			s.mu.Lock()
+			if priority == priorityInteractive {
+				s.waiting--
+				// Background requests may have waited on this one only
+				s.broadcast()
+			}
+			s.mu.Unlock()
+			return nil, ctx.Err()
+		}
+	}
+	if priority == priorityInteractive {
+		s.waiting--
+	} else {
+		s.background++
+	}
+	s.active[key]++
+	s.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			s.mu.Lock()
+			s.active[key]--
+			if priority == priorityBackground {
+				s.background--
+			}
+			s.broadcast()
+			s.mu.Unlock()
+		})
+	}, nil
+}
+
+// broadcast wakes the waiting requests, under s.mu
+func (s *requestScheduler) broadcast() {
+	close(s.freed)
+	s.freed = make(chan struct{})
+}
+
+// releaseOnClose keeps the slot until the response body is read
+type releaseOnClose struct {
+	io.ReadCloser
+	release func()
+}
+
+func (r releaseOnClose) Close() error {
+	err := r.ReadCloser.Close()
+	r.release()
+	return err
+}
+
+func (s *requestScheduler) roundTrip(req *http.Request, priority requestPriority) (*http.Response, error) {
+	release, err := s.acquire(req.Context(), endpointKey(req.URL.Path), priority)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := serverRateLimit.roundTrip(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	resp.Body = releaseOnClose{resp.Body, release}
+	return resp, nil
+}
+
+// backgroundClient shares the cookies of client with a background priority
+// and the timeout of class
+func backgroundClient(client *http.Client, class timeoutClass) *http.Client {
+	c := *client
+	c.Transport = userAgentTransport{priority: priorityBackground, timeout: class}
+	return &c
+}
+
+// Background returns a copy of the API sending background requests, polling
+// or bulk ones
+func (api *MemoryAPI) Background(class timeoutClass) *MemoryAPI {
+	bg := *api
+	bg.Client = backgroundClient(api.Client, class)
+	return &bg
+}
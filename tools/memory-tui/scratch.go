@@ -0,0 +1,300 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/bubbletea"
+)
+
+// Scratch files: with "scratch" set or after /scratch on, each fenced code
+// block in Tom's answers (POST /process) is also written to
+// ~/.tom/scratch/SESSION/N.EXT, SESSION being the start time of the
+// interface and EXT following the language of the block, so generated code
+// can be edited or run at once. /scratch lists the blocks of the session;
+// Enter opens one in $VISUAL or $EDITOR, y copies its path.
+const scratchListHeight = 8 // Blocks listed above the preview
+
+// scratchExtensions maps the language of a block to a file extension
+var scratchExtensions = map[string]string{
+	"go": "go", "golang": "go", "python": "py", "py": "py", "python3": "py",
+	"bash": "sh", "sh": "sh", "shell": "sh", "zsh": "sh", "console": "sh",
+	"javascript": "js", "js": "js", "typescript": "ts", "ts": "ts",
+	"json": "json", "yaml": "yaml", "yml": "yaml", "toml": "toml", "ini": "ini",
+	"sql": "sql", "html": "html", "css": "css", "xml": "xml",
+	"rust": "rs", "c": "c", "cpp": "cpp", "c++": "cpp", "java": "java", "ruby": "rb", "php": "php",
+	"markdown": "md", "md": "md", "diff": "diff", "patch": "diff", "dockerfile": "dockerfile",
+}
+
+// scratchMu numbers the blocks of concurrent answers one at a time
+var scratchMu sync.Mutex
+
+type codeBlock struct {
+	lang string
+	code string
+}
+
+type scratchFile struct {
+	n     int
+	path  string
+	title string // First line of the block
+}
+
+type scratchEditedMsg struct{ err error }
+
+// scratchSessionDir is the directory of the blocks of this interface
+func scratchSessionDir(started time.Time) (string, error) {
+	return tomPath("scratch", started.Format("20060102-150405"))
+}
+
+// extractCodeBlocks returns the fenced blocks of a Markdown text, ``` or ~~~
+func extractCodeBlocks(text string) []codeBlock {
+	var blocks []codeBlock
+	var current *codeBlock
+	var fence string
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if current == nil {
+			for _, f := range []string{"```", "~~~"} {
+				if strings.HasPrefix(trimmed, f) {
+					fields := strings.Fields(strings.TrimLeft(trimmed, f[:1]))
+					current, fence, lines = &codeBlock{}, f, nil
+					if len(fields) > 0 {
+						current.lang = strings.ToLower(fields[0])
+					}
+					break
+				}
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+			current.code = strings.Join(lines, "\n") + "\n"
+			blocks = append(blocks, *current)
+			current = nil
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return blocks
+}
+
+func scratchExtension(lang string) string {
+	if ext, ok := scratchExtensions[lang]; ok {
+		return ext
+	}
+	return "txt"
+}
+
+// saveScratch writes the code blocks of the answer after the ones already in
+// dir, returning their paths
+func saveScratch(dir, answer string) ([]string, error) {
+	blocks := extractCodeBlocks(answer)
+	if len(blocks) == 0 {
+		return nil, nil
+	}
+	scratchMu.Lock()
+	defer scratchMu.Unlock()
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	files, err := listScratch(dir)
+	if err != nil {
+		return nil, err
+	}
+	n := 0
+	if len(files) > 0 {
+		n = files[len(files)-1].n
+	}
+	var paths []string
+	for _, block := range blocks {
+		n++
+		path := filepath.Join(dir, fmt.Sprintf("%d.%s", n, scratchExtension(block.lang)))
+		if err := os.WriteFile(path, []byte(block.code), 0600); err != nil {
+			return paths, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// listScratch returns the blocks written to dir, by number
+func listScratch(dir string) ([]scratchFile, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var files []scratchFile
+	for _, entry := range entries {
+		name, _, _ := strings.Cut(entry.Name(), ".")
+		n, err := strconv.Atoi(name)
+		if err != nil || entry.IsDir() {
+			continue
+		}
+		files = append(files, scratchFile{n: n, path: filepath.Join(dir, entry.Name())})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].n < files[j].n })
+	return files, nil
+}
+
+func (m *model) setScratch(on bool) {
+	m.scratch = on
+	if m.api == nil {
+		return
+	}
+	m.api.Scratch = ""
+	if on {
+		m.api.Scratch = m.scratchDir
+	}
+}
+
+func runScratchCommand(m model, args string) (tea.Model, tea.Cmd) {
+	switch args {
+	case "on", "off":
+		m.setScratch(args == "on")
+		if m.scratch {
+			m.message = tr("Scratch on: the code blocks of Tom's answers go to %s", m.scratchDir)
+		} else {
+			m.message = tr("Scratch off")
+		}
+		return m, nil
+	case "":
+	default:
+		m.message = tr("Usage: %s", "/scratch [on|off]")
+		return m, nil
+	}
+
+	files, err := listScratch(m.scratchDir)
+	if err != nil {
+		m.err = err
+		return m, nil
+	}
+	for i := range files {
+		if data, err := os.ReadFile(files[i].path); err == nil {
+			files[i].title = strings.TrimSpace(strings.SplitN(strings.TrimSpace(string(data)), "\n", 2)[0])
+		}
+	}
+	m.scratchFiles = files
+	m.scratchCursor = max(0, len(files)-1)
+	m.layoutScratch()
+	cmd := m.showView(scratchView)
+	return m, cmd
+}
+
+// layoutScratch shows the selected block in the preview
+func (m *model) layoutScratch() {
+	m.scratchViewport = viewport.New(max(20, m.width-8), max(3, m.height-14-min(len(m.scratchFiles), scratchListHeight)))
+	if len(m.scratchFiles) == 0 {
+		return
+	}
+	data, err := os.ReadFile(m.scratchFiles[m.scratchCursor].path)
+	if err != nil {
+		m.scratchViewport.SetContent(loginErrorStyle.Render(err.Error()))
+		return
+	}
+	m.scratchViewport.SetContent(string(data))
+}
+
+// editScratch opens the block in the editor, the interface waiting for it
+func editScratch(path string) tea.Cmd {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	cmd := exec.Command("sh", "-c", editor+` "$1"`, "sh", path)
+	return tea.ExecProcess(cmd, func(err error) tea.Msg { return scratchEditedMsg{err} })
+}
+
+func (m model) updateScratchView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		cmd := m.showView(listView)
+		return m, cmd
+	case "up", "k":
+		if m.scratchCursor > 0 {
+			m.scratchCursor--
+			m.layoutScratch()
+		}
+		return m, nil
+	case "down", "j":
+		if m.scratchCursor < len(m.scratchFiles)-1 {
+			m.scratchCursor++
+			m.layoutScratch()
+		}
+		return m, nil
+	case "enter", "e":
+		if len(m.scratchFiles) == 0 {
+			return m, nil
+		}
+		return m, editScratch(m.scratchFiles[m.scratchCursor].path)
+	case "y":
+		if len(m.scratchFiles) == 0 {
+			return m, nil
+		}
+		if err := clipboard.WriteAll(m.scratchFiles[m.scratchCursor].path); err != nil {
+			m.err = fmt.Errorf("failed to copy to clipboard: %w", err)
+			return m, nil
+		}
+		m.message = tr("Path copied to the clipboard")
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.scratchViewport, cmd = m.scratchViewport.Update(msg)
+	return m, cmd
+}
+
+func (m model) renderScratchView() string {
+	style := contentBoxStyle.Width(m.width - 4)
+	if m.focus == focusContent {
+		style = contentBoxFocusedStyle.Width(m.width - 4)
+	}
+	width := max(20, m.width-10)
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(tr("📝 Scratch")))
+	b.WriteString(" " + helpStyle.Render(m.scratchDir))
+	b.WriteString("\n\n")
+	if len(m.scratchFiles) == 0 {
+		hint := tr("No code block yet in Tom's answers")
+		if !m.scratch {
+			hint = tr("Scratch is off, /scratch on saves the code blocks of Tom's answers")
+		}
+		b.WriteString(helpStyle.Render(hint))
+		b.WriteString("\n\n")
+		b.WriteString(m.keyHint(""))
+		return style.Render(b.String())
+	}
+
+	start := max(0, m.scratchCursor-scratchListHeight+1)
+	for i := start; i < min(len(m.scratchFiles), start+scratchListHeight); i++ {
+		f := m.scratchFiles[i]
+		line := truncateString(fmt.Sprintf("%3d  %-14s %s", f.n, filepath.Base(f.path), f.title), width-2)
+		if i == m.scratchCursor {
+			b.WriteString(selectedItemStyle.Render("▸ " + line))
+		} else {
+			b.WriteString("  " + line)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	b.WriteString(m.scratchViewport.View())
+	b.WriteString("\n\n")
+	b.WriteString(m.keyHint(""))
+	return style.Render(b.String())
+}
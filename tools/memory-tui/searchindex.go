@@ -0,0 +1,201 @@
+package main
+
+import (
+	"errors"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/charmbracelet/bubbletea"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Local search index: the words of the loaded memories in an inverted index,
+// updated in the background after each load with only the memories added,
+// changed or gone since. /filter narrows the list with it without a server
+// request, and a search the server cannot be reached for falls back on it.
+// Words are matched by prefix, ignoring case and accents.
+type searchIndex struct {
+	mu       sync.RWMutex
+	postings map[string]map[string]bool // Word to the IDs of the memories having it
+	words    []string                   // Sorted keys of postings, for prefix lookups
+	docs     map[string]indexedMemory
+}
+
+type indexedMemory struct {
+	text  string // Indexed text, a different one is reindexed
+	words []string
+}
+
+type indexUpdatedMsg struct{}
+
+func newSearchIndex() *searchIndex {
+	return &searchIndex{postings: map[string]map[string]bool{}, docs: map[string]indexedMemory{}}
+}
+
+// indexWords splits text into distinct lowercase words without accents
+func indexWords(text string) []string {
+	folded, _, err := transform.String(transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC), text)
+	if err != nil {
+		folded = text
+	}
+	seen := map[string]bool{}
+	var words []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(folded), func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+		if !seen[word] {
+			seen[word] = true
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// update indexes the memories, returning how many were (re)indexed and
+// removed
+func (idx *searchIndex) update(memories []Memory) (indexed, removed int) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	current := make(map[string]bool, len(memories))
+	for _, mem := range memories {
+		current[mem.ID] = true
+		if doc, ok := idx.docs[mem.ID]; ok && doc.text == mem.Memory {
+			continue
+		}
+		idx.remove(mem.ID)
+		doc := indexedMemory{text: mem.Memory, words: indexWords(mem.Memory)}
+		for _, word := range doc.words {
+			if idx.postings[word] == nil {
+				idx.postings[word] = map[string]bool{}
+			}
+			idx.postings[word][mem.ID] = true
+		}
+		idx.docs[mem.ID] = doc
+		indexed++
+	}
+	for id := range idx.docs {
+		if !current[id] {
+			idx.remove(id)
+			removed++
+		}
+	}
+
+	if indexed > 0 || removed > 0 {
+		idx.words = idx.words[:0]
+		for word := range idx.postings {
+			idx.words = append(idx.words, word)
+		}
+		sort.Strings(idx.words)
+	}
+	return indexed, removed
+}
+
+// remove drops a memory from the postings, the lock being held
+func (idx *searchIndex) remove(id string) {
+	doc, ok := idx.docs[id]
+	if !ok {
+		return
+	}
+	for _, word := range doc.words {
+		delete(idx.postings[word], id)
+		if len(idx.postings[word]) == 0 {
+			delete(idx.postings, word)
+		}
+	}
+	delete(idx.docs, id)
+}
+
+// search returns the IDs of the memories having a word starting with each
+// word of the query
+func (idx *searchIndex) search(query string) map[string]bool {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	var found map[string]bool
+	for _, prefix := range indexWords(query) {
+		matches := map[string]bool{}
+		for i := sort.SearchStrings(idx.words, prefix); i < len(idx.words) && strings.HasPrefix(idx.words[i], prefix); i++ {
+			for id := range idx.postings[idx.words[i]] {
+				if found == nil || found[id] {
+					matches[id] = true
+				}
+			}
+		}
+		found = matches
+		if len(found) == 0 {
+			break
+		}
+	}
+	return found
+}
+
+func (idx *searchIndex) size() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.docs)
+}
+
+// refresh updates the index with the loaded memories in the background
+func (idx *searchIndex) refresh(memories []Memory) tea.Cmd {
+	return func() tea.Msg {
+		indexed, removed := idx.update(memories)
+		if indexed > 0 || removed > 0 {
+			logf("INFO", "search index: %d memories indexed, %d removed", indexed, removed)
+		}
+		return indexUpdatedMsg{}
+	}
+}
+
+// matchMemories returns the loaded memories matching the query, in the list
+// order
+func (m model) matchMemories(query string, limit int) []Memory {
+	ids := m.index.search(query)
+	var matches []Memory
+	for _, mem := range m.memories {
+		if ids[mem.ID] {
+			matches = append(matches, mem)
+			if len(matches) == limit {
+				break
+			}
+		}
+	}
+	return matches
+}
+
+func runFilterCommand(m model, args string) (tea.Model, tea.Cmd) {
+	m.linting = false
+	m.filterQuery = args
+	m.showView(listView)
+	m.applyFilter()
+	return m, nil
+}
+
+// applyFilter narrows the list to the memories matching /filter, again after
+// each reload while the filter is shown
+func (m *model) applyFilter() {
+	matches := m.matchMemories(m.filterQuery, 0)
+	m.list.SetItems(m.memoryItems(matches))
+	m.list.ResetSelected()
+	m.message = tr("%d of %d memories match %q, /refresh to list them all", len(matches), len(m.memories), m.filterQuery)
+}
+
+// searchMemories searches on the server, or in the local index when the
+// server cannot be reached
+func (m model) searchMemories(query string) tea.Cmd {
+	return func() tea.Msg {
+		results, err := m.api.SearchMemories(query, 20)
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) && m.index.size() > 0 {
+			logf("WARN", "search failed, using the local index: %v", err)
+			return searchResultsMsg{memories: m.matchMemories(query, 20), local: true}
+		}
+		if err != nil {
+			return errMsg{err}
+		}
+		return searchResultsMsg{memories: results}
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Secret detection: memories are kept for good, so the texts about to be
+// added or imported are scanned for private keys, passwords and tokens, each
+// flagged text asking Y/N before it is stored. The scan runs after the
+// redaction, a masked secret being no longer flagged.
+var secretDetectors = []struct {
+	kind string
+	re   *regexp.Regexp
+}{
+	{"private key", regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY( BLOCK)?-----`)},
+	{"password", regexp.MustCompile(`(?i)\b(?:password|passwd|pwd|mot de passe|mdp)\s*[:=]\s*\S{4,}`)},
+	{"API key", builtinRedactions["api_key"].re},
+	{"token", regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}|(?i)\b(?:token|secret|api[_-]?key)\s*[:=]\s*["']?[A-Za-z0-9._~+/-]{16,}`)},
+}
+
+// findSecrets returns the kinds of secrets found in text
+func findSecrets(text string) []string {
+	var kinds []string
+	for _, d := range secretDetectors {
+		if d.re.MatchString(text) {
+			kinds = append(kinds, d.kind)
+		}
+	}
+	return kinds
+}
+
+// A flagged text waiting for its Y/N
+type secretCheck struct {
+	pos   int // Index of the text
+	title string
+	text  string
+	kinds []string
+}
+
+// Texts being confirmed, then what to do with the answers
+type secretReview struct {
+	checks []secretCheck
+	keep   []bool
+	index  int
+	from   viewState
+	done   func(m model, keep []bool) (tea.Model, tea.Cmd)
+}
+
+// reviewSecrets asks about each flagged text of texts, then calls done with
+// the texts to keep, all of them when none is flagged
+func (m model) reviewSecrets(titles, texts []string, done func(m model, keep []bool) (tea.Model, tea.Cmd)) (tea.Model, tea.Cmd) {
+	review := secretReview{keep: make([]bool, len(texts)), from: m.state, done: done}
+	for i, text := range texts {
+		review.keep[i] = true
+		if kinds := findSecrets(text); len(kinds) > 0 {
+			review.checks = append(review.checks, secretCheck{i, titles[i], text, kinds})
+		}
+	}
+	if len(review.checks) == 0 {
+		return done(m, review.keep)
+	}
+	m.secrets = review
+	cmd := m.showView(secretView)
+	return m, cmd
+}
+
+func (m model) updateSecretView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	review := &m.secrets
+	switch msg.String() {
+	case "y", "Y", "n", "N":
+		if strings.EqualFold(msg.String(), "n") {
+			review.keep[review.checks[review.index].pos] = false
+		}
+		review.index++
+		if review.index < len(review.checks) {
+			return m, nil
+		}
+		done, keep := review.done, review.keep
+		m.showView(review.from)
+		m.secrets = secretReview{}
+		return done(m, keep)
+	case "esc":
+		from := review.from
+		m.secrets = secretReview{}
+		cmd := m.showView(from)
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m model) renderSecretView() string {
+	modalWidth := min(70, m.width-10)
+	review := m.secrets
+	check := review.checks[review.index]
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(tr("🔑 Possible secret")))
+	if len(review.checks) > 1 {
+		b.WriteString(" " + helpStyle.Render(fmt.Sprintf("%d/%d", review.index+1, len(review.checks))))
+	}
+	b.WriteString("\n\n")
+	if check.title != "" {
+		b.WriteString(selectedItemStyle.Render(check.title) + "\n")
+	}
+	kinds := make([]string, len(check.kinds))
+	for i, kind := range check.kinds {
+		kinds[i] = tr(kind)
+	}
+	b.WriteString(tr("Looks like it contains: %s", strings.Join(kinds, ", ")) + "\n\n")
+	b.WriteString(wrapText(truncateString(singleLine(check.text), 300), modalWidth-6) + "\n\n")
+	b.WriteString(tr("Memories are kept for good, store it anyway?") + "\n\n")
+	b.WriteString(m.keyHint(""))
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, modalStyle.Width(modalWidth).Render(b.String()))
+}
@@ -0,0 +1,334 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/Mickael-Roger/tom/tools/memory-tui/pkg/chatclient"
+	"github.com/Mickael-Roger/tom/tools/memory-tui/pkg/memoryclient"
+)
+
+// `memory-tui serve-local`: a small REST API on localhost in front of the
+// Tom session, so that Shortcuts, Tasker, curl or a home automation ask Tom
+// and manage the memories without dealing with the login and its cookie.
+// The session is the daemon's when it runs, else the saved credentials, and
+// is opened again when it expires. Every request but the OpenAPI document
+// carries the token of ~/.tom/serve-local.token, created at the first start,
+// as a bearer token: any local process, or a web page through the browser,
+// can reach a port on localhost. The prompts of /v1/ask go to the "local-api"
+// conversation, a history Tom keeps apart from his apps.
+const (
+	defaultServeLocalAddr = "127.0.0.1:8765"
+	serveLocalClientType  = "local-api"
+	serveLocalMaxBody     = 1 << 20
+	serveLocalAskTimeout  = 3 * time.Minute
+)
+
+func serveLocalTokenPath() (string, error) {
+	return tomPath("serve-local.token")
+}
+
+// serveLocalToken reads the token, creating it the first time
+func serveLocalToken(path string) (string, error) {
+	if data, err := os.ReadFile(path); err == nil {
+		if token := strings.TrimSpace(string(data)); token != "" {
+			return token, nil
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+	random := make([]byte, 24)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(random)
+	if err := os.WriteFile(path, []byte(token+"\n"), 0600); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+type localServer struct {
+	sharedSession
+	token string
+}
+
+func runServeLocal(addr string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		logf("WARN", "failed to load config, using defaults: %v", err)
+	}
+	path, err := serveLocalTokenPath()
+	if err != nil {
+		return err
+	}
+	token, err := serveLocalToken(path)
+	if err != nil {
+		return fmt.Errorf("token: %w", err)
+	}
+	s := &localServer{sharedSession: sharedSession{cfg: cfg}, token: token}
+	if _, err := s.session(); err != nil {
+		return err
+	}
+
+	srv := &http.Server{Addr: addr, Handler: s.routes(), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		<-signals
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		srv.Shutdown(ctx)
+	}()
+
+	fmt.Printf("Listening on http://%s, OpenAPI document at /openapi.json\n", addr)
+	fmt.Printf("Send the token of %s as \"Authorization: Bearer TOKEN\"\n", path)
+	logf("INFO", "serve-local listening on %s", addr)
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func (s *localServer) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, serveLocalOpenAPI)
+	})
+	mux.Handle("/v1/ask", s.authorized(s.handleAsk))
+	mux.Handle("/v1/memories", s.authorized(s.handleMemories))
+	mux.Handle("/v1/memories/", s.authorized(s.handleMemory))
+	mux.Handle("/v1/notifications", s.authorized(s.handleNotifications))
+	return mux
+}
+
+// authorized refuses the requests without the token
+func (s *localServer) authorized(handler http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			writeLocalError(w, http.StatusUnauthorized, errors.New("missing or wrong bearer token"))
+			return
+		}
+		handler(w, r)
+	})
+}
+
+func writeLocalJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeLocalError(w http.ResponseWriter, status int, err error) {
+	writeLocalJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// writeTomError answers the error of a request to Tom with the status
+// matching it
+func writeTomError(w http.ResponseWriter, err error) {
+	status := http.StatusBadGateway
+	switch {
+	case errors.Is(err, errReadOnly):
+		status = http.StatusForbidden
+	case errors.Is(err, memoryclient.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, errSessionExpired):
+		status = http.StatusServiceUnavailable
+	}
+	logf("WARN", "serve-local: %v", err)
+	writeLocalError(w, status, err)
+}
+
+func decodeLocalBody(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, serveLocalMaxBody)).Decode(v); err != nil {
+		writeLocalError(w, http.StatusBadRequest, fmt.Errorf("invalid JSON body: %w", err))
+		return false
+	}
+	return true
+}
+
+func allowMethods(w http.ResponseWriter, r *http.Request, methods ...string) bool {
+	for _, m := range methods {
+		if r.Method == m {
+			return true
+		}
+	}
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	writeLocalError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+	return false
+}
+
+// handleAsk sends a prompt to Tom, in the conversation of the local API
+func (s *localServer) handleAsk(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodPost) {
+		return
+	}
+	var req struct {
+		Prompt string `json:"prompt"`
+		Reset  bool   `json:"reset"`
+	}
+	if !decodeLocalBody(w, r, &req) {
+		return
+	}
+	if strings.TrimSpace(req.Prompt) == "" && !req.Reset {
+		writeLocalError(w, http.StatusBadRequest, errors.New("prompt is empty"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), serveLocalAskTimeout)
+	defer cancel()
+	var answer chatclient.Answer
+	err := s.do(func(api *MemoryAPI) error {
+		chat := chatclient.NewWithHTTPClient(api.ServerURL, serveLocalClientType, api.Client)
+		if req.Reset {
+			if err := chat.Reset(ctx); err != nil {
+				return chatError(err)
+			}
+		}
+		if strings.TrimSpace(req.Prompt) == "" {
+			return nil
+		}
+		var err error
+		answer, err = chat.Process(ctx, req.Prompt)
+		return chatError(err)
+	})
+	if err != nil {
+		writeTomError(w, err)
+		return
+	}
+	writeLocalJSON(w, http.StatusOK, map[string]interface{}{"answer": answer.Text, "conversation_reset": req.Reset || answer.ConversationReset})
+}
+
+// handleMemories lists, searches (q) and adds memories
+func (s *localServer) handleMemories(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet, http.MethodPost) {
+		return
+	}
+	if r.Method == http.MethodPost {
+		var req struct {
+			Text     string                 `json:"text"`
+			Metadata map[string]interface{} `json:"metadata"`
+		}
+		if !decodeLocalBody(w, r, &req) {
+			return
+		}
+		if strings.TrimSpace(req.Text) == "" {
+			writeLocalError(w, http.StatusBadRequest, errors.New("text is empty"))
+			return
+		}
+		// Masked as in the interface; no one sees a preview here
+		text, found := redactText(req.Text)
+		if len(found) > 0 {
+			logf("INFO", "serve-local: %d values masked", len(found))
+		}
+		if err := s.do(func(api *MemoryAPI) error { return api.AddMemory(text, req.Metadata) }); err != nil {
+			writeTomError(w, err)
+			return
+		}
+		writeLocalJSON(w, http.StatusCreated, map[string]interface{}{"text": text, "masked": len(found)})
+		return
+	}
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	limit := 0
+	if l := r.URL.Query().Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 1 {
+			writeLocalError(w, http.StatusBadRequest, fmt.Errorf("invalid limit %q", l))
+			return
+		}
+		limit = n
+	}
+	var memories []Memory
+	err := s.do(func(api *MemoryAPI) error {
+		var err error
+		if query != "" {
+			memories, err = api.SearchMemories(query, limit)
+		} else {
+			memories, err = api.GetAllMemories()
+		}
+		return err
+	})
+	if err != nil {
+		writeTomError(w, err)
+		return
+	}
+	if limit > 0 && len(memories) > limit {
+		memories = memories[:limit]
+	}
+	if memories == nil {
+		memories = []Memory{}
+	}
+	writeLocalJSON(w, http.StatusOK, map[string]interface{}{"memories": memories})
+}
+
+// handleMemory reads and deletes a memory
+func (s *localServer) handleMemory(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet, http.MethodDelete) {
+		return
+	}
+	id := strings.TrimPrefix(r.URL.Path, "/v1/memories/")
+	if id == "" || strings.Contains(id, "/") {
+		writeLocalError(w, http.StatusNotFound, fmt.Errorf("no memory at %s", r.URL.Path))
+		return
+	}
+
+	var memory Memory
+	err := s.do(func(api *MemoryAPI) error {
+		var err error
+		memory, err = api.memories().Get(r.Context(), id)
+		if errors.Is(err, memoryclient.ErrNotFound) {
+			return fmt.Errorf("memory %s %w", id, err)
+		}
+		if err != nil || r.Method == http.MethodGet {
+			return clientError(err)
+		}
+		return api.DeleteMemory(memory)
+	})
+	switch {
+	case err != nil:
+		writeTomError(w, err)
+	case r.Method == http.MethodDelete:
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeLocalJSON(w, http.StatusOK, memory)
+	}
+}
+
+// handleNotifications returns the notification status of Tom's modules,
+// the background tasks of /tasks
+func (s *localServer) handleNotifications(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+	var tasks chatclient.Tasks
+	err := s.do(func(api *MemoryAPI) error {
+		var err error
+		tasks, err = chatclient.NewWithHTTPClient(api.ServerURL, serveLocalClientType, api.Client).Tasks(r.Context())
+		return chatError(err)
+	})
+	if err != nil {
+		writeTomError(w, err)
+		return
+	}
+	notifications := make([]map[string]string, 0, len(tasks.Background))
+	for _, t := range tasks.Background {
+		notifications = append(notifications, map[string]string{"module": t.Module, "status": t.Status})
+	}
+	writeLocalJSON(w, http.StatusOK, map[string]interface{}{"notifications": notifications})
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Sessions for the commands running without the interface: they reuse the
+// credentials saved by the TUI, logging in again when the cookie expired.
+// Encrypted credentials are unlocked with $TOM_PASSPHRASE.
+type session struct {
+	API           *MemoryAPI
+	Username      string
+	SessionCookie string
+}
+
+// parseSessionCookie reads the "name=value; Path=/..." form saved in ~/.tom/auth
+func parseSessionCookie(raw string) *http.Cookie {
+	pair, _, _ := strings.Cut(raw, ";")
+	name, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+	if !ok {
+		return nil
+	}
+	return &http.Cookie{Name: name, Value: value}
+}
+
+// newSessionAPI builds an API client sending the given session cookie
+func newSessionAPI(serverURL, sessionCookie string) (*MemoryAPI, error) {
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{
+		Jar:       jar,
+		Transport: userAgentTransport{},
+	}
+	if cookie := parseSessionCookie(sessionCookie); cookie != nil {
+		u, err := url.Parse(serverURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid server URL: %w", err)
+		}
+		jar.SetCookies(u, []*http.Cookie{cookie})
+	}
+	return NewMemoryAPIWithClient(serverURL, client), nil
+}
+
+func openSession() (*session, error) {
+	passphrase := os.Getenv("TOM_PASSPHRASE")
+	username, password, serverURL, sessionCookie, err := loadCredentials(passphrase)
+	if err == errPassphraseRequired {
+		return nil, fmt.Errorf("%w: set TOM_PASSPHRASE", err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("no usable credentials in ~/.tom/auth, run memory-tui to log in first: %w", err)
+	}
+
+	if cfg, err := loadConfig(); err == nil {
+		applyTimeoutConfig(cfg)
+	}
+
+	api, err := newSessionAPI(serverURL, "")
+	if err != nil {
+		return nil, err
+	}
+	if validateSessionCookie(serverURL, sessionCookie, api.Client) {
+		api, err = newSessionAPI(serverURL, sessionCookie)
+		if err != nil {
+			return nil, err
+		}
+		return &session{api, username, sessionCookie}, nil
+	}
+
+	if password == "" {
+		return nil, fmt.Errorf("%w: run memory-tui to log in", errSessionExpired)
+	}
+	sessionCookie, err = postLogin(api.Client, serverURL, username, password)
+	if err != nil {
+		return nil, err
+	}
+	if err := saveCredentials(username, password, serverURL, sessionCookie, passphrase); err != nil {
+		return nil, fmt.Errorf("failed to save credentials: %w", err)
+	}
+	return &session{api, username, sessionCookie}, nil
+}
+
+// sharedSession is the session of the long-running commands serving several
+// requests at once, serve-local, --nvim and --kiosk: the daemon's or the
+// saved one, opened at the first request and again when it expires
+type sharedSession struct {
+	cfg  config
+	open func() (*MemoryAPI, error) // quickSession when nil
+
+	mu  sync.Mutex
+	api *MemoryAPI
+}
+
+// session returns the API of the session, opening it when needed
+func (s *sharedSession) session() (*MemoryAPI, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.api == nil {
+		open := s.open
+		if open == nil {
+			open = func() (*MemoryAPI, error) { return quickSession(s.cfg) }
+		}
+		api, err := open()
+		if err != nil {
+			return nil, err
+		}
+		s.api = api
+	}
+	return s.api, nil
+}
+
+// do runs a request with the session, once more with a new one when it
+// expired
+func (s *sharedSession) do(request func(api *MemoryAPI) error) error {
+	for attempt := 0; ; attempt++ {
+		api, err := s.session()
+		if err != nil {
+			return err
+		}
+		err = request(api)
+		if !errors.Is(err, errSessionExpired) || attempt > 0 {
+			return err
+		}
+		logf("INFO", "session expired, opening a new one")
+		s.mu.Lock()
+		if s.api == api {
+			s.api = nil
+		}
+		s.mu.Unlock()
+	}
+}
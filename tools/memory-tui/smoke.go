@@ -0,0 +1,245 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Mickael-Roger/tom/tools/memory-tui/pkg/chatclient"
+	"github.com/Mickael-Roger/tom/tools/memory-tui/pkg/memoryclient"
+)
+
+// `memory-tui smoke`: checks a server after a deployment, from CI or
+// Ansible, with a login, a stateless prompt to Tom, a memory added, searched
+// and deleted, and the notifications. It uses neither the saved credentials
+// nor the configuration, and leaves no trace in the local store: the
+// password comes from $TOM_PASSWORD or --password-file, the report goes to
+// stdout and the exit status is 1 when a step failed.
+const (
+	smokeClientType   = "smoke"
+	smokePrompt       = "Reply with the single word OK."
+	smokeMemoryText   = "The memory-tui smoke test code word is %s."
+	smokeSearchQuery  = "smoke test code word"
+	smokeSearchTries  = 3
+	smokeSearchWait   = 2 * time.Second
+	smokeStepTimeout  = time.Minute
+	smokePasswordEnv  = "TOM_PASSWORD"
+	smokeFormatJSON   = "json"
+	smokeFormatText   = "text"
+	smokeSkippedError = "skipped, an earlier step failed"
+)
+
+var errSmokeFailed = errors.New("smoke test failed")
+
+type smokeReport struct {
+	Server     string      `json:"server"`
+	User       string      `json:"user"`
+	Client     string      `json:"client"`
+	OK         bool        `json:"ok"`
+	StartedAt  time.Time   `json:"started_at"`
+	DurationMS int64       `json:"duration_ms"`
+	Steps      []smokeStep `json:"steps"`
+}
+
+type smokeStep struct {
+	Name       string `json:"name"` // login, process, memory_add, memory_search, memory_delete or tasks
+	OK         bool   `json:"ok"`
+	Skipped    bool   `json:"skipped,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+	Detail     string `json:"detail,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+type smokeTest struct {
+	report  *smokeReport
+	timeout time.Duration
+}
+
+// step runs a check unless skip, recording its outcome
+func (s *smokeTest) step(name string, skip bool, check func(ctx context.Context) (string, error)) bool {
+	if skip {
+		s.report.Steps = append(s.report.Steps, smokeStep{Name: name, Skipped: true, Error: smokeSkippedError})
+		return false
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	start := time.Now()
+	detail, err := check(ctx)
+	step := smokeStep{Name: name, OK: err == nil, DurationMS: time.Since(start).Milliseconds(), Detail: detail}
+	if err != nil {
+		step.Error = err.Error()
+	}
+	s.report.Steps = append(s.report.Steps, step)
+	return err == nil
+}
+
+func runSmoke(server, user, passwordFile, format string, timeout time.Duration) error {
+	switch {
+	case server == "" || user == "":
+		return errors.New("--server and --user are required")
+	case format != smokeFormatJSON && format != smokeFormatText:
+		return fmt.Errorf("unknown --format %q, expected json or text", format)
+	}
+	password, err := readPassword(passwordFile)
+	if err != nil {
+		return err
+	}
+	server = strings.TrimRight(server, "/")
+
+	report := &smokeReport{Server: server, User: user, Client: versionString(), StartedAt: time.Now()}
+	s := &smokeTest{report: report, timeout: timeout}
+	client := NewMemoryAPI(server).Client
+	chat := chatclient.NewWithHTTPClient(server, smokeClientType, client)
+	memories := memoryclient.NewWithHTTPClient(server, client)
+
+	loggedIn := s.step("login", false, func(context.Context) (string, error) {
+		cookie, err := postLogin(client, server, user, password)
+		if err == nil && cookie == "" {
+			err = errors.New("no session cookie in the answer")
+		}
+		return "", err
+	})
+
+	s.step("process", !loggedIn, func(ctx context.Context) (string, error) {
+		// Leave no conversation behind
+		answer, err := chat.Process(ctx, smokePrompt, chatclient.WithoutHistory())
+		if err != nil {
+			return "", err
+		}
+		if strings.TrimSpace(answer.Text) == "" {
+			return "", errors.New("empty answer")
+		}
+		return truncateString(singleLine(answer.Text), 80), nil
+	})
+
+	word := smokeWord()
+	var added memoryclient.Memory
+	stored := s.step("memory_add", !loggedIn, func(ctx context.Context) (string, error) {
+		var err error
+		added, err = memories.Add(ctx, fmt.Sprintf(smokeMemoryText, word), map[string]interface{}{"source": smokeClientType})
+		return added.ID, err
+	})
+
+	// mem0 may rewrite the text: the memories to delete are those holding
+	// the code word, the one added included
+	var found []memoryclient.Memory
+	s.step("memory_search", !stored, func(ctx context.Context) (string, error) {
+		for try := 1; ; try++ {
+			results, err := memories.Search(ctx, smokeSearchQuery+" "+word, 10)
+			if err != nil {
+				return "", err
+			}
+			for _, m := range results {
+				if strings.Contains(m.Memory, word) {
+					found = append(found, m)
+				}
+			}
+			if len(found) > 0 {
+				return fmt.Sprintf("%d found", len(found)), nil
+			}
+			if try == smokeSearchTries {
+				return "", fmt.Errorf("the memory added is not in the %d results", len(results))
+			}
+			select {
+			case <-ctx.Done():
+				return "", ctx.Err()
+			case <-time.After(smokeSearchWait):
+			}
+		}
+	})
+
+	if added.ID != "" && !smokeHas(found, added.ID) {
+		found = append(found, added)
+	}
+	s.step("memory_delete", !stored || len(found) == 0, func(ctx context.Context) (string, error) {
+		for _, m := range found {
+			if err := memories.Delete(ctx, m.ID); err != nil && !errors.Is(err, memoryclient.ErrNotFound) {
+				return "", fmt.Errorf("memory %s: %w", m.ID, err)
+			}
+		}
+		return fmt.Sprintf("%d deleted", len(found)), nil
+	})
+
+	s.step("tasks", !loggedIn, func(ctx context.Context) (string, error) {
+		tasks, err := chat.Tasks(ctx)
+		return fmt.Sprintf("%d modules with a status", len(tasks.Background)), err
+	})
+
+	report.DurationMS = time.Since(report.StartedAt).Milliseconds()
+	report.OK = true
+	for _, step := range report.Steps {
+		report.OK = report.OK && step.OK
+	}
+	if err := printSmokeReport(report, format); err != nil {
+		return err
+	}
+	if !report.OK {
+		return errSmokeFailed
+	}
+	return nil
+}
+
+// readPassword returns the password of the commands checking a server, from
+// a file or $TOM_PASSWORD, never from a flag others could read in ps
+func readPassword(passwordFile string) (string, error) {
+	password := os.Getenv(smokePasswordEnv)
+	if passwordFile != "" {
+		data, err := os.ReadFile(passwordFile)
+		if err != nil {
+			return "", err
+		}
+		password = strings.TrimRight(string(data), "\r\n")
+	}
+	if password == "" {
+		return "", fmt.Errorf("no password, set $%s or give --password-file", smokePasswordEnv)
+	}
+	return password, nil
+}
+
+// smokeWord is the code word marking the memory of a run
+func smokeWord() string {
+	b := make([]byte, 6)
+	rand.Read(b)
+	return "smoke-" + hex.EncodeToString(b)
+}
+
+func smokeHas(memories []memoryclient.Memory, id string) bool {
+	for _, m := range memories {
+		if m.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func printSmokeReport(report *smokeReport, format string) error {
+	if format == smokeFormatJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	for _, step := range report.Steps {
+		status := "ok"
+		switch {
+		case step.Skipped:
+			status = "skip"
+		case !step.OK:
+			status = "FAIL"
+		}
+		line := fmt.Sprintf("%-4s  %-13s  %6dms", status, step.Name, step.DurationMS)
+		if step.Error != "" {
+			line += "  " + step.Error
+		} else if step.Detail != "" {
+			line += "  " + step.Detail
+		}
+		fmt.Println(line)
+	}
+	fmt.Printf("%s as %s: ok=%t in %dms\n", report.Server, report.User, report.OK, report.DurationMS)
+	return nil
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Local spellcheck through aspell or hunspell, both speaking the ispell pipe protocol
+type spellIssue struct {
+	Word        string
+	Offset      int // Rune offset of the word in the checked text
+	Suggestions []string
+}
+
+type spellcheckMsg struct {
+	text   string
+	issues []spellIssue
+}
+
+var misspelledStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("#FF5F87")).
+	Underline(true)
+
+// spellchecker returns the command checking text in language, or an error
+// when no supported checker is installed
+func spellchecker(language string) (*exec.Cmd, error) {
+	if path, err := exec.LookPath("aspell"); err == nil {
+		return exec.Command(path, "-a", "--lang="+language), nil
+	}
+	if path, err := exec.LookPath("hunspell"); err == nil {
+		return exec.Command(path, "-a", "-d", hunspellDictionary(language)), nil
+	}
+	return nil, fmt.Errorf("spellcheck requires aspell or hunspell to be installed")
+}
+
+// hunspellDictionary maps a short language code to a hunspell dictionary name
+func hunspellDictionary(language string) string {
+	switch language {
+	case "fr":
+		return "fr_FR"
+	case "en":
+		return "en_US"
+	case "de":
+		return "de_DE"
+	case "es":
+		return "es_ES"
+	}
+	return language
+}
+
+func checkSpelling(text, language string) ([]spellIssue, error) {
+	cmd, err := spellchecker(language)
+	if err != nil {
+		return nil, err
+	}
+
+	// Prefix every line with ^ so the checker never interprets it as a command
+	var input strings.Builder
+	lines := strings.Split(text, "\n")
+	for _, line := range lines {
+		input.WriteString("^" + line + "\n")
+	}
+	cmd.Stdin = strings.NewReader(input.String())
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("spellcheck failed: %v %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return parseSpellOutput(string(out), lines), nil
+}
+
+// parseSpellOutput reads the ispell pipe output, one result block per input
+// line. Words are located in the line itself since checkers disagree on
+// whether offsets count bytes or characters.
+func parseSpellOutput(output string, lines []string) []spellIssue {
+	var issues []spellIssue
+	lineIdx := 0
+	lineStart := 0 // Rune offset of the current line in the whole text
+	cursor := 0    // Byte position in the current line after the last issue
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		row := scanner.Text()
+		switch {
+		case strings.HasPrefix(row, "@(#)"):
+			// Version banner
+		case row == "":
+			// End of the results for the current line
+			if lineIdx < len(lines) {
+				lineStart += len([]rune(lines[lineIdx])) + 1
+				lineIdx++
+				cursor = 0
+			}
+		case row[0] == '&' || row[0] == '#':
+			issue, ok := parseSpellRow(row)
+			if !ok || lineIdx >= len(lines) {
+				continue
+			}
+			line := lines[lineIdx]
+			idx := strings.Index(line[cursor:], issue.Word)
+			if idx < 0 {
+				continue
+			}
+			issue.Offset = lineStart + len([]rune(line[:cursor+idx]))
+			cursor += idx + len(issue.Word)
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+// parseSpellRow parses "& word count offset: s1, s2" and "# word offset"
+func parseSpellRow(row string) (spellIssue, bool) {
+	head, suggestions, _ := strings.Cut(row, ":")
+	fields := strings.Fields(head)
+
+	var issue spellIssue
+	switch {
+	case fields[0] == "&" && len(fields) == 4:
+		issue.Word = fields[1]
+	case fields[0] == "#" && len(fields) == 3:
+		issue.Word = fields[1]
+	default:
+		return issue, false
+	}
+
+	for _, s := range strings.Split(suggestions, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			issue.Suggestions = append(issue.Suggestions, s)
+		}
+	}
+	return issue, true
+}
+
+func (m model) spellcheck() tea.Cmd {
+	text := m.textArea.Value()
+	language := m.config.Language
+	return func() tea.Msg {
+		issues, err := checkSpelling(text, language)
+		if err != nil {
+			return errMsg{err}
+		}
+		return spellcheckMsg{text, issues}
+	}
+}
+
+// applySuggestion replaces the current misspelling with its first suggestion
+func (m *model) applySuggestion() {
+	if m.spellIndex >= len(m.spellIssues) {
+		return
+	}
+	issue := m.spellIssues[m.spellIndex]
+	if len(issue.Suggestions) == 0 {
+		m.message = tr("No suggestion for %q", issue.Word)
+		return
+	}
+
+	text := []rune(m.textArea.Value())
+	end := issue.Offset + len([]rune(issue.Word))
+	if end > len(text) || string(text[issue.Offset:end]) != issue.Word {
+		m.message = tr("Text changed since the spellcheck, press Ctrl+K again")
+		return
+	}
+
+	replacement := []rune(issue.Suggestions[0])
+	updated := append(append(append([]rune{}, text[:issue.Offset]...), replacement...), text[end:]...)
+	m.editHistory.snapshot(string(text))
+	m.textArea.SetValue(string(updated))
+
+	// Shift the following issues by the length difference
+	delta := len(replacement) - len([]rune(issue.Word))
+	m.spellIssues = append(m.spellIssues[:m.spellIndex], m.spellIssues[m.spellIndex+1:]...)
+	for i := m.spellIndex; i < len(m.spellIssues); i++ {
+		m.spellIssues[i].Offset += delta
+	}
+	if m.spellIndex >= len(m.spellIssues) {
+		m.spellIndex = 0
+	}
+	m.spellText = m.textArea.Value()
+}
+
+// renderSpellcheck shows the checked text with misspellings underlined
+func (m model) renderSpellcheck() string {
+	if m.spellText == "" {
+		return ""
+	}
+	if len(m.spellIssues) == 0 {
+		return helpStyle.Render(tr("✓ No spelling mistakes found"))
+	}
+
+	text := []rune(m.spellText)
+	var b strings.Builder
+	pos := 0
+	for _, issue := range m.spellIssues {
+		end := issue.Offset + len([]rune(issue.Word))
+		if issue.Offset < pos || end > len(text) {
+			continue
+		}
+		b.WriteString(string(text[pos:issue.Offset]))
+		b.WriteString(misspelledStyle.Render(issue.Word))
+		pos = end
+	}
+	b.WriteString(string(text[pos:]))
+	b.WriteString("\n\n")
+
+	issue := m.spellIssues[m.spellIndex]
+	suggestions := "no suggestion"
+	if len(issue.Suggestions) > 0 {
+		suggestions = strings.Join(issue.Suggestions[:min(5, len(issue.Suggestions))], ", ")
+	}
+	b.WriteString(fmt.Sprintf("%d/%d %s → %s\n", m.spellIndex+1, len(m.spellIssues), selectedItemStyle.Render(issue.Word), suggestions))
+	b.WriteString(helpStyle.Render(tr("Ctrl+R: use first suggestion | Ctrl+N: next")))
+	return b.String()
+}
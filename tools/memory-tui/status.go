@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/Mickael-Roger/tom/tools/memory-tui/pkg/chatclient"
+)
+
+// `memory-tui status`: the state of the assistant at a glance, for the
+// desktop bars. The daemon refreshes it every minute: the connection, the
+// notification status of Tom's modules (/tasks) and the next reminder, the
+// soonest of the /timer timers and, with "status_reminders" set, of Tom's
+// own reminders. Those are read every 30 minutes by asking Tom, the server
+// pushing them to its apps only, which costs a request to the model each
+// time; the request is stateless, kept out of every conversation. The
+// command asks the daemon and prints one line, for Polybar, or with --waybar
+// the JSON of a Waybar custom module.
+const (
+	statusRefreshInterval   = time.Minute
+	statusRemindersInterval = 30 * time.Minute
+	statusClientType        = "status"
+	statusRemindersPrompt   = "List my pending reminders with list_reminders. Reply with a JSON array of " +
+		`{"text", "datetime"} objects, datetime as YYYY-MM-DD HH:MM in my time zone, and nothing ` +
+		"else; [] when there is none."
+	statusReminderLayout = "2006-01-02 15:04"
+)
+
+type daemonStatus struct {
+	Connected     bool              `json:"connected"`
+	Error         string            `json:"error,omitempty"`
+	Notifications []chatclient.Task `json:"notifications"`
+	NextReminder  *statusReminder   `json:"next_reminder,omitempty"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+}
+
+type statusReminder struct {
+	Text   string    `json:"text"`
+	At     time.Time `json:"at"`
+	Source string    `json:"source"` // timer or tom
+}
+
+// refreshStatus updates the status the daemon serves
+func (d *daemon) refreshStatus() {
+	status := daemonStatus{UpdatedAt: time.Now()}
+	var tasks chatclient.Tasks
+	err := d.withSession(timeoutPolling, func(api *MemoryAPI) error {
+		var err error
+		tasks, err = chatclient.NewWithHTTPClient(api.ServerURL, statusClientType, api.Client).Tasks(context.Background())
+		return chatError(err)
+	})
+	if err != nil {
+		status.Error = err.Error()
+	} else {
+		status.Connected = true
+		status.Notifications = tasks.Background
+	}
+
+	d.mu.Lock()
+	reminders, readAt := d.tomReminders, d.remindersReadAt
+	d.mu.Unlock()
+	if d.statusReminders && status.Connected && time.Since(readAt) >= statusRemindersInterval {
+		if read, err := d.readTomReminders(); err != nil {
+			logf("WARN", "status: reminders: %v", err)
+		} else {
+			reminders = read
+		}
+		readAt = time.Now()
+	}
+
+	candidates := append([]statusReminder(nil), reminders...)
+	if timers, err := loadTimers(); err == nil {
+		for _, t := range timers {
+			candidates = append(candidates, statusReminder{Text: t.Label, At: t.FiresAt, Source: "timer"})
+		}
+	}
+	for i := range candidates {
+		c := candidates[i]
+		if c.At.After(status.UpdatedAt) && (status.NextReminder == nil || c.At.Before(status.NextReminder.At)) {
+			status.NextReminder = &c
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	// The notification hook runs on each change, not when they go away
+	if status.Connected && len(status.Notifications) > 0 && !reflect.DeepEqual(status.Notifications, d.status.Notifications) {
+		runHook(d.hooks, hookNotification, map[string]interface{}{"notifications": status.Notifications})
+	}
+	d.status = status
+	d.tomReminders, d.remindersReadAt = reminders, readAt
+}
+
+// readTomReminders asks Tom for the pending reminders
+func (d *daemon) readTomReminders() ([]statusReminder, error) {
+	var answer string
+	err := d.withSession(timeoutBulk, func(api *MemoryAPI) error {
+		a, err := chatclient.NewWithHTTPClient(api.ServerURL, statusClientType, api.Client).Process(context.Background(), statusRemindersPrompt, chatclient.WithoutHistory())
+		answer = a.Text
+		return chatError(err)
+	})
+	if err != nil {
+		return nil, err
+	}
+	var items []struct {
+		Text     string `json:"text"`
+		Datetime string `json:"datetime"`
+	}
+	if err := decodeAnswerList(answer, &items); err != nil {
+		return nil, err
+	}
+	var reminders []statusReminder
+	for _, item := range items {
+		at, err := time.ParseInLocation(statusReminderLayout, item.Datetime, time.Local)
+		if err != nil {
+			continue
+		}
+		reminders = append(reminders, statusReminder{Text: item.Text, At: at, Source: "tom"})
+	}
+	return reminders, nil
+}
+
+// waybarOutput is the JSON a Waybar custom module with "return-type": "json"
+// reads
+type waybarOutput struct {
+	Text    string `json:"text"`
+	Tooltip string `json:"tooltip"`
+	Class   string `json:"class"` // offline, disconnected, notifications or idle
+	Alt     string `json:"alt"`   // The class too, for format-icons
+}
+
+func runStatus(waybar bool) error {
+	var out waybarOutput
+	resp, err := callDaemon("status")
+	switch {
+	case err != nil:
+		out = waybarOutput{Text: "Tom ⊘", Tooltip: "The memory-tui daemon is not running: " + err.Error(), Class: "offline"}
+	case resp.Status == nil:
+		out = waybarOutput{Text: "Tom …", Tooltip: "Waiting for the first status of the daemon", Class: "offline"}
+	default:
+		out = statusOutput(*resp.Status, time.Now())
+	}
+	out.Alt = out.Class
+
+	if waybar {
+		return json.NewEncoder(os.Stdout).Encode(out)
+	}
+	fmt.Println(out.Text)
+	return nil
+}
+
+func statusOutput(s daemonStatus, now time.Time) waybarOutput {
+	var tooltip []string
+	out := waybarOutput{Class: "idle"}
+	if !s.Connected {
+		out.Class = "disconnected"
+		out.Text = "Tom ✗"
+		tooltip = append(tooltip, "Disconnected: "+s.Error)
+	} else {
+		out.Text = "Tom"
+		if n := len(s.Notifications); n > 0 {
+			out.Class = "notifications"
+			out.Text = fmt.Sprintf("Tom %d", n)
+		}
+		for _, t := range s.Notifications {
+			tooltip = append(tooltip, t.Module+": "+t.Status)
+		}
+		if len(s.Notifications) == 0 {
+			tooltip = append(tooltip, "No notification")
+		}
+	}
+	if r := s.NextReminder; r != nil {
+		when := r.At.Format("15:04")
+		if r.At.YearDay() != now.YearDay() || r.At.Year() != now.Year() {
+			when = r.At.Format("Mon 15:04")
+		}
+		out.Text += " · ⏰ " + when
+		tooltip = append(tooltip, fmt.Sprintf("Next: %s %s", when, r.Text))
+	}
+	tooltip = append(tooltip, "Updated "+s.UpdatedAt.Format("15:04"))
+	out.Tooltip = strings.Join(tooltip, "\n")
+	return out
+}
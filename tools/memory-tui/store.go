@@ -0,0 +1,360 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// Local store: the daemon cache, the audit log, the import checkpoint, the
+// scheduled prompts, the timers and the pins in a single SQLite database,
+// ~/.tom/tom.db, opened by the daemon and the interface at once (WAL journal,
+// each waiting out the other's locks). The schema version is PRAGMA user_version and the missing
+// migrations run at opening; the first one moves in the JSON files used
+// before and removes them. `memory-tui store export` dumps everything as JSON.
+const storeBusyTimeout = 5 * time.Second
+
+type storeMigration struct {
+	up   func(tx *sql.Tx) error
+	done func() // Once committed
+}
+
+var storeMigrations = []storeMigration{
+	{up: migrateStoreV1, done: removeLegacyFiles},
+	{up: migrateStoreV2},
+	{up: migrateStoreV3},
+	{up: migrateStoreV4},
+}
+
+var localStore struct {
+	once sync.Once
+	db   *sql.DB
+	err  error
+}
+
+func storePath() (string, error) {
+	return tomPath("tom.db")
+}
+
+// openStore opens the database once per process, migrating it first
+func openStore() (*sql.DB, error) {
+	localStore.once.Do(func() {
+		localStore.db, localStore.err = openStoreAt()
+		if localStore.err != nil {
+			logf("WARN", "local store: %v", localStore.err)
+		}
+	})
+	return localStore.db, localStore.err
+}
+
+func openStoreAt() (*sql.DB, error) {
+	path, err := storePath()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	// Created private first: SQLite gives its journal files the same mode
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+	f.Close()
+	// Immediate transactions take the write lock first, waiting for it
+	// instead of failing midway when the other process writes
+	dsn := fmt.Sprintf("file:%s?_pragma=busy_timeout(%d)&_pragma=journal_mode(WAL)&_txlock=immediate", path, storeBusyTimeout.Milliseconds())
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err := migrateStore(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// migrateStore runs the migrations past the schema version, each in its own
+// transaction
+func migrateStore(db *sql.DB) error {
+	var version int
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return err
+	}
+	for version < len(storeMigrations) {
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		// Read again holding the lock, the other process may have migrated
+		if err := tx.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if version >= len(storeMigrations) {
+			tx.Rollback()
+			break
+		}
+		migration := storeMigrations[version]
+		if err := migration.up(tx); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d: %w", version+1, err)
+		}
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", version+1)); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+		if migration.done != nil {
+			migration.done()
+		}
+		version++
+		logf("INFO", "local store migrated to version %d", version)
+	}
+	return nil
+}
+
+// migrateStoreV1 creates the tables and imports the legacy files
+func migrateStoreV1(tx *sql.Tx) error {
+	for _, stmt := range []string{
+		`CREATE TABLE cache (name TEXT PRIMARY KEY, synced_at TEXT NOT NULL, data TEXT NOT NULL)`,
+		`CREATE TABLE audit (id INTEGER PRIMARY KEY AUTOINCREMENT, at TEXT NOT NULL, action TEXT NOT NULL,
+			memory_id TEXT NOT NULL DEFAULT '', before TEXT NOT NULL DEFAULT '', after TEXT NOT NULL DEFAULT '', server TEXT NOT NULL DEFAULT '')`,
+		`CREATE TABLE import_checkpoint (id INTEGER PRIMARY KEY CHECK (id = 1), data TEXT NOT NULL)`,
+	} {
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
+		}
+	}
+
+	if path, err := tomPath("cache", "memories.json"); err == nil {
+		if data, err := os.ReadFile(path); err == nil {
+			var cache memoryCache
+			if json.Unmarshal(data, &cache) == nil {
+				if err := putMemoryCache(tx, cache); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if path, err := tomPath("audit.log"); err == nil {
+		if data, err := os.ReadFile(path); err == nil {
+			for _, line := range strings.Split(string(data), "\n") {
+				var entry auditEntry
+				if json.Unmarshal([]byte(line), &entry) != nil {
+					continue
+				}
+				if err := insertAudit(tx, entry); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if path, err := tomPath("import-checkpoint.json"); err == nil {
+		if data, err := os.ReadFile(path); err == nil {
+			if json.Valid(data) {
+				if _, err := tx.Exec(`INSERT INTO import_checkpoint (id, data) VALUES (1, ?)`, string(data)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// migrateStoreV2 adds the scheduled prompts
+func migrateStoreV2(tx *sql.Tx) error {
+	_, err := tx.Exec(`CREATE TABLE schedule (id INTEGER PRIMARY KEY AUTOINCREMENT, spec TEXT NOT NULL, prompt TEXT NOT NULL,
+		notify INTEGER NOT NULL, digest TEXT NOT NULL DEFAULT '', created_at TEXT NOT NULL, last_run TEXT NOT NULL DEFAULT '')`)
+	return err
+}
+
+// migrateStoreV3 adds the timers
+func migrateStoreV3(tx *sql.Tx) error {
+	_, err := tx.Exec(`CREATE TABLE timer (id INTEGER PRIMARY KEY AUTOINCREMENT, label TEXT NOT NULL, fires_at TEXT NOT NULL,
+		created_at TEXT NOT NULL)`)
+	return err
+}
+
+// migrateStoreV4 adds the pinned answers
+func migrateStoreV4(tx *sql.Tx) error {
+	_, err := tx.Exec(`CREATE TABLE pin (id INTEGER PRIMARY KEY AUTOINCREMENT, server TEXT NOT NULL, source TEXT NOT NULL,
+		title TEXT NOT NULL, text TEXT NOT NULL, created_at TEXT NOT NULL)`)
+	return err
+}
+
+// legacyFiles were replaced by the store in its first version
+var legacyFiles = [][]string{{"cache", "memories.json"}, {"audit.log"}, {"import-checkpoint.json"}}
+
+func removeLegacyFiles() {
+	for _, elem := range legacyFiles {
+		path, err := tomPath(elem...)
+		if err != nil {
+			continue
+		}
+		if err := os.Remove(path); err == nil {
+			logf("INFO", "local store: imported and removed %s", path)
+		} else if !errors.Is(err, os.ErrNotExist) {
+			logf("WARN", "local store: %v", err)
+		}
+	}
+}
+
+// execer is a *sql.DB or a *sql.Tx
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// putCache stores v as JSON in the cache entry name
+func putCache(db execer, name string, at time.Time, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`INSERT INTO cache (name, synced_at, data) VALUES (?, ?, ?)
+		ON CONFLICT (name) DO UPDATE SET synced_at = excluded.synced_at, data = excluded.data`,
+		name, at.Format(time.RFC3339Nano), string(data))
+	return err
+}
+
+func saveCache(name string, at time.Time, v interface{}) error {
+	db, err := openStore()
+	if err != nil {
+		return err
+	}
+	return putCache(db, name, at, v)
+}
+
+// loadCache decodes the cache entry name into v, returning when it was
+// stored, or sql.ErrNoRows when it never was
+func loadCache(name string, v interface{}) (time.Time, error) {
+	db, err := openStore()
+	if err != nil {
+		return time.Time{}, err
+	}
+	var at, data string
+	if err := db.QueryRow(`SELECT synced_at, data FROM cache WHERE name = ?`, name).Scan(&at, &data); err != nil {
+		return time.Time{}, err
+	}
+	if err := json.Unmarshal([]byte(data), v); err != nil {
+		return time.Time{}, err
+	}
+	stored, _ := time.Parse(time.RFC3339Nano, at)
+	return stored, nil
+}
+
+func putMemoryCache(db execer, cache memoryCache) error {
+	return putCache(db, "memories", cache.SyncedAt, cache.Memories)
+}
+
+func saveMemoryCache(cache memoryCache) error {
+	return saveCache("memories", cache.SyncedAt, cache.Memories)
+}
+
+// loadMemoryCache returns sql.ErrNoRows before the first sync
+func loadMemoryCache() (memoryCache, error) {
+	var cache memoryCache
+	syncedAt, err := loadCache("memories", &cache.Memories)
+	if err != nil {
+		return memoryCache{}, err
+	}
+	cache.SyncedAt = syncedAt
+	return cache, nil
+}
+
+func insertAudit(db execer, entry auditEntry) error {
+	_, err := db.Exec(`INSERT INTO audit (at, action, memory_id, before, after, server) VALUES (?, ?, ?, ?, ?, ?)`,
+		entry.At, entry.Action, entry.ID, entry.Before, entry.After, entry.Server)
+	return err
+}
+
+// auditTail returns the last limit entries, oldest first
+func auditTail(limit int) ([]auditEntry, error) {
+	db, err := openStore()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.Query(`SELECT at, action, memory_id, before, after, server FROM
+		(SELECT * FROM audit ORDER BY id DESC LIMIT ?) ORDER BY id`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []auditEntry
+	for rows.Next() {
+		var e auditEntry
+		if err := rows.Scan(&e.At, &e.Action, &e.ID, &e.Before, &e.After, &e.Server); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// storeExport is the JSON written by `memory-tui store export`
+type storeExport struct {
+	Version          int               `json:"version"`
+	ExportedAt       string            `json:"exported_at"`
+	Cache            *memoryCache      `json:"cache,omitempty"`
+	Audit            []auditEntry      `json:"audit"`
+	ImportCheckpoint *importCheckpoint `json:"import_checkpoint,omitempty"`
+	Schedules        []scheduledPrompt `json:"schedules"`
+	Timers           []timer           `json:"timers"`
+	Pins             []pin             `json:"pins"`
+}
+
+func runStoreExport(output string) error {
+	db, err := openStore()
+	if err != nil {
+		return err
+	}
+	export := storeExport{ExportedAt: time.Now().Format(time.RFC3339)}
+	if err := db.QueryRow("PRAGMA user_version").Scan(&export.Version); err != nil {
+		return err
+	}
+	if cache, err := loadMemoryCache(); err == nil {
+		export.Cache = &cache
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	if export.Audit, err = auditTail(-1); err != nil {
+		return err
+	}
+	if export.Schedules, err = loadSchedules(); err != nil {
+		return err
+	}
+	if export.Timers, err = loadTimers(); err != nil {
+		return err
+	}
+	if export.Pins, err = loadPins(""); err != nil {
+		return err
+	}
+	if cp, err := loadCheckpoint(); err == nil {
+		export.ImportCheckpoint = cp
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if output == "" || output == "-" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(output, data, 0600)
+}
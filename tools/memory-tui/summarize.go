@@ -0,0 +1,59 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbletea"
+)
+
+// Summaries by Tom: /summarize sends the marked memories, or the listed ones
+// when nothing is marked, to Tom with a summarization prompt, then opens the
+// editor on the answer so it can be stored as a new memory, the IDs of the
+// summarized ones in its "summarizes" metadata. Unlike /merge the originals
+// stay; /delete removes them once the summary looks right.
+const summarizePrompt = "Summarize these notes from my memory into a single consolidated note: keep the facts " +
+	"still useful, drop the trivia and the repetitions. Do not use any tool. Reply with the note only, " +
+	"in the language of the notes."
+
+type summaryMsg struct {
+	summary string
+	ids     []string
+}
+
+func runSummarizeCommand(m model, args string) (tea.Model, tea.Cmd) {
+	memories := m.selectedMemories()
+	switch {
+	case len(memories) == 0:
+		m.message = tr("No memory to summarize")
+		return m, nil
+	case len(memories) > maxProcessMemories:
+		m.message = tr("%d memories are too many to summarize, mark or /filter %d at most", len(memories), maxProcessMemories)
+		return m, nil
+	}
+
+	ids := make([]string, len(memories))
+	for i, mem := range memories {
+		ids[i] = mem.ID
+	}
+	m.loading = true
+	m.setFocus(focusContent)
+	api := m.api
+	return m, func() tea.Msg {
+		summary, err := api.Process(memoriesPrompt(summarizePrompt, memories))
+		if err != nil {
+			return errMsg{err}
+		}
+		return summaryMsg{strings.TrimSpace(summary), ids}
+	}
+}
+
+// openSummary opens the editor on the summary, stored with Ctrl+S
+func (m model) openSummary(msg summaryMsg) (tea.Model, tea.Cmd) {
+	m.loading = false
+	m, cmd := m.openComposer(msg.summary)
+	if m.state == addView {
+		m.composeMetadata = map[string]interface{}{"summarizes": msg.ids}
+		m.message = tr("Summary of %d memories by Tom: Ctrl+S stores it, Esc drops it", len(msg.ids))
+	}
+	return m, cmd
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbletea"
+)
+
+// Memory templates for structured facts
+type templateField struct {
+	Key         string
+	Label       string
+	Placeholder string
+	Required    bool
+}
+
+type memoryTemplate struct {
+	Name        string
+	Description string
+	Fields      []templateField
+	// Sentences of the memory text, with {key} placeholders replaced by field
+	// values. A sentence referencing an empty field is left out.
+	Sentences []string
+}
+
+var memoryTemplates = map[string]memoryTemplate{
+	"person": {
+		Name:        "person",
+		Description: "Someone you know",
+		Fields: []templateField{
+			{Key: "name", Label: "Name", Placeholder: "Alice Martin", Required: true},
+			{Key: "relationship", Label: "Relationship", Placeholder: "sister, colleague, neighbour..."},
+			{Key: "notes", Label: "Notes", Placeholder: "Works at the hospital, has two kids"},
+		},
+		Sentences: []string{"Person: {name}.", "{name} is my {relationship}.", "About {name}: {notes}"},
+	},
+	"birthday": {
+		Name:        "birthday",
+		Description: "Birthday of a person",
+		Fields: []templateField{
+			{Key: "name", Label: "Name", Placeholder: "Alice Martin", Required: true},
+			{Key: "date", Label: "Date", Placeholder: "YYYY-MM-DD or MM-DD", Required: true},
+		},
+		Sentences: []string{"{name}'s birthday is on {date}."},
+	},
+	"preference": {
+		Name:        "preference",
+		Description: "Something someone likes or dislikes",
+		Fields: []templateField{
+			{Key: "subject", Label: "Who", Placeholder: "I, Alice...", Required: true},
+			{Key: "verb", Label: "Likes/Dislikes", Placeholder: "likes, prefers, dislikes...", Required: true},
+			{Key: "object", Label: "What", Placeholder: "green tea without sugar", Required: true},
+			{Key: "context", Label: "Context", Placeholder: "in the morning"},
+		},
+		Sentences: []string{"{subject} {verb} {object}.", "Context: {context}."},
+	},
+	"address": {
+		Name:        "address",
+		Description: "Postal address of a person or place",
+		Fields: []templateField{
+			{Key: "owner", Label: "Whose", Placeholder: "Alice Martin, my office...", Required: true},
+			{Key: "street", Label: "Street", Placeholder: "12 rue de la Paix", Required: true},
+			{Key: "city", Label: "City", Placeholder: "75002 Paris", Required: true},
+			{Key: "country", Label: "Country", Placeholder: "France"},
+		},
+		Sentences: []string{"The address of {owner} is {street}, {city}.", "Country: {country}."},
+	},
+}
+
+func templateNames() []string {
+	names := make([]string, 0, len(memoryTemplates))
+	for name := range memoryTemplates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// compose builds the memory text and its structured metadata from field values
+func (t memoryTemplate) compose(values map[string]string) (string, map[string]interface{}, error) {
+	metadata := map[string]interface{}{"template": t.Name}
+	var replacements []string
+
+	for _, f := range t.Fields {
+		value := strings.TrimSpace(values[f.Key])
+		if value == "" && f.Required {
+			return "", nil, fmt.Errorf("%s is required", f.Label)
+		}
+		if value != "" {
+			metadata[f.Key] = value
+		}
+		replacements = append(replacements, "{"+f.Key+"}", value)
+	}
+
+	replacer := strings.NewReplacer(replacements...)
+	var sentences []string
+	for _, sentence := range t.Sentences {
+		if missingField(sentence, values) {
+			continue
+		}
+		sentences = append(sentences, replacer.Replace(sentence))
+	}
+
+	return strings.Join(sentences, " "), metadata, nil
+}
+
+// missingField reports whether sentence references a field left empty
+func missingField(sentence string, values map[string]string) bool {
+	for {
+		start := strings.Index(sentence, "{")
+		if start < 0 {
+			return false
+		}
+		end := strings.Index(sentence[start:], "}")
+		if end < 0 {
+			return false
+		}
+		if strings.TrimSpace(values[sentence[start+1:start+end]]) == "" {
+			return true
+		}
+		sentence = sentence[start+end+1:]
+	}
+}
+
+func (m model) openTemplate(name string) (model, error) {
+	tmpl, ok := memoryTemplates[name]
+	if !ok {
+		return m, fmt.Errorf("unknown template %q (available: %s)", name, strings.Join(templateNames(), ", "))
+	}
+
+	inputs := make([]textinput.Model, len(tmpl.Fields))
+	for i, f := range tmpl.Fields {
+		in := textinput.New()
+		in.Placeholder = f.Placeholder
+		in.Width = 40
+		inputs[i] = in
+	}
+	m.template = tmpl
+	m.templateInputs = inputs
+	m.templateFocus = 0
+	m.showView(templateView)
+	return m, nil
+}
+
+func (m model) updateTemplateView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.showView(listView)
+		return m, nil
+	case "down", "enter":
+		m.focusTemplateField(m.templateFocus + 1)
+		return m, nil
+	case "up":
+		m.focusTemplateField(m.templateFocus - 1)
+		return m, nil
+	case "ctrl+s":
+		text, metadata, err := m.template.compose(m.templateValues())
+		if err != nil {
+			m.err = err
+			return m, nil
+		}
+		return m.submitMemory(text, metadata, false)
+	}
+
+	var cmd tea.Cmd
+	m.templateInputs[m.templateFocus], cmd = m.templateInputs[m.templateFocus].Update(msg)
+	return m, cmd
+}
+
+func (m model) templateValues() map[string]string {
+	values := make(map[string]string, len(m.templateInputs))
+	for i, f := range m.template.Fields {
+		values[f.Key] = m.templateInputs[i].Value()
+	}
+	return values
+}
+
+func (m *model) focusTemplateField(i int) {
+	if i < 0 || i >= len(m.templateInputs) {
+		return
+	}
+	m.templateInputs[m.templateFocus].Blur()
+	m.templateFocus = i
+	m.templateInputs[i].Focus()
+}
+
+func (m model) renderTemplateView() string {
+	style := contentBoxStyle.Width(m.width - 4)
+	if m.focus == focusContent {
+		style = contentBoxFocusedStyle.Width(m.width - 4)
+	}
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("🧩 New " + m.template.Name))
+	b.WriteString("\n\n")
+	for i, f := range m.template.Fields {
+		label := f.Label
+		if f.Required {
+			label += " *"
+		}
+		if i == m.templateFocus {
+			b.WriteString(selectedItemStyle.Render(label))
+		} else {
+			b.WriteString(label)
+		}
+		b.WriteString("\n")
+		b.WriteString(m.templateInputs[i].View())
+		b.WriteString("\n\n")
+	}
+
+	if text, _, err := m.template.compose(m.templateValues()); err == nil {
+		b.WriteString(helpStyle.Render("Preview: " + text))
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString(m.keyHint(""))
+	return style.Render(b.String())
+}
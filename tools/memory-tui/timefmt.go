@@ -0,0 +1,97 @@
+package main
+
+import (
+	"time"
+)
+
+// Dates are shown in the local timezone with the layout of the
+// "time_format" setting (a Go layout), and relative ("2 hours ago",
+// "yesterday") in the list unless "relative_times" is off. Detail views
+// keep the absolute form.
+const defaultTimeFormat = "2006-01-02 15:04"
+
+var (
+	timeFormat    = defaultTimeFormat
+	relativeTimes = true
+)
+
+// Layouts sent by the servers, the zoneless ones being UTC
+var serverTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+}
+
+// applyTimeConfig sets the date display from the configuration
+func applyTimeConfig(cfg config) {
+	timeFormat = defaultTimeFormat
+	if cfg.TimeFormat != "" {
+		timeFormat = cfg.TimeFormat
+	}
+	relativeTimes = cfg.RelativeTimes
+}
+
+func parseServerTime(timeStr string) (time.Time, bool) {
+	for _, layout := range serverTimeLayouts {
+		if t, err := time.Parse(layout, timeStr); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func formatTime(timeStr string) string {
+	if timeStr == "" {
+		return "Unknown"
+	}
+	t, ok := parseServerTime(timeStr)
+	if !ok {
+		return timeStr[:min(10, len(timeStr))] // Return first 10 chars if parsing fails
+	}
+	return t.Local().Format(timeFormat)
+}
+
+// listTime is the date shown in the list, relative when enabled
+func listTime(timeStr string) string {
+	if !relativeTimes {
+		return formatTime(timeStr)
+	}
+	t, ok := parseServerTime(timeStr)
+	if !ok {
+		return formatTime(timeStr)
+	}
+	return relativeTime(t, time.Now())
+}
+
+// relativeTime describes t from now, the absolute date beyond a week
+func relativeTime(t, now time.Time) string {
+	d := now.Sub(t)
+	plural := func(n int, one, many string) string {
+		if n == 1 {
+			return tr(one)
+		}
+		return tr(many, n)
+	}
+
+	// Calendar days, in the local timezone
+	local, today := t.Local(), now.Local()
+	days := int(time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.Local).
+		Sub(time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.Local)).Hours()+12) / 24
+
+	switch {
+	case d < -time.Minute:
+		return t.Local().Format(timeFormat) // Server clock ahead
+	case d < time.Minute:
+		return tr("just now")
+	case d < time.Hour:
+		return plural(int(d.Minutes()), "1 minute ago", "%d minutes ago")
+	case days == 0:
+		return plural(int(d.Hours()), "1 hour ago", "%d hours ago")
+	case days == 1:
+		return tr("yesterday %s", local.Format("15:04"))
+	case days < 7:
+		return plural(days, "1 day ago", "%d days ago")
+	}
+	return local.Format(timeFormat)
+}
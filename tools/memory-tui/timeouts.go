@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Request timeouts by class, in seconds in the "timeouts" setting (0 for
+// none): the requests of the interface, the daemon polling and the bulk
+// operations (imports, splits, deletes, map). The wait for a slot of the
+// request scheduler and for the end of a rate limit count in the time. The
+// check of a saved session has a limit of its own, sessionCheckTimeout, so a
+// server that hangs never holds the startup.
+type timeoutClass string
+
+const (
+	timeoutInteractive timeoutClass = "interactive"
+	timeoutPolling     timeoutClass = "polling"
+	timeoutBulk        timeoutClass = "bulk"
+)
+
+const sessionCheckTimeout = 30 * time.Second
+
+var defaultTimeouts = map[timeoutClass]time.Duration{
+	timeoutInteractive: 2 * time.Minute,
+	timeoutPolling:     5 * time.Minute, // Long polls of slow servers
+	timeoutBulk:        5 * time.Minute,
+}
+
+var requestTimeouts = defaultTimeouts
+
+// applyTimeoutConfig sets the timeouts of the configuration over the defaults
+func applyTimeoutConfig(cfg config) {
+	requestTimeouts = map[timeoutClass]time.Duration{}
+	for class, d := range defaultTimeouts {
+		requestTimeouts[class] = d
+	}
+	for name, secs := range cfg.Timeouts {
+		class := timeoutClass(name)
+		if _, ok := defaultTimeouts[class]; !ok {
+			logf("WARN", "unknown timeout class %q, expected interactive, polling or bulk", name)
+			continue
+		}
+		requestTimeouts[class] = time.Duration(secs) * time.Second
+	}
+}
+
+// limit is the timeout of the class, the unset class being interactive
+func (c timeoutClass) limit() time.Duration {
+	if c == "" {
+		c = timeoutInteractive
+	}
+	return requestTimeouts[c]
+}
+
+// timeoutError replaces the bare "context deadline exceeded" of a request
+// the server did not answer in time
+type timeoutError struct {
+	class timeoutClass
+	limit time.Duration
+}
+
+func (e *timeoutError) Error() string {
+	return tr("the server did not answer within %s (%s requests), try again or raise \"timeouts\" in ~/.tom/memory-tui.json", e.limit, tr(string(e.class)))
+}
+
+func (e *timeoutError) Timeout() bool { return true }
+
+// friendlyError unwraps the timeout of a failed request from its URL error
+func friendlyError(err error) error {
+	var te *timeoutError
+	if errors.As(err, &te) {
+		return te
+	}
+	return err
+}
+
+// withTimeout sends req under the timeout of class, reading the response
+// body included
+func withTimeout(req *http.Request, class timeoutClass, send func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	limit := class.limit()
+	if limit <= 0 {
+		return send(req)
+	}
+	if class == "" {
+		class = timeoutInteractive
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), limit)
+	resp, err := send(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, &timeoutError{class, limit}
+		}
+		return nil, err
+	}
+	resp.Body = &timeoutBody{resp.Body, ctx, cancel, &timeoutError{class, limit}}
+	return resp, nil
+}
+
+type timeoutBody struct {
+	io.ReadCloser
+	ctx     context.Context
+	cancel  context.CancelFunc
+	expired *timeoutError
+}
+
+func (b *timeoutBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && err != io.EOF && errors.Is(b.ctx.Err(), context.DeadlineExceeded) {
+		return n, b.expired
+	}
+	return n, err
+}
+
+func (b *timeoutBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
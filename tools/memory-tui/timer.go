@@ -0,0 +1,298 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Timers: /timer 25m "review PR" stores a timer in the local store; when it
+// fires the interface rings the bell, shows it in the status bar and sends a
+// desktop notification. The daemon notifies the timers no interface took a
+// few seconds past their end, so a timer still fires with the interface
+// closed. /timers lists the running ones and cancels them.
+const (
+	timerTickInterval = time.Second
+	timerDaemonGrace  = 10 * time.Second // Left to the interface before the daemon notifies
+	defaultTimerLabel = "Timer"
+)
+
+type timer struct {
+	ID        int64     `json:"id"`
+	Label     string    `json:"label"`
+	FiresAt   time.Time `json:"fires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type timerTickMsg struct{}
+
+type timersFiredMsg struct {
+	fired   []timer
+	running []timer
+}
+
+func addTimer(t timer) (int64, error) {
+	db, err := openStore()
+	if err != nil {
+		return 0, err
+	}
+	res, err := db.Exec(`INSERT INTO timer (label, fires_at, created_at) VALUES (?, ?, ?)`,
+		t.Label, t.FiresAt.Format(time.RFC3339), t.CreatedAt.Format(time.RFC3339))
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+func loadTimers() ([]timer, error) {
+	db, err := openStore()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.Query(`SELECT id, label, fires_at, created_at FROM timer ORDER BY fires_at, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var timers []timer
+	for rows.Next() {
+		var t timer
+		var firesAt, createdAt string
+		if err := rows.Scan(&t.ID, &t.Label, &firesAt, &createdAt); err != nil {
+			return nil, err
+		}
+		t.FiresAt, _ = time.Parse(time.RFC3339, firesAt)
+		t.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+		timers = append(timers, t)
+	}
+	return timers, rows.Err()
+}
+
+// removeTimer deletes the timer, reporting false when it was already gone:
+// the interface and the daemon both claim the timers ending this way, and
+// only the one removing it notifies
+func removeTimer(id int64) (bool, error) {
+	db, err := openStore()
+	if err != nil {
+		return false, err
+	}
+	res, err := db.Exec(`DELETE FROM timer WHERE id = ?`, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// fireTimers claims the timers ended before at
+func fireTimers(timers []timer, at time.Time) (fired, running []timer) {
+	for _, t := range timers {
+		if t.FiresAt.After(at) {
+			running = append(running, t)
+			continue
+		}
+		claimed, err := removeTimer(t.ID)
+		if err != nil {
+			logf("WARN", "timer %d: %v", t.ID, err)
+			continue
+		}
+		if claimed {
+			fired = append(fired, t)
+		}
+	}
+	return fired, running
+}
+
+// duration is the length the timer was set for
+func (t timer) duration() time.Duration {
+	return t.FiresAt.Sub(t.CreatedAt)
+}
+
+func notifyTimer(t timer) {
+	if err := desktopNotify("⏰ "+t.Label, fmt.Sprintf("%s elapsed", t.duration())); err != nil {
+		logf("WARN", "timer %d notification: %v", t.ID, err)
+	}
+}
+
+// parseTimer reads "25m review PR", the label optionally quoted
+func parseTimer(args string) (time.Duration, string, error) {
+	value, label, _ := strings.Cut(strings.TrimSpace(args), " ")
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, "", fmt.Errorf("invalid duration %q, expected e.g. 25m, 1h30m or 90s", value)
+	}
+	label = strings.Trim(strings.TrimSpace(label), `"'`)
+	if label == "" {
+		label = defaultTimerLabel
+	}
+	return d, label, nil
+}
+
+func runTimerCommand(m model, args string) (tea.Model, tea.Cmd) {
+	d, label, err := parseTimer(args)
+	if err != nil {
+		m.err = err
+		return m, nil
+	}
+	now := time.Now()
+	t := timer{Label: label, FiresAt: now.Add(d), CreatedAt: now}
+	if t.ID, err = addTimer(t); err != nil {
+		m.err = err
+		return m, nil
+	}
+	logf("INFO", "timer %d set for %s", t.ID, d)
+	m.message = tr("⏰ %s in %s, at %s", label, d, t.FiresAt.Format("15:04:05"))
+	m.timers = append(m.timers, t)
+	return m, m.startTimerTick()
+}
+
+func runTimersCommand(m model, args string) (tea.Model, tea.Cmd) {
+	timers, err := loadTimers()
+	if err != nil {
+		m.err = err
+		return m, nil
+	}
+	m.timers = timers
+	m.timerCursor = 0
+	cmd := m.showView(timersView)
+	return m, cmd
+}
+
+// startTimerTick starts checking the timers unless it already runs
+func (m *model) startTimerTick() tea.Cmd {
+	if m.timerTicking {
+		return nil
+	}
+	m.timerTicking = true
+	return timerTick()
+}
+
+func timerTick() tea.Cmd {
+	return tea.Tick(tickInterval(timerTickInterval), func(time.Time) tea.Msg { return timerTickMsg{} })
+}
+
+// checkTimers claims the ended timers, the tick stopping once none runs
+func checkTimers() tea.Msg {
+	timers, err := loadTimers()
+	if err != nil {
+		logf("WARN", "timers: %v", err)
+		return timersFiredMsg{}
+	}
+	fired, running := fireTimers(timers, time.Now())
+	return timersFiredMsg{fired, running}
+}
+
+func (m model) handleTimersFired(msg timersFiredMsg) (tea.Model, tea.Cmd) {
+	m.timers = msg.running
+	if m.timerCursor >= len(m.timers) {
+		m.timerCursor = max(0, len(m.timers)-1)
+	}
+	var cmds []tea.Cmd
+	if len(msg.running) > 0 {
+		cmds = append(cmds, timerTick())
+	} else {
+		m.timerTicking = false
+	}
+	if len(msg.fired) == 0 {
+		return m, tea.Batch(cmds...)
+	}
+
+	labels := make([]string, len(msg.fired))
+	for i, t := range msg.fired {
+		labels[i] = t.Label
+		logf("INFO", "timer %d fired", t.ID)
+	}
+	m.message = tr("⏰ Time's up: %s", strings.Join(labels, ", "))
+	fired, hooks := msg.fired, m.config.Hooks
+	cmds = append(cmds, ringBell(m.config), func() tea.Msg {
+		for _, t := range fired {
+			notifyTimer(t)
+			runHook(hooks, hookTaskDue, timerHookPayload(t))
+		}
+		return nil
+	})
+	return m, tea.Batch(cmds...)
+}
+
+// runTimers notifies the timers the interface left past their end
+func (d *daemon) runTimers() {
+	timers, err := loadTimers()
+	if err != nil {
+		logf("WARN", "timers: %v", err)
+		return
+	}
+	fired, _ := fireTimers(timers, time.Now().Add(-timerDaemonGrace))
+	for _, t := range fired {
+		logf("INFO", "timer %d fired", t.ID)
+		notifyTimer(t)
+		runHook(d.hooks, hookTaskDue, timerHookPayload(t))
+	}
+}
+
+func timerHookPayload(t timer) map[string]interface{} {
+	return map[string]interface{}{"id": t.ID, "label": t.Label, "fires_at": t.FiresAt}
+}
+
+func (m model) updateTimersView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		cmd := m.showView(listView)
+		return m, cmd
+	case "up", "k":
+		if m.timerCursor > 0 {
+			m.timerCursor--
+		}
+	case "down", "j":
+		if m.timerCursor < len(m.timers)-1 {
+			m.timerCursor++
+		}
+	case "x", "d", "delete":
+		if len(m.timers) == 0 {
+			return m, nil
+		}
+		t := m.timers[m.timerCursor]
+		if _, err := removeTimer(t.ID); err != nil {
+			m.err = err
+			return m, nil
+		}
+		logf("INFO", "timer %d canceled", t.ID)
+		m.message = tr("Timer %q canceled", t.Label)
+		m.timers = append(m.timers[:m.timerCursor:m.timerCursor], m.timers[m.timerCursor+1:]...)
+		if m.timerCursor >= len(m.timers) {
+			m.timerCursor = max(0, len(m.timers)-1)
+		}
+	}
+	return m, nil
+}
+
+func (m model) renderTimersModal() string {
+	modalWidth := min(70, m.width-10)
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(tr("⏰ Timers")))
+	b.WriteString("\n\n")
+	if len(m.timers) == 0 {
+		b.WriteString(helpStyle.Render(tr("No timer running, /timer 25m \"review PR\" sets one")))
+		b.WriteString("\n")
+	}
+	for i, t := range m.timers {
+		remaining := time.Until(t.FiresAt).Round(time.Second)
+		if remaining < 0 {
+			remaining = 0
+		}
+		line := fmt.Sprintf("%8s  %s  %s", remaining, t.FiresAt.Local().Format("15:04"), truncateString(t.Label, modalWidth-24))
+		if i == m.timerCursor {
+			b.WriteString(selectedItemStyle.Render("▸ " + line))
+		} else {
+			b.WriteString("  " + line)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	b.WriteString(m.keyHint(""))
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, modalStyle.Width(modalWidth).Render(b.String()))
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/charmbracelet/bubbletea"
+)
+
+// Transfer progress: the request and response bodies are counted as they go
+// through the transport, and the loading screen and the batch modal show the
+// largest transfer running, with its speed, instead of a bare "Loading...".
+// Short transfers are not shown to avoid flickering.
+const (
+	transferShowAfter    = 300 * time.Millisecond
+	transferTickInterval = 200 * time.Millisecond
+)
+
+type transfer struct {
+	upload  bool
+	done    atomic.Int64
+	total   int64 // -1 when unknown
+	started time.Time
+}
+
+// size is the expected size of the transfer, or its bytes so far when larger
+func (t *transfer) size() int64 {
+	if done := t.done.Load(); done > t.total {
+		return done
+	}
+	return t.total
+}
+
+type transferTracker struct {
+	mu     sync.Mutex
+	active map[*transfer]bool
+}
+
+var transfers = &transferTracker{active: map[*transfer]bool{}}
+
+type transferTickMsg struct{}
+
+func transferTick() tea.Cmd {
+	return tea.Tick(tickInterval(transferTickInterval), func(time.Time) tea.Msg { return transferTickMsg{} })
+}
+
+func (tt *transferTracker) start(upload bool, total int64) *transfer {
+	t := &transfer{upload: upload, total: total, started: time.Now()}
+	tt.mu.Lock()
+	tt.active[t] = true
+	tt.mu.Unlock()
+	return t
+}
+
+func (tt *transferTracker) end(t *transfer) {
+	tt.mu.Lock()
+	delete(tt.active, t)
+	tt.mu.Unlock()
+}
+
+// largest returns the transfer with the most bytes among those running for
+// a while, nil when none
+func (tt *transferTracker) largest() *transfer {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+	var best *transfer
+	for t := range tt.active {
+		if time.Since(t.started) < transferShowAfter {
+			continue
+		}
+		if best == nil || t.size() > best.size() {
+			best = t
+		}
+	}
+	return best
+}
+
+// countingBody counts the bytes read from a body, ending the transfer on
+// close
+type countingBody struct {
+	io.ReadCloser
+	t *transfer
+}
+
+func (b countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.t.done.Add(int64(n))
+	return n, err
+}
+
+func (b countingBody) Close() error {
+	transfers.end(b.t)
+	return b.ReadCloser.Close()
+}
+
+// trackTransfer counts the upload of req and the download of its response
+func trackTransfer(req *http.Request, send func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	if req.Body != nil && req.Body != http.NoBody {
+		up := transfers.start(true, req.ContentLength)
+		req.Body = countingBody{req.Body, up}
+		defer transfers.end(up)
+	}
+	resp, err := send(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = countingBody{resp.Body, transfers.start(false, resp.ContentLength)}
+	return resp, nil
+}
+
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1f MB", float64(n)/1_000_000)
+	case n >= 1_000:
+		return fmt.Sprintf("%.1f kB", float64(n)/1_000)
+	}
+	return fmt.Sprintf("%d B", n)
+}
+
+// transferStatus describes the largest running transfer, "" when none
+func transferStatus() string {
+	t := transfers.largest()
+	if t == nil {
+		return ""
+	}
+	arrow := "⇣"
+	if t.upload {
+		arrow = "⇡"
+	}
+	done := t.done.Load()
+	s := arrow + " " + formatBytes(done)
+	if t.total > 0 {
+		s += " / " + formatBytes(t.total)
+	}
+	if elapsed := time.Since(t.started).Seconds(); elapsed > 0 {
+		s += " • " + formatBytes(int64(float64(done)/elapsed)) + "/s"
+	}
+	return s
+}
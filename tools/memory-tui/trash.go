@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/Mickael-Roger/tom/tools/memory-tui/pkg/memoryclient"
+	"github.com/charmbracelet/bubbletea"
+)
+
+// Server trash: servers advertising the "soft_delete" capability in /status
+// keep deleted memories and can restore them, so DeleteMemory moves memories
+// to the trash there and /restore brings them back. Other servers delete for
+// good, which the confirmation says.
+const capSoftDelete = "soft_delete"
+
+type memoryRestoredMsg struct{ id string }
+
+// hasCapability reports whether the server advertised a capability
+func (info serverInfo) hasCapability(name string) bool {
+	for _, c := range info.Capabilities {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
+
+// applyServerInfo sets what the handshake negotiated on the API client
+func (api *MemoryAPI) applyServerInfo(info serverInfo) {
+	api.APIVersion = info.APIVersion
+	api.SoftDelete = info.hasCapability(capSoftDelete)
+	api.Embeddings = info.hasCapability(capEmbeddings)
+	api.Batch = info.hasCapability(capBatch)
+	api.Modules = info.enabledModules()
+	// The server can make a session read-only, not lift the configuration
+	api.ReadOnly = api.ReadOnly || info.ReadOnly
+}
+
+// deleteEndpoint is the request DeleteMemory sends for a memory
+func (api *MemoryAPI) deleteEndpoint(id string) (method, endpoint string) {
+	if api.SoftDelete {
+		return "POST", "/trash/" + url.PathEscape(id)
+	}
+	return "DELETE", "/delete/" + id
+}
+
+// RestoreMemory brings a memory back from the server trash
+func (api *MemoryAPI) RestoreMemory(id string) error {
+	if !api.SoftDelete {
+		return fmt.Errorf("the server has no trash: deleted memories cannot be restored")
+	}
+	if err := api.refuseReadOnly(); err != nil {
+		return err
+	}
+	endpoint := "/restore/" + url.PathEscape(id)
+	if api.skipDryRun("POST", endpoint, nil) {
+		return nil
+	}
+
+	restored, err := api.memories().Restore(context.Background(), id)
+	if errors.Is(err, memoryclient.ErrNotFound) {
+		return fmt.Errorf("memory %s is not in the trash", id)
+	}
+	if err != nil {
+		return clientError(err)
+	}
+
+	api.recordAudit("restore", id, "", restored.Memory)
+	return nil
+}
+
+func runRestoreCommand(m model, args string) (tea.Model, tea.Cmd) {
+	if args == "" {
+		m.message = tr("Usage: %s", "/restore ID")
+		return m, nil
+	}
+	id, err := parseMemoryLink(args)
+	if err != nil {
+		m.message = err.Error()
+		return m, nil
+	}
+	if !m.api.SoftDelete {
+		m.message = tr("This server has no trash: deleted memories cannot be restored")
+		return m, nil
+	}
+	m.loading = true
+	api := m.api
+	return m, func() tea.Msg {
+		if err := api.RestoreMemory(id); err != nil {
+			return errMsg{err}
+		}
+		return memoryRestoredMsg{id}
+	}
+}
+
+// deleteWarning ends the delete confirmation
+func (m model) deleteWarning() string {
+	if m.api != nil && m.api.SoftDelete {
+		return tr("The server keeps it in its trash: /restore ID brings it back.")
+	}
+	return meterWarningStyle.Render(tr("This server has no trash: this action cannot be undone."))
+}
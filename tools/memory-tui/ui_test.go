@@ -0,0 +1,271 @@
+package main
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/exp/golden"
+	"github.com/charmbracelet/x/exp/teatest"
+	"github.com/muesli/termenv"
+)
+
+// UI flows driven through teatest against the mock server of mock.go, each
+// screen compared with its golden file under testdata/. After a deliberate
+// change of the interface, `go test -run TestUI -update` rewrites them: check
+// the diff of testdata/ before committing.
+const (
+	uiTestWidth   = 100
+	uiTestHeight  = 30
+	uiTestTimeout = 5 * time.Second
+	uiTestConfig  = `{"language": "en", "relative_times": false, "time_format": "2006-01-02 15:04"}`
+)
+
+// The memories of the mock, with fixed IDs and dates for stable screens
+var uiTestMemories = []Memory{
+	{ID: "0b5e1c2a-9f3d-4e8a-b7c6-1d2e3f4a5b6c", Memory: "The spare key is under the blue pot", Hash: "5d41402abc4b2a76b9719d911017c592", CreatedAt: "2024-03-01T09:30:00Z", UserID: "mock"},
+	{ID: "7c9d8e1f-2a3b-4c5d-8e9f-0a1b2c3d4e5f", Memory: "Alice is allergic to peanuts", Hash: "7d793037a0760186574b0282f2f435e7", CreatedAt: "2024-03-02T18:05:00Z", UserID: "mock", Metadata: map[string]interface{}{"source": "chat"}},
+	{ID: "e4f5a6b7-c8d9-4eaf-9b0c-1d2e3f405162", Memory: "The boiler is serviced every October", Hash: "9e107d9d372bb6826bd81d3542a419d6", CreatedAt: "2024-03-05T07:45:00Z", UserID: "mock"},
+}
+
+func init() {
+	// No colors, and dates in UTC whatever the machine
+	lipgloss.SetColorProfile(termenv.Ascii)
+	time.Local = time.UTC
+}
+
+// uiHarness wraps the model of the interface so that the test can read the
+// screen, and the messages handled so far, between two steps
+type uiHarness struct {
+	model tea.Model
+	seen  map[string]int // Messages handled, by type
+}
+
+// screenMsg asks the harness for the screen
+type screenMsg chan uiScreen
+
+type uiScreen struct {
+	view string
+	seen map[string]int
+}
+
+func (h *uiHarness) Init() tea.Cmd { return h.model.Init() }
+
+func (h *uiHarness) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if reply, ok := msg.(screenMsg); ok {
+		seen := make(map[string]int, len(h.seen))
+		for k, v := range h.seen {
+			seen[k] = v
+		}
+		reply <- uiScreen{h.model.View(), seen}
+		return h, nil
+	}
+	h.seen[fmt.Sprintf("%T", msg)]++
+	var cmd tea.Cmd
+	h.model, cmd = h.model.Update(msg)
+	return h, cmd
+}
+
+func (h *uiHarness) View() string { return h.model.View() }
+
+type uiTest struct {
+	t      *testing.T
+	mock   *mockServer
+	server *httptest.Server
+	tm     *teatest.TestModel
+}
+
+// newUITest starts the interface in an empty home, on the login form, the
+// mock server holding uiTestMemories
+func newUITest(t *testing.T) *uiTest {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if err := os.MkdirAll(filepath.Join(home, ".tom"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, ".tom", "memory-tui.json"), []byte(uiTestConfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	mock := newMockServer("mock", "mock", 1, mockChaos{})
+	for _, m := range uiTestMemories {
+		mock.memories[m.ID] = m
+		mock.order = append(mock.order, m.ID)
+	}
+	server := httptest.NewServer(mock.routes())
+	t.Cleanup(server.Close)
+
+	u := &uiTest{t: t, mock: mock, server: server}
+	u.tm = teatest.NewTestModel(t, &uiHarness{model: initialModel(), seen: map[string]int{}},
+		teatest.WithInitialTermSize(uiTestWidth, uiTestHeight))
+	// The next test changes the settings the program reads: it must be gone
+	t.Cleanup(func() {
+		u.tm.Quit()
+		u.tm.WaitFinished(t, teatest.WithFinalTimeout(uiTestTimeout))
+	})
+	u.waitFor("the login form", func(s uiScreen) bool { return strings.Contains(s.view, "Memory Manager Login") })
+	return u
+}
+
+// screen returns the screen as shown now
+func (u *uiTest) screen() uiScreen {
+	u.t.Helper()
+	reply := make(screenMsg, 1)
+	u.tm.Send(reply)
+	select {
+	case s := <-reply:
+		return s
+	case <-time.After(uiTestTimeout):
+		u.t.Fatal("the interface does not answer")
+		return uiScreen{}
+	}
+}
+
+// waitFor polls the screen until done says so
+func (u *uiTest) waitFor(what string, done func(uiScreen) bool) uiScreen {
+	u.t.Helper()
+	deadline := time.Now().Add(uiTestTimeout)
+	for {
+		s := u.screen()
+		if done(s) {
+			return s
+		}
+		if time.Now().After(deadline) {
+			u.t.Fatalf("timed out waiting for %s, the screen is:\n%s", what, s.view)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// waitMsg waits for the interface to have handled n messages of the type of
+// msg
+func (u *uiTest) waitMsg(msg tea.Msg, n int) uiScreen {
+	u.t.Helper()
+	name := fmt.Sprintf("%T", msg)
+	return u.waitFor(fmt.Sprintf("%d %s", n, name), func(s uiScreen) bool { return s.seen[name] >= n })
+}
+
+// requireScreen compares the screen with the golden file of name, the port
+// of the mock server aside
+func (u *uiTest) requireScreen(name string, s uiScreen) {
+	u.t.Helper()
+	port := u.server.URL[strings.LastIndex(u.server.URL, ":")+1:]
+	view := strings.ReplaceAll(s.view, u.server.URL, "http://127.0.0.1:"+strings.Repeat("X", len(port)))
+	u.t.Run(name, func(t *testing.T) { golden.RequireEqual(t, []byte(view)) })
+}
+
+// memories returns the number of memories the mock server holds
+func (u *uiTest) memories() int {
+	u.mock.mu.Lock()
+	defer u.mock.mu.Unlock()
+	return len(u.mock.order)
+}
+
+func (u *uiTest) key(keyType tea.KeyType) { u.tm.Send(tea.KeyMsg{Type: keyType}) }
+
+// typeKeys types text a key at a time, slower than a paste
+func (u *uiTest) typeKeys(text string) {
+	for _, r := range text {
+		u.tm.Send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		time.Sleep(2 * pasteBurstGap)
+	}
+}
+
+func (u *uiTest) login(username, password string) {
+	u.tm.Type(username)
+	u.key(tea.KeyTab)
+	u.tm.Type(password)
+	u.key(tea.KeyTab)
+	u.tm.Type(u.server.URL)
+	u.key(tea.KeyEnter)
+}
+
+// loggedIn logs in and waits for the list and the server version check
+func (u *uiTest) loggedIn() uiScreen {
+	u.login("mock", "mock")
+	u.waitMsg(serverInfoMsg{}, 1)
+	return u.waitMsg(memoriesLoadedMsg{}, 1)
+}
+
+func TestUILogin(t *testing.T) {
+	u := newUITest(t)
+	u.requireScreen("form", u.screen())
+
+	u.login("mock", "wrong")
+	s := u.waitMsg(errorMsg{}, 1)
+	u.requireScreen("invalid", s)
+}
+
+func TestUIList(t *testing.T) {
+	u := newUITest(t)
+	u.requireScreen("list", u.loggedIn())
+}
+
+func TestUIDetail(t *testing.T) {
+	u := newUITest(t)
+	u.loggedIn()
+
+	u.tm.Send(tea.KeyMsg{Type: tea.KeyDown})
+	u.key(tea.KeyEnter)
+	s := u.waitMsg(accessLogMsg{}, 1)
+	if !strings.Contains(s.view, "Alice is allergic to peanuts") {
+		t.Fatalf("the detail of the second memory is not shown:\n%s", s.view)
+	}
+	u.requireScreen("detail", s)
+
+	u.key(tea.KeyEsc)
+	s = u.waitFor("the list", func(s uiScreen) bool { return !strings.Contains(s.view, "Memory Details") })
+	u.requireScreen("closed", s)
+}
+
+func TestUIDelete(t *testing.T) {
+	u := newUITest(t)
+	u.loggedIn()
+
+	u.key(tea.KeyDelete)
+	s := u.waitFor("the confirmation", func(s uiScreen) bool { return strings.Contains(s.view, "Confirm Delete") })
+	u.requireScreen("confirm", s)
+
+	u.tm.Type("n")
+	u.waitFor("the list", func(s uiScreen) bool { return !strings.Contains(s.view, "Confirm Delete") })
+	if u.memories() != len(uiTestMemories) {
+		t.Fatal("the memory was deleted after a no")
+	}
+
+	u.key(tea.KeyDelete)
+	u.waitFor("the confirmation", func(s uiScreen) bool { return strings.Contains(s.view, "Confirm Delete") })
+	u.tm.Type("y")
+	s = u.waitMsg(memoriesLoadedMsg{}, 2)
+	if left := u.memories(); left != len(uiTestMemories)-1 {
+		t.Fatalf("the server holds %d memories, want %d", left, len(uiTestMemories)-1)
+	}
+	u.requireScreen("deleted", s)
+}
+
+// Enter and Delete do nothing when /filter matches no memory
+func TestUIEmptyFilter(t *testing.T) {
+	u := newUITest(t)
+	u.loggedIn()
+
+	u.key(tea.KeyTab)
+	u.typeKeys("/filter zzz")
+	u.key(tea.KeyEnter)
+	u.waitFor("the empty list", func(s uiScreen) bool { return strings.Contains(s.view, "No items.") })
+
+	for _, keyType := range []tea.KeyType{tea.KeyEnter, tea.KeyDelete} {
+		time.Sleep(2 * pasteBurstGap)
+		u.key(keyType)
+	}
+	s := u.screen()
+	if strings.Contains(s.view, "Memory Details") || strings.Contains(s.view, "Confirm Delete") {
+		t.Fatalf("a memory was opened from an empty list:\n%s", s.view)
+	}
+	u.requireScreen("empty", s)
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"time"
+	"unicode"
+
+	"github.com/charmbracelet/bubbletea"
+)
+
+// Undo history of the add view editor. Snapshots of the whole text are
+// recorded before each edit, consecutive typing being grouped so that Ctrl+Z
+// removes a word rather than a letter; kills (Ctrl+U, Ctrl+W...) and pastes
+// are their own steps.
+const (
+	maxUndoSteps   = 200
+	undoGroupDelay = time.Second // Typing pause closing a group
+)
+
+type editHistory struct {
+	undo     []string
+	redo     []string
+	lastEdit time.Time
+	grouping bool // The last edit was typing that the next one may extend
+}
+
+func (h *editHistory) reset() {
+	*h = editHistory{}
+}
+
+// groupEdit reports whether a key extends the current group: a letter typed,
+// or a key of the paste in progress
+func (m model) groupEdit(msg tea.KeyMsg) bool {
+	if m.paste.active {
+		return !m.paste.started
+	}
+	if msg.Type != tea.KeyRunes || len(msg.Runes) != 1 {
+		return false
+	}
+	return !unicode.IsSpace(msg.Runes[0])
+}
+
+// record is called after an edit that changed the text from before
+func (h *editHistory) record(before string, grouped bool) {
+	now := time.Now()
+	if !(grouped && h.grouping && now.Sub(h.lastEdit) < undoGroupDelay) {
+		h.push(before)
+	}
+	h.redo = nil
+	h.lastEdit = now
+	h.grouping = grouped
+}
+
+// snapshot records before as its own step, for edits made outside the
+// textarea such as spellcheck replacements
+func (h *editHistory) snapshot(before string) {
+	h.push(before)
+	h.redo = nil
+	h.grouping = false
+}
+
+func (h *editHistory) push(text string) {
+	if n := len(h.undo); n > 0 && h.undo[n-1] == text {
+		return
+	}
+	h.undo = append(h.undo, text)
+	if len(h.undo) > maxUndoSteps {
+		h.undo = h.undo[1:]
+	}
+}
+
+// undoFrom returns the text preceding current, if any
+func (h *editHistory) undoFrom(current string) (string, bool) {
+	if len(h.undo) == 0 {
+		return "", false
+	}
+	text := h.undo[len(h.undo)-1]
+	h.undo = h.undo[:len(h.undo)-1]
+	h.redo = append(h.redo, current)
+	h.grouping = false
+	return text, true
+}
+
+// redoFrom returns the text undone last, if any
+func (h *editHistory) redoFrom(current string) (string, bool) {
+	if len(h.redo) == 0 {
+		return "", false
+	}
+	text := h.redo[len(h.redo)-1]
+	h.redo = h.redo[:len(h.redo)-1]
+	h.undo = append(h.undo, current)
+	h.grouping = false
+	return text, true
+}
+
+func (m *model) undoEdit() {
+	if text, ok := m.editHistory.undoFrom(m.textArea.Value()); ok {
+		m.textArea.SetValue(text)
+		return
+	}
+	m.message = tr("Nothing to undo")
+}
+
+func (m *model) redoEdit() {
+	if text, ok := m.editHistory.redoFrom(m.textArea.Value()); ok {
+		m.textArea.SetValue(text)
+		return
+	}
+	m.message = tr("Nothing to redo")
+}
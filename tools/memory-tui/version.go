@@ -0,0 +1,157 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/charmbracelet/bubbletea"
+)
+
+// Build information, set at link time:
+//
+//	go build -ldflags "-X main.version=1.2.0 -X main.commit=$(git rev-parse --short HEAD) -X main.buildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var (
+	version   = "dev"
+	commit    = ""
+	buildDate = ""
+)
+
+// buildInfo falls back to the VCS stamp of the Go toolchain for builds made
+// without ldflags (go install, go build from a checkout)
+func buildInfo() (ver, rev, date string) {
+	ver, rev, date = version, commit, buildDate
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ver, rev, date
+	}
+	if ver == "dev" && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		ver = info.Main.Version
+	}
+	for _, setting := range info.Settings {
+		switch {
+		case setting.Key == "vcs.revision" && rev == "":
+			rev = setting.Value
+			if len(rev) > 12 {
+				rev = rev[:12]
+			}
+		case setting.Key == "vcs.time" && date == "":
+			date = setting.Value
+		}
+	}
+	return ver, rev, date
+}
+
+func versionString() string {
+	ver, rev, date := buildInfo()
+	s := "memory-tui " + ver
+	if rev != "" {
+		s += " (" + rev
+		if date != "" {
+			s += ", built " + date
+		}
+		s += ")"
+	}
+	return s
+}
+
+// userAgentTransport identifies the client version in the server logs and
+// sends the requests through the scheduler
+type userAgentTransport struct {
+	priority requestPriority
+	timeout  timeoutClass
+}
+
+func (t userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ver, _, _ := buildInfo()
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", fmt.Sprintf("memory-tui/%s (%s/%s)", ver, runtime.GOOS, runtime.GOARCH))
+	return withTimeout(req, t.timeout, func(req *http.Request) (*http.Response, error) {
+		return trackTransfer(req, func(req *http.Request) (*http.Response, error) {
+			return scheduler.roundTrip(req, t.priority)
+		})
+	})
+}
+
+// API version handshake with the server, advertised in /status.
+// Servers predating the handshake don't send it and are treated as version 1.
+const (
+	minAPIVersion = 1
+	maxAPIVersion = 2
+)
+
+type serverInfo struct {
+	APIVersion   int            `json:"api_version"`
+	Capabilities []string       `json:"capabilities"` // Optional features, such as capSoftDelete
+	Modules      []serverModule `json:"modules"`      // Nil when the server does not list them
+	ReadOnly     bool           `json:"read_only"`    // Granted by the role of the user
+}
+
+type serverInfoMsg struct {
+	info serverInfo
+	err  error
+}
+
+func fetchServerInfo(client *http.Client, serverURL string) (serverInfo, error) {
+	resp, err := client.Get(serverURL + "/status")
+	if err != nil {
+		return serverInfo{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return serverInfo{}, errSessionExpired
+	}
+	if resp.StatusCode != http.StatusOK {
+		return serverInfo{}, fmt.Errorf("status request failed: %s", resp.Status)
+	}
+
+	var info serverInfo
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return serverInfo{}, fmt.Errorf("invalid status response: %w", err)
+	}
+	if info.APIVersion == 0 {
+		info.APIVersion = 1
+	}
+	return info, nil
+}
+
+func (m model) checkServerVersion() tea.Cmd {
+	client, serverURL := m.client, m.serverURL
+	return func() tea.Msg {
+		info, err := fetchServerInfo(backgroundClient(client, timeoutPolling), serverURL)
+		return serverInfoMsg{info, err}
+	}
+}
+
+// versionWarning explains an API version outside the supported range
+func versionWarning(version int) string {
+	switch {
+	case version < minAPIVersion:
+		return fmt.Sprintf("Server API v%d is too old for this client (needs v%d to v%d): please upgrade the Tom server", version, minAPIVersion, maxAPIVersion)
+	case version > maxAPIVersion:
+		return fmt.Sprintf("Server API v%d is newer than %s supports (v%d to v%d): please upgrade it", version, versionString(), minAPIVersion, maxAPIVersion)
+	}
+	return ""
+}
+
+// UnmarshalJSON accepts both result shapes: the flat list sent by API v1
+// servers and the {"results": [...]} object sent by v2 ones
+func (r *MemoryResults) UnmarshalJSON(data []byte) error {
+	var list []Memory
+	if err := json.Unmarshal(data, &list); err == nil {
+		r.Results = list
+		return nil
+	}
+
+	var nested struct {
+		Results []Memory `json:"results"`
+	}
+	if err := json.Unmarshal(data, &nested); err != nil {
+		return err
+	}
+	r.Results = nested.Results
+	return nil
+}
@@ -0,0 +1,415 @@
+package main
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbletea"
+)
+
+// Content views as components: Update and View route keys, other messages and
+// rendering through this table instead of switching on the state, and the
+// keyboard focus always moves through setFocus/showView so exactly one text
+// field, the prompt or the view's own, holds the cursor.
+type viewComponent struct {
+	name   string
+	update func(m model, msg tea.KeyMsg) (tea.Model, tea.Cmd)
+	render func(m model) string
+
+	// Bindings handled by update, for the hint line and the help overlay
+	keys []key.Binding
+
+	// Views with a text field: focus gives it the keyboard, blur takes it
+	// back, forward passes it the other messages (cursor blinks...)
+	focus   func(m *model) tea.Cmd
+	blur    func(m *model)
+	forward func(m model, msg tea.Msg) (model, tea.Cmd)
+
+	// Modal views hide the prompt, which cannot get the focus then
+	modal bool
+}
+
+var viewComponents map[viewState]viewComponent
+
+// Registered in init since the handlers switch views themselves
+func init() {
+	viewComponents = map[viewState]viewComponent{
+		listView: {
+			name:   "Memory list",
+			update: model.updateListView,
+			render: model.renderListView,
+			forward: func(m model, msg tea.Msg) (model, tea.Cmd) {
+				var cmd tea.Cmd
+				m.list, cmd = m.list.Update(msg)
+				return m, cmd
+			},
+			keys: []key.Binding{
+				binding("a", "add", "a"),
+				binding("/", "search", "/"),
+				binding("Enter", "view detail", "enter"),
+				binding("Space", "mark", " "),
+				binding("m/'", "bookmark/jump", "m", "'"),
+				binding("Del", "delete", "delete", "backspace"),
+				binding("f", "filter", "f"),
+				binding("q", "quit", "q"),
+			},
+		},
+		detailView: {
+			name:   "Memory details",
+			update: model.updateDetailView,
+			render: model.renderDetailModal,
+			modal:  true,
+			keys:   []key.Binding{binding("Esc", "close", "esc", "q")},
+		},
+		addView: {
+			name:   "Add a memory",
+			update: model.updateAddView,
+			render: model.renderAddView,
+			focus:  func(m *model) tea.Cmd { return m.textArea.Focus() },
+			blur:   func(m *model) { m.textArea.Blur() },
+			forward: func(m model, msg tea.Msg) (model, tea.Cmd) {
+				var cmd tea.Cmd
+				m.textArea, cmd = m.textArea.Update(msg)
+				return m, cmd
+			},
+			keys: []key.Binding{
+				binding("Ctrl+S", "save", "ctrl+s"),
+				binding("Ctrl+Z/Ctrl+Y", "undo/redo", "ctrl+z", "ctrl+y", "ctrl+shift+z"),
+				binding("Esc", "cancel", "esc"),
+			},
+		},
+		searchView: {
+			name:   "Search",
+			update: model.updateSearchView,
+			render: model.renderSearchView,
+			focus:  func(m *model) tea.Cmd { return m.searchInput.Focus() },
+			blur:   func(m *model) { m.searchInput.Blur() },
+			forward: func(m model, msg tea.Msg) (model, tea.Cmd) {
+				var cmd tea.Cmd
+				m.searchInput, cmd = m.searchInput.Update(msg)
+				return m, cmd
+			},
+			keys: []key.Binding{
+				binding("Enter", "search", "enter"),
+				binding("Esc", "cancel", "esc"),
+			},
+		},
+		confirmDeleteView: {
+			name:   "Confirm delete",
+			update: model.updateConfirmDeleteView,
+			render: model.renderConfirmDeleteModal,
+			modal:  true,
+			keys: []key.Binding{
+				binding("Y", "delete", "y", "Y"),
+				binding("N", "cancel", "n", "N", "esc"),
+			},
+		},
+		confirmMergeView: {
+			name:   "Confirm merge",
+			update: model.updateConfirmMergeView,
+			render: model.renderConfirmMergeModal,
+			modal:  true,
+			keys: []key.Binding{
+				binding("Y", "merge", "y", "Y"),
+				binding("N", "back", "n", "N", "esc"),
+			},
+		},
+		redactView: {
+			name:   "Redaction",
+			update: model.updateRedactView,
+			render: model.renderRedactView,
+			modal:  true,
+			keys: []key.Binding{
+				binding("Enter", "send masked", "enter", "y"),
+				binding("O", "send as typed", "o"),
+				binding("Esc", "back", "esc", "n"),
+			},
+		},
+		secretView: {
+			name:   "Possible secret",
+			update: model.updateSecretView,
+			render: model.renderSecretView,
+			modal:  true,
+			keys: []key.Binding{
+				binding("Y", "store it", "y", "Y"),
+				binding("N", "skip it", "n", "N"),
+				binding("Esc", "back", "esc"),
+			},
+		},
+		templateView: {
+			name:   "Template",
+			update: model.updateTemplateView,
+			render: model.renderTemplateView,
+			focus: func(m *model) tea.Cmd {
+				if len(m.templateInputs) == 0 {
+					return nil
+				}
+				return m.templateInputs[m.templateFocus].Focus()
+			},
+			blur: func(m *model) {
+				if len(m.templateInputs) > 0 {
+					m.templateInputs[m.templateFocus].Blur()
+				}
+			},
+			forward: func(m model, msg tea.Msg) (model, tea.Cmd) {
+				if len(m.templateInputs) == 0 {
+					return m, nil
+				}
+				var cmd tea.Cmd
+				m.templateInputs[m.templateFocus], cmd = m.templateInputs[m.templateFocus].Update(msg)
+				return m, cmd
+			},
+			keys: []key.Binding{
+				binding("↑/↓/Enter", "previous/next field", "up", "down", "enter"),
+				binding("Ctrl+S", "save", "ctrl+s"),
+				binding("Esc", "cancel", "esc"),
+			},
+		},
+		chunkPreviewView: {
+			name:   "Chunk preview",
+			update: model.updateChunkPreviewView,
+			render: model.renderChunkPreviewView,
+			keys: []key.Binding{
+				binding("↑/↓", "select", "up", "down", "k", "j"),
+				binding("m", "merge with next", "m"),
+				binding("x", "drop", "x"),
+				binding("+/-", "chunk size", "+", "-"),
+				binding("o", "overlap", "o"),
+				binding("w", "keep whole", "w"),
+				binding("Enter", "save all", "enter", "ctrl+s"),
+				binding("Esc", "back", "esc"),
+			},
+		},
+		importView: {
+			name:   "Import",
+			update: model.updateImportView,
+			render: model.renderImportView,
+			keys: []key.Binding{
+				binding("↑/↓", "navigate", "up", "down", "k", "j"),
+				binding("Space", "include/skip", " ", "x"),
+				binding("a", "toggle all", "a"),
+				binding("Enter", "import selected", "enter", "ctrl+s"),
+				binding("Esc", "cancel", "esc"),
+			},
+		},
+		batchView: {
+			name:   "Batch",
+			update: model.updateBatchView,
+			render: model.renderBatchModal,
+			modal:  true,
+			keys:   []key.Binding{binding("Esc", "cancel remaining items", "esc", "c", "ctrl+c")},
+		},
+		logsView: {
+			name:   "Logs",
+			update: model.updateLogsView,
+			render: model.renderLogsView,
+			keys: append(append([]key.Binding{}, scrollKeys...),
+				binding("l", "level", "l"),
+				binding("r", "reload", "r"),
+				binding("Esc", "back", "esc", "q"),
+			),
+		},
+		auditView: {
+			name:   "Audit",
+			update: model.updateAuditView,
+			render: model.renderAuditView,
+			keys: append(append([]key.Binding{}, scrollKeys...),
+				binding("r", "reload", "r"),
+				binding("Esc", "back", "esc", "q"),
+			),
+		},
+		mapView: {
+			name:   "Memory map",
+			update: model.updateMapView,
+			render: model.renderMapView,
+			keys: []key.Binding{
+				binding("←/→", "select a cluster", "left", "right", "h", "l", "up", "down", "k", "j"),
+				binding("1-9", "select a cluster", "1", "2", "3", "4", "5", "6", "7", "8", "9"),
+				binding("Enter", "list its memories", "enter"),
+				binding("Esc", "back", "esc", "q"),
+			},
+		},
+		pluginOutputView: {
+			name:   "Plugin output",
+			update: model.updatePluginOutputView,
+			render: model.renderPluginOutputView,
+			keys: []key.Binding{
+				scrollKeys[0],
+				binding("Esc", "back", "esc", "q", "enter"),
+			},
+		},
+		askView: {
+			name:   "Answer",
+			update: model.updateAskView,
+			render: model.renderAskModal,
+			modal:  true,
+			keys: append(append([]key.Binding{}, scrollKeys...),
+				binding("p", "pin", "p"),
+				binding("Esc", "close", "esc", "q", "enter"),
+			),
+		},
+		digestView: {
+			name:   "Digest",
+			update: model.updateDigestView,
+			render: model.renderDigestView,
+			keys: append(append([]key.Binding{}, scrollKeys...),
+				binding("r", "reload", "r"),
+				binding("p", "pin", "p"),
+				binding("Esc", "back", "esc", "q"),
+			),
+		},
+		timersView: {
+			name:   "Timers",
+			update: model.updateTimersView,
+			render: model.renderTimersModal,
+			modal:  true,
+			keys: []key.Binding{
+				binding("↑/↓", "select", "up", "down", "k", "j"),
+				binding("x", "cancel the timer", "x", "d", "delete"),
+				binding("Esc", "close", "esc", "q"),
+			},
+		},
+		haView: {
+			name:   "Shortcuts",
+			update: model.updateHAView,
+			render: model.renderHAModal,
+			modal:  true,
+			keys: []key.Binding{
+				binding("↑/↓", "select", "up", "down", "k", "j"),
+				binding("Enter", "run", "enter"),
+				binding("Esc", "close", "esc", "q"),
+			},
+		},
+		mailView: {
+			name:   "Mail",
+			update: model.updateMailView,
+			render: model.renderMailView,
+			keys: []key.Binding{
+				binding("↑/↓", "select", "up", "down", "k", "j"),
+				binding("m", "mark read", "m"),
+				binding("a", "archive", "a"),
+				binding("d", "draft a reply", "d"),
+				binding("y", "copy the draft", "y"),
+				binding("p", "pin the draft", "p"),
+				binding("r", "reload", "r"),
+				binding("Esc", "back", "esc", "q"),
+			},
+		},
+		newsView: {
+			name:   "News",
+			update: model.updateNewsView,
+			render: model.renderNewsView,
+			keys: []key.Binding{
+				binding("↑/↓", "select", "up", "down", "k", "j"),
+				binding("s", "summarize", "s"),
+				binding("m", "mark read", "m"),
+				binding("o", "open in the browser", "o"),
+				binding("p", "pin", "p"),
+				binding("r", "reload", "r"),
+				binding("Esc", "back", "esc", "q"),
+			},
+		},
+		whoView: {
+			name:   "Profile",
+			update: model.updateWhoView,
+			render: model.renderWhoView,
+			keys:   append(append([]key.Binding{}, scrollKeys...), binding("Esc", "back", "esc", "q")),
+		},
+		pinsView: {
+			name:   "Pins",
+			update: model.updatePinsView,
+			render: model.renderPinsView,
+			keys: append([]key.Binding{
+				binding("↑/↓", "select", "up", "down", "k", "j"),
+				binding("y", "copy", "y"),
+				binding("x", "unpin", "x", "delete"),
+				binding("e", "export to the clipboard", "e"),
+				binding("Esc", "back", "esc", "q"),
+			}, scrollKeys[1:]...),
+		},
+		scratchView: {
+			name:   "Scratch",
+			update: model.updateScratchView,
+			render: model.renderScratchView,
+			keys: append([]key.Binding{
+				binding("↑/↓", "select", "up", "down", "k", "j"),
+				binding("Enter", "edit", "enter", "e"),
+				binding("y", "copy the path", "y"),
+				binding("Esc", "back", "esc", "q"),
+			}, scrollKeys[1:]...),
+		},
+		runConfirmView: {
+			name:   "Confirm run",
+			update: model.updateRunConfirmView,
+			render: model.renderRunConfirmModal,
+			modal:  true,
+			keys: append([]key.Binding{
+				binding("Y", "run", "y", "Y"),
+				binding("N", "cancel", "n", "N", "esc"),
+			}, scrollKeys...),
+		},
+		runOutputView: {
+			name:   "Run output",
+			update: model.updateRunOutputView,
+			render: model.renderRunOutputView,
+			keys: append([]key.Binding{
+				binding("s", "send to Tom", "s"),
+				binding("y", "copy", "y"),
+				binding("Esc", "back", "esc", "q"),
+			}, scrollKeys...),
+		},
+		applyView: {
+			name:   "Apply diff",
+			update: model.updateApplyView,
+			render: model.renderApplyView,
+			keys: append([]key.Binding{
+				binding("Y", "apply", "y", "Y"),
+				binding("O", "apply, replacing the .orig backups", "O"),
+				binding("N", "cancel", "n", "N", "esc", "q"),
+			}, scrollKeys...),
+		},
+		filesView: {
+			name:   "Files",
+			update: model.updateFilesView,
+			render: model.renderFilesView,
+			keys: append([]key.Binding{
+				binding("c", "include in the next prompt", "c"),
+				binding("y", "copy", "y"),
+				binding("Esc", "back", "esc", "q"),
+			}, scrollKeys...),
+		},
+		helpView: {
+			name:   "Help",
+			update: model.updateHelpView,
+			render: model.renderHelpView,
+			modal:  true,
+			keys:   append(append([]key.Binding{}, scrollKeys...), binding("Esc", "close", "esc", "q", "?", "f1")),
+		},
+	}
+}
+
+// setFocus moves the keyboard focus between the prompt and the current view
+func (m *model) setFocus(f focusState) tea.Cmd {
+	c := viewComponents[m.state]
+	if f == focusPrompt && !c.modal {
+		m.focus = focusPrompt
+		if c.blur != nil {
+			c.blur(m)
+		}
+		return m.promptInput.Focus()
+	}
+
+	m.focus = focusContent
+	m.promptInput.Blur()
+	if c.focus != nil {
+		return c.focus(m)
+	}
+	return nil
+}
+
+// showView switches to the view state, giving it the focus
+func (m *model) showView(state viewState) tea.Cmd {
+	if c := viewComponents[m.state]; c.blur != nil {
+		c.blur(m)
+	}
+	m.state = state
+	return m.setFocus(focusContent)
+}
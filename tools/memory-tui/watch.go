@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbletea"
+)
+
+// Watched files: /watch GLOB watches the local files matching GLOB and tells
+// Tom their new content when they change, so a long session working on them
+// stays in sync. A change is told once the file stays the same for one
+// check, not halfway through a save; /watch lists the globs and /unwatch
+// [GLOB] stops watching one, all of them by default. Nothing is watched
+// until asked for.
+const (
+	watchInterval = 2 * time.Second
+	watchMaxFiles = 50
+	watchPrompt   = "Files I am working on changed. Keep their new content in mind for what follows, " +
+		"and reply OK alone.\n"
+)
+
+// fileStamp tells when a file changed
+type fileStamp struct {
+	modTime int64
+	size    int64
+}
+
+type watchTickMsg struct{}
+
+type watchScanMsg struct{ stamps map[string]fileStamp }
+
+type watchToldMsg struct {
+	paths []string
+	err   error
+}
+
+func runWatchCommand(m model, args string) (tea.Model, tea.Cmd) {
+	if args == "" {
+		if len(m.watches) == 0 {
+			m.message = tr("Nothing watched, /watch GLOB watches files")
+		} else {
+			m.message = tr("Watching %s", strings.Join(m.watches, ", "))
+		}
+		return m, nil
+	}
+	glob, err := filepath.Abs(expandHome(args))
+	if err == nil {
+		_, err = filepath.Glob(glob)
+	}
+	if err != nil {
+		m.err = fmt.Errorf("invalid glob %q: %w", args, err)
+		return m, nil
+	}
+	for _, w := range m.watches {
+		if w == glob {
+			m.message = tr("Already watching %s", glob)
+			return m, nil
+		}
+	}
+
+	stamps := scanWatches([]string{glob})
+	if len(stamps) > watchMaxFiles {
+		m.err = fmt.Errorf("%s matches %d files, %d at most", glob, len(stamps), watchMaxFiles)
+		return m, nil
+	}
+	// The files as they are now are known, only their changes are told
+	if m.watchSeen == nil {
+		m.watchSeen, m.watchTold = map[string]fileStamp{}, map[string]fileStamp{}
+	}
+	for path, stamp := range stamps {
+		m.watchSeen[path], m.watchTold[path] = stamp, stamp
+	}
+	m.watches = append(m.watches, glob)
+	logf("INFO", "watching %s", glob)
+	m.message = tr("Watching %s, %d files for now", glob, len(stamps))
+	if m.watchTicking {
+		return m, nil
+	}
+	m.watchTicking = true
+	return m, watchTick()
+}
+
+func runUnwatchCommand(m model, args string) (tea.Model, tea.Cmd) {
+	if args == "" {
+		m.watches = nil
+		m.watchSeen, m.watchTold = nil, nil
+		m.message = tr("Nothing watched anymore")
+		return m, nil
+	}
+	glob, _ := filepath.Abs(expandHome(args))
+	for i, w := range m.watches {
+		if w == glob {
+			m.watches = append(m.watches[:i:i], m.watches[i+1:]...)
+			m.message = tr("%s not watched anymore", glob)
+			return m, nil
+		}
+	}
+	m.message = tr("%s is not watched", glob)
+	return m, nil
+}
+
+func watchTick() tea.Cmd {
+	return tea.Tick(tickInterval(watchInterval), func(time.Time) tea.Msg { return watchTickMsg{} })
+}
+
+// scanWatches stamps the files matching the globs
+func scanWatches(globs []string) map[string]fileStamp {
+	stamps := map[string]fileStamp{}
+	for _, glob := range globs {
+		paths, _ := filepath.Glob(glob)
+		for _, path := range paths {
+			info, err := os.Stat(path)
+			if err != nil || info.IsDir() {
+				continue
+			}
+			stamps[path] = fileStamp{info.ModTime().UnixNano(), info.Size()}
+		}
+	}
+	return stamps
+}
+
+func (m model) handleWatchTick() (tea.Model, tea.Cmd) {
+	if len(m.watches) == 0 {
+		m.watchTicking = false
+		return m, nil
+	}
+	globs := append([]string{}, m.watches...)
+	return m, func() tea.Msg { return watchScanMsg{scanWatches(globs)} }
+}
+
+// handleWatchScan tells Tom the files changed and stable since the last scan
+func (m model) handleWatchScan(msg watchScanMsg) (tea.Model, tea.Cmd) {
+	if len(m.watches) == 0 {
+		m.watchTicking = false
+		return m, nil
+	}
+	var changed, deleted []string
+	for path, stamp := range msg.stamps {
+		if seen, ok := m.watchSeen[path]; ok && seen == stamp && m.watchTold[path] != stamp {
+			changed = append(changed, path)
+			m.watchTold[path] = stamp
+		}
+	}
+	for path := range m.watchTold {
+		if _, ok := msg.stamps[path]; ok {
+			continue
+		}
+		if _, ok := m.watchSeen[path]; !ok {
+			// Gone for two scans, or no longer watched
+			if m.watched(path) {
+				deleted = append(deleted, path)
+			}
+			delete(m.watchTold, path)
+		}
+	}
+	m.watchSeen = msg.stamps
+	if len(changed) == 0 && len(deleted) == 0 {
+		return m, watchTick()
+	}
+	sort.Strings(changed)
+	sort.Strings(deleted)
+	return m, tea.Batch(watchTick(), tellWatched(m.api, changed, deleted))
+}
+
+// watched tells whether a glob watched matches the path
+func (m model) watched(path string) bool {
+	for _, glob := range m.watches {
+		if ok, _ := filepath.Match(glob, path); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// tellWatched sends the new content of the files to Tom
+func tellWatched(api *MemoryAPI, changed, deleted []string) tea.Cmd {
+	return func() tea.Msg {
+		var b strings.Builder
+		b.WriteString(watchPrompt)
+		for _, path := range changed {
+			data, err := os.ReadFile(path)
+			switch {
+			case err != nil:
+				fmt.Fprintf(&b, "\nFile %s changed but cannot be read: %v\n", path, err)
+			case bytes.IndexByte(data, 0) >= 0:
+				fmt.Fprintf(&b, "\nFile %s changed, it is a binary file.\n", path)
+			case len(data) > fileContextLimit:
+				fmt.Fprintf(&b, "\nFile %s changed, new content, cut at %d characters:\n```\n%s\n```\n", path, fileContextLimit, data[:fileContextLimit])
+			default:
+				fmt.Fprintf(&b, "\nFile %s changed, new content:\n```\n%s\n```\n", path, strings.TrimRight(string(data), "\n"))
+			}
+		}
+		for _, path := range deleted {
+			fmt.Fprintf(&b, "\nFile %s was deleted.\n", path)
+		}
+		_, err := api.Process(b.String())
+		return watchToldMsg{append(changed, deleted...), err}
+	}
+}
+
+func (m model) handleWatchTold(msg watchToldMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		logf("WARN", "watch: %v", msg.err)
+		m.err = fmt.Errorf("failed to tell Tom about %s: %w", strings.Join(msg.paths, ", "), msg.err)
+		return m, nil
+	}
+	names := make([]string, len(msg.paths))
+	for i, path := range msg.paths {
+		names[i] = filepath.Base(path)
+		logf("INFO", "told Tom %s changed", path)
+	}
+	m.message = tr("👁 Tom knows %s changed", strings.Join(names, ", "))
+	return m, nil
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/bubbletea"
+)
+
+// Profile cards: /who NAME searches the memories for the person and keeps
+// the ones naming them, in their text or in the metadata the templates
+// write ("name", "owner", "subject"): the template ones make the facts and
+// the birthday, the others the related memories. On servers with the
+// contacts module Tom also looks the person up in the contacts.
+const (
+	whoSearchLimit   = 50
+	moduleContacts   = "contacts"
+	whoContactPrompt = "Look up %q in my contacts with get_contacts. Reply with what the contact holds, one " +
+		"\"field: value\" per line and nothing else, or NONE when there is no such contact."
+)
+
+// whoMetadataKeys are the template fields naming a person
+var whoMetadataKeys = []string{"name", "owner", "subject"}
+
+type whoProfile struct {
+	name     string
+	contact  string // From the contacts module, empty when none
+	facts    []Memory
+	birthday string // As stored, YYYY-MM-DD or MM-DD
+	related  []Memory
+	err      error // Contacts lookup failure, the memories still show
+}
+
+type whoMsg struct{ profile whoProfile }
+
+func runWhoCommand(m model, args string) (tea.Model, tea.Cmd) {
+	m.loading = true
+	m.setFocus(focusContent)
+	api := m.api
+	contacts := api.supports(moduleContacts)
+	return m, func() tea.Msg {
+		var (
+			wg      sync.WaitGroup
+			contact string
+			lookup  error
+		)
+		if contacts {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				answer, err := api.Process(fmt.Sprintf(whoContactPrompt, args))
+				if err != nil {
+					lookup = err
+					return
+				}
+				if answer = strings.TrimSpace(answer); !strings.EqualFold(answer, "NONE") {
+					contact = answer
+				}
+			}()
+		}
+		memories, err := api.SearchMemories(args, whoSearchLimit)
+		wg.Wait()
+		if err != nil {
+			return errMsg{err}
+		}
+		profile := buildProfile(args, memories)
+		profile.contact, profile.err = contact, lookup
+		return whoMsg{profile}
+	}
+}
+
+// buildProfile sorts the search results naming the person into facts and
+// related memories, newest first, dropping the others
+func buildProfile(name string, memories []Memory) whoProfile {
+	profile := whoProfile{name: name}
+	for _, mem := range memories {
+		switch {
+		case namedInMetadata(mem, name):
+			if date, ok := mem.Metadata["date"].(string); ok && mem.Metadata["template"] == "birthday" {
+				profile.birthday = date
+				continue
+			}
+			profile.facts = append(profile.facts, mem)
+		case containsFold(mem.Memory, name):
+			profile.related = append(profile.related, mem)
+		}
+	}
+	sort.SliceStable(profile.related, func(i, j int) bool { return profile.related[i].CreatedAt > profile.related[j].CreatedAt })
+	return profile
+}
+
+func namedInMetadata(mem Memory, name string) bool {
+	for _, key := range whoMetadataKeys {
+		if value, ok := mem.Metadata[key].(string); ok && containsFold(value, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// nextBirthday returns the next occurrence of the date, the days until it
+// and the age then, zero when the year is unknown
+func nextBirthday(date string, now time.Time) (next time.Time, days, age int, err error) {
+	var year int
+	day, err := time.Parse("2006-01-02", date)
+	if err == nil {
+		year = day.Year()
+	} else if day, err = time.Parse("01-02", date); err != nil {
+		return next, 0, 0, fmt.Errorf("invalid birthday %q", date)
+	}
+	now = now.Local()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	next = time.Date(today.Year(), day.Month(), day.Day(), 0, 0, 0, 0, today.Location())
+	if next.Before(today) {
+		next = next.AddDate(1, 0, 0)
+	}
+	if year > 0 {
+		age = next.Year() - year
+	}
+	// Rounded, a day across a DST change lasting 23 or 25 hours
+	days = int(next.Sub(today).Hours()/24 + 0.5)
+	return next, days, age, nil
+}
+
+func (m model) openProfile(msg whoMsg) (tea.Model, tea.Cmd) {
+	m.loading = false
+	m.profile = msg.profile
+	m.layoutProfile()
+	cmd := m.showView(whoView)
+	return m, cmd
+}
+
+// layoutProfile renders the card to the terminal width
+func (m *model) layoutProfile() {
+	width := max(20, m.width-10)
+	p := m.profile
+	var b strings.Builder
+	section := func(title string) {
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(selectedItemStyle.Render(tr(title)))
+		b.WriteString("\n")
+	}
+
+	if p.contact != "" {
+		section("Contact")
+		b.WriteString(wrapText(p.contact, width))
+		b.WriteString("\n")
+	} else if p.err != nil {
+		section("Contact")
+		b.WriteString(loginErrorStyle.Render(wrapText("❌ "+friendlyError(p.err).Error(), width)))
+		b.WriteString("\n")
+	}
+	if p.birthday != "" {
+		section("Birthday")
+		if next, days, age, err := nextBirthday(p.birthday, time.Now()); err != nil {
+			b.WriteString(p.birthday)
+		} else {
+			line := tr("%s, in %d days", next.Format("Mon 2006-01-02"), days)
+			if days == 0 {
+				line = tr("Today")
+			}
+			if age > 0 {
+				line += " " + tr("(turns %d)", age)
+			}
+			b.WriteString(line)
+		}
+		b.WriteString("\n")
+	}
+	if len(p.facts) > 0 {
+		section("Facts")
+		for _, mem := range p.facts {
+			b.WriteString(wrapText("• "+mem.Memory, width))
+			b.WriteString("\n")
+		}
+	}
+	if len(p.related) > 0 {
+		section("Related memories")
+		for _, mem := range p.related {
+			b.WriteString(wrapText("• "+mem.Memory, width))
+			b.WriteString(" " + helpStyle.Render(formatTime(mem.CreatedAt)))
+			b.WriteString("\n")
+		}
+	}
+	if b.Len() == 0 {
+		b.WriteString(helpStyle.Render(tr("Tom knows nothing about %s yet", p.name)))
+	}
+	m.profileViewport = viewport.New(max(20, m.width-8), max(5, m.height-12))
+	m.profileViewport.SetContent(b.String())
+}
+
+func (m model) updateWhoView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		cmd := m.showView(listView)
+		return m, cmd
+	case "g", "home":
+		m.profileViewport.GotoTop()
+		return m, nil
+	case "G", "end":
+		m.profileViewport.GotoBottom()
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.profileViewport, cmd = m.profileViewport.Update(msg)
+	return m, cmd
+}
+
+func (m model) renderWhoView() string {
+	style := contentBoxStyle.Width(m.width - 4)
+	if m.focus == focusContent {
+		style = contentBoxFocusedStyle.Width(m.width - 4)
+	}
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("👤 " + m.profile.name))
+	b.WriteString(" " + helpStyle.Render(tr("%d memories", len(m.profile.facts)+len(m.profile.related))))
+	b.WriteString("\n\n")
+	b.WriteString(m.profileViewport.View())
+	b.WriteString("\n\n")
+	b.WriteString(m.keyHint(""))
+	return style.Render(b.String())
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// The hotkey is read from the Linux input devices (/dev/input/event*), which
+// works the same under X11, Wayland and the console, but needs read access
+// to them: the user in the "input" group, usually. Each read is an
+// input_event, a timeval followed by the type, code and value of the event.
+const (
+	evKey          = 1 // Type of key events
+	keyReleased    = 0
+	keyPressed     = 1
+	keyboardsGlob  = "/dev/input/by-id/*-event-kbd"
+	inputEventSize = 2*strconv.IntSize/8 + 8
+)
+
+// keyCodes are the codes of the keys suited to a hotkey, from
+// linux/input-event-codes.h; any other key is given by its code
+var keyCodes = map[string]uint16{
+	"KEY_LEFTCTRL": 29, "KEY_LEFTALT": 56, "KEY_CAPSLOCK": 58, "KEY_SCROLLLOCK": 70,
+	"KEY_RIGHTCTRL": 97, "KEY_RIGHTALT": 100, "KEY_INSERT": 110, "KEY_PAUSE": 119,
+	"KEY_LEFTMETA": 125, "KEY_RIGHTMETA": 126, "KEY_COMPOSE": 127,
+	"KEY_F1": 59, "KEY_F2": 60, "KEY_F3": 61, "KEY_F4": 62, "KEY_F5": 63, "KEY_F6": 64,
+	"KEY_F7": 65, "KEY_F8": 66, "KEY_F9": 67, "KEY_F10": 68, "KEY_F11": 87, "KEY_F12": 88,
+}
+
+func parseKey(name string) (uint16, error) {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if !strings.HasPrefix(name, "KEY_") {
+		name = "KEY_" + name
+	}
+	if code, ok := keyCodes[name]; ok {
+		return code, nil
+	}
+	if n := strings.TrimPrefix(name, "KEY_F"); n != name {
+		// F13 to F24, often free for a hotkey
+		if i, err := strconv.Atoi(n); err == nil && i >= 13 && i <= 24 {
+			return uint16(183 + i - 13), nil
+		}
+	}
+	code, err := strconv.ParseUint(strings.TrimPrefix(name, "KEY_"), 10, 16)
+	if err != nil {
+		return 0, fmt.Errorf("unknown key %q, give its code from linux/input-event-codes.h", name)
+	}
+	return uint16(code), nil
+}
+
+// keyboards returns the devices to read, those configured or else every
+// keyboard
+func keyboards(configured []string) ([]string, error) {
+	if len(configured) > 0 {
+		return configured, nil
+	}
+	devices, _ := filepath.Glob(keyboardsGlob)
+	if len(devices) == 0 {
+		return nil, fmt.Errorf("no keyboard in %s, set devices", keyboardsGlob)
+	}
+	return devices, nil
+}
+
+// watchKey sends true on presses of the key on a device and false on its
+// releases, the repeats of a held key ignored, until ctx ends or the device
+// fails
+func watchKey(ctx context.Context, device string, code uint16, events chan<- bool) error {
+	f, err := os.Open(device)
+	if err != nil {
+		return err
+	}
+	go func() {
+		<-ctx.Done()
+		f.Close()
+	}()
+
+	buf := make([]byte, inputEventSize)
+	for {
+		if _, err := io.ReadFull(f, buf); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("%s: %w", device, err)
+		}
+		event := buf[inputEventSize-8:]
+		if binary.NativeEndian.Uint16(event) != evKey || binary.NativeEndian.Uint16(event[2:]) != code {
+			continue
+		}
+		switch int32(binary.NativeEndian.Uint32(event[4:])) {
+		case keyPressed:
+			events <- true
+		case keyReleased:
+			events <- false
+		}
+	}
+}
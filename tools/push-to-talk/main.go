@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/Mickael-Roger/tom/tools/memory-tui/pkg/chatclient"
+)
+
+// Push to talk: while a key is held, the microphone is recorded; on its
+// release the recording is transcribed on this machine, sent to Tom as a
+// spoken request in the "push-to-talk" conversation, a history he keeps
+// apart from his apps, and his answer shown in a notification and read aloud.
+// Tom has no speech recognition of his own, his apps transcribing on the
+// device too: the recorder, transcriber and speaker are commands of the
+// configuration. SIGUSR1 starts and stops a recording as the key does, for
+// a key binding of the desktop instead of the input devices.
+const (
+	clientType        = "push-to-talk"
+	defaultKey        = "KEY_RIGHTCTRL"
+	noKey             = "none" // Key of the configuration for SIGUSR1 alone
+	minRecording      = 400 * time.Millisecond
+	processTimeout    = 3 * time.Minute
+	nothingHeard      = "Nothing heard, hold the key while speaking."
+	defaultConfigName = "push-to-talk.json"
+)
+
+type config struct {
+	ServerURL  string   `json:"server_url"`
+	Username   string   `json:"username"`
+	Password   string   `json:"password"`
+	Key        string   `json:"key"`     // KEY_ name or code, or "none"
+	Devices    []string `json:"devices"` // Every keyboard when empty
+	Record     []string `json:"record"`
+	Transcribe []string `json:"transcribe"`
+	Speak      []string `json:"speak"`
+	Notify     []string `json:"notify"`
+	Mute       bool     `json:"mute"` // Notifications only
+}
+
+type talker struct {
+	cfg  config
+	chat *chatclient.Client
+
+	answering sync.Mutex // One request to Tom at a time
+
+	mu       sync.Mutex
+	speaking context.CancelFunc
+}
+
+func main() {
+	home, _ := os.UserHomeDir()
+	configPath := flag.String("config", filepath.Join(home, ".tom", defaultConfigName), "configuration file")
+	flag.Parse()
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	chat := chatclient.New(cfg.ServerURL, clientType)
+	chat.Credentials = &chatclient.Credentials{Username: cfg.Username, Password: cfg.Password}
+	t := &talker{cfg: cfg, chat: chat}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	events := make(chan bool)
+	if cfg.Key != noKey {
+		code, err := parseKey(cfg.Key)
+		if err != nil {
+			log.Fatal(err)
+		}
+		devices, err := keyboards(cfg.Devices)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, device := range devices {
+			go func(device string) {
+				if err := watchKey(ctx, device, code, events); err != nil {
+					log.Printf("keyboard: %v", err)
+				}
+			}(device)
+		}
+		log.Printf("hold %s to talk to Tom (%d keyboards)", cfg.Key, len(devices))
+	}
+	toggles := make(chan os.Signal, 1)
+	signal.Notify(toggles, syscall.SIGUSR1)
+
+	var rec *recording
+	for {
+		var pressed bool
+		select {
+		case <-ctx.Done():
+			if rec != nil {
+				rec.stop()
+				rec.remove()
+			}
+			return
+		case pressed = <-events:
+		case <-toggles:
+			pressed = rec == nil
+		}
+
+		switch {
+		case pressed && rec == nil:
+			t.silence()
+			if rec, err = startRecording(cfg.Record); err != nil {
+				log.Print(err)
+				t.notify("Tom", "Cannot record: "+err.Error())
+			}
+		case !pressed && rec != nil:
+			if d := rec.stop(); d < minRecording {
+				rec.remove()
+			} else {
+				go t.answer(ctx, rec)
+			}
+			rec = nil
+		}
+	}
+}
+
+func loadConfig(path string) (config, error) {
+	cfg := config{Key: defaultKey, Record: defaultRecord, Speak: defaultSpeak, Notify: defaultNotify}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+	switch {
+	case cfg.ServerURL == "":
+		return cfg, fmt.Errorf("%s: no server_url", path)
+	case cfg.Username == "":
+		return cfg, fmt.Errorf("%s: no username", path)
+	case len(cfg.Transcribe) == 0:
+		return cfg, fmt.Errorf("%s: no transcribe command, such as whisper.cpp's", path)
+	case len(cfg.Record) == 0, len(cfg.Speak) == 0, len(cfg.Notify) == 0:
+		return cfg, fmt.Errorf("%s: empty record, speak or notify command", path)
+	}
+	return cfg, nil
+}
+
+// answer transcribes a recording, asks Tom and tells his answer
+func (t *talker) answer(ctx context.Context, rec *recording) {
+	defer rec.remove()
+	t.answering.Lock()
+	defer t.answering.Unlock()
+
+	text, err := transcribe(ctx, t.cfg.Transcribe, rec.file)
+	if err != nil {
+		log.Printf("transcribe: %v", err)
+		t.notify("Tom", "Cannot transcribe: "+err.Error())
+		return
+	}
+	if text == "" {
+		t.notify("Tom", nothingHeard)
+		return
+	}
+	log.Printf("heard: %s", text)
+
+	pctx, cancel := context.WithTimeout(ctx, processTimeout)
+	defer cancel()
+	answer, err := t.chat.Process(pctx, text, chatclient.WithSound(!t.cfg.Mute))
+	if err != nil {
+		log.Printf("process: %v", err)
+		t.notify("Tom", "Tom could not answer: "+err.Error())
+		return
+	}
+	t.notify("Tom", answer.Text)
+	if t.cfg.Mute {
+		return
+	}
+	speech := answer.Speech
+	if speech == "" {
+		speech = answer.Text
+	}
+
+	sctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	t.mu.Lock()
+	t.speaking = cancel
+	t.mu.Unlock()
+	if err := speak(sctx, t.cfg.Speak, speech); err != nil {
+		log.Printf("speak: %v", err)
+	}
+}
+
+// silence stops the answer being read, the user speaking again
+func (t *talker) silence() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.speaking != nil {
+		t.speaking()
+		t.speaking = nil
+	}
+}
+
+func (t *talker) notify(title, body string) {
+	if err := notify(t.cfg.Notify, title, body); err != nil {
+		log.Printf("notify: %v", err)
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// The audio goes through external commands, given as argument lists in the
+// configuration, {file} standing for the recording and {title} and {body}
+// for the notification: the recorder runs while the key is held and is
+// stopped by SIGINT, the transcriber prints the text of the recording, the
+// speaker reads the answer on its standard input.
+var (
+	defaultRecord = []string{"arecord", "-q", "-f", "S16_LE", "-r", "16000", "-c", "1", "{file}"}
+	defaultSpeak  = []string{"espeak-ng", "-v", "fr", "--stdin"}
+	defaultNotify = []string{"notify-send", "-a", "Tom", "{title}", "{body}"}
+)
+
+const (
+	commandTimeout   = time.Minute
+	notifyBodyLength = 600 // Notifications show little more
+)
+
+// command builds a command, its placeholders replaced
+func command(ctx context.Context, args []string, values map[string]string) *exec.Cmd {
+	expanded := make([]string, len(args))
+	for i, arg := range args {
+		for key, value := range values {
+			arg = strings.ReplaceAll(arg, "{"+key+"}", value)
+		}
+		expanded[i] = arg
+	}
+	return exec.CommandContext(ctx, expanded[0], expanded[1:]...)
+}
+
+// recording is a recorder running while the key is held
+type recording struct {
+	cmd     *exec.Cmd
+	file    string
+	started time.Time
+}
+
+func startRecording(args []string) (*recording, error) {
+	f, err := os.CreateTemp("", "push-to-talk-*.wav")
+	if err != nil {
+		return nil, err
+	}
+	f.Close()
+	cmd := command(context.Background(), args, map[string]string{"file": f.Name()})
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		os.Remove(f.Name())
+		return nil, fmt.Errorf("%s: %w", args[0], err)
+	}
+	return &recording{cmd: cmd, file: f.Name(), started: time.Now()}, nil
+}
+
+// stop ends the recording, returning how long it lasted
+func (r *recording) stop() time.Duration {
+	r.cmd.Process.Signal(syscall.SIGINT)
+	r.cmd.Wait() // Interrupted, its error says nothing
+	return time.Since(r.started)
+}
+
+func (r *recording) remove() {
+	os.Remove(r.file)
+}
+
+// transcribe returns the text the transcriber prints for a recording
+func transcribe(ctx context.Context, args []string, file string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+	var stderr bytes.Buffer
+	cmd := command(ctx, args, map[string]string{"file": file})
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("%s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.Join(strings.Fields(string(out)), " "), nil
+}
+
+// speak reads a text aloud, until done or ctx ends
+func speak(ctx context.Context, args []string, text string) error {
+	cmd := command(ctx, args, nil)
+	cmd.Stdin = strings.NewReader(text)
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("%s: %w", args[0], err)
+	}
+	return nil
+}
+
+func notify(args []string, title, body string) error {
+	if r := []rune(body); len(r) > notifyBodyLength {
+		body = string(r[:notifyBodyLength]) + "…"
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := command(ctx, args, map[string]string{"title": title, "body": body}).Run(); err != nil {
+		return fmt.Errorf("%s: %w", args[0], err)
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// command is a slash command, as Slack and Mattermost post it
+type command struct {
+	Token       string
+	TeamID      string
+	UserID      string
+	UserName    string
+	Command     string
+	Text        string
+	ResponseURL string
+
+	body []byte // For the Slack signature
+}
+
+// slackSkew is how old a signed Slack request can be
+const slackSkew = 5 * time.Minute
+
+func readCommand(w http.ResponseWriter, r *http.Request) (command, error) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBody))
+	if err != nil {
+		return command{}, err
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return command{}, err
+	}
+	return command{
+		Token:       form.Get("token"),
+		TeamID:      form.Get("team_id"),
+		UserID:      form.Get("user_id"),
+		UserName:    form.Get("user_name"),
+		Command:     form.Get("command"),
+		Text:        form.Get("text"),
+		ResponseURL: form.Get("response_url"),
+		body:        body,
+	}, nil
+}
+
+// verifySlack checks the signature Slack puts on its requests with the
+// signing secret of the app
+func verifySlack(h http.Header, body []byte, secret string, now time.Time) bool {
+	ts, err := strconv.ParseInt(h.Get("X-Slack-Request-Timestamp"), 10, 64)
+	if err != nil || now.Sub(time.Unix(ts, 0)).Abs() > slackSkew {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "v0:%d:%s", ts, body)
+	return hmac.Equal([]byte("v0="+hex.EncodeToString(mac.Sum(nil))), []byte(h.Get("X-Slack-Signature")))
+}
+
+func constantTimeEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// response is the message both platforms take, as the answer to the
+// command or posted to its response URL
+type response struct {
+	ResponseType string `json:"response_type"`
+	Text         string `json:"text"`
+}
+
+func newResponse(ws *workspace, inChannel bool, text string) response {
+	r := response{ResponseType: "ephemeral", Text: text}
+	if inChannel {
+		r.ResponseType = "in_channel"
+	}
+	if ws.Platform == "slack" {
+		r.Text = markdownToMrkdwn(r.Text)
+	}
+	return r
+}
+
+func writeResponse(w http.ResponseWriter, ws *workspace, inChannel bool, text string) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(newResponse(ws, inChannel, text))
+}
+
+func (g *gateway) postResponse(ctx context.Context, ws *workspace, responseURL string, inChannel bool, text string) error {
+	data, err := json.Marshal(newResponse(ws, inChannel, text))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, responseURL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// Tom answers in Markdown, which Mattermost shows as is. Slack has its own
+// mrkdwn: single stars for bold, underscores for italic, <url|text> links,
+// no headings, and &, < and > escaped. Code blocks are the same.
+var (
+	mdLink    = regexp.MustCompile(`\[([^\]\n]+)\]\((https?://[^)\s]+)\)`)
+	mdBold    = regexp.MustCompile(`\*\*([^*\n]+)\*\*|__([^_\n]+)__`)
+	mdItalic  = regexp.MustCompile(`(^|[^\w*])\*([^*\s][^*\n]*)\*`)
+	mdHeading = regexp.MustCompile(`^#{1,6}\s+(.*)$`)
+	mdBullet  = regexp.MustCompile(`^(\s*)[-*+]\s+`)
+
+	mrkdwnEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+)
+
+func markdownToMrkdwn(md string) string {
+	lines := strings.Split(md, "\n")
+	inCode := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCode = !inCode
+			lines[i] = "```"
+			continue
+		}
+		line = mrkdwnEscaper.Replace(line)
+		if inCode {
+			lines[i] = line
+			continue
+		}
+		// A quote is the only > kept, as Slack reads it
+		if strings.HasPrefix(line, "&gt; ") {
+			line = ">" + strings.TrimPrefix(line, "&gt;")
+		}
+		if m := mdHeading.FindStringSubmatch(line); m != nil {
+			line = "\x01" + m[1] + "\x01"
+		}
+		line = mdBullet.ReplaceAllString(line, "$1• ")
+		line = mdLink.ReplaceAllString(line, "<$2|$1>")
+		line = mdBold.ReplaceAllString(line, "\x01$1$2\x01")
+		line = mdItalic.ReplaceAllString(line, "${1}_${2}_")
+		lines[i] = strings.ReplaceAll(line, "\x01", "*")
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/Mickael-Roger/tom/tools/memory-tui/pkg/chatclient"
+)
+
+// Slash-command gateway: a /tom command of a Slack or Mattermost workspace
+// posts here, the gateway acknowledges it at once, as both want an answer
+// within 3 seconds, and posts Tom's answer to the response URL of the
+// command. Each workspace is known by the token of its command and talks
+// as a Tom profile, its users possibly as their own. The conversation of a
+// profile is the history Tom keeps for clientType, apart from his apps,
+// which /tom reset clears.
+const (
+	clientType        = "slash-command"
+	defaultListen     = ":8090"
+	processTimeout    = 3 * time.Minute
+	maxBody           = 64 << 10
+	defaultConfigName = "slash-gateway.json"
+	usageMessage      = "Usage: %s QUESTION, or %s reset to start a new conversation."
+	thinkingMessage   = "Asking Tom…"
+)
+
+type config struct {
+	Listen     string      `json:"listen"`
+	ServerURL  string      `json:"server_url"`
+	Workspaces []workspace `json:"workspaces"`
+}
+
+type workspace struct {
+	Name          string                `json:"name"`
+	Platform      string                `json:"platform"`       // "slack" or "mattermost"
+	Token         string                `json:"token"`          // Of the slash command
+	SigningSecret string                `json:"signing_secret"` // Slack only, checked when set
+	InChannel     bool                  `json:"in_channel"`     // Answers seen by the channel, not the user only
+	Profile       tomProfile            `json:"profile"`
+	Users         map[string]tomProfile `json:"users"` // By user ID, instead of profile
+}
+
+// tomProfile is the Tom user a workspace or one of its users talks as
+type tomProfile struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type gateway struct {
+	cfg    config
+	client *http.Client // For the response URLs
+
+	mu    sync.Mutex
+	chats map[string]*chatclient.Client // By Tom username
+}
+
+func main() {
+	home, _ := os.UserHomeDir()
+	configPath := flag.String("config", filepath.Join(home, ".tom", defaultConfigName), "configuration file")
+	flag.Parse()
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	g := &gateway{cfg: cfg, client: &http.Client{Timeout: 30 * time.Second}, chats: map[string]*chatclient.Client{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/command", g.handleCommand)
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) { fmt.Fprintln(w, "ok") })
+	srv := &http.Server{Addr: cfg.Listen, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdown)
+	}()
+	log.Printf("slash commands of %d workspaces on %s/command, forwarded to %s", len(cfg.Workspaces), cfg.Listen, cfg.ServerURL)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal(err)
+	}
+}
+
+func loadConfig(path string) (config, error) {
+	var cfg config
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+	if cfg.Listen == "" {
+		cfg.Listen = defaultListen
+	}
+	switch {
+	case cfg.ServerURL == "":
+		return cfg, fmt.Errorf("%s: no server_url", path)
+	case len(cfg.Workspaces) == 0:
+		return cfg, fmt.Errorf("%s: no workspaces", path)
+	}
+	tokens := map[string]bool{}
+	for i, ws := range cfg.Workspaces {
+		name := ws.Name
+		if name == "" {
+			name = fmt.Sprintf("workspace %d", i+1)
+		}
+		switch {
+		case ws.Platform != "slack" && ws.Platform != "mattermost":
+			return cfg, fmt.Errorf("%s: %s: platform must be slack or mattermost", path, name)
+		case ws.Token == "":
+			return cfg, fmt.Errorf("%s: %s: no token", path, name)
+		case tokens[ws.Token]:
+			return cfg, fmt.Errorf("%s: %s: token already used by another workspace", path, name)
+		case ws.Profile.Username == "" && len(ws.Users) == 0:
+			return cfg, fmt.Errorf("%s: %s: no profile nor users", path, name)
+		}
+		tokens[ws.Token] = true
+	}
+	return cfg, nil
+}
+
+// workspace returns the workspace of a command token, nil when unknown
+func (g *gateway) workspace(token string) *workspace {
+	for i := range g.cfg.Workspaces {
+		if constantTimeEqual(g.cfg.Workspaces[i].Token, token) {
+			return &g.cfg.Workspaces[i]
+		}
+	}
+	return nil
+}
+
+// chat returns the Tom client of a profile, shared by the workspaces and
+// users talking as it
+func (g *gateway) chat(profile tomProfile) *chatclient.Client {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if c, ok := g.chats[profile.Username]; ok {
+		return c
+	}
+	c := chatclient.New(g.cfg.ServerURL, clientType)
+	c.Credentials = &chatclient.Credentials{Username: profile.Username, Password: profile.Password}
+	g.chats[profile.Username] = c
+	return c
+}
+
+// profile returns the Tom profile a user of the workspace talks as, false
+// when the user is not allowed
+func (ws *workspace) profile(userID string) (tomProfile, bool) {
+	if p, ok := ws.Users[userID]; ok {
+		return p, true
+	}
+	return ws.Profile, ws.Profile.Username != ""
+}
+
+func (g *gateway) handleCommand(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	cmd, err := readCommand(w, r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ws := g.workspace(cmd.Token)
+	if ws == nil {
+		log.Printf("refused a command with an unknown token from team %s", cmd.TeamID)
+		http.Error(w, "unknown token", http.StatusUnauthorized)
+		return
+	}
+	if ws.Platform == "slack" && ws.SigningSecret != "" && !verifySlack(r.Header, cmd.body, ws.SigningSecret, time.Now()) {
+		log.Printf("%s: refused a command with an invalid signature", ws.Name)
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+	profile, ok := ws.profile(cmd.UserID)
+	if !ok {
+		log.Printf("%s: refused a command of user %s (%s), not in users", ws.Name, cmd.UserID, cmd.UserName)
+		writeResponse(w, ws, false, fmt.Sprintf("You are not allowed to talk to Tom here. Your user ID is %s.", cmd.UserID))
+		return
+	}
+
+	text := strings.TrimSpace(cmd.Text)
+	switch {
+	case text == "" || text == "help":
+		writeResponse(w, ws, false, fmt.Sprintf(usageMessage, cmd.Command, cmd.Command))
+		return
+	case cmd.ResponseURL == "":
+		http.Error(w, "no response_url", http.StatusBadRequest)
+		return
+	}
+	writeResponse(w, ws, false, thinkingMessage)
+	go g.answer(ws, cmd, g.chat(profile), text)
+}
+
+// answer asks Tom and posts his answer to the response URL of the command
+func (g *gateway) answer(ws *workspace, cmd command, chat *chatclient.Client, text string) {
+	ctx, cancel := context.WithTimeout(context.Background(), processTimeout)
+	defer cancel()
+
+	var reply string
+	inChannel := ws.InChannel
+	if text == "reset" {
+		reply = "New conversation started."
+		inChannel = false
+		if err := chat.Reset(ctx); err != nil {
+			reply = failMessage(ws, cmd, err)
+		}
+	} else if answer, err := chat.Process(ctx, text); err != nil {
+		reply, inChannel = failMessage(ws, cmd, err), false
+	} else {
+		reply = answer.Text
+		if inChannel {
+			reply = quote(cmd.UserName, text) + reply
+		}
+	}
+	if err := g.postResponse(ctx, ws, cmd.ResponseURL, inChannel, reply); err != nil {
+		log.Printf("%s: response of user %s: %v", ws.Name, cmd.UserID, err)
+	}
+}
+
+// failMessage tells the user Tom could not answer, the details going to the
+// log
+func failMessage(ws *workspace, cmd command, err error) string {
+	log.Printf("%s: request of user %s: %v", ws.Name, cmd.UserID, err)
+	if errors.Is(err, chatclient.ErrInvalidCredentials) {
+		return "The Tom profile of this workspace refuses its password."
+	}
+	return "Tom could not answer, try again later."
+}
+
+// quote recalls the question above an answer the channel sees
+func quote(user, text string) string {
+	return fmt.Sprintf("> @%s: %s\n\n", user, strings.ReplaceAll(text, "\n", "\n> "))
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/Mickael-Roger/tom/tools/memory-tui/pkg/chatclient"
+)
+
+// Telegram bridge: forwards the messages of the Telegram users mapped to a
+// Tom profile to Tom, in the conversation of the "telegram" client, and
+// sends his answers back, and delivers Tom's reminders as Telegram messages.
+// Messages from users not mapped are refused, their ID logged so they can
+// be added to the configuration.
+const (
+	clientType        = "telegram"
+	defaultReminders  = 15 * time.Minute // Between two reads of the reminders
+	retryDelay        = 5 * time.Second  // After a failed getUpdates
+	processTimeout    = 3 * time.Minute
+	startMessage      = "Hello, I forward your messages to Tom. /reset starts a new conversation."
+	refusedMessage    = "You are not allowed to talk to Tom here. Your Telegram ID is %d."
+	resetDoneMessage  = "New conversation started."
+	defaultConfigName = "telegram-bridge.json"
+)
+
+type config struct {
+	TelegramToken    string                `json:"telegram_token"` // Or $TELEGRAM_BOT_TOKEN
+	ServerURL        string                `json:"server_url"`
+	Users            map[string]tomProfile `json:"users"`             // By Telegram user ID
+	ReminderInterval int                   `json:"reminder_interval"` // Seconds, 0 for the default, -1 for no reminders
+}
+
+// tomProfile is the Tom user a Telegram user talks as
+type tomProfile struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type bridge struct {
+	cfg      config
+	telegram *telegram
+	state    *bridgeState
+
+	mu    sync.Mutex
+	chats map[int64]*chatclient.Client // By Telegram user ID
+}
+
+func main() {
+	home, _ := os.UserHomeDir()
+	configPath := flag.String("config", filepath.Join(home, ".tom", defaultConfigName), "configuration file")
+	flag.Parse()
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	state, err := loadState(statePath(*configPath))
+	if err != nil {
+		log.Fatal(err)
+	}
+	b := &bridge{cfg: cfg, telegram: newTelegram(cfg.TelegramToken), state: state, chats: map[int64]*chatclient.Client{}}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	log.Printf("forwarding Telegram messages of %d users to %s", len(cfg.Users), cfg.ServerURL)
+	if interval := cfg.reminderInterval(); interval > 0 {
+		go b.runReminders(ctx, interval)
+	}
+	b.run(ctx)
+}
+
+func loadConfig(path string) (config, error) {
+	var cfg config
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+	if token := os.Getenv("TELEGRAM_BOT_TOKEN"); token != "" {
+		cfg.TelegramToken = token
+	}
+	switch {
+	case cfg.TelegramToken == "":
+		return cfg, fmt.Errorf("%s: no telegram_token, nor $TELEGRAM_BOT_TOKEN", path)
+	case cfg.ServerURL == "":
+		return cfg, fmt.Errorf("%s: no server_url", path)
+	case len(cfg.Users) == 0:
+		return cfg, fmt.Errorf("%s: no users, map Telegram user IDs to Tom profiles", path)
+	}
+	for id := range cfg.Users {
+		if _, err := strconv.ParseInt(id, 10, 64); err != nil {
+			return cfg, fmt.Errorf("%s: invalid Telegram user ID %q", path, id)
+		}
+	}
+	return cfg, nil
+}
+
+func (cfg config) reminderInterval() time.Duration {
+	if cfg.ReminderInterval == 0 {
+		return defaultReminders
+	}
+	return time.Duration(cfg.ReminderInterval) * time.Second
+}
+
+// chat returns the Tom client of a Telegram user, nil when not mapped
+func (b *bridge) chat(userID int64) *chatclient.Client {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if c, ok := b.chats[userID]; ok {
+		return c
+	}
+	profile, ok := b.cfg.Users[strconv.FormatInt(userID, 10)]
+	if !ok {
+		return nil
+	}
+	c := chatclient.New(b.cfg.ServerURL, clientType)
+	c.Credentials = &chatclient.Credentials{Username: profile.Username, Password: profile.Password}
+	b.chats[userID] = c
+	return c
+}
+
+// run polls Telegram until ctx ends, each message handled apart so a slow
+// answer does not hold the others
+func (b *bridge) run(ctx context.Context) {
+	for ctx.Err() == nil {
+		updates, err := b.telegram.updates(ctx, b.state.offset())
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("getUpdates: %v", err)
+				sleep(ctx, retryDelay)
+			}
+			continue
+		}
+		for _, u := range updates {
+			b.state.setOffset(u.UpdateID + 1)
+			if u.Message != nil && u.Message.From != nil && u.Message.Text != "" {
+				go b.handle(ctx, *u.Message)
+			}
+		}
+	}
+}
+
+func (b *bridge) handle(ctx context.Context, msg telegramMessage) {
+	chat := b.chat(msg.From.ID)
+	if chat == nil {
+		log.Printf("refused a message of Telegram user %d (@%s), not in users", msg.From.ID, msg.From.Username)
+		b.reply(ctx, msg.Chat.ID, fmt.Sprintf(refusedMessage, msg.From.ID))
+		return
+	}
+	b.state.setChat(msg.From.ID, msg.Chat.ID)
+
+	ctx, cancel := context.WithTimeout(ctx, processTimeout)
+	defer cancel()
+	command, _, _ := strings.Cut(msg.Text, " ")
+	switch command {
+	case "/start":
+		b.reply(ctx, msg.Chat.ID, startMessage)
+		return
+	case "/reset":
+		if err := chat.Reset(ctx); err != nil {
+			b.fail(ctx, msg, err)
+			return
+		}
+		b.reply(ctx, msg.Chat.ID, resetDoneMessage)
+		return
+	}
+
+	b.telegram.typing(ctx, msg.Chat.ID)
+	answer, err := chat.Process(ctx, msg.Text)
+	if err != nil {
+		b.fail(ctx, msg, err)
+		return
+	}
+	b.reply(ctx, msg.Chat.ID, answer.Text)
+}
+
+func (b *bridge) reply(ctx context.Context, chatID int64, text string) {
+	if err := b.telegram.send(ctx, chatID, text); err != nil {
+		log.Printf("sendMessage to chat %d: %v", chatID, err)
+	}
+}
+
+// fail tells the user Tom could not answer, the details going to the log
+func (b *bridge) fail(ctx context.Context, msg telegramMessage, err error) {
+	log.Printf("request of Telegram user %d: %v", msg.From.ID, err)
+	text := "Tom could not answer, try again later."
+	if errors.Is(err, chatclient.ErrInvalidCredentials) {
+		text = "The Tom profile of this bridge refuses its password."
+	}
+	b.reply(ctx, msg.Chat.ID, text)
+}
+
+func sleep(ctx context.Context, d time.Duration) {
+	select {
+	case <-ctx.Done():
+	case <-time.After(d):
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// Tom answers in Markdown, which Telegram reads in its own dialect only
+// with every punctuation escaped: the answers go as the few HTML tags
+// Telegram takes instead. Code blocks and spans, bold, italic, links and
+// headings are kept, lists get bullets and the rest is plain text.
+var (
+	mdCodeSpan = regexp.MustCompile("`([^`\n]+)`")
+	mdLink     = regexp.MustCompile(`\[([^\]\n]+)\]\((https?://[^)\s]+)\)`)
+	mdBold     = regexp.MustCompile(`\*\*([^*\n]+)\*\*|__([^_\n]+)__`)
+	mdItalic   = regexp.MustCompile(`(^|[^\w*])\*([^*\s][^*\n]*)\*`)
+	mdHeading  = regexp.MustCompile(`^#{1,6}\s+(.*)$`)
+	mdBullet   = regexp.MustCompile(`^(\s*)[-*+]\s+`)
+)
+
+// markdownToHTML converts a Markdown answer to Telegram HTML
+func markdownToHTML(md string) string {
+	var b strings.Builder
+	var code []string
+	inCode, lang := false, ""
+	for _, line := range strings.Split(md, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			if inCode {
+				writeCodeBlock(&b, lang, code)
+				inCode, code = false, nil
+			} else {
+				inCode, lang = true, strings.TrimSpace(strings.TrimPrefix(trimmed, "```"))
+			}
+			continue
+		}
+		if inCode {
+			code = append(code, line)
+			continue
+		}
+		b.WriteString(inlineToHTML(line))
+		b.WriteString("\n")
+	}
+	if inCode {
+		writeCodeBlock(&b, lang, code)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func writeCodeBlock(b *strings.Builder, lang string, code []string) {
+	if lang != "" {
+		fmt.Fprintf(b, `<pre><code class="language-%s">`, html.EscapeString(lang))
+	} else {
+		b.WriteString("<pre><code>")
+	}
+	b.WriteString(html.EscapeString(strings.Join(code, "\n")))
+	b.WriteString("</code></pre>\n")
+}
+
+// inlineToHTML converts a line out of the code blocks
+func inlineToHTML(line string) string {
+	if m := mdHeading.FindStringSubmatch(line); m != nil {
+		return "<b>" + html.EscapeString(m[1]) + "</b>"
+	}
+	line = mdBullet.ReplaceAllString(line, "$1• ")
+
+	// The code spans are set aside so nothing formats inside them
+	var spans []string
+	line = mdCodeSpan.ReplaceAllStringFunc(line, func(s string) string {
+		spans = append(spans, "<code>"+html.EscapeString(s[1:len(s)-1])+"</code>")
+		return fmt.Sprintf("\x00%d\x00", len(spans)-1)
+	})
+	line = html.EscapeString(line)
+	line = mdLink.ReplaceAllString(line, `<a href="$2">$1</a>`)
+	line = mdBold.ReplaceAllString(line, "<b>$1$2</b>")
+	line = mdItalic.ReplaceAllString(line, "$1<i>$2</i>")
+	for i, span := range spans {
+		line = strings.Replace(line, fmt.Sprintf("\x00%d\x00", i), span, 1)
+	}
+	return line
+}
+
+// splitMessage cuts a text in pieces of limit characters at most, between
+// paragraphs or lines when it can
+func splitMessage(text string, limit int) []string {
+	var parts []string
+	for len([]rune(text)) > limit {
+		runes := []rune(text)
+		cut := string(runes[:limit])
+		at := strings.LastIndex(cut, "\n\n")
+		if at < len(cut)/2 {
+			at = strings.LastIndex(cut, "\n")
+		}
+		if at < len(cut)/2 {
+			at = len(cut)
+		}
+		parts = append(parts, strings.TrimRight(text[:at], "\n"))
+		text = strings.TrimLeft(text[at:], "\n")
+	}
+	if strings.TrimSpace(text) != "" {
+		parts = append(parts, text)
+	}
+	return parts
+}
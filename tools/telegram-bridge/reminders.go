@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Mickael-Roger/tom/tools/memory-tui/pkg/chatclient"
+)
+
+// Reminders: Tom pushes them to his own apps only, so the bridge reads the
+// pending reminders of each user from Tom every reminder_interval, by a
+// stateless request that stays out of the conversation of the messages, and
+// sends each of them to the user's chat once due. Users who never wrote to
+// the bot have no chat to get them.
+const (
+	reminderCheck  = 30 * time.Second // Between two checks of the due reminders
+	reminderPrompt = "List my pending reminders with list_reminders. Reply with a JSON array of " +
+		`{"id", "text", "datetime"} objects, datetime as YYYY-MM-DD HH:MM in my time zone, and nothing ` +
+		"else; [] when there is none."
+	reminderLayout = "2006-01-02 15:04"
+)
+
+type reminder struct {
+	ID       json.RawMessage `json:"id"` // A number or a string
+	Text     string          `json:"text"`
+	Datetime string          `json:"datetime"`
+}
+
+func (r reminder) key(userID int64) string {
+	return fmt.Sprintf("%d/%s/%s", userID, strings.Trim(string(r.ID), `"`), r.Datetime)
+}
+
+func (b *bridge) runReminders(ctx context.Context, interval time.Duration) {
+	pending := map[int64][]reminder{}
+	var readAt time.Time
+	ticker := time.NewTicker(reminderCheck)
+	defer ticker.Stop()
+	for {
+		if time.Since(readAt) >= interval {
+			readAt = time.Now()
+			for userID := range b.state.chats() {
+				reminders, err := b.readReminders(ctx, userID)
+				if err != nil {
+					log.Printf("reminders of Telegram user %d: %v", userID, err)
+					continue
+				}
+				pending[userID] = reminders
+			}
+		}
+		b.deliverReminders(ctx, pending, time.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// readReminders asks Tom for the pending reminders of a user
+func (b *bridge) readReminders(ctx context.Context, userID int64) ([]reminder, error) {
+	profile, ok := b.cfg.Users[fmt.Sprint(userID)]
+	if !ok {
+		return nil, nil
+	}
+	c := chatclient.New(b.cfg.ServerURL, clientType)
+	c.Credentials = &chatclient.Credentials{Username: profile.Username, Password: profile.Password}
+
+	ctx, cancel := context.WithTimeout(ctx, processTimeout)
+	defer cancel()
+	answer, err := c.Process(ctx, reminderPrompt, chatclient.WithoutHistory())
+	if err != nil {
+		return nil, err
+	}
+	start, end := strings.Index(answer.Text, "["), strings.LastIndex(answer.Text, "]")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("unexpected answer from Tom: %.200s", answer.Text)
+	}
+	var reminders []reminder
+	if err := json.Unmarshal([]byte(answer.Text[start:end+1]), &reminders); err != nil {
+		return nil, fmt.Errorf("unexpected answer from Tom: %w", err)
+	}
+	return reminders, nil
+}
+
+// deliverReminders sends the reminders due and not delivered yet
+func (b *bridge) deliverReminders(ctx context.Context, pending map[int64][]reminder, now time.Time) {
+	chats := b.state.chats()
+	for userID, reminders := range pending {
+		for _, r := range reminders {
+			due, err := time.ParseInLocation(reminderLayout, r.Datetime, time.Local)
+			if err != nil || due.After(now) || !b.state.deliver(r.key(userID), now) {
+				continue
+			}
+			log.Printf("reminder %s delivered to Telegram user %d", r.ID, userID)
+			b.reply(ctx, chats[userID], "⏰ "+r.Text)
+		}
+	}
+}
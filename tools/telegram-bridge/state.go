@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// bridgeState is what the bridge remembers across restarts, next to its
+// configuration: the Telegram updates already read, the chat of each user
+// for the reminders and the reminders delivered
+type bridgeState struct {
+	path string
+	mu   sync.Mutex
+
+	Offset    int64                `json:"offset"`
+	Chats     map[int64]int64      `json:"chats"`     // Chat by Telegram user ID
+	Delivered map[string]time.Time `json:"delivered"` // Reminder keys, when delivered
+}
+
+const deliveredKept = 7 * 24 * time.Hour
+
+func statePath(configPath string) string {
+	return strings.TrimSuffix(configPath, ".json") + ".state.json"
+}
+
+func loadState(path string) (*bridgeState, error) {
+	s := &bridgeState{path: path, Chats: map[int64]int64{}, Delivered: map[string]time.Time{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, err
+	}
+	if s.Chats == nil {
+		s.Chats = map[int64]int64{}
+	}
+	if s.Delivered == nil {
+		s.Delivered = map[string]time.Time{}
+	}
+	return s, nil
+}
+
+// save writes the state, the caller holding the lock
+func (s *bridgeState) save() {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err == nil {
+		err = os.WriteFile(s.path, data, 0600)
+	}
+	if err != nil {
+		log.Printf("state: %v", err)
+	}
+}
+
+func (s *bridgeState) offset() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.Offset
+}
+
+func (s *bridgeState) setOffset(offset int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Offset = offset
+	s.save()
+}
+
+func (s *bridgeState) setChat(userID, chatID int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Chats[userID] == chatID {
+		return
+	}
+	s.Chats[userID] = chatID
+	s.save()
+}
+
+func (s *bridgeState) chats() map[int64]int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	chats := make(map[int64]int64, len(s.Chats))
+	for user, chat := range s.Chats {
+		chats[user] = chat
+	}
+	return chats
+}
+
+// deliver records a reminder as delivered, false when it already was
+func (s *bridgeState) deliver(key string, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.Delivered[key]; ok {
+		return false
+	}
+	for k, at := range s.Delivered {
+		if now.Sub(at) > deliveredKept {
+			delete(s.Delivered, k)
+		}
+	}
+	s.Delivered[key] = now
+	s.save()
+	return true
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// The few methods of the Telegram Bot API the bridge needs, over plain HTTP
+const (
+	telegramAPI        = "https://api.telegram.org/bot"
+	telegramPollWait   = 50          // Seconds getUpdates waits for a message
+	telegramMessageMax = 4096        // Characters of a message
+	telegramParseMode  = "HTML"      // Of the answers, see markdown.go
+	telegramTimeout    = time.Minute // Above telegramPollWait
+)
+
+type telegram struct {
+	token  string
+	client *http.Client
+}
+
+type telegramUpdate struct {
+	UpdateID int64            `json:"update_id"`
+	Message  *telegramMessage `json:"message"`
+}
+
+type telegramMessage struct {
+	MessageID int64         `json:"message_id"`
+	From      *telegramUser `json:"from"`
+	Chat      telegramChat  `json:"chat"`
+	Text      string        `json:"text"`
+}
+
+type telegramUser struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+}
+
+type telegramChat struct {
+	ID int64 `json:"id"`
+}
+
+// telegramError is a request the Bot API refused
+type telegramError struct {
+	Code        int
+	Description string
+}
+
+func (e *telegramError) Error() string {
+	return fmt.Sprintf("telegram: %d %s", e.Code, e.Description)
+}
+
+func newTelegram(token string) *telegram {
+	return &telegram{token: token, client: &http.Client{Timeout: telegramTimeout}}
+}
+
+// call sends a Bot API method and decodes its result into v
+func (t *telegram) call(ctx context.Context, method string, params, v interface{}) error {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, telegramAPI+t.token+"/"+method, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var r struct {
+		OK          bool            `json:"ok"`
+		Result      json.RawMessage `json:"result"`
+		ErrorCode   int             `json:"error_code"`
+		Description string          `json:"description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return fmt.Errorf("telegram: %s: %w", resp.Status, err)
+	}
+	if !r.OK {
+		return &telegramError{r.ErrorCode, r.Description}
+	}
+	if v == nil {
+		return nil
+	}
+	return json.Unmarshal(r.Result, v)
+}
+
+// updates waits for the updates after offset
+func (t *telegram) updates(ctx context.Context, offset int64) ([]telegramUpdate, error) {
+	var updates []telegramUpdate
+	err := t.call(ctx, "getUpdates", map[string]interface{}{
+		"offset":          offset,
+		"timeout":         telegramPollWait,
+		"allowed_updates": []string{"message"},
+	}, &updates)
+	return updates, err
+}
+
+// send sends a message in HTML, cut in pieces past the size of a message,
+// as plain text when Telegram refuses the HTML
+func (t *telegram) send(ctx context.Context, chatID int64, markdown string) error {
+	for _, part := range splitMessage(markdown, telegramMessageMax/2) {
+		err := t.call(ctx, "sendMessage", map[string]interface{}{
+			"chat_id":    chatID,
+			"text":       markdownToHTML(part),
+			"parse_mode": telegramParseMode,
+		}, nil)
+		if te, ok := err.(*telegramError); ok && te.Code == http.StatusBadRequest {
+			err = t.call(ctx, "sendMessage", map[string]interface{}{"chat_id": chatID, "text": part}, nil)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// typing shows the bot as typing while Tom thinks
+func (t *telegram) typing(ctx context.Context, chatID int64) {
+	_ = t.call(ctx, "sendChatAction", map[string]interface{}{"chat_id": chatID, "action": "typing"}, nil)
+}